@@ -14,13 +14,17 @@ import (
 	"os/signal"
 	"strings"
 	"syscall"
+	"time"
 
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/opentelemetry"
 
 	"github.com/urfave/cli/v2"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // argsSet checks that all the required arguments are set. args is a list of
@@ -95,6 +99,40 @@ func installSignals() (context.Context, context.CancelFunc) {
 	return ctx, cancel
 }
 
+// traceShutdownTimeout bounds the time a sub command waits for its spans to be exported before exiting
+const traceShutdownTimeout = 5 * time.Second
+
+// initTrace starts the tracer provider of a sub command if tracing is enabled. Nothing is written to the console
+// as the output of the sub commands run over SSH goes to the git client. The returned function flushes the spans.
+func initTrace(ctx context.Context) func() {
+	// the stdout of the sub commands is read by the git client, the telemetry can't be written to it
+	if !setting.Otel.Enabled || setting.Otel.Exporter == setting.OtelExporterStdout {
+		return func() {}
+	}
+
+	shutdown, err := opentelemetry.Init(ctx, &setting.Otel)
+	if err != nil {
+		log.Debug("Unable to initialize tracing: %v", err)
+		return func() {}
+	}
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), traceShutdownTimeout)
+		defer cancel()
+		if err := shutdown(ctx); err != nil {
+			log.Debug("Unable to flush the spans: %v", err)
+		}
+	}
+}
+
+// endSpan records the error returned by a sub command on its span and ends it
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
 func setupConsoleLogger(level log.Level, colorize bool, out io.Writer) {
 	if out != os.Stdout && out != os.Stderr {
 		panic("setupConsoleLogger can only be used with os.Stdout or os.Stderr")
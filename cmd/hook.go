@@ -19,8 +19,12 @@ import (
 	"code.gitea.io/gitea/modules/private"
 	repo_module "code.gitea.io/gitea/modules/repository"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/utils"
 
 	"github.com/urfave/cli/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -161,7 +165,7 @@ func (n *nilWriter) WriteString(s string) (int, error) {
 	return len(s), nil
 }
 
-func runHookPreReceive(c *cli.Context) error {
+func runHookPreReceive(c *cli.Context) (err error) {
 	if isInternal, _ := strconv.ParseBool(os.Getenv(repo_module.EnvIsInternal)); isInternal {
 		return nil
 	}
@@ -170,6 +174,10 @@ func runHookPreReceive(c *cli.Context) error {
 
 	setup(ctx, c.Bool("debug"))
 
+	defer initTrace(ctx)()
+	ctx, span := startHookSpan(ctx, "pre-receive")
+	defer func() { endSpan(span, err) }()
+
 	if len(os.Getenv("SSH_ORIGINAL_COMMAND")) == 0 {
 		if setting.OnlyAllowPushIfGiteaEnvironmentSet {
 			return fail(ctx, `Rejecting changes as Gitea environment not set.
@@ -293,17 +301,32 @@ Gitea or set your environment appropriately.`, "")
 	return nil
 }
 
+// startHookSpan starts the span of a hook, it continues the trace of the push passed in the environment by git
+func startHookSpan(ctx context.Context, hook string) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(utils.ContextFromEnv(ctx), "git hook "+hook,
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(
+			attribute.String("git.hook", hook),
+			attribute.String("repo.name", os.Getenv(repo_module.EnvRepoUsername)+"/"+os.Getenv(repo_module.EnvRepoName)),
+		),
+	)
+}
+
 func runHookUpdate(c *cli.Context) error {
 	// Update is empty and is kept only for backwards compatibility
 	return nil
 }
 
-func runHookPostReceive(c *cli.Context) error {
+func runHookPostReceive(c *cli.Context) (err error) {
 	ctx, cancel := installSignals()
 	defer cancel()
 
 	setup(ctx, c.Bool("debug"))
 
+	defer initTrace(ctx)()
+	ctx, span := startHookSpan(ctx, "post-receive")
+	defer func() { endSpan(span, err) }()
+
 	// First of all run update-server-info no matter what
 	if _, _, err := git.NewCommand(ctx, "update-server-info").RunStdString(nil); err != nil {
 		return fmt.Errorf("Failed to call 'git update-server-info': %w", err)
@@ -481,12 +504,16 @@ func pushOptions() map[string]string {
 	return opts
 }
 
-func runHookProcReceive(c *cli.Context) error {
+func runHookProcReceive(c *cli.Context) (err error) {
 	ctx, cancel := installSignals()
 	defer cancel()
 
 	setup(ctx, c.Bool("debug"))
 
+	defer initTrace(ctx)()
+	ctx, span := startHookSpan(ctx, "proc-receive")
+	defer func() { endSpan(span, err) }()
+
 	if len(os.Getenv("SSH_ORIGINAL_COMMAND")) == 0 {
 		if setting.OnlyAllowPushIfGiteaEnvironmentSet {
 			return fail(ctx, `Rejecting changes as Gitea environment not set.
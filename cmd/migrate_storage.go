@@ -5,6 +5,7 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -19,6 +20,7 @@ import (
 	packages_module "code.gitea.io/gitea/modules/packages"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/storage"
+	"code.gitea.io/gitea/modules/system"
 
 	"github.com/urfave/cli/v2"
 )
@@ -34,13 +36,26 @@ var CmdMigrateStorage = &cli.Command{
 			Name:    "type",
 			Aliases: []string{"t"},
 			Value:   "",
-			Usage:   "Type of stored files to copy.  Allowed types: 'attachments', 'lfs', 'avatars', 'repo-avatars', 'repo-archivers', 'packages', 'actions-log'",
+			Usage:   "Type of stored files to copy.  Allowed types: 'attachments', 'lfs', 'avatars', 'repo-avatars', 'repo-archivers', 'packages', 'actions-log', or 'all' with --online",
 		},
 		&cli.StringFlag{
 			Name:    "storage",
 			Aliases: []string{"s"},
 			Value:   "",
-			Usage:   "New storage type: local (default) or minio",
+			Usage:   "New storage type: local (default), minio or the name of a [storage.xxx] section of the configuration. With --online, the DUAL_WRITE_STORAGE_TYPE of the stored files is the default",
+		},
+		&cli.BoolFlag{
+			Name:  "online",
+			Usage: "Migrate while Gitea is running: verify the copies with checksums, save the progress in the database to resume an interrupted migration and report the differences between the storages",
+		},
+		&cli.BoolFlag{
+			Name:  "restart",
+			Usage: "Discard the saved progress of an interrupted online migration",
+		},
+		&cli.StringFlag{
+			Name:  "report",
+			Value: "",
+			Usage: "File the JSON report of an online migration is written to (default: standard output)",
 		},
 		&cli.StringFlag{
 			Name:    "path",
@@ -177,41 +192,12 @@ func runMigrateStorage(ctx *cli.Context) error {
 		return err
 	}
 
-	var dstStorage storage.ObjectStorage
-	var err error
-	switch strings.ToLower(ctx.String("storage")) {
-	case "":
-		fallthrough
-	case string(setting.LocalStorageType):
-		p := ctx.String("path")
-		if p == "" {
-			log.Fatal("Path must be given when storage is local")
-			return nil
-		}
-		dstStorage, err = storage.NewLocalStorage(
-			stdCtx,
-			&setting.Storage{
-				Path: p,
-			})
-	case string(setting.MinioStorageType):
-		dstStorage, err = storage.NewMinioStorage(
-			stdCtx,
-			&setting.Storage{
-				MinioConfig: setting.MinioStorageConfig{
-					Endpoint:           ctx.String("minio-endpoint"),
-					AccessKeyID:        ctx.String("minio-access-key-id"),
-					SecretAccessKey:    ctx.String("minio-secret-access-key"),
-					Bucket:             ctx.String("minio-bucket"),
-					Location:           ctx.String("minio-location"),
-					BasePath:           ctx.String("minio-base-path"),
-					UseSSL:             ctx.Bool("minio-use-ssl"),
-					InsecureSkipVerify: ctx.Bool("minio-insecure-skip-verify"),
-					ChecksumAlgorithm:  ctx.String("minio-checksum-algorithm"),
-				},
-			})
-	default:
-		return fmt.Errorf("unsupported storage type: %s", ctx.String("storage"))
+	tp := strings.ToLower(ctx.String("type"))
+	if ctx.Bool("online") {
+		return runOnlineMigrateStorage(ctx, stdCtx, tp)
 	}
+
+	dstStorage, _, err := newMigrateStorageDestination(ctx, storageMigrations[tp].name, nil)
 	if err != nil {
 		return err
 	}
@@ -226,7 +212,6 @@ func runMigrateStorage(ctx *cli.Context) error {
 		"actions-log":    migrateActionsLog,
 	}
 
-	tp := strings.ToLower(ctx.String("type"))
 	if m, ok := migratedMethods[tp]; ok {
 		if err := m(stdCtx, dstStorage); err != nil {
 			return err
@@ -237,3 +222,93 @@ func runMigrateStorage(ctx *cli.Context) error {
 
 	return fmt.Errorf("unsupported storage: %s", ctx.String("type"))
 }
+
+// newMigrateStorageDestination returns the storage the files of the named storage are copied to, with its settings
+func newMigrateStorageDestination(ctx *cli.Context, name string, srcSetting *setting.Storage) (storage.ObjectStorage, *setting.Storage, error) {
+	var cfg *setting.Storage
+	switch typ := strings.ToLower(ctx.String("storage")); typ {
+	case "":
+		if ctx.Bool("online") && srcSetting != nil && srcSetting.DualWrite != nil {
+			cfg = srcSetting.DualWrite
+			break
+		}
+		fallthrough
+	case string(setting.LocalStorageType):
+		p := ctx.String("path")
+		if p == "" {
+			return nil, nil, errors.New("path must be given when storage is local")
+		}
+		cfg = &setting.Storage{
+			Type: setting.LocalStorageType,
+			Path: p,
+		}
+	case string(setting.MinioStorageType):
+		cfg = &setting.Storage{
+			Type: setting.MinioStorageType,
+			MinioConfig: setting.MinioStorageConfig{
+				Endpoint:           ctx.String("minio-endpoint"),
+				AccessKeyID:        ctx.String("minio-access-key-id"),
+				SecretAccessKey:    ctx.String("minio-secret-access-key"),
+				Bucket:             ctx.String("minio-bucket"),
+				Location:           ctx.String("minio-location"),
+				BasePath:           ctx.String("minio-base-path"),
+				UseSSL:             ctx.Bool("minio-use-ssl"),
+				InsecureSkipVerify: ctx.Bool("minio-insecure-skip-verify"),
+				ChecksumAlgorithm:  ctx.String("minio-checksum-algorithm"),
+			},
+		}
+	default:
+		if name == "" {
+			return nil, nil, fmt.Errorf("unsupported storage type: %s", ctx.String("storage"))
+		}
+		var err error
+		if cfg, err = setting.GetStorageByType(name, typ); err != nil {
+			return nil, nil, fmt.Errorf("unsupported storage type: %s: %w", ctx.String("storage"), err)
+		}
+	}
+
+	dstStorage, err := storage.NewStorage(cfg.Type, cfg)
+	return dstStorage, cfg, err
+}
+
+func runOnlineMigrateStorage(ctx *cli.Context, stdCtx context.Context, tp string) error {
+	if err := system.Init(); err != nil {
+		return err
+	}
+
+	all := tp == "all"
+	types := []string{tp}
+	if all {
+		switch strings.ToLower(ctx.String("storage")) {
+		case string(setting.LocalStorageType), string(setting.MinioStorageType):
+			return errors.New("the storage must be configured in a [storage.xxx] section or by DUAL_WRITE_STORAGE_TYPE to migrate all the types")
+		}
+		types = storageMigrationTypes
+	}
+
+	reports := make([]*storageMigrationReport, 0, len(types))
+	for _, tp := range types {
+		m, ok := storageMigrations[tp]
+		if !ok {
+			return fmt.Errorf("unsupported storage: %s", tp)
+		}
+		srcSetting := m.setting()
+		if all && ctx.String("storage") == "" && srcSetting.DualWrite == nil {
+			log.Info("%s files have no dual write storage, they are not migrated", tp)
+			continue
+		}
+
+		dstStorage, dstSetting, err := newMigrateStorageDestination(ctx, m.name, srcSetting)
+		if err != nil {
+			return err
+		}
+		report, err := migrateStorageOnline(stdCtx, m, dstStorage, storageDestination(dstSetting), ctx.Bool("restart"))
+		if err != nil {
+			return err
+		}
+		log.Info("%s files have been migrated to the new storage: %d copied, %d skipped, %d failed, %d missing, %d with a different size", tp,
+			report.Copied, report.Skipped, len(report.Failed), len(report.MissingInDestination), len(report.SizeMismatch))
+		reports = append(reports, report)
+	}
+	return writeStorageMigrationReports(ctx.String("report"), reports)
+}
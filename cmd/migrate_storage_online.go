@@ -0,0 +1,424 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	git_model "code.gitea.io/gitea/models/git"
+	packages_model "code.gitea.io/gitea/models/packages"
+	repo_model "code.gitea.io/gitea/models/repo"
+	system_model "code.gitea.io/gitea/models/system"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	packages_module "code.gitea.io/gitea/modules/packages"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
+	"code.gitea.io/gitea/modules/system"
+
+	"xorm.io/builder"
+)
+
+// storageMigrationSaveInterval is the number of objects migrated between two saves of the progress
+const storageMigrationSaveInterval = 100
+
+var errChecksumMismatch = errors.New("checksum mismatch")
+
+// storageMigration describes the objects of a storage type which are migrated online
+type storageMigration struct {
+	// name is the name of the storage in the settings, used to find its base path in the destination storage
+	name    string
+	storage func() storage.ObjectStorage
+	setting func() *setting.Storage
+	// iterate calls fn with the path of every object referenced by a record whose id is greater than afterID,
+	// in the order of the ids
+	iterate func(ctx context.Context, afterID int64, fn func(id int64, path string) error) error
+}
+
+// storageMigrationTypes is the order in which the storage types are migrated with the type "all"
+var storageMigrationTypes = []string{"attachments", "lfs", "avatars", "repo-avatars", "repo-archivers", "packages", "actions-log"}
+
+var storageMigrations = map[string]storageMigration{
+	"attachments": {
+		name:    "attachments",
+		storage: func() storage.ObjectStorage { return storage.Attachments },
+		setting: func() *setting.Storage { return setting.Attachment.Storage },
+		iterate: func(ctx context.Context, afterID int64, fn func(int64, string) error) error {
+			return iterateObjectPaths(ctx, nil, afterID, func(attach *repo_model.Attachment) (int64, string) {
+				return attach.ID, attach.RelativePath()
+			}, fn)
+		},
+	},
+	"lfs": {
+		name:    "lfs",
+		storage: func() storage.ObjectStorage { return storage.LFS },
+		setting: func() *setting.Storage { return setting.LFS.Storage },
+		iterate: func(ctx context.Context, afterID int64, fn func(int64, string) error) error {
+			return iterateObjectPaths(ctx, nil, afterID, func(mo *git_model.LFSMetaObject) (int64, string) {
+				return mo.ID, mo.RelativePath()
+			}, fn)
+		},
+	},
+	"avatars": {
+		name:    "avatars",
+		storage: func() storage.ObjectStorage { return storage.Avatars },
+		setting: func() *setting.Storage { return setting.Avatar.Storage },
+		iterate: func(ctx context.Context, afterID int64, fn func(int64, string) error) error {
+			return iterateObjectPaths(ctx, builder.Neq{"avatar": ""}, afterID, func(user *user_model.User) (int64, string) {
+				return user.ID, user.CustomAvatarRelativePath()
+			}, fn)
+		},
+	},
+	"repo-avatars": {
+		name:    "repo-avatars",
+		storage: func() storage.ObjectStorage { return storage.RepoAvatars },
+		setting: func() *setting.Storage { return setting.RepoAvatar.Storage },
+		iterate: func(ctx context.Context, afterID int64, fn func(int64, string) error) error {
+			return iterateObjectPaths(ctx, builder.Neq{"avatar": ""}, afterID, func(repo *repo_model.Repository) (int64, string) {
+				return repo.ID, repo.CustomAvatarRelativePath()
+			}, fn)
+		},
+	},
+	"repo-archivers": {
+		name:    "repo-archive",
+		storage: func() storage.ObjectStorage { return storage.RepoArchives },
+		setting: func() *setting.Storage { return setting.RepoArchive.Storage },
+		iterate: func(ctx context.Context, afterID int64, fn func(int64, string) error) error {
+			return iterateObjectPaths(ctx, builder.Eq{"status": repo_model.ArchiverReady}, afterID, func(archiver *repo_model.RepoArchiver) (int64, string) {
+				return archiver.ID, archiver.RelativePath()
+			}, fn)
+		},
+	},
+	"packages": {
+		name:    "packages",
+		storage: func() storage.ObjectStorage { return storage.Packages },
+		setting: func() *setting.Storage { return setting.Packages.Storage },
+		iterate: func(ctx context.Context, afterID int64, fn func(int64, string) error) error {
+			return iterateObjectPaths(ctx, nil, afterID, func(pb *packages_model.PackageBlob) (int64, string) {
+				return pb.ID, packages_module.KeyToRelativePath(packages_module.BlobHash256Key(pb.HashSHA256))
+			}, fn)
+		},
+	},
+	"actions-log": {
+		name:    "actions_log",
+		storage: func() storage.ObjectStorage { return storage.Actions },
+		setting: func() *setting.Storage { return setting.Actions.LogStorage },
+		iterate: func(ctx context.Context, afterID int64, fn func(int64, string) error) error {
+			// expired logs have been cleared and running tasks store their logs in DBFS
+			cond := builder.Eq{"log_expired": false, "log_in_storage": true}
+			return iterateObjectPaths(ctx, cond, afterID, func(task *actions_model.ActionTask) (int64, string) {
+				return task.ID, task.LogFilename
+			}, fn)
+		},
+	},
+}
+
+// iterateObjectPaths calls fn with the id and object path of the beans matching cond whose id is greater than afterID,
+// in the order of their ids so an interrupted iteration can be resumed
+func iterateObjectPaths[Bean any](ctx context.Context, cond builder.Cond, afterID int64, object func(bean *Bean) (int64, string), fn func(id int64, path string) error) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		where := builder.Cond(builder.Gt{"id": afterID})
+		if cond != nil {
+			where = where.And(cond)
+		}
+		beans := make([]*Bean, 0, setting.Database.IterateBufferSize)
+		if err := db.GetEngine(ctx).Where(where).OrderBy("id").Limit(setting.Database.IterateBufferSize).Find(&beans); err != nil {
+			return err
+		}
+		if len(beans) == 0 {
+			return nil
+		}
+		for _, bean := range beans {
+			id, p := object(bean)
+			if err := fn(id, p); err != nil {
+				return err
+			}
+			afterID = id
+		}
+	}
+}
+
+// storageMigrationState is the progress of the online migration of a storage type, it's saved in the database
+// so an interrupted migration is resumed where it stopped. The migrated objects are saved in their own table, with the
+// checksum of their verified copy or the error of their migration.
+type storageMigrationState struct {
+	Type        string
+	Destination string
+	LastID      int64
+	Copied      int64
+	Skipped     int64
+	CopiedSize  int64
+	Finished    bool
+}
+
+// Name returns the name of the app state item
+func (state *storageMigrationState) Name() string {
+	return "storage-migration-" + state.Type
+}
+
+// storageMigrationFailuresBatchSize is the number of objects which couldn't be migrated loaded at once by the report
+const storageMigrationFailuresBatchSize = 100
+
+// storageMigrationReport lists the differences between the source and destination storages after a migration
+type storageMigrationReport struct {
+	Type                 string            `json:"type"`
+	Destination          string            `json:"destination"`
+	Copied               int64             `json:"copied"`
+	Skipped              int64             `json:"skipped"`
+	CopiedSize           int64             `json:"copied_size"`
+	Failed               map[string]string `json:"failed,omitempty"`
+	MissingInDestination []string          `json:"missing_in_destination,omitempty"`
+	SizeMismatch         []string          `json:"size_mismatch,omitempty"`
+	OnlyInDestination    []string          `json:"only_in_destination,omitempty"`
+}
+
+// storageDestination returns a description of the storage used to check the saved progress is for the same destination
+func storageDestination(cfg *setting.Storage) string {
+	switch cfg.Type {
+	case setting.MinioStorageType, setting.HWCloudStorageType:
+		return fmt.Sprintf("%s:%s/%s/%s", cfg.Type, cfg.MinioConfig.Endpoint, cfg.MinioConfig.Bucket, cfg.MinioConfig.BasePath)
+	default:
+		return fmt.Sprintf("%s:%s", setting.LocalStorageType, cfg.Path)
+	}
+}
+
+// migrateStorageOnline copies the objects of the storage type to the destination while Gitea is running. The copies
+// are verified with their checksums, the progress is saved to resume an interrupted migration and the differences
+// between both storages are reported at the end. The objects created meanwhile are expected to be written to the
+// destination too with a dual write storage, so another pass is only needed for the objects the report lists. The
+// objects whose copy has already been verified are skipped without being hashed again if the sizes of the copy and of
+// the source object haven't changed, a restarted migration verifies them all again.
+func migrateStorageOnline(ctx context.Context, m storageMigration, dst storage.ObjectStorage, destination string, restart bool) (*storageMigrationReport, error) {
+	src := m.storage()
+	state := &storageMigrationState{Type: m.name}
+	if err := system.AppState.Get(ctx, state); err != nil {
+		return nil, err
+	}
+	if restart || state.Finished || state.Destination != destination {
+		if state.Destination != "" && !state.Finished {
+			log.Info("Restarting the migration of %s to %s, the migration to %s at %d is discarded", m.name, destination, state.Destination, state.LastID)
+		}
+		if state.Destination != destination || restart {
+			if err := system_model.DeleteStorageMigrationObjects(ctx, m.name); err != nil {
+				return nil, err
+			}
+		}
+		*state = storageMigrationState{Type: m.name, Destination: destination}
+	} else if state.LastID > 0 {
+		log.Info("Resuming the migration of %s to %s after %d", m.name, destination, state.LastID)
+	}
+
+	var unsaved int
+	err := m.iterate(ctx, state.LastID, func(id int64, p string) error {
+		migrated, err := system_model.GetStorageMigrationObject(ctx, m.name, p)
+		if err != nil {
+			return err
+		}
+		if migrated == nil {
+			migrated = &system_model.StorageMigrationObject{Type: m.name, Path: p}
+		}
+
+		copied, size, sum, err := migrateObject(dst, src, p, migrated)
+		switch {
+		case err == nil && copied:
+			state.Copied++
+			state.CopiedSize += size
+		case err == nil:
+			state.Skipped++
+		case ctx.Err() != nil:
+			return ctx.Err()
+		default:
+			log.Warn("Unable to migrate %s of %s: %v", p, m.name, err)
+		}
+		if err == nil {
+			migrated.Size, migrated.Checksum, migrated.Error = size, sum, ""
+		} else {
+			migrated.Size, migrated.Checksum, migrated.Error = 0, "", err.Error()
+		}
+		if err := system_model.SaveStorageMigrationObject(ctx, migrated); err != nil {
+			return err
+		}
+		state.LastID = id
+
+		if unsaved++; unsaved >= storageMigrationSaveInterval {
+			unsaved = 0
+			if err := system.AppState.Set(ctx, state); err != nil {
+				return err
+			}
+			failed, err := system_model.CountStorageMigrationFailures(ctx, m.name)
+			if err != nil {
+				return err
+			}
+			log.Info("Migrated %s up to %d: %d copied, %d skipped, %d failed", m.name, id, state.Copied, state.Skipped, failed)
+		}
+		return nil
+	})
+	if err != nil {
+		// the context may be canceled, the progress is still saved
+		if saveErr := system.AppState.Set(db.DefaultContext, state); saveErr != nil {
+			log.Error("Unable to save the progress of the migration of %s: %v", m.name, saveErr)
+		}
+		return nil, err
+	}
+
+	report, err := diffMigratedStorage(ctx, m, dst, src)
+	if err != nil {
+		return nil, err
+	}
+	report.Destination = destination
+	report.Copied = state.Copied
+	report.Skipped = state.Skipped
+	report.CopiedSize = state.CopiedSize
+	if report.Failed, err = findStorageMigrationFailures(ctx, m.name); err != nil {
+		return nil, err
+	}
+
+	state.Finished = true
+	return report, system.AppState.Set(ctx, state)
+}
+
+// findStorageMigrationFailures maps the paths of the objects of the storage type which couldn't be migrated to the error
+func findStorageMigrationFailures(ctx context.Context, typ string) (map[string]string, error) {
+	failed := map[string]string{}
+	var afterID int64
+	for {
+		objects, err := system_model.FindStorageMigrationFailures(ctx, typ, afterID, storageMigrationFailuresBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		if len(objects) == 0 {
+			return failed, nil
+		}
+		for _, o := range objects {
+			failed[o.Path] = o.Error
+		}
+		afterID = objects[len(objects)-1].ID
+	}
+}
+
+// diffMigratedStorage checks every referenced object is in the destination with the same size,
+// and lists the objects of the destination which aren't in the source
+func diffMigratedStorage(ctx context.Context, m storageMigration, dst, src storage.ObjectStorage) (*storageMigrationReport, error) {
+	report := &storageMigrationReport{Type: m.name}
+	err := m.iterate(ctx, 0, func(_ int64, p string) error {
+		srcInfo, err := src.Stat(p)
+		if err != nil {
+			// the object is missing from the source too, it's reported as failed by the copy
+			return nil
+		}
+		dstInfo, err := dst.Stat(p)
+		if err != nil {
+			report.MissingInDestination = append(report.MissingInDestination, p)
+		} else if dstInfo.Size() != srcInfo.Size() {
+			report.SizeMismatch = append(report.SizeMismatch, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = dst.IterateObjectsKeyOnly("", func(p string) error {
+		if _, err := src.Stat(p); errors.Is(err, os.ErrNotExist) {
+			report.OnlyInDestination = append(report.OnlyInDestination, p)
+		}
+		return nil
+	})
+	return report, err
+}
+
+// migrateObject copies the object to the destination unless it already has an identical copy, and checks the
+// checksum of the copy. The copy verified by a previous pass is kept without being hashed if both the copy and the
+// source object still have its size. It returns whether the object has been copied, its size and its checksum.
+func migrateObject(dst, src storage.ObjectStorage, p string, migrated *system_model.StorageMigrationObject) (bool, int64, string, error) {
+	if dstInfo, err := dst.Stat(p); err == nil {
+		if migrated.IsVerified() && dstInfo.Size() == migrated.Size {
+			if srcInfo, err := src.Stat(p); err == nil && srcInfo.Size() == migrated.Size {
+				return false, migrated.Size, migrated.Checksum, nil
+			}
+		}
+		srcSum, srcSize, err := hashObject(src, p)
+		if err != nil {
+			return false, 0, "", err
+		}
+		if dstInfo.Size() == srcSize {
+			dstSum, _, err := hashObject(dst, p)
+			if err != nil {
+				return false, 0, "", err
+			}
+			if dstSum == srcSum {
+				return false, srcSize, srcSum, nil
+			}
+		}
+	}
+
+	f, err := src.Open(p)
+	if err != nil {
+		return false, 0, "", err
+	}
+	defer f.Close()
+
+	size := int64(-1)
+	if info, err := f.Stat(); err == nil {
+		size = info.Size()
+	}
+	hash := sha256.New()
+	if _, err := dst.Save(p, io.TeeReader(f, hash), size); err != nil {
+		return false, 0, "", err
+	}
+
+	dstSum, dstSize, err := hashObject(dst, p)
+	if err != nil {
+		return false, 0, "", err
+	}
+	if dstSum != hex.EncodeToString(hash.Sum(nil)) {
+		if err := dst.Delete(p); err != nil {
+			log.Error("Unable to delete the corrupted copy of %s: %v", p, err)
+		}
+		return false, 0, "", errChecksumMismatch
+	}
+	return true, dstSize, dstSum, nil
+}
+
+// hashObject returns the hex encoded sha256 checksum and the size of the object
+func hashObject(s storage.ObjectStorage, p string) (string, int64, error) {
+	f, err := s.Open(p)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, f)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), size, nil
+}
+
+// writeStorageMigrationReports writes the reports as JSON to the file, or to the standard output if it's empty
+func writeStorageMigrationReports(file string, reports []*storageMigrationReport) error {
+	b, err := json.MarshalIndent(reports, "", "  ")
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	if file == "" {
+		_, err = os.Stdout.Write(b)
+		return err
+	}
+	return os.WriteFile(file, b, 0o644)
+}
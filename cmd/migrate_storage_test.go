@@ -5,17 +5,21 @@ package cmd
 
 import (
 	"context"
+	"crypto/sha256"
+	"fmt"
 	"os"
 	"strings"
 	"testing"
 
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/models/packages"
+	system_model "code.gitea.io/gitea/models/system"
 	"code.gitea.io/gitea/models/unittest"
 	user_model "code.gitea.io/gitea/models/user"
 	packages_module "code.gitea.io/gitea/modules/packages"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/storage"
+	system_module "code.gitea.io/gitea/modules/system"
 	packages_service "code.gitea.io/gitea/services/packages"
 
 	"github.com/stretchr/testify/assert"
@@ -73,3 +77,120 @@ func TestMigratePackages(t *testing.T) {
 	assert.EqualValues(t, "01", entries[0].Name())
 	assert.EqualValues(t, "tmp", entries[1].Name())
 }
+
+func TestMigrateStorageOnline(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	assert.NoError(t, system_module.Init())
+
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	creator := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 1})
+
+	content := "package main\n\nfunc main() {\nfmt.Println(\"hi\")\n}\n"
+	buf, err := packages_module.CreateHashedBufferFromReaderWithSize(strings.NewReader(content), 1024)
+	assert.NoError(t, err)
+	defer buf.Close()
+
+	v, f, err := packages_service.CreatePackageAndAddFile(db.DefaultContext, &packages_service.PackageCreationInfo{
+		PackageInfo: packages_service.PackageInfo{
+			Owner:       creator,
+			PackageType: packages.TypeGeneric,
+			Name:        "test",
+			Version:     "2.0.0",
+		},
+		Creator:           creator,
+		SemverCompatible:  true,
+		VersionProperties: map[string]string{},
+	}, &packages_service.PackageFileCreationInfo{
+		PackageFileInfo: packages_service.PackageFileInfo{
+			Filename: "a.go",
+		},
+		Creator: creator,
+		Data:    buf,
+		IsLead:  true,
+	})
+	assert.NoError(t, err)
+	assert.NotNil(t, v)
+	assert.NotNil(t, f)
+
+	ctx := context.Background()
+	p := t.TempDir()
+	dstStorage, err := storage.NewLocalStorage(ctx, &setting.Storage{Path: p})
+	assert.NoError(t, err)
+
+	m := storageMigrations["packages"]
+	t.Cleanup(func() {
+		assert.NoError(t, system_model.DeleteStorageMigrationObjects(db.DefaultContext, m.name))
+	})
+	blobPath := packages_module.KeyToRelativePath(packages_module.BlobHash256Key(fmt.Sprintf("%x", sha256.Sum256([]byte(content)))))
+
+	report, err := migrateStorageOnline(ctx, m, dstStorage, "local:"+p, false)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, report.Copied)
+	assert.EqualValues(t, len(content), report.CopiedSize)
+	assert.Empty(t, report.Failed)
+	assert.Empty(t, report.MissingInDestination)
+	assert.Empty(t, report.OnlyInDestination)
+
+	state := &storageMigrationState{Type: "packages"}
+	assert.NoError(t, system_module.AppState.Get(ctx, state))
+	assert.True(t, state.Finished)
+	assert.EqualValues(t, "local:"+p, state.Destination)
+	assert.EqualValues(t, f.BlobID, state.LastID)
+	migrated, err := system_model.GetStorageMigrationObject(ctx, m.name, blobPath)
+	assert.NoError(t, err)
+	assert.True(t, migrated.IsVerified())
+	assert.EqualValues(t, len(content), migrated.Size)
+	assert.EqualValues(t, fmt.Sprintf("%x", sha256.Sum256([]byte(content))), migrated.Checksum)
+
+	// the identical copy is skipped by the next pass
+	report, err = migrateStorageOnline(ctx, m, dstStorage, "local:"+p, false)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, report.Copied)
+	assert.EqualValues(t, 1, report.Skipped)
+
+	// the verified copy isn't hashed again unless the migration is restarted
+	_, err = dstStorage.Save(blobPath, strings.NewReader(strings.Repeat("a", len(content))), -1)
+	assert.NoError(t, err)
+	report, err = migrateStorageOnline(ctx, m, dstStorage, "local:"+p, false)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, report.Copied)
+	assert.EqualValues(t, 1, report.Skipped)
+	report, err = migrateStorageOnline(ctx, m, dstStorage, "local:"+p, true)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, report.Copied)
+
+	// a corrupted copy is copied again and the extra objects are reported
+	_, err = dstStorage.Save(blobPath, strings.NewReader("corrupted"), -1)
+	assert.NoError(t, err)
+	_, err = dstStorage.Save("extra", strings.NewReader("extra"), -1)
+	assert.NoError(t, err)
+	report, err = migrateStorageOnline(ctx, m, dstStorage, "local:"+p, false)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, report.Copied)
+	assert.EqualValues(t, []string{"extra"}, report.OnlyInDestination)
+
+	// an interrupted migration is resumed after the last migrated record
+	state.Finished = false
+	state.Copied = 5
+	assert.NoError(t, system_module.AppState.Set(ctx, state))
+	report, err = migrateStorageOnline(ctx, m, dstStorage, "local:"+p, false)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 5, report.Copied)
+	assert.EqualValues(t, 0, report.Skipped)
+
+	// the progress for another destination is discarded
+	state.Finished = false
+	assert.NoError(t, system_module.AppState.Set(ctx, state))
+	report, err = migrateStorageOnline(ctx, m, dstStorage, "local:/elsewhere", false)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, report.Copied)
+	assert.EqualValues(t, 1, report.Skipped)
+
+	// the objects which can't be migrated are reported
+	assert.NoError(t, storage.Packages.Delete(blobPath))
+	report, err = migrateStorageOnline(ctx, m, dstStorage, "local:"+p, true)
+	assert.NoError(t, err)
+	assert.Len(t, report.Failed, 1)
+	assert.Contains(t, report.Failed, blobPath)
+}
@@ -22,6 +22,7 @@ import (
 	"code.gitea.io/gitea/models/perm"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/lfstransfer"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/pprof"
 	"code.gitea.io/gitea/modules/private"
@@ -29,14 +30,21 @@ import (
 	repo_module "code.gitea.io/gitea/modules/repository"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/services/lfs"
+	"code.gitea.io/gitea/utils"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/kballard/go-shellquote"
 	"github.com/urfave/cli/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
 	lfsAuthenticateVerb = "git-lfs-authenticate"
+	lfsTransferVerb     = "git-lfs-transfer"
+
+	tracerName = "gitea_cmd_tracer"
 )
 
 // CmdServ represents the available serv sub-command.
@@ -89,6 +97,7 @@ var (
 		"git-upload-archive": perm.AccessModeRead,
 		"git-receive-pack":   perm.AccessModeWrite,
 		lfsAuthenticateVerb:  perm.AccessModeNone,
+		lfsTransferVerb:      perm.AccessModeNone,
 	}
 	alphaDashDotPattern = regexp.MustCompile(`[^\w-\.]`)
 )
@@ -135,7 +144,7 @@ func handleCliResponseExtra(extra private.ResponseExtra) error {
 	return nil
 }
 
-func runServ(c *cli.Context) error {
+func runServ(c *cli.Context) (err error) {
 	ctx, cancel := installSignals()
 	defer cancel()
 
@@ -154,6 +163,10 @@ func runServ(c *cli.Context) error {
 		return nil
 	}
 
+	defer initTrace(ctx)()
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "ssh session", trace.WithSpanKind(trace.SpanKindServer))
+	defer func() { endSpan(span, err) }()
+
 	keys := strings.Split(c.Args().First(), "-")
 	if len(keys) != 2 || keys[0] != "key" {
 		return fail(ctx, "Key ID format error", "Invalid key argument: %s", c.Args().First())
@@ -206,7 +219,7 @@ func runServ(c *cli.Context) error {
 	}
 
 	var lfsVerb string
-	if verb == lfsAuthenticateVerb {
+	if verb == lfsAuthenticateVerb || verb == lfsTransferVerb {
 		if !setting.LFS.StartServer {
 			return fail(ctx, "Unknown git command", "LFS authentication request over SSH denied, LFS support is disabled")
 		}
@@ -249,12 +262,19 @@ func runServ(c *cli.Context) error {
 		}()
 	}
 
+	span.SetName("ssh " + verb)
+	span.SetAttributes(
+		attribute.String("ssh.verb", verb),
+		attribute.Int64("ssh.key_id", keyID),
+		attribute.String("repo.name", username+"/"+reponame),
+	)
+
 	requestedMode, has := allowedCommands[verb]
 	if !has {
 		return fail(ctx, "Unknown git command", "Unknown git command %s", verb)
 	}
 
-	if verb == lfsAuthenticateVerb {
+	if verb == lfsAuthenticateVerb || verb == lfsTransferVerb {
 		if lfsVerb == "upload" {
 			requestedMode = perm.AccessModeWrite
 		} else if lfsVerb == "download" {
@@ -273,20 +293,7 @@ func runServ(c *cli.Context) error {
 	if verb == lfsAuthenticateVerb {
 		url := fmt.Sprintf("%s%s/%s.git/info/lfs", setting.AppURL, url.PathEscape(results.OwnerName), url.PathEscape(results.RepoName))
 
-		now := time.Now()
-		claims := lfs.Claims{
-			RegisteredClaims: jwt.RegisteredClaims{
-				ExpiresAt: jwt.NewNumericDate(now.Add(setting.LFS.HTTPAuthExpiry)),
-				NotBefore: jwt.NewNumericDate(now),
-			},
-			RepoID: results.RepoID,
-			Op:     lfsVerb,
-			UserID: results.UserID,
-		}
-		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-		// Sign and get the complete encoded token as a string using the secret
-		tokenString, err := token.SignedString(setting.LFS.JWTSecretBytes)
+		tokenString, err := getLFSAuthToken(results, lfsVerb)
 		if err != nil {
 			return fail(ctx, "Failed to sign JWT Token", "Failed to sign JWT token: %v", err)
 		}
@@ -305,6 +312,19 @@ func runServ(c *cli.Context) error {
 		return nil
 	}
 
+	// LFS transfer over SSH, the objects are handled by the LFS server on behalf of the user
+	if verb == lfsTransferVerb {
+		tokenString, err := getLFSAuthToken(results, lfsVerb)
+		if err != nil {
+			return fail(ctx, "Failed to sign JWT Token", "Failed to sign JWT token: %v", err)
+		}
+		backend := lfstransfer.NewHTTPBackend(ctx, results.OwnerName, results.RepoName, "Bearer "+tokenString, results.UserName)
+		if err := lfstransfer.Serve(ctx, os.Stdin, os.Stdout, backend, lfsVerb); err != nil {
+			return fail(ctx, "Failed to transfer LFS objects", "Failed to transfer LFS objects: %v", err)
+		}
+		return nil
+	}
+
 	var gitcmd *exec.Cmd
 	gitBinPath := filepath.Dir(git.GitExecutable) // e.g. /usr/bin
 	gitBinVerb := filepath.Join(gitBinPath, verb) // e.g. /usr/bin/git-upload-pack
@@ -325,6 +345,12 @@ func runServ(c *cli.Context) error {
 	process.SetSysProcAttribute(gitcmd)
 	gitcmd.Dir = setting.RepoRootPath
 	gitcmd.Stdout = os.Stdout
+	// only the fetches and the clones which receive a pack are counted, not the listings of the refs
+	var packDetector *git.PackDetector
+	if verb == "git-upload-pack" && !results.IsWiki {
+		packDetector = git.NewPackDetector(os.Stdout)
+		gitcmd.Stdout = packDetector
+	}
 	gitcmd.Stdin = os.Stdin
 	gitcmd.Stderr = os.Stderr
 	gitcmd.Env = append(gitcmd.Env, os.Environ()...)
@@ -344,11 +370,19 @@ func runServ(c *cli.Context) error {
 	// to avoid breaking, here only use the minimal environment variables for the "gitea serv" command.
 	// it could be re-considered whether to use the same git.CommonGitCmdEnvs() as "git" command later.
 	gitcmd.Env = append(gitcmd.Env, git.CommonCmdServEnvs()...)
+	// the hooks run by git continue the trace of the session
+	gitcmd.Env = append(gitcmd.Env, utils.TraceEnv(ctx)...)
 
 	if err = gitcmd.Run(); err != nil {
 		return fail(ctx, "Failed to execute git command", "Failed to execute git command: %v", err)
 	}
 
+	if packDetector != nil && packDetector.PackSent() {
+		if err = private.RecordSSHClone(ctx, keyID, results.RepoID); err != nil {
+			log.Error("Unable to count the clone of %s/%s: %v", results.OwnerName, results.RepoName, err)
+		}
+	}
+
 	// Update user key activity.
 	if results.KeyID > 0 {
 		if err = private.UpdatePublicKeyInRepo(ctx, results.KeyID, results.RepoID); err != nil {
@@ -358,3 +392,21 @@ func runServ(c *cli.Context) error {
 
 	return nil
 }
+
+// getLFSAuthToken returns a token authenticating the user of the SSH session on the LFS server of the repository
+func getLFSAuthToken(results *private.ServCommandResults, lfsVerb string) (string, error) {
+	now := time.Now()
+	claims := lfs.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(setting.LFS.HTTPAuthExpiry)),
+			NotBefore: jwt.NewNumericDate(now),
+		},
+		RepoID: results.RepoID,
+		Op:     lfsVerb,
+		UserID: results.UserID,
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+
+	// Sign and get the complete encoded token as a string using the secret
+	return token.SignedString(setting.LFS.JWTSecretBytes)
+}
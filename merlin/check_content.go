@@ -0,0 +1,210 @@
+package merlin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"code.gitea.io/gitea/modules/cache"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+const (
+	blobVerdictCacheKeyPrefix = "merlin_blob_verdict_"
+	blobVerdictPass           = "pass"
+	blobVerdictBlock          = "block"
+)
+
+// ContentBlob is a text file to be moderated
+type ContentBlob struct {
+	SHA     string
+	Path    string
+	Content string
+}
+
+// ErrSensitiveBlob represents a file rejected by the moderator
+type ErrSensitiveBlob struct {
+	SHA     string
+	Path    string
+	Verdict *Verdict
+}
+
+func (err ErrSensitiveBlob) Error() string {
+	return fmt.Sprintf("Sensitive data found in file %s, Please remove it and try again.", err.Path)
+}
+
+func (err ErrSensitiveBlob) Unwrap() error {
+	return ErrSensitiveContent
+}
+
+// IsErrSensitiveBlob checks if an error is a ErrSensitiveBlob
+func IsErrSensitiveBlob(err error) bool {
+	_, ok := err.(ErrSensitiveBlob)
+	return ok
+}
+
+// CheckContents moderates the blobs in batches. The verdict of every blob is cached by its SHA,
+// so the same content pushed again or pushed to a fork is not moderated again.
+func CheckContents(ctx context.Context, blobs []*ContentBlob) error {
+	checker := NewContentChecker(ctx)
+	for _, blob := range blobs {
+		if err := checker.Add(blob); err != nil {
+			return err
+		}
+	}
+	return checker.Flush()
+}
+
+// ContentChecker moderates the blobs added one by one in batches like CheckContents,
+// only the blobs of the current batch are kept in memory
+type ContentChecker struct {
+	ctx      context.Context
+	batch    []*ContentBlob
+	batchLen int
+}
+
+// NewContentChecker returns a checker moderating the blobs with the configured moderator
+func NewContentChecker(ctx context.Context) *ContentChecker {
+	return &ContentChecker{ctx: ctx}
+}
+
+// Add moderates the blob once its batch is full, Flush must be called after the last blob
+func (c *ContentChecker) Add(blob *ContentBlob) error {
+	switch getCachedBlobVerdict(blob.SHA) {
+	case blobVerdictPass:
+		return nil
+	case blobVerdictBlock:
+		return ErrSensitiveBlob{SHA: blob.SHA, Path: blob.Path, Verdict: &Verdict{Label: "cached"}}
+	}
+
+	maxLen := setting.MaxTextLen
+	separatorLen := utf8.RuneCountInString(templateText)
+	length := utf8.RuneCountInString(blob.Content)
+	if length > maxLen {
+		return checkBlob(c.ctx, blob)
+	}
+	if len(c.batch) > 0 && c.batchLen+separatorLen+length > maxLen {
+		if err := c.Flush(); err != nil {
+			return err
+		}
+	}
+	if len(c.batch) > 0 {
+		c.batchLen += separatorLen
+	}
+	c.batch = append(c.batch, blob)
+	c.batchLen += length
+	return nil
+}
+
+// Flush moderates the blobs of the current batch
+func (c *ContentChecker) Flush() error {
+	if len(c.batch) == 0 {
+		return nil
+	}
+	err := checkBatch(c.ctx, c.batch)
+	c.batch, c.batchLen = nil, 0
+	return err
+}
+
+// checkBatch moderates the blobs at once, if the batch is rejected the blobs are moderated one by one to find the offending one
+func checkBatch(ctx context.Context, batch []*ContentBlob) error {
+	if len(batch) == 1 {
+		return checkBlob(ctx, batch[0])
+	}
+
+	texts := make([]string, 0, len(batch))
+	for _, blob := range batch {
+		texts = append(texts, blob.Content)
+	}
+	verdict, err := ModerateText(ctx, joinTexts(texts))
+	if err == nil {
+		for _, blob := range batch {
+			cacheBlobVerdict(blob.SHA, verdict)
+		}
+		return nil
+	}
+	if !errors.Is(err, ErrSensitiveContent) {
+		return err
+	}
+	for _, blob := range batch {
+		if err := checkBlob(ctx, blob); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkBlob moderates a single blob, splitting it into segments if it is too long
+func checkBlob(ctx context.Context, blob *ContentBlob) error {
+	var verdict *Verdict
+	for _, segment := range splitText(blob.Content, setting.MaxTextLen) {
+		var err error
+		verdict, err = ModerateText(ctx, segment)
+		if errors.Is(err, ErrSensitiveContent) {
+			cacheBlobVerdict(blob.SHA, verdict)
+			return ErrSensitiveBlob{SHA: blob.SHA, Path: blob.Path, Verdict: verdict}
+		} else if err != nil {
+			return err
+		}
+	}
+	if verdict != nil {
+		cacheBlobVerdict(blob.SHA, verdict)
+	}
+	return nil
+}
+
+func joinTexts(texts []string) string {
+	length := 0
+	for _, text := range texts {
+		length += len(text) + len(templateText)
+	}
+	buf := make([]byte, 0, length)
+	for i, text := range texts {
+		if i > 0 {
+			buf = append(buf, templateText...)
+		}
+		buf = append(buf, text...)
+	}
+	return string(buf)
+}
+
+// splitText splits the text into segments of at most maxLen runes
+func splitText(text string, maxLen int) []string {
+	if maxLen <= 0 || utf8.RuneCountInString(text) <= maxLen {
+		return []string{text}
+	}
+	var segments []string
+	runes := []rune(text)
+	for len(runes) > maxLen {
+		segments = append(segments, string(runes[:maxLen]))
+		runes = runes[maxLen:]
+	}
+	if len(runes) > 0 {
+		segments = append(segments, string(runes))
+	}
+	return segments
+}
+
+func getCachedBlobVerdict(sha string) string {
+	c := cache.GetCache()
+	if c == nil {
+		return ""
+	}
+	if v, ok := c.Get(blobVerdictCacheKeyPrefix + sha).(string); ok {
+		return v
+	}
+	return ""
+}
+
+func cacheBlobVerdict(sha string, verdict *Verdict) {
+	c := cache.GetCache()
+	if c == nil || verdict == nil || verdict.Label == VerdictLabelFailOpen {
+		return
+	}
+	v := blobVerdictPass
+	if !verdict.Pass {
+		v = blobVerdictBlock
+	}
+	_ = c.Put(blobVerdictCacheKeyPrefix+sha, v, setting.CacheService.TTLSeconds())
+}
@@ -1,18 +1,40 @@
 package merlin
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"strings"
 	"unicode/utf8"
 
 	moderationsdk "code.gitea.io/gitea/modules/setting"
-	"gitee.com/modelers/moderation-server-sdk/moderation"
-	moderationapi "gitee.com/modelers/moderation-server-sdk/moderation/api"
 )
 
 const templateText = "一一一一一一一一一一"
 
-func CheckText(filenames []string, commitMessage string) (err error) {
+// ErrSensitiveText represents a commit message or file name segment rejected by the moderator
+type ErrSensitiveText struct {
+	// Hash is the SHA256 of the rejected segment
+	Hash    string
+	Verdict *Verdict
+}
+
+func (err ErrSensitiveText) Error() string {
+	return ErrSensitiveContent.Error()
+}
+
+func (err ErrSensitiveText) Unwrap() error {
+	return ErrSensitiveContent
+}
+
+// IsErrSensitiveText checks if an error is a ErrSensitiveText
+func IsErrSensitiveText(err error) bool {
+	_, ok := err.(ErrSensitiveText)
+	return ok
+}
+
+func CheckText(ctx context.Context, filenames []string, commitMessage string) (err error) {
 	if checkTextLen(commitMessage) {
 		return errors.New("text length should be less than 1500")
 	}
@@ -20,7 +42,7 @@ func CheckText(filenames []string, commitMessage string) (err error) {
 	file.WriteString(commitMessage)
 	for _, filename := range filenames {
 		if checkTextLen(file.String() + filename + templateText) {
-			if err = moderationText(file.String()); err != nil {
+			if err = moderationText(ctx, file.String()); err != nil {
 				return
 			}
 			file.Reset()
@@ -30,24 +52,16 @@ func CheckText(filenames []string, commitMessage string) (err error) {
 			file.WriteString(filename)
 		}
 	}
-	return moderationText(file.String())
+	return moderationText(ctx, file.String())
 }
 
-func moderationText(content string) (err error) {
-	req := moderation.ReqToModerationText{
-		Text:    content,
-		Type:    "comment",
-		BizType: "test",
-	}
-	var res moderation.RespModeration
-	res, _, err = moderationapi.ModerationText(&req)
-	if err != nil {
-		return
-	}
-	if res.Result != "pass" {
-		return errors.New("Sensitive data found in submission, Please remove it and try again.")
+func moderationText(ctx context.Context, content string) error {
+	verdict, err := ModerateText(ctx, content)
+	if errors.Is(err, ErrSensitiveContent) {
+		sum := sha256.Sum256([]byte(content))
+		return ErrSensitiveText{Hash: hex.EncodeToString(sum[:]), Verdict: verdict}
 	}
-	return
+	return err
 }
 
 func checkTextLen(text string) bool {
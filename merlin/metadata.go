@@ -0,0 +1,287 @@
+package merlin
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/parser"
+	"go.abhg.dev/goldmark/frontmatter"
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// MetadataFile is the model card whose front matter is validated
+const MetadataFile = "README.md"
+
+// the types of the front matter fields
+const (
+	FieldTypeString       = "string"
+	FieldTypeList         = "list"
+	FieldTypeStringOrList = "string_or_list"
+)
+
+// MetadataFieldRule describes the valid values of a front matter field
+type MetadataFieldRule struct {
+	// Type is string, list or string_or_list, the items of the lists are strings
+	Type     string   `yaml:"type"`
+	Required bool     `yaml:"required"`
+	Enum     []string `yaml:"enum"`
+	// Pattern is a regular expression every value must match
+	Pattern   string `yaml:"pattern"`
+	MaxItems  int    `yaml:"max_items"`
+	MaxLength int    `yaml:"max_length"`
+
+	enum    sets.String
+	pattern *regexp.Regexp
+}
+
+// MetadataSchema maps the front matter fields to their rules, the fields not in the schema are not validated
+type MetadataSchema map[string]*MetadataFieldRule
+
+// MetadataFieldError is a front matter field violating its rule
+type MetadataFieldError struct {
+	Field   string
+	Message string
+}
+
+func (err MetadataFieldError) String() string {
+	return fmt.Sprintf("%q %s", err.Field, err.Message)
+}
+
+// ErrInvalidMetadata represents a model card whose front matter violates the schema
+type ErrInvalidMetadata struct {
+	Fields []MetadataFieldError
+}
+
+func (err ErrInvalidMetadata) Error() string {
+	var sb strings.Builder
+	sb.WriteString("invalid YAML metadata in " + MetadataFile)
+	for _, field := range err.Fields {
+		sb.WriteString("\n - Error: ")
+		sb.WriteString(field.String())
+	}
+	return sb.String()
+}
+
+// IsErrInvalidMetadata checks if an error is a ErrInvalidMetadata
+func IsErrInvalidMetadata(err error) bool {
+	_, ok := err.(ErrInvalidMetadata)
+	return ok
+}
+
+var (
+	metadataSchema     MetadataSchema
+	metadataSchemaErr  error
+	metadataSchemaOnce sync.Once
+)
+
+// DefaultMetadataSchema returns the schema used when [merlin] METADATA_SCHEMA_FILE is not set, it only validates the
+// license like the license hook it replaces. The rules of the other fields are enabled by the schema file.
+func DefaultMetadataSchema() MetadataSchema {
+	schema := MetadataSchema{
+		"license": {Type: FieldTypeStringOrList},
+	}
+	_ = schema.compile()
+	return schema
+}
+
+// GetMetadataSchema returns the schema configured by [merlin] METADATA_SCHEMA_FILE
+func GetMetadataSchema() (MetadataSchema, error) {
+	metadataSchemaOnce.Do(func() {
+		if setting.Metadata.SchemaFile == "" {
+			metadataSchema = DefaultMetadataSchema()
+			return
+		}
+		data, err := os.ReadFile(setting.Metadata.SchemaFile)
+		if err != nil {
+			metadataSchemaErr = fmt.Errorf("read metadata schema: %w", err)
+			return
+		}
+		metadataSchema, metadataSchemaErr = ParseMetadataSchema(data)
+	})
+	return metadataSchema, metadataSchemaErr
+}
+
+// WithLicenses returns a copy of the schema whose license field is required and restricted to the licenses
+func (schema MetadataSchema) WithLicenses(licenses []string) MetadataSchema {
+	copied := make(MetadataSchema, len(schema)+1)
+	for field, rule := range schema {
+		copied[field] = rule
+	}
+	license := &MetadataFieldRule{Type: FieldTypeStringOrList}
+	if rule, ok := schema["license"]; ok {
+		*license = *rule
+	}
+	license.Required = true
+	license.Enum = licenses
+	license.enum = sets.NewString(licenses...)
+	copied["license"] = license
+	return copied
+}
+
+// ParseMetadataSchema parses a YAML schema
+func ParseMetadataSchema(data []byte) (MetadataSchema, error) {
+	schema := MetadataSchema{}
+	if err := yaml.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("parse metadata schema: %w", err)
+	}
+	return schema, schema.compile()
+}
+
+func (schema MetadataSchema) compile() error {
+	for field, rule := range schema {
+		if rule == nil {
+			rule = &MetadataFieldRule{}
+			schema[field] = rule
+		}
+		switch rule.Type {
+		case "":
+			rule.Type = FieldTypeStringOrList
+		case FieldTypeString, FieldTypeList, FieldTypeStringOrList:
+		default:
+			return fmt.Errorf("invalid type %q of metadata field %q", rule.Type, field)
+		}
+		rule.enum = sets.NewString(rule.Enum...)
+		rule.pattern = nil
+		if rule.Pattern != "" {
+			var err error
+			if rule.pattern, err = regexp.Compile(rule.Pattern); err != nil {
+				return fmt.Errorf("invalid pattern of metadata field %q: %w", field, err)
+			}
+		}
+	}
+	return nil
+}
+
+// CheckMetadata validates the front matter of the model card content with the configured schema,
+// the license is required and must be one of the licenses unless they are empty.
+func CheckMetadata(content []byte, licenses []string) error {
+	schema, err := GetMetadataSchema()
+	if err != nil {
+		return err
+	}
+	if len(licenses) > 0 {
+		schema = schema.WithLicenses(licenses)
+	}
+	return ValidateMetadata(content, schema)
+}
+
+// ValidateMetadata validates the front matter of the model card content with the schema, the content without
+// front matter only violates the required fields. The schema must be created by DefaultMetadataSchema,
+// ParseMetadataSchema or WithLicenses.
+func ValidateMetadata(content []byte, schema MetadataSchema) error {
+	meta, err := parseFrontMatter(content)
+	if err != nil {
+		return ErrInvalidMetadata{Fields: []MetadataFieldError{{Field: "---", Message: "is not a valid YAML front matter: " + err.Error()}}}
+	}
+
+	fields := make([]string, 0, len(schema))
+	for field := range schema {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	var errs []MetadataFieldError
+	for _, field := range fields {
+		if msg := schema[field].validate(meta[field]); msg != "" {
+			errs = append(errs, MetadataFieldError{Field: field, Message: msg})
+		}
+	}
+	if len(errs) > 0 {
+		return ErrInvalidMetadata{Fields: errs}
+	}
+	return nil
+}
+
+func parseFrontMatter(content []byte) (map[string]any, error) {
+	md := goldmark.New(
+		goldmark.WithExtensions(&frontmatter.Extender{}),
+	)
+
+	ctx := parser.NewContext()
+	var buf bytes.Buffer
+	if err := md.Convert(content, &buf, parser.WithContext(ctx)); err != nil {
+		return nil, err
+	}
+
+	data := frontmatter.Get(ctx)
+	if data == nil {
+		return nil, nil
+	}
+	meta := map[string]any{}
+	if err := data.Decode(&meta); err != nil {
+		return nil, err
+	}
+	return meta, nil
+}
+
+// validate returns the violation of the rule by the value, or an empty string if the value is valid
+func (rule *MetadataFieldRule) validate(value any) string {
+	var values []string
+	switch v := value.(type) {
+	case nil:
+	case []any:
+		if rule.Type == FieldTypeString {
+			return "must be a string"
+		}
+		for _, item := range v {
+			s, ok := scalarString(item)
+			if !ok {
+				return "must be a list of strings"
+			}
+			values = append(values, s)
+		}
+	default:
+		s, ok := scalarString(v)
+		if !ok {
+			return fmt.Sprintf("must be a %s", strings.ReplaceAll(rule.Type, "_", " "))
+		}
+		if rule.Type == FieldTypeList {
+			return "must be a list"
+		}
+		values = []string{s}
+	}
+
+	if len(values) == 0 {
+		if rule.Required {
+			return "is required"
+		}
+		return ""
+	}
+	if rule.MaxItems > 0 && len(values) > rule.MaxItems {
+		return fmt.Sprintf("must have at most %d items", rule.MaxItems)
+	}
+	for _, s := range values {
+		if s == "" {
+			return "must not be empty"
+		}
+		if rule.MaxLength > 0 && len(s) > rule.MaxLength {
+			return fmt.Sprintf("must be at most %d characters", rule.MaxLength)
+		}
+		if rule.pattern != nil && !rule.pattern.MatchString(s) {
+			return fmt.Sprintf("must match %s", rule.Pattern)
+		}
+		if rule.enum.Len() > 0 && !rule.enum.Has(s) {
+			return fmt.Sprintf("must be one of (%s)", strings.Join(rule.Enum, " "))
+		}
+	}
+	return ""
+}
+
+func scalarString(v any) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case int, int64, uint64, float64, bool:
+		return fmt.Sprint(v), true
+	}
+	return "", false
+}
@@ -0,0 +1,95 @@
+package merlin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateMetadata(t *testing.T) {
+	schema, err := ParseMetadataSchema([]byte(`
+license:
+  required: true
+  enum: [apache-2.0, mit]
+tags:
+  type: list
+  max_items: 2
+datasets:
+  type: list
+  pattern: '^[\w-]+/[\w.-]+$'
+pipeline_tag:
+  type: string
+  enum: [text-generation, fill-mask]
+`))
+	assert.NoError(t, err)
+
+	cases := []struct {
+		name    string
+		content string
+		errs    []MetadataFieldError
+	}{
+		{
+			name:    "no front matter",
+			content: "# Model\n",
+			errs:    []MetadataFieldError{{Field: "license", Message: "is required"}},
+		},
+		{
+			name:    "valid",
+			content: "---\nlicense: mit\ntags: [nlp, 2024]\ndatasets:\n  - org/data.v1\npipeline_tag: fill-mask\n---\n# Model\n",
+		},
+		{
+			name:    "license list",
+			content: "---\nlicense:\n  - mit\n  - apache-2.0\n---\n",
+		},
+		{
+			name:    "missing license",
+			content: "---\ntags: [nlp]\n---\n",
+			errs:    []MetadataFieldError{{Field: "license", Message: "is required"}},
+		},
+		{
+			name:    "invalid fields",
+			content: "---\nlicense: [mit, gpl]\ntags: nlp\ndatasets: [data]\npipeline_tag: [fill-mask]\n---\n",
+			errs: []MetadataFieldError{
+				{Field: "datasets", Message: `must match ^[\w-]+/[\w.-]+$`},
+				{Field: "license", Message: "must be one of (apache-2.0 mit)"},
+				{Field: "pipeline_tag", Message: "must be a string"},
+				{Field: "tags", Message: "must be a list"},
+			},
+		},
+		{
+			name:    "too many tags",
+			content: "---\nlicense: mit\ntags: [a, b, c]\n---\n",
+			errs:    []MetadataFieldError{{Field: "tags", Message: "must have at most 2 items"}},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := ValidateMetadata([]byte(c.content), schema)
+			if c.errs == nil {
+				assert.NoError(t, err)
+				return
+			}
+			if assert.True(t, IsErrInvalidMetadata(err)) {
+				assert.Equal(t, c.errs, err.(ErrInvalidMetadata).Fields)
+			}
+		})
+	}
+
+	// the licenses of the push policy replace the enum of the schema
+	restricted := schema.WithLicenses([]string{"gpl-3.0"})
+	assert.NoError(t, ValidateMetadata([]byte("---\nlicense: gpl-3.0\n---\n"), restricted))
+	assert.True(t, IsErrInvalidMetadata(ValidateMetadata([]byte("---\nlicense: mit\n---\n"), restricted)))
+	assert.True(t, IsErrInvalidMetadata(ValidateMetadata([]byte("---\nlicense: gpl-3.0\n---\n"), schema)))
+
+	// the default schema only validates the license, which is required once the licenses are restricted
+	defaultSchema := DefaultMetadataSchema()
+	assert.NoError(t, ValidateMetadata([]byte("# Model\n"), defaultSchema))
+	assert.NoError(t, ValidateMetadata([]byte("---\nlicense: mit\ntags: nlp\npipeline_tag: [fill-mask]\n---\n"), defaultSchema))
+	err = ValidateMetadata([]byte("# Model\n"), defaultSchema.WithLicenses([]string{"mit"}))
+	if assert.True(t, IsErrInvalidMetadata(err)) {
+		assert.Equal(t, []MetadataFieldError{{Field: "license", Message: "is required"}}, err.(ErrInvalidMetadata).Fields)
+	}
+
+	_, err = ParseMetadataSchema([]byte("license:\n  type: number\n"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,132 @@
+package merlin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// ErrSensitiveContent is returned when the moderator rejects the submitted content
+var ErrSensitiveContent = errors.New("Sensitive data found in submission, Please remove it and try again.")
+
+// VerdictLabelFailOpen is the label of the verdicts accepted by the fail-open policy without moderation
+const VerdictLabelFailOpen = "fail-open"
+
+// Verdict is the result of a moderation
+type Verdict struct {
+	Pass bool
+	// Label is the provider specific reason of the verdict
+	Label string
+}
+
+// Moderator checks whether a text contains sensitive content
+type Moderator interface {
+	Name() string
+	ModerateText(ctx context.Context, content string) (*Verdict, error)
+}
+
+// NewModeratorFunc creates a moderator from the settings
+type NewModeratorFunc func() (Moderator, error)
+
+var (
+	moderatorMap  = map[string]NewModeratorFunc{}
+	moderator     Moderator
+	moderatorErr  error
+	moderatorOnce sync.Once
+)
+
+// RegisterModerator registers a moderator provider with a function to create it
+func RegisterModerator(name string, fn NewModeratorFunc) {
+	moderatorMap[name] = fn
+}
+
+// GetModerator returns the moderator configured by [merlin] MODERATOR
+func GetModerator() (Moderator, error) {
+	moderatorOnce.Do(func() {
+		fn, ok := moderatorMap[setting.Moderation.Provider]
+		if !ok {
+			moderatorErr = fmt.Errorf("unknown moderator %q", setting.Moderation.Provider)
+			return
+		}
+		moderator, moderatorErr = fn()
+	})
+	return moderator, moderatorErr
+}
+
+// ProviderName returns the name of the configured moderator
+func ProviderName() string {
+	return setting.Moderation.Provider
+}
+
+// ModerateText checks the content with the configured moderator and applies the failure policy
+// when the moderator is unavailable. It returns ErrSensitiveContent if the content is rejected.
+func ModerateText(ctx context.Context, content string) (*Verdict, error) {
+	verdict, err := moderateText(ctx, content)
+	if err != nil {
+		if setting.Moderation.FailOpen {
+			log.Warn("moderation failed, the content is accepted by the fail-open policy: %v", err)
+			verdict = &Verdict{Pass: true, Label: VerdictLabelFailOpen}
+			recordVerdict(ctx, verdict)
+			return verdict, nil
+		}
+		recordVerdict(ctx, nil)
+		return nil, err
+	}
+	recordVerdict(ctx, verdict)
+	if !verdict.Pass {
+		return verdict, ErrSensitiveContent
+	}
+	return verdict, nil
+}
+
+var verdictCounter, _ = otel.Meter("gitea_moderation_meter").Int64Counter("gitea.moderation.verdicts",
+	metric.WithDescription("Number of moderation verdicts by outcome"),
+)
+
+// recordVerdict counts a verdict of the configured moderator, a nil verdict counts a failed moderation
+func recordVerdict(ctx context.Context, verdict *Verdict) {
+	outcome, label := "error", ""
+	if verdict != nil {
+		outcome, label = "rejected", verdict.Label
+		if verdict.Pass {
+			outcome = "passed"
+		}
+	}
+	verdictCounter.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("moderation.provider", ProviderName()),
+		attribute.String("moderation.outcome", outcome),
+		attribute.String("moderation.label", label),
+	))
+}
+
+func moderateText(ctx context.Context, content string) (*Verdict, error) {
+	m, err := GetModerator()
+	if err != nil {
+		return nil, err
+	}
+	return m.ModerateText(ctx, content)
+}
+
+type noneModerator struct{}
+
+func (noneModerator) Name() string {
+	return "none"
+}
+
+func (noneModerator) ModerateText(_ context.Context, _ string) (*Verdict, error) {
+	return &Verdict{Pass: true}, nil
+}
+
+func init() {
+	RegisterModerator("none", func() (Moderator, error) {
+		return noneModerator{}, nil
+	})
+}
@@ -0,0 +1,89 @@
+package merlin
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"code.gitea.io/gitea/modules/setting"
+)
+
+const dictionaryRegexpPrefix = "re:"
+
+// dictionaryModerator rejects the texts containing a keyword or matching a regular expression of the dictionary
+type dictionaryModerator struct {
+	keywords []string
+	patterns []*regexp.Regexp
+}
+
+// newDictionaryModerator creates a dictionary moderator from the entries, the entries prefixed with "re:" are regular expressions
+func newDictionaryModerator(entries []string) (*dictionaryModerator, error) {
+	m := &dictionaryModerator{}
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		if expr, ok := strings.CutPrefix(entry, dictionaryRegexpPrefix); ok {
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid moderation pattern %q: %w", expr, err)
+			}
+			m.patterns = append(m.patterns, re)
+			continue
+		}
+		m.keywords = append(m.keywords, strings.ToLower(entry))
+	}
+	return m, nil
+}
+
+func (m *dictionaryModerator) Name() string {
+	return "dictionary"
+}
+
+func (m *dictionaryModerator) ModerateText(_ context.Context, content string) (*Verdict, error) {
+	lower := strings.ToLower(content)
+	for _, keyword := range m.keywords {
+		if strings.Contains(lower, keyword) {
+			return &Verdict{Pass: false, Label: "keyword"}, nil
+		}
+	}
+	for _, re := range m.patterns {
+		if re.MatchString(content) {
+			return &Verdict{Pass: false, Label: "pattern"}, nil
+		}
+	}
+	return &Verdict{Pass: true}, nil
+}
+
+func loadDictionaryFile(name string) ([]string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		entries = append(entries, scanner.Text())
+	}
+	return entries, scanner.Err()
+}
+
+func init() {
+	RegisterModerator("dictionary", func() (Moderator, error) {
+		entries := setting.Moderation.DictionaryKeywords
+		if setting.Moderation.DictionaryFile != "" {
+			fileEntries, err := loadDictionaryFile(setting.Moderation.DictionaryFile)
+			if err != nil {
+				return nil, fmt.Errorf("unable to load moderation dictionary: %w", err)
+			}
+			entries = append(append([]string{}, entries...), fileEntries...)
+		}
+		return newDictionaryModerator(entries)
+	})
+}
@@ -0,0 +1,34 @@
+package merlin
+
+import (
+	"context"
+
+	"gitee.com/modelers/moderation-server-sdk/moderation"
+	moderationapi "gitee.com/modelers/moderation-server-sdk/moderation/api"
+)
+
+// sdkModerator checks the texts with the moderation server
+type sdkModerator struct{}
+
+func (sdkModerator) Name() string {
+	return "sdk"
+}
+
+func (sdkModerator) ModerateText(_ context.Context, content string) (*Verdict, error) {
+	req := moderation.ReqToModerationText{
+		Text:    content,
+		Type:    "comment",
+		BizType: "test",
+	}
+	res, _, err := moderationapi.ModerationText(&req)
+	if err != nil {
+		return nil, err
+	}
+	return &Verdict{Pass: res.Result == "pass", Label: res.Result}, nil
+}
+
+func init() {
+	RegisterModerator("sdk", func() (Moderator, error) {
+		return sdkModerator{}, nil
+	})
+}
@@ -0,0 +1,152 @@
+package merlin
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestDictionaryModerator(t *testing.T) {
+	m, err := newDictionaryModerator([]string{"# comment", "Forbidden", "re:secret-[0-9]+", ""})
+	assert.NoError(t, err)
+
+	verdict, err := m.ModerateText(context.Background(), "an acceptable text")
+	assert.NoError(t, err)
+	assert.True(t, verdict.Pass)
+
+	verdict, err = m.ModerateText(context.Background(), "a FORBIDDEN text")
+	assert.NoError(t, err)
+	assert.False(t, verdict.Pass)
+	assert.Equal(t, "keyword", verdict.Label)
+
+	verdict, err = m.ModerateText(context.Background(), "leaks secret-42")
+	assert.NoError(t, err)
+	assert.False(t, verdict.Pass)
+	assert.Equal(t, "pattern", verdict.Label)
+
+	_, err = newDictionaryModerator([]string{"re:("})
+	assert.Error(t, err)
+}
+
+type failingModerator struct{}
+
+func (failingModerator) Name() string {
+	return "failing"
+}
+
+func (failingModerator) ModerateText(_ context.Context, _ string) (*Verdict, error) {
+	return nil, errors.New("unavailable")
+}
+
+func TestModerateTextFailurePolicy(t *testing.T) {
+	defer test.MockVariableValue(&moderator, Moderator(failingModerator{}))()
+	defer test.MockVariableValue(&moderatorErr, nil)()
+	moderatorOnce.Do(func() {})
+
+	defer test.MockVariableValue(&setting.Moderation.FailOpen, false)()
+	_, err := ModerateText(context.Background(), "text")
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, ErrSensitiveContent))
+
+	setting.Moderation.FailOpen = true
+	verdict, err := ModerateText(context.Background(), "text")
+	assert.NoError(t, err)
+	assert.True(t, verdict.Pass)
+}
+
+func TestModerateTextVerdictMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	counter, err := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("test").Int64Counter("gitea.moderation.verdicts")
+	assert.NoError(t, err)
+	defer test.MockVariableValue(&verdictCounter, counter)()
+
+	defer test.MockVariableValue(&moderator, Moderator(&recordingModerator{}))()
+	defer test.MockVariableValue(&moderatorErr, nil)()
+	moderatorOnce.Do(func() {})
+
+	_, _ = ModerateText(context.Background(), "fine")
+	_, _ = ModerateText(context.Background(), "also fine")
+	_, _ = ModerateText(context.Background(), "forbidden")
+
+	var rm metricdata.ResourceMetrics
+	assert.NoError(t, reader.Collect(context.Background(), &rm))
+	counts := map[string]int64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+				outcome, _ := dp.Attributes.Value("moderation.outcome")
+				counts[outcome.AsString()] += dp.Value
+			}
+		}
+	}
+	assert.Equal(t, map[string]int64{"passed": 2, "rejected": 1}, counts)
+}
+
+type recordingModerator struct {
+	texts []string
+}
+
+func (m *recordingModerator) Name() string {
+	return "recording"
+}
+
+func (m *recordingModerator) ModerateText(_ context.Context, content string) (*Verdict, error) {
+	m.texts = append(m.texts, content)
+	return &Verdict{Pass: !strings.Contains(content, "forbidden")}, nil
+}
+
+func TestCheckContents(t *testing.T) {
+	m := &recordingModerator{}
+	defer test.MockVariableValue(&moderator, Moderator(m))()
+	defer test.MockVariableValue(&moderatorErr, nil)()
+	moderatorOnce.Do(func() {})
+	defer test.MockVariableValue(&setting.MaxTextLen, 30)()
+
+	blobs := []*ContentBlob{
+		{SHA: "1", Path: "a.md", Content: "first"},
+		{SHA: "2", Path: "b.md", Content: "second"},
+		{SHA: "3", Path: "c.md", Content: strings.Repeat("x", 45)},
+	}
+	assert.NoError(t, CheckContents(context.Background(), blobs))
+	// the long file is split into two segments, the small files are moderated in one batch
+	assert.Equal(t, []string{strings.Repeat("x", 30), strings.Repeat("x", 15), "first" + templateText + "second"}, m.texts)
+
+	m.texts = nil
+	blobs = []*ContentBlob{
+		{SHA: "4", Path: "d.md", Content: "fine"},
+		{SHA: "5", Path: "e.md", Content: "forbidden"},
+	}
+	err := CheckContents(context.Background(), blobs)
+	assert.True(t, IsErrSensitiveBlob(err))
+	assert.True(t, errors.Is(err, ErrSensitiveContent))
+	assert.Equal(t, "e.md", err.(ErrSensitiveBlob).Path)
+	// the rejected batch is moderated again file by file
+	assert.Equal(t, []string{"fine" + templateText + "forbidden", "fine", "forbidden"}, m.texts)
+}
+
+func TestContentChecker(t *testing.T) {
+	m := &recordingModerator{}
+	defer test.MockVariableValue(&moderator, Moderator(m))()
+	defer test.MockVariableValue(&moderatorErr, nil)()
+	moderatorOnce.Do(func() {})
+	defer test.MockVariableValue(&setting.MaxTextLen, 10)()
+
+	checker := NewContentChecker(context.Background())
+	assert.NoError(t, checker.Add(&ContentBlob{SHA: "6", Path: "f.md", Content: "abc"}))
+	assert.Empty(t, m.texts)
+	// the batch is moderated once it is full, before the next blobs are read
+	assert.NoError(t, checker.Add(&ContentBlob{SHA: "7", Path: "g.md", Content: "defghij"}))
+	assert.Equal(t, []string{"abc"}, m.texts)
+	assert.NoError(t, checker.Flush())
+	assert.Equal(t, []string{"abc", "defghij"}, m.texts)
+	assert.NoError(t, checker.Flush())
+	assert.Len(t, m.texts, 2)
+}
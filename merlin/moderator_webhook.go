@@ -0,0 +1,82 @@
+package merlin
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+type webhookModerationRequest struct {
+	Text string `json:"text"`
+	Type string `json:"type"`
+}
+
+type webhookModerationResponse struct {
+	Result string `json:"result"`
+	Label  string `json:"label"`
+}
+
+// webhookModerator posts the texts to a generic http endpoint, which responds with {"result": "pass"} for acceptable content
+type webhookModerator struct {
+	client      *http.Client
+	url         string
+	token       string
+	tokenHeader string
+}
+
+func (m *webhookModerator) Name() string {
+	return "webhook"
+}
+
+func (m *webhookModerator) ModerateText(ctx context.Context, content string) (*Verdict, error) {
+	body, err := json.Marshal(&webhookModerationRequest{Text: content, Type: "comment"})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if m.token != "" {
+		req.Header.Set(m.tokenHeader, m.token)
+	}
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("moderation webhook responded with status %d", resp.StatusCode)
+	}
+	var res webhookModerationResponse
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, err
+	}
+	label := res.Label
+	if label == "" {
+		label = res.Result
+	}
+	return &Verdict{Pass: res.Result == "pass", Label: label}, nil
+}
+
+func init() {
+	RegisterModerator("webhook", func() (Moderator, error) {
+		if setting.Moderation.WebhookURL == "" {
+			return nil, errors.New("[moderation_server] WEBHOOK_URL is required by the webhook moderator")
+		}
+		return &webhookModerator{
+			client:      &http.Client{Timeout: setting.Moderation.WebhookTimeout},
+			url:         setting.Moderation.WebhookURL,
+			token:       setting.Moderation.WebhookToken,
+			tokenHeader: setting.Moderation.WebhookTokenHeader,
+		}, nil
+	})
+}
@@ -14,6 +14,8 @@ import (
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/util"
+
+	"xorm.io/builder"
 )
 
 // ArtifactStatus is the status of an artifact, uploading, expired or need-delete
@@ -143,6 +145,86 @@ func ListUploadedArtifactsMeta(ctx context.Context, runID int64) ([]*ActionArtif
 		Find(&arts)
 }
 
+// ActionArtifactSummary is an artifact as a whole, which consists of the files uploaded with the same name in a run
+type ActionArtifactSummary struct {
+	ID           int64 // the id of the first file of the artifact
+	RunID        int64
+	ArtifactName string
+	FileSize     int64
+	StorageSize  int64 // the size of the files in the storage, which may be compressed
+	Status       int64
+	CreatedUnix  timeutil.TimeStamp
+	UpdatedUnix  timeutil.TimeStamp
+	ExpiredUnix  timeutil.TimeStamp
+}
+
+// IsExpired returns true if the files of the artifact have been removed
+func (art *ActionArtifactSummary) IsExpired() bool {
+	return art.Status == int64(ArtifactStatusExpired)
+}
+
+// FindArtifactsOptions are the options to find uploaded artifacts
+type FindArtifactsOptions struct {
+	db.ListOptions
+	RepoID       int64
+	RunID        int64
+	ArtifactName string
+}
+
+func (opts FindArtifactsOptions) toConds() builder.Cond {
+	cond := builder.In("status", ArtifactStatusUploadConfirmed, ArtifactStatusExpired)
+	if opts.RepoID > 0 {
+		cond = cond.And(builder.Eq{"repo_id": opts.RepoID})
+	}
+	if opts.RunID > 0 {
+		cond = cond.And(builder.Eq{"run_id": opts.RunID})
+	}
+	if opts.ArtifactName != "" {
+		cond = cond.And(builder.Eq{"artifact_name": opts.ArtifactName})
+	}
+	return cond
+}
+
+// FindArtifactSummaries returns the uploaded artifacts, including the expired ones, newest first
+func FindArtifactSummaries(ctx context.Context, opts FindArtifactsOptions) ([]*ActionArtifactSummary, int64, error) {
+	cond := opts.toConds()
+	total, err := db.GetEngine(ctx).
+		Where(builder.In("id", builder.Select("min(id)").From("action_artifact").Where(cond).GroupBy("run_id, artifact_name"))).
+		Count(new(ActionArtifact))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sess := db.GetEngine(ctx).Table("action_artifact").Where(cond).
+		GroupBy("run_id, artifact_name").
+		Select("min(id) AS id, run_id, artifact_name, sum(file_size) AS file_size, sum(file_compressed_size) AS storage_size, max(status) AS status, " +
+			"min(created_unix) AS created_unix, max(updated_unix) AS updated_unix, max(expired_unix) AS expired_unix").
+		OrderBy("min(id) DESC")
+	if opts.PageSize > 0 && opts.Page >= 1 {
+		sess.Limit(opts.PageSize, (opts.Page-1)*opts.PageSize)
+	}
+	arts := make([]*ActionArtifactSummary, 0, 10)
+	return arts, total, sess.Find(&arts)
+}
+
+// GetArtifactSummaryByID returns the artifact of a repository which the file with the id belongs to
+func GetArtifactSummaryByID(ctx context.Context, repoID, id int64) (*ActionArtifactSummary, error) {
+	art, err := GetArtifactByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if art.RepoID != repoID {
+		return nil, util.ErrNotExist
+	}
+	arts, _, err := FindArtifactSummaries(ctx, FindArtifactsOptions{RunID: art.RunID, ArtifactName: art.ArtifactName})
+	if err != nil {
+		return nil, err
+	} else if len(arts) == 0 || arts[0].ID != id {
+		return nil, util.ErrNotExist
+	}
+	return arts[0], nil
+}
+
 // ListArtifactsByRepoID returns all artifacts of a repo
 func ListArtifactsByRepoID(ctx context.Context, repoID int64) ([]*ActionArtifact, error) {
 	arts := make([]*ActionArtifact, 0, 10)
@@ -155,6 +237,40 @@ func ListArtifactsByRunIDAndName(ctx context.Context, runID int64, name string)
 	return arts, db.GetEngine(ctx).Where("run_id=? AND artifact_name=?", runID, name).Find(&arts)
 }
 
+// ListUploadedArtifactsByRepoID returns all uploaded artifacts of a repository, oldest first
+func ListUploadedArtifactsByRepoID(ctx context.Context, repoID int64) ([]*ActionArtifact, error) {
+	arts := make([]*ActionArtifact, 0, 10)
+	return arts, db.GetEngine(ctx).Where("repo_id=? AND status=?", repoID, ArtifactStatusUploadConfirmed).Asc("id").Find(&arts)
+}
+
+// GetArtifactsStorageSize returns the size in the storage of the uploaded artifacts of a repository
+func GetArtifactsStorageSize(ctx context.Context, repoID int64) (int64, error) {
+	return db.GetEngine(ctx).Where("repo_id=? AND status=?", repoID, ArtifactStatusUploadConfirmed).
+		SumInt(new(ActionArtifact), "file_compressed_size")
+}
+
+// ArtifactsStorageUsage is the size in the storage of the uploaded artifacts of a repository
+type ArtifactsStorageUsage struct {
+	RepoID      int64
+	OwnerID     int64
+	StorageSize int64
+}
+
+// ListArtifactsStorageUsages returns the size in the storage of the uploaded artifacts of every repository having some,
+// if ownerID is set only the repositories of the owner are returned
+func ListArtifactsStorageUsages(ctx context.Context, ownerID int64) ([]*ArtifactsStorageUsage, error) {
+	cond := builder.Eq{"status": ArtifactStatusUploadConfirmed}
+	if ownerID > 0 {
+		cond["owner_id"] = ownerID
+	}
+	usages := make([]*ArtifactsStorageUsage, 0, 10)
+	return usages, db.GetEngine(ctx).Table("action_artifact").Where(cond).
+		GroupBy("repo_id, owner_id").
+		Select("repo_id, owner_id, sum(file_compressed_size) AS storage_size").
+		OrderBy("storage_size DESC").
+		Find(&usages)
+}
+
 // ListNeedExpiredArtifacts returns all need expired artifacts but not deleted
 func ListNeedExpiredArtifacts(ctx context.Context) ([]*ActionArtifact, error) {
 	arts := make([]*ActionArtifact, 0, 10)
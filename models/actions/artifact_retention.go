@@ -0,0 +1,174 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// ArtifactRetentionUnlimitedSize disables the artifacts size limit inherited from the organization or the global setting
+const ArtifactRetentionUnlimitedSize = -1
+
+// ActionArtifactRetention overrides how long and how much the artifacts are kept for the repositories of an organization
+// (RepoID is 0) or for a single repository (OwnerID is 0). The zero values inherit the retention of the upper level.
+type ActionArtifactRetention struct {
+	ID      int64
+	OwnerID int64 `xorm:"INDEX UNIQUE(owner_repo) NOT NULL DEFAULT 0"`
+	RepoID  int64 `xorm:"INDEX UNIQUE(owner_repo) NOT NULL DEFAULT 0"`
+	// RetentionDays is the number of days the artifacts are kept after being uploaded
+	RetentionDays int64
+	// MaxSize is the max total size in bytes of the artifacts of a repository, ArtifactRetentionUnlimitedSize for no limit
+	MaxSize     int64
+	CreatedUnix timeutil.TimeStamp `xorm:"created NOT NULL"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated NOT NULL"`
+}
+
+func init() {
+	db.RegisterModel(new(ActionArtifactRetention))
+}
+
+// IsEmpty returns true if the retention inherits everything
+func (r *ActionArtifactRetention) IsEmpty() bool {
+	return r.RetentionDays == 0 && r.MaxSize == 0
+}
+
+// GetArtifactRetention returns the retention of the organization or the repository, an empty retention is returned if it is not set
+func GetArtifactRetention(ctx context.Context, ownerID, repoID int64) (*ActionArtifactRetention, error) {
+	r := &ActionArtifactRetention{OwnerID: ownerID, RepoID: repoID}
+	if _, err := db.GetEngine(ctx).Where("owner_id = ? AND repo_id = ?", ownerID, repoID).Get(r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// SetArtifactRetention creates or updates the retention of the organization or the repository, an empty retention is removed
+func SetArtifactRetention(ctx context.Context, r *ActionArtifactRetention) error {
+	if (r.OwnerID == 0) == (r.RepoID == 0) {
+		return fmt.Errorf("the artifact retention must be bound to either an owner or a repository")
+	}
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		e := db.GetEngine(ctx)
+		if r.IsEmpty() {
+			_, err := e.Where("owner_id = ? AND repo_id = ?", r.OwnerID, r.RepoID).Delete(new(ActionArtifactRetention))
+			r.ID = 0
+			return err
+		}
+		existing := new(ActionArtifactRetention)
+		has, err := e.Where("owner_id = ? AND repo_id = ?", r.OwnerID, r.RepoID).Get(existing)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return db.Insert(ctx, r)
+		}
+		r.ID = existing.ID
+		_, err = e.ID(r.ID).Cols("retention_days", "max_size").Update(r)
+		return err
+	})
+}
+
+// DeleteArtifactRetentions removes the retentions of the organization or the repository
+func DeleteArtifactRetentions(ctx context.Context, ownerID, repoID int64) error {
+	_, err := db.GetEngine(ctx).Where("owner_id = ? AND repo_id = ?", ownerID, repoID).Delete(new(ActionArtifactRetention))
+	return err
+}
+
+// the levels a retention is inherited from
+const (
+	ArtifactRetentionLevelGlobal = "global"
+	ArtifactRetentionLevelOrg    = "org"
+	ArtifactRetentionLevelRepo   = "repo"
+)
+
+// EffectiveArtifactRetention is the retention applied to the artifacts after the inheritance is resolved
+type EffectiveArtifactRetention struct {
+	RetentionDays      int64
+	RetentionDaysLevel string
+	// MaxSize is the max total size in bytes of the artifacts of a repository, there is no limit if it is 0
+	MaxSize      int64
+	MaxSizeLevel string
+}
+
+func globalArtifactRetention() *EffectiveArtifactRetention {
+	return &EffectiveArtifactRetention{
+		RetentionDays:      setting.Actions.ArtifactRetentionDays,
+		RetentionDaysLevel: ArtifactRetentionLevelGlobal,
+		MaxSize:            setting.Actions.ArtifactMaxSize,
+		MaxSizeLevel:       ArtifactRetentionLevelGlobal,
+	}
+}
+
+func (e *EffectiveArtifactRetention) override(r *ActionArtifactRetention, level string) {
+	if r.RetentionDays > 0 {
+		e.RetentionDays, e.RetentionDaysLevel = r.RetentionDays, level
+	}
+	if r.MaxSize != 0 {
+		e.MaxSize, e.MaxSizeLevel = max(r.MaxSize, 0), level
+	}
+}
+
+// GetEffectiveArtifactRetention resolves the retention of the repository, which overrides the retention of its owner,
+// which overrides the global settings. If repoID is 0 the retention inherited by the repositories of the owner is returned.
+func GetEffectiveArtifactRetention(ctx context.Context, ownerID, repoID int64) (*EffectiveArtifactRetention, error) {
+	retentions := make([]*ActionArtifactRetention, 0, 2)
+	cond := "owner_id = ? AND repo_id = 0"
+	args := []any{ownerID}
+	if repoID > 0 {
+		cond = "(owner_id = ? AND repo_id = 0) OR (owner_id = 0 AND repo_id = ?)"
+		args = append(args, repoID)
+	}
+	if err := db.GetEngine(ctx).Where(cond, args...).Find(&retentions); err != nil {
+		return nil, err
+	}
+
+	effective := globalArtifactRetention()
+	for _, level := range []string{ArtifactRetentionLevelOrg, ArtifactRetentionLevelRepo} {
+		for _, r := range retentions {
+			if (r.RepoID == 0) == (level == ArtifactRetentionLevelOrg) {
+				effective.override(r, level)
+			}
+		}
+	}
+	return effective, nil
+}
+
+// ExpiredDays returns the number of days an artifact is kept, the requested retention days of the upload are
+// honored within the effective retention
+func (e *EffectiveArtifactRetention) ExpiredDays(requested int64) int64 {
+	if requested <= 0 || requested > e.RetentionDays {
+		return e.RetentionDays
+	}
+	return requested
+}
+
+// ErrArtifactRetentionNotPermitted represents a retention looser than the retention it overrides
+type ErrArtifactRetentionNotPermitted struct {
+	Field string
+}
+
+// IsErrArtifactRetentionNotPermitted checks if an error is a ErrArtifactRetentionNotPermitted
+func IsErrArtifactRetentionNotPermitted(err error) bool {
+	_, ok := err.(ErrArtifactRetentionNotPermitted)
+	return ok
+}
+
+func (err ErrArtifactRetentionNotPermitted) Error() string {
+	return fmt.Sprintf("the %s can not be looser than the inherited retention", err.Field)
+}
+
+// CheckOverride checks the retention only restricts the inherited retention, only the site admins may loosen it
+func (e *EffectiveArtifactRetention) CheckOverride(r *ActionArtifactRetention) error {
+	if r.RetentionDays > e.RetentionDays {
+		return ErrArtifactRetentionNotPermitted{Field: "retention days"}
+	}
+	if e.MaxSize > 0 && (r.MaxSize < 0 || r.MaxSize > e.MaxSize) {
+		return ErrArtifactRetentionNotPermitted{Field: "max size"}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEffectiveArtifactRetention(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	defer test.MockVariableValue(&setting.Actions.ArtifactRetentionDays, int64(90))()
+	defer test.MockVariableValue(&setting.Actions.ArtifactMaxSize, int64(1000))()
+
+	// the organization 3 owns the repository 3
+	retention, err := GetEffectiveArtifactRetention(db.DefaultContext, 3, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, &EffectiveArtifactRetention{
+		RetentionDays:      90,
+		RetentionDaysLevel: ArtifactRetentionLevelGlobal,
+		MaxSize:            1000,
+		MaxSizeLevel:       ArtifactRetentionLevelGlobal,
+	}, retention)
+	assert.EqualValues(t, 90, retention.ExpiredDays(0))
+	assert.EqualValues(t, 5, retention.ExpiredDays(5))
+	assert.EqualValues(t, 90, retention.ExpiredDays(400))
+
+	orgRetention := &ActionArtifactRetention{OwnerID: 3, RetentionDays: 30, MaxSize: 500}
+	assert.NoError(t, retention.CheckOverride(orgRetention))
+	assert.NoError(t, SetArtifactRetention(db.DefaultContext, orgRetention))
+
+	repoRetention := &ActionArtifactRetention{RepoID: 3, MaxSize: ArtifactRetentionUnlimitedSize}
+	inherited, err := GetEffectiveArtifactRetention(db.DefaultContext, 3, 0)
+	assert.NoError(t, err)
+	assert.True(t, IsErrArtifactRetentionNotPermitted(inherited.CheckOverride(repoRetention)))
+	assert.True(t, IsErrArtifactRetentionNotPermitted(inherited.CheckOverride(&ActionArtifactRetention{RepoID: 3, RetentionDays: 60})))
+	assert.NoError(t, SetArtifactRetention(db.DefaultContext, repoRetention))
+
+	retention, err = GetEffectiveArtifactRetention(db.DefaultContext, 3, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, &EffectiveArtifactRetention{
+		RetentionDays:      30,
+		RetentionDaysLevel: ArtifactRetentionLevelOrg,
+		MaxSize:            0,
+		MaxSizeLevel:       ArtifactRetentionLevelRepo,
+	}, retention)
+
+	// the empty retention inherits everything and is removed
+	repoRetention.MaxSize = 0
+	assert.NoError(t, SetArtifactRetention(db.DefaultContext, repoRetention))
+	unittest.AssertNotExistsBean(t, &ActionArtifactRetention{RepoID: 3})
+
+	retention, err = GetEffectiveArtifactRetention(db.DefaultContext, 3, 3)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 500, retention.MaxSize)
+
+	assert.NoError(t, DeleteArtifactRetentions(db.DefaultContext, 3, 0))
+	unittest.AssertNotExistsBean(t, &ActionArtifactRetention{OwnerID: 3})
+}
+
+func TestArtifactsStorageUsage(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	for _, art := range []*ActionArtifact{
+		{RunID: 791, RepoID: 4, OwnerID: 5, ArtifactName: "dist", ArtifactPath: "a.txt", FileSize: 30, FileCompressedSize: 3, Status: int64(ArtifactStatusUploadConfirmed)},
+		{RunID: 791, RepoID: 4, OwnerID: 5, ArtifactName: "dist", ArtifactPath: "b.txt", FileSize: 4, FileCompressedSize: 4, Status: int64(ArtifactStatusUploadConfirmed)},
+		{RunID: 791, RepoID: 4, OwnerID: 5, ArtifactName: "coverage", ArtifactPath: "c.txt", FileSize: 5, FileCompressedSize: 5, Status: int64(ArtifactStatusExpired)},
+		{RunID: 792, RepoID: 4, OwnerID: 5, ArtifactName: "dist", ArtifactPath: "a.txt", FileSize: 7, FileCompressedSize: 7, Status: int64(ArtifactStatusUploadConfirmed)},
+		{RunID: 793, RepoID: 1, OwnerID: 2, ArtifactName: "dist", ArtifactPath: "a.txt", FileSize: 1, FileCompressedSize: 1, Status: int64(ArtifactStatusUploadConfirmed)},
+	} {
+		insertTestArtifact(t, art)
+	}
+
+	size, err := GetArtifactsStorageSize(db.DefaultContext, 4)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 14, size)
+
+	arts, err := ListUploadedArtifactsByRepoID(db.DefaultContext, 4)
+	assert.NoError(t, err)
+	if assert.Len(t, arts, 3) {
+		assert.EqualValues(t, 791, arts[0].RunID)
+		assert.EqualValues(t, 792, arts[2].RunID)
+	}
+
+	usages, err := ListArtifactsStorageUsages(db.DefaultContext, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []*ArtifactsStorageUsage{
+		{RepoID: 4, OwnerID: 5, StorageSize: 14},
+		{RepoID: 1, OwnerID: 2, StorageSize: 1},
+	}, usages)
+
+	usages, err = ListArtifactsStorageUsages(db.DefaultContext, 2)
+	assert.NoError(t, err)
+	assert.Len(t, usages, 1)
+}
@@ -0,0 +1,63 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// insertTestArtifact inserts the artifact and deletes it when the test finishes,
+// so that the tests sharing the artifacts table don't see each other's rows.
+func insertTestArtifact(t *testing.T, art *ActionArtifact) {
+	assert.NoError(t, db.Insert(db.DefaultContext, art))
+	t.Cleanup(func() {
+		_, err := db.DeleteByID(db.DefaultContext, art.ID, &ActionArtifact{})
+		assert.NoError(t, err)
+	})
+}
+
+func TestFindArtifactSummaries(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	for _, art := range []*ActionArtifact{
+		{RunID: 791, RepoID: 4, ArtifactName: "dist", ArtifactPath: "a.txt", FileSize: 3, Status: int64(ArtifactStatusUploadConfirmed)},
+		{RunID: 791, RepoID: 4, ArtifactName: "dist", ArtifactPath: "b.txt", FileSize: 4, Status: int64(ArtifactStatusUploadConfirmed)},
+		{RunID: 791, RepoID: 4, ArtifactName: "coverage", ArtifactPath: "c.txt", FileSize: 5, Status: int64(ArtifactStatusExpired)},
+		{RunID: 791, RepoID: 4, ArtifactName: "pending", ArtifactPath: "d.txt", FileSize: 6, Status: int64(ArtifactStatusUploadPending)},
+		{RunID: 792, RepoID: 4, ArtifactName: "dist", ArtifactPath: "a.txt", FileSize: 7, Status: int64(ArtifactStatusUploadConfirmed)},
+	} {
+		insertTestArtifact(t, art)
+	}
+
+	arts, total, err := FindArtifactSummaries(db.DefaultContext, FindArtifactsOptions{RepoID: 4})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, total)
+	if assert.Len(t, arts, 3) {
+		assert.EqualValues(t, 792, arts[0].RunID)
+		assert.Equal(t, "coverage", arts[1].ArtifactName)
+		assert.EqualValues(t, ArtifactStatusExpired, arts[1].Status)
+		assert.Equal(t, "dist", arts[2].ArtifactName)
+		assert.EqualValues(t, 7, arts[2].FileSize)
+	}
+
+	arts, total, err = FindArtifactSummaries(db.DefaultContext, FindArtifactsOptions{
+		ListOptions:  db.ListOptions{Page: 1, PageSize: 1},
+		RepoID:       4,
+		ArtifactName: "dist",
+	})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, total)
+	assert.Len(t, arts, 1)
+
+	art, err := GetArtifactSummaryByID(db.DefaultContext, 4, arts[0].ID)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 792, art.RunID)
+	_, err = GetArtifactSummaryByID(db.DefaultContext, 1, arts[0].ID)
+	assert.Error(t, err)
+}
@@ -0,0 +1,165 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/container"
+
+	"xorm.io/builder"
+)
+
+func notDoneCond() builder.Cond {
+	return builder.NotIn("status", StatusSuccess, StatusFailure, StatusCancelled, StatusSkipped)
+}
+
+// findPreviousConcurrencyRuns returns the unfinished runs created before the run in its concurrency group
+func findPreviousConcurrencyRuns(ctx context.Context, run *ActionRun) (RunList, error) {
+	var runs RunList
+	return runs, db.GetEngine(ctx).
+		Where(builder.Eq{"repo_id": run.RepoID, "concurrency_group": run.ConcurrencyGroup}).
+		And(builder.Lt{"id": run.ID}).
+		And(notDoneCond()).
+		OrderBy("id").
+		Find(&runs)
+}
+
+// ShouldBlockRunByConcurrency returns whether the jobs of the run have to wait for a previous run of its concurrency group
+func ShouldBlockRunByConcurrency(ctx context.Context, run *ActionRun) (bool, error) {
+	if run.ConcurrencyGroup == "" {
+		return false, nil
+	}
+	runs, err := findPreviousConcurrencyRuns(ctx, run)
+	return len(runs) > 0, err
+}
+
+// CancelPreviousRunsByConcurrency cancels the previous runs of the concurrency group of the run.
+// In-progress runs are only cancelled if the run has cancel-in-progress set,
+// otherwise only the runs which are still pending are cancelled, since the run supersedes them.
+// It returns the jobs which have been cancelled.
+func CancelPreviousRunsByConcurrency(ctx context.Context, run *ActionRun) ([]*ActionRunJob, error) {
+	if run.ConcurrencyGroup == "" {
+		return nil, nil
+	}
+	runs, err := findPreviousConcurrencyRuns(ctx, run)
+	if err != nil {
+		return nil, err
+	}
+
+	var cancelledJobs []*ActionRunJob
+	for _, previous := range runs {
+		jobs, err := GetRunJobsByRunID(ctx, previous.ID)
+		if err != nil {
+			return cancelledJobs, err
+		}
+		if !run.ConcurrencyCancel {
+			started := false
+			for _, job := range jobs {
+				if job.TaskID != 0 {
+					started = true
+					break
+				}
+			}
+			if started {
+				continue
+			}
+		}
+		cancelled, err := CancelJobs(ctx, jobs)
+		cancelledJobs = append(cancelledJobs, cancelled...)
+		if err != nil {
+			return cancelledJobs, err
+		}
+	}
+	return cancelledJobs, nil
+}
+
+// ShouldBlockJobByConcurrency returns whether another job of the concurrency group of the job is waiting or running
+func ShouldBlockJobByConcurrency(ctx context.Context, job *ActionRunJob) (bool, error) {
+	if job.ConcurrencyGroup == "" {
+		return false, nil
+	}
+	return db.GetEngine(ctx).
+		Where(builder.Eq{"repo_id": job.RepoID, "concurrency_group": job.ConcurrencyGroup}).
+		And(builder.Neq{"id": job.ID}).
+		And(builder.In("status", StatusWaiting, StatusRunning)).
+		Exist(new(ActionRunJob))
+}
+
+// CancelPreviousJobsByConcurrency cancels the previous jobs of the concurrency group of the job.
+// In-progress jobs are only cancelled if the job has cancel-in-progress set,
+// otherwise only the jobs which are still blocked by the concurrency group are cancelled.
+// It returns the jobs which have been cancelled.
+func CancelPreviousJobsByConcurrency(ctx context.Context, job *ActionRunJob) ([]*ActionRunJob, error) {
+	if job.ConcurrencyGroup == "" {
+		return nil, nil
+	}
+	statuses := []Status{StatusBlocked}
+	if job.ConcurrencyCancel {
+		statuses = append(statuses, StatusWaiting, StatusRunning)
+	}
+	var jobs []*ActionRunJob
+	if err := db.GetEngine(ctx).
+		Where(builder.Eq{"repo_id": job.RepoID, "concurrency_group": job.ConcurrencyGroup}).
+		And(builder.Lt{"id": job.ID}).
+		And(builder.In("status", statuses)).
+		Find(&jobs); err != nil {
+		return nil, err
+	}
+	return CancelJobs(ctx, jobs)
+}
+
+// FindRunIDsUnblockedByConcurrency returns the runs which may be able to go on,
+// because the run or some of its jobs have finished and released their concurrency groups.
+func FindRunIDsUnblockedByConcurrency(ctx context.Context, run *ActionRun, jobs []*ActionRunJob) ([]int64, error) {
+	e := db.GetEngine(ctx)
+	runIDs := make(container.Set[int64])
+
+	if run.ConcurrencyGroup != "" && run.Status.IsDone() {
+		next := &ActionRun{}
+		has, err := e.Where(builder.Eq{"repo_id": run.RepoID, "concurrency_group": run.ConcurrencyGroup}).
+			And(builder.Neq{"id": run.ID}).
+			And(notDoneCond()).
+			OrderBy("id").
+			Get(next)
+		if err != nil {
+			return nil, err
+		}
+		if has {
+			runIDs.Add(next.ID)
+		}
+	}
+
+	groups := make(container.Set[string])
+	for _, job := range jobs {
+		if job.ConcurrencyGroup != "" && job.Status.IsDone() {
+			groups.Add(job.ConcurrencyGroup)
+		}
+	}
+	for group := range groups {
+		busy, err := e.Where(builder.Eq{"repo_id": run.RepoID, "concurrency_group": group}).
+			And(builder.In("status", StatusWaiting, StatusRunning)).
+			Exist(new(ActionRunJob))
+		if err != nil {
+			return nil, err
+		}
+		if busy {
+			continue
+		}
+		next := &ActionRunJob{}
+		has, err := e.Where(builder.Eq{"repo_id": run.RepoID, "concurrency_group": group, "status": StatusBlocked}).
+			And(builder.Neq{"run_id": run.ID}).
+			OrderBy("id").
+			Get(next)
+		if err != nil {
+			return nil, err
+		}
+		if has {
+			runIDs.Add(next.RunID)
+		}
+	}
+
+	return runIDs.Values(), nil
+}
@@ -0,0 +1,115 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func insertConcurrencyRun(t *testing.T, ctx context.Context, group string, cancel bool, jobs ...*ActionRunJob) (*ActionRun, []*ActionRunJob) {
+	run := &ActionRun{
+		RepoID:            4,
+		OwnerID:           5,
+		Index:             int64(unittest.GetCount(t, &ActionRun{}) + 1),
+		WorkflowID:        "test.yaml",
+		Ref:               "refs/heads/master",
+		Status:            StatusWaiting,
+		ConcurrencyGroup:  group,
+		ConcurrencyCancel: cancel,
+	}
+	require.NoError(t, db.Insert(ctx, run))
+	for _, job := range jobs {
+		job.RunID = run.ID
+		job.RepoID = run.RepoID
+		job.OwnerID = run.OwnerID
+		require.NoError(t, db.Insert(ctx, job))
+	}
+	return run, jobs
+}
+
+func TestRunConcurrency(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	ctx := db.DefaultContext
+
+	running, _ := insertConcurrencyRun(t, ctx, "deploy", false,
+		&ActionRunJob{JobID: "deploy", Status: StatusRunning, TaskID: 1})
+	pending, pendingJobs := insertConcurrencyRun(t, ctx, "deploy", false,
+		&ActionRunJob{JobID: "deploy", Status: StatusBlocked})
+	_, _ = insertConcurrencyRun(t, ctx, "other", false,
+		&ActionRunJob{JobID: "deploy", Status: StatusBlocked})
+
+	blocked, err := ShouldBlockRunByConcurrency(ctx, running)
+	assert.NoError(t, err)
+	assert.False(t, blocked)
+	blocked, err = ShouldBlockRunByConcurrency(ctx, pending)
+	assert.NoError(t, err)
+	assert.True(t, blocked)
+
+	// a new run supersedes the pending run but waits for the running one
+	latest, _ := insertConcurrencyRun(t, ctx, "deploy", false,
+		&ActionRunJob{JobID: "deploy", Status: StatusBlocked})
+	cancelled, err := CancelPreviousRunsByConcurrency(ctx, latest)
+	assert.NoError(t, err)
+	if assert.Len(t, cancelled, 1) {
+		assert.EqualValues(t, pendingJobs[0].ID, cancelled[0].ID)
+		assert.EqualValues(t, StatusCancelled, cancelled[0].Status)
+	}
+	blocked, err = ShouldBlockRunByConcurrency(ctx, latest)
+	assert.NoError(t, err)
+	assert.True(t, blocked)
+
+	// the latest run goes on once the running one is done
+	running.Status = StatusSuccess
+	assert.NoError(t, UpdateRun(ctx, running, "status"))
+	runIDs, err := FindRunIDsUnblockedByConcurrency(ctx, running, nil)
+	assert.NoError(t, err)
+	assert.EqualValues(t, []int64{latest.ID}, runIDs)
+	blocked, err = ShouldBlockRunByConcurrency(ctx, latest)
+	assert.NoError(t, err)
+	assert.False(t, blocked)
+}
+
+func TestJobConcurrency(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	ctx := db.DefaultContext
+
+	runningRun, runningJobs := insertConcurrencyRun(t, ctx, "", false,
+		&ActionRunJob{JobID: "train", Status: StatusRunning, TaskID: 1, IsConcurrencyEvaluated: true, ConcurrencyGroup: "gpu"})
+	_, pendingJobs := insertConcurrencyRun(t, ctx, "", false,
+		&ActionRunJob{JobID: "train", Status: StatusBlocked, IsConcurrencyEvaluated: true, ConcurrencyGroup: "gpu"})
+	_, latestJobs := insertConcurrencyRun(t, ctx, "", false,
+		&ActionRunJob{JobID: "train", Status: StatusBlocked, IsConcurrencyEvaluated: true, ConcurrencyGroup: "gpu"},
+		&ActionRunJob{JobID: "lint", Status: StatusBlocked})
+
+	blocked, err := ShouldBlockJobByConcurrency(ctx, latestJobs[0])
+	assert.NoError(t, err)
+	assert.True(t, blocked)
+	blocked, err = ShouldBlockJobByConcurrency(ctx, latestJobs[1])
+	assert.NoError(t, err)
+	assert.False(t, blocked)
+
+	cancelled, err := CancelPreviousJobsByConcurrency(ctx, latestJobs[0])
+	assert.NoError(t, err)
+	if assert.Len(t, cancelled, 1) {
+		assert.EqualValues(t, pendingJobs[0].ID, cancelled[0].ID)
+	}
+
+	// the group is released once the running job is done
+	runIDs, err := FindRunIDsUnblockedByConcurrency(ctx, runningRun, runningJobs)
+	assert.NoError(t, err)
+	assert.Empty(t, runIDs)
+	runningJobs[0].Status = StatusSuccess
+	_, err = UpdateRunJob(ctx, runningJobs[0], nil, "status")
+	assert.NoError(t, err)
+	runIDs, err = FindRunIDsUnblockedByConcurrency(ctx, runningRun, runningJobs)
+	assert.NoError(t, err)
+	assert.EqualValues(t, []int64{latestJobs[0].RunID}, runIDs)
+}
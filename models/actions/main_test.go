@@ -12,7 +12,9 @@ import (
 func TestMain(m *testing.M) {
 	unittest.MainTest(m, &unittest.TestOptions{
 		FixtureFiles: []string{
+			"action_artifact_retention.yml",
 			"action_runner_token.yml",
+			"repository.yml",
 		},
 	})
 }
@@ -44,6 +44,9 @@ type ActionRun struct {
 	Event             webhook_module.HookEventType // the webhook event that causes the workflow to run
 	EventPayload      string                       `xorm:"LONGTEXT"`
 	TriggerEvent      string                       // the trigger event defined in the `on` configuration of the triggered workflow
+	RawConcurrency    string                       `xorm:"TEXT"`  // the unevaluated `concurrency` of the workflow
+	ConcurrencyGroup  string                       `xorm:"index"` // the evaluated concurrency group, empty if the run doesn't belong to any
+	ConcurrencyCancel bool                         // whether in-progress runs of the concurrency group are cancelled when the run starts
 	Status            Status                       `xorm:"index"`
 	Version           int                          `xorm:"version default 0"` // Status could be updated concomitantly, so an optimistic lock is needed
 	Started           timeutil.TimeStamp
@@ -168,7 +171,8 @@ func updateRepoRunsNumbers(ctx context.Context, repo *repo_model.Repository) err
 }
 
 // CancelRunningJobs cancels all running and waiting jobs associated with a specific workflow.
-func CancelRunningJobs(ctx context.Context, repoID int64, ref, workflowID string) error {
+// It returns the jobs which have been cancelled.
+func CancelRunningJobs(ctx context.Context, repoID int64, ref, workflowID string) ([]*ActionRunJob, error) {
 	// Find all runs in the specified repository, reference, and workflow with statuses 'Running' or 'Waiting'.
 	runs, total, err := FindRuns(ctx, FindRunOptions{
 		RepoID:     repoID,
@@ -177,14 +181,15 @@ func CancelRunningJobs(ctx context.Context, repoID int64, ref, workflowID string
 		Status:     []Status{StatusRunning, StatusWaiting},
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// If there are no runs found, there's no need to proceed with cancellation, so return nil.
 	if total == 0 {
-		return nil
+		return nil, nil
 	}
 
+	var cancelledJobs []*ActionRunJob
 	// Iterate over each found run and cancel its associated jobs.
 	for _, run := range runs {
 		// Find all jobs associated with the current run.
@@ -192,50 +197,65 @@ func CancelRunningJobs(ctx context.Context, repoID int64, ref, workflowID string
 			RunID: run.ID,
 		})
 		if err != nil {
-			return err
+			return cancelledJobs, err
 		}
 
-		// Iterate over each job and attempt to cancel it.
-		for _, job := range jobs {
-			// Skip jobs that are already in a terminal state (completed, cancelled, etc.).
-			status := job.Status
-			if status.IsDone() {
-				continue
-			}
+		cancelled, err := CancelJobs(ctx, jobs)
+		cancelledJobs = append(cancelledJobs, cancelled...)
+		if err != nil {
+			return cancelledJobs, err
+		}
+	}
 
-			// If the job has no associated task (probably an error), set its status to 'Cancelled' and stop it.
-			if job.TaskID == 0 {
-				job.Status = StatusCancelled
-				job.Stopped = timeutil.TimeStampNow()
+	// Return nil to indicate successful cancellation of all running and waiting jobs.
+	return cancelledJobs, nil
+}
 
-				// Update the job's status and stopped time in the database.
-				n, err := UpdateRunJob(ctx, job, builder.Eq{"task_id": 0}, "status", "stopped")
-				if err != nil {
-					return err
-				}
+// CancelJobs cancels the jobs which are not done yet, and returns them with their new status.
+func CancelJobs(ctx context.Context, jobs []*ActionRunJob) ([]*ActionRunJob, error) {
+	cancelledJobs := make([]*ActionRunJob, 0, len(jobs))
+	for _, job := range jobs {
+		// Skip jobs that are already in a terminal state (completed, cancelled, etc.).
+		status := job.Status
+		if status.IsDone() {
+			continue
+		}
 
-				// If the update affected 0 rows, it means the job has changed in the meantime, so we need to try again.
-				if n == 0 {
-					return fmt.Errorf("job has changed, try again")
-				}
+		// If the job has no associated task (probably an error), set its status to 'Cancelled' and stop it.
+		if job.TaskID == 0 {
+			job.Status = StatusCancelled
+			job.Stopped = timeutil.TimeStampNow()
 
-				// Continue with the next job.
-				continue
+			// Update the job's status and stopped time in the database.
+			n, err := UpdateRunJob(ctx, job, builder.Eq{"task_id": 0}, "status", "stopped")
+			if err != nil {
+				return cancelledJobs, err
 			}
 
-			// If the job has an associated task, try to stop the task, effectively cancelling the job.
-			if err := StopTask(ctx, job.TaskID, StatusCancelled); err != nil {
-				return err
+			// If the update affected 0 rows, it means the job has changed in the meantime, so we need to try again.
+			if n == 0 {
+				return cancelledJobs, fmt.Errorf("job has changed, try again")
 			}
+
+			cancelledJobs = append(cancelledJobs, job)
+			// Continue with the next job.
+			continue
 		}
-	}
 
-	// Return nil to indicate successful cancellation of all running and waiting jobs.
-	return nil
+		// If the job has an associated task, try to stop the task, effectively cancelling the job.
+		if err := StopTask(ctx, job.TaskID, StatusCancelled); err != nil {
+			return cancelledJobs, err
+		}
+		job.Status = StatusCancelled
+		job.Stopped = timeutil.TimeStampNow()
+		cancelledJobs = append(cancelledJobs, job)
+	}
+	return cancelledJobs, nil
 }
 
-// InsertRun inserts a run
-func InsertRun(ctx context.Context, run *ActionRun, jobs []*jobparser.SingleWorkflow) error {
+// InsertRun inserts a run, jobsConcurrency contains the raw concurrency of the jobs keyed by job id.
+// If the run or a job has a concurrency, the job is blocked until the job emitter has evaluated it.
+func InsertRun(ctx context.Context, run *ActionRun, jobs []*jobparser.SingleWorkflow, jobsConcurrency map[string]string) error {
 	ctx, commiter, err := db.TxContext(ctx)
 	if err != nil {
 		return err
@@ -274,7 +294,8 @@ func InsertRun(ctx context.Context, run *ActionRun, jobs []*jobparser.SingleWork
 		}
 		payload, _ := v.Marshal()
 		status := StatusWaiting
-		if len(needs) > 0 || run.NeedApproval {
+		rawConcurrency := jobsConcurrency[id]
+		if len(needs) > 0 || run.NeedApproval || run.RawConcurrency != "" || rawConcurrency != "" {
 			status = StatusBlocked
 		} else {
 			hasWaiting = true
@@ -291,6 +312,7 @@ func InsertRun(ctx context.Context, run *ActionRun, jobs []*jobparser.SingleWork
 			JobID:             id,
 			Needs:             needs,
 			RunsOn:            job.RunsOn(),
+			RawConcurrency:    rawConcurrency,
 			Status:            status,
 		})
 	}
@@ -13,30 +13,36 @@ import (
 	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/util"
 
+	"github.com/nektos/act/pkg/jobparser"
+	"gopkg.in/yaml.v3"
 	"xorm.io/builder"
 )
 
 // ActionRunJob represents a job of a run
 type ActionRunJob struct {
-	ID                int64
-	RunID             int64      `xorm:"index"`
-	Run               *ActionRun `xorm:"-"`
-	RepoID            int64      `xorm:"index"`
-	OwnerID           int64      `xorm:"index"`
-	CommitSHA         string     `xorm:"index"`
-	IsForkPullRequest bool
-	Name              string `xorm:"VARCHAR(255)"`
-	Attempt           int64
-	WorkflowPayload   []byte
-	JobID             string   `xorm:"VARCHAR(255)"` // job id in workflow, not job's id
-	Needs             []string `xorm:"JSON TEXT"`
-	RunsOn            []string `xorm:"JSON TEXT"`
-	TaskID            int64    // the latest task of the job
-	Status            Status   `xorm:"index"`
-	Started           timeutil.TimeStamp
-	Stopped           timeutil.TimeStamp
-	Created           timeutil.TimeStamp `xorm:"created"`
-	Updated           timeutil.TimeStamp `xorm:"updated index"`
+	ID                     int64
+	RunID                  int64      `xorm:"index"`
+	Run                    *ActionRun `xorm:"-"`
+	RepoID                 int64      `xorm:"index"`
+	OwnerID                int64      `xorm:"index"`
+	CommitSHA              string     `xorm:"index"`
+	IsForkPullRequest      bool
+	Name                   string `xorm:"VARCHAR(255)"`
+	Attempt                int64
+	WorkflowPayload        []byte
+	JobID                  string   `xorm:"VARCHAR(255)"` // job id in workflow, not job's id
+	Needs                  []string `xorm:"JSON TEXT"`
+	RunsOn                 []string `xorm:"JSON TEXT"`
+	TaskID                 int64    // the latest task of the job
+	RawConcurrency         string   `xorm:"TEXT"` // the unevaluated `concurrency` of the job
+	IsConcurrencyEvaluated bool     // the raw concurrency is evaluated when the job is about to leave the blocked status, since it may depend on its needs
+	ConcurrencyGroup       string   `xorm:"index"`
+	ConcurrencyCancel      bool
+	Status                 Status `xorm:"index"`
+	Started                timeutil.TimeStamp
+	Stopped                timeutil.TimeStamp
+	Created                timeutil.TimeStamp `xorm:"created"`
+	Updated                timeutil.TimeStamp `xorm:"updated index"`
 }
 
 func init() {
@@ -47,6 +53,17 @@ func (job *ActionRunJob) Duration() time.Duration {
 	return calculateDuration(job.Started, job.Stopped, job.Status)
 }
 
+// IsReusableWorkflowCall returns whether the job calls a reusable workflow.
+// Such a job is never picked by a runner, it's done once the jobs of the called workflow are done.
+func (job *ActionRunJob) IsReusableWorkflowCall() bool {
+	var workflow jobparser.SingleWorkflow
+	if err := yaml.Unmarshal(job.WorkflowPayload, &workflow); err != nil {
+		return false
+	}
+	_, parsed := workflow.Job()
+	return parsed != nil && parsed.Uses != ""
+}
+
 func (job *ActionRunJob) LoadRun(ctx context.Context) error {
 	if job.Run == nil {
 		run, err := GetRunByID(ctx, job.RunID)
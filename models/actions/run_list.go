@@ -10,6 +10,7 @@ import (
 	repo_model "code.gitea.io/gitea/models/repo"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/container"
+	webhook_module "code.gitea.io/gitea/modules/webhook"
 
 	"xorm.io/builder"
 )
@@ -73,6 +74,8 @@ type FindRunOptions struct {
 	TriggerUserID int64
 	Approved      bool // not util.OptionalBool, it works only when it's true
 	Status        []Status
+	Event         webhook_module.HookEventType
+	CommitSHA     string
 }
 
 func (opts FindRunOptions) toConds() builder.Cond {
@@ -98,6 +101,12 @@ func (opts FindRunOptions) toConds() builder.Cond {
 	if opts.Ref != "" {
 		cond = cond.And(builder.Eq{"ref": opts.Ref})
 	}
+	if opts.Event != "" {
+		cond = cond.And(builder.Eq{"event": opts.Event})
+	}
+	if opts.CommitSHA != "" {
+		cond = cond.And(builder.Eq{"commit_sha": opts.CommitSHA})
+	}
 	return cond
 }
 
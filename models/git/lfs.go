@@ -115,6 +115,8 @@ type LFSMetaObject struct {
 	Existing     bool               `xorm:"-"`
 	CreatedUnix  timeutil.TimeStamp `xorm:"created"`
 	UpdatedUnix  timeutil.TimeStamp `xorm:"INDEX updated"`
+	// AccessedUnix is the last time the object was downloaded or a direct url to it was issued
+	AccessedUnix timeutil.TimeStamp `xorm:"INDEX NOT NULL DEFAULT 0"`
 }
 
 func init() {
@@ -226,7 +228,8 @@ func GetLFSMetaObjects(ctx context.Context, repoID int64, page, pageSize int) ([
 		if page > 0 {
 			start = (page - 1) * pageSize
 		}
-		sess.Limit(pageSize, start)
+		// the pages must be stable
+		sess.Limit(pageSize, start).OrderBy("id ASC")
 	}
 	lfsObjects := make([]*LFSMetaObject, 0, pageSize)
 	return lfsObjects, sess.Find(&lfsObjects, &LFSMetaObject{RepositoryID: repoID})
@@ -240,6 +243,15 @@ func GetAllLFSMetaObjects(ctx context.Context, repoID int64) ([]*LFSMetaObject,
 	return lfsObjects, sess.Find(&lfsObjects, &LFSMetaObject{RepositoryID: repoID})
 }
 
+// GetLFSMetaObjectsByOids returns the LFSMetaObjects of a repository among the oids
+func GetLFSMetaObjectsByOids(ctx context.Context, repoID int64, oids []string) ([]*LFSMetaObject, error) {
+	lfsObjects := make([]*LFSMetaObject, 0, len(oids))
+	if len(oids) == 0 {
+		return lfsObjects, nil
+	}
+	return lfsObjects, db.GetEngine(ctx).Where("repository_id = ?", repoID).In("oid", oids).Find(&lfsObjects)
+}
+
 func ContainsLFSMetaObject(slice []*LFSMetaObject, id string) *LFSMetaObject {
 	for _, item := range slice {
 		if item != nil && item.Oid == id {
@@ -0,0 +1,144 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package git
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/lfs"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// LFSArchiveStatus is the status of an object moved to the archive storage
+type LFSArchiveStatus int
+
+const (
+	// LFSArchiveStatusArchived means the object is only in the archive storage
+	LFSArchiveStatusArchived LFSArchiveStatus = iota + 1
+	// LFSArchiveStatusRestoring means a client requested the object, it is being copied back to the LFS storage
+	LFSArchiveStatusRestoring
+)
+
+// lfsAccessInterval throttles the updates of the access time of the objects downloaded again and again
+const lfsAccessInterval = time.Hour
+
+// LFSArchivedObject is an object moved from the LFS storage to the archive storage because it wasn't accessed for a while.
+// The objects are content addressed so the archive is shared by all the LFSMetaObjects with the same oid.
+type LFSArchivedObject struct {
+	ID          int64              `xorm:"pk autoincr"`
+	Oid         string             `xorm:"UNIQUE NOT NULL"`
+	Size        int64              `xorm:"NOT NULL"`
+	Status      LFSArchiveStatus   `xorm:"INDEX NOT NULL"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX"`
+}
+
+func init() {
+	db.RegisterModel(new(LFSArchivedObject))
+}
+
+// ErrLFSArchivedObjectNotExist is returned when an object isn't archived
+var ErrLFSArchivedObjectNotExist = db.ErrNotExist{Resource: "LFS archived object"}
+
+// Pointer returns the pointer of the archived object
+func (o *LFSArchivedObject) Pointer() lfs.Pointer {
+	return lfs.Pointer{Oid: o.Oid, Size: o.Size}
+}
+
+// IsPending returns true if the object is being restored
+func (o *LFSArchivedObject) IsPending() bool {
+	return o.Status == LFSArchiveStatusRestoring
+}
+
+// MarkLFSMetaObjectsAccessed records that the objects of the repository have been accessed
+func MarkLFSMetaObjectsAccessed(ctx context.Context, repoID int64, oids ...string) error {
+	if len(oids) == 0 {
+		return nil
+	}
+	now := timeutil.TimeStampNow()
+	_, err := db.GetEngine(ctx).
+		Where(builder.Eq{"repository_id": repoID}.
+			And(builder.In("oid", oids)).
+			And(builder.Lt{"accessed_unix": now.Add(-int64(lfsAccessInterval.Seconds()))})).
+		Cols("accessed_unix").
+		NoAutoTime().
+		Update(&LFSMetaObject{AccessedUnix: now})
+	return err
+}
+
+// FindLFSObjectsToArchive returns up to limit objects with an oid after the given one, which are not archived yet
+// and whose LFSMetaObjects have neither been accessed nor created since the given time
+func FindLFSObjectsToArchive(ctx context.Context, before timeutil.TimeStamp, afterOid string, limit int) ([]lfs.Pointer, error) {
+	pointers := make([]lfs.Pointer, 0, limit)
+	return pointers, db.GetEngine(ctx).Table("lfs_meta_object").
+		Select("oid, size").
+		Where(builder.Gt{"oid": afterOid}.
+			And(builder.NotIn("oid", builder.Select("oid").From("lfs_archived_object")))).
+		GroupBy("oid, size").
+		Having(fmt.Sprintf("MAX(created_unix) < %d AND MAX(accessed_unix) < %d", before, before)).
+		OrderBy("oid ASC").
+		Limit(limit).
+		Find(&pointers)
+}
+
+// GetLFSArchivedObject returns the archived object with the oid
+func GetLFSArchivedObject(ctx context.Context, oid string) (*LFSArchivedObject, error) {
+	o := &LFSArchivedObject{}
+	has, err := db.GetEngine(ctx).Where("oid = ?", oid).Get(o)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrLFSArchivedObjectNotExist
+	}
+	return o, nil
+}
+
+// GetLFSArchivedObjectsByOids returns the archived objects among the oids, keyed by their oid
+func GetLFSArchivedObjectsByOids(ctx context.Context, oids []string) (map[string]*LFSArchivedObject, error) {
+	objects := make(map[string]*LFSArchivedObject)
+	if len(oids) == 0 {
+		return objects, nil
+	}
+	list := make([]*LFSArchivedObject, 0, len(oids))
+	if err := db.GetEngine(ctx).In("oid", oids).Find(&list); err != nil {
+		return nil, err
+	}
+	for _, o := range list {
+		objects[o.Oid] = o
+	}
+	return objects, nil
+}
+
+// NewLFSArchivedObject records that the object has been moved to the archive storage
+func NewLFSArchivedObject(ctx context.Context, p lfs.Pointer) error {
+	return db.Insert(ctx, &LFSArchivedObject{
+		Oid:         p.Oid,
+		Size:        p.Size,
+		Status:      LFSArchiveStatusArchived,
+		UpdatedUnix: timeutil.TimeStampNow(),
+	})
+}
+
+// MarkLFSArchivedObjectRestoring marks the archived object as being restored. It returns true if the restore
+// has to be started, i.e. if the object was archived or if the last attempt of the restore is older than retryAfter,
+// so the restore of an object requested by many clients is started once.
+func MarkLFSArchivedObjectRestoring(ctx context.Context, oid string, retryAfter time.Duration) (bool, error) {
+	now := timeutil.TimeStampNow()
+	cond := builder.Eq{"oid": oid}.And(builder.Eq{"status": LFSArchiveStatusArchived}.
+		Or(builder.Eq{"status": LFSArchiveStatusRestoring}.And(builder.Lt{"updated_unix": now.Add(-int64(retryAfter.Seconds()))})))
+	n, err := db.GetEngine(ctx).Where(cond).Cols("status", "updated_unix").
+		Update(&LFSArchivedObject{Status: LFSArchiveStatusRestoring, UpdatedUnix: now})
+	return n > 0, err
+}
+
+// DeleteLFSArchivedObject removes the record of the archived object once it is back in the LFS storage or deleted
+func DeleteLFSArchivedObject(ctx context.Context, oid string) error {
+	_, err := db.GetEngine(ctx).Where("oid = ?", oid).Delete(new(LFSArchivedObject))
+	return err
+}
@@ -0,0 +1,69 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package git_test
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	git_model "code.gitea.io/gitea/models/git"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/lfs"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindLFSObjectsToArchive(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	const (
+		oid1 = "0b8d8b5f15046343fd32f451df93acc2bdd9e6373be478b968e4cad6b6647351"
+		oid2 = "2eccdb43825d2a49d99d542daa20075cff1d97d9d2349a8977efe9c03661737c"
+	)
+
+	now := timeutil.TimeStampNow()
+	pointers, err := git_model.FindLFSObjectsToArchive(db.DefaultContext, now, "", 100)
+	assert.NoError(t, err)
+	assert.Len(t, pointers, unittest.GetCount(t, &git_model.LFSMetaObject{}, "repository_id = 54"))
+	assert.Equal(t, oid1, pointers[0].Oid)
+
+	// the accessed objects are not archived
+	assert.NoError(t, git_model.MarkLFSMetaObjectsAccessed(db.DefaultContext, 54, oid1))
+	meta := unittest.AssertExistsAndLoadBean(t, &git_model.LFSMetaObject{ID: 1})
+	assert.NotZero(t, meta.AccessedUnix)
+	assert.Zero(t, meta.UpdatedUnix)
+	pointers, err = git_model.FindLFSObjectsToArchive(db.DefaultContext, now, "", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []lfs.Pointer{{Oid: oid2, Size: 107}}, pointers)
+
+	// nor the archived ones
+	assert.NoError(t, git_model.NewLFSArchivedObject(db.DefaultContext, pointers[0]))
+	pointers, err = git_model.FindLFSObjectsToArchive(db.DefaultContext, now, "", 100)
+	assert.NoError(t, err)
+	for _, p := range pointers {
+		assert.NotContains(t, []string{oid1, oid2}, p.Oid)
+	}
+
+	archived, err := git_model.GetLFSArchivedObjectsByOids(db.DefaultContext, []string{oid1, oid2})
+	assert.NoError(t, err)
+	assert.Len(t, archived, 1)
+	assert.False(t, archived[oid2].IsPending())
+
+	// the restore is started once until the retry interval passes
+	start, err := git_model.MarkLFSArchivedObjectRestoring(db.DefaultContext, oid2, time.Hour)
+	assert.NoError(t, err)
+	assert.True(t, start)
+	start, err = git_model.MarkLFSArchivedObjectRestoring(db.DefaultContext, oid2, time.Hour)
+	assert.NoError(t, err)
+	assert.False(t, start)
+	o, err := git_model.GetLFSArchivedObject(db.DefaultContext, oid2)
+	assert.NoError(t, err)
+	assert.True(t, o.IsPending())
+
+	assert.NoError(t, git_model.DeleteLFSArchivedObject(db.DefaultContext, oid2))
+	_, err = git_model.GetLFSArchivedObject(db.DefaultContext, oid2)
+	assert.ErrorIs(t, err, git_model.ErrLFSArchivedObjectNotExist)
+}
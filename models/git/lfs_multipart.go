@@ -0,0 +1,74 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package git
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// LFSMultipartPart tracks a part of a multipart LFS upload which is uploaded through Gitea
+type LFSMultipartPart struct {
+	ID           int64              `xorm:"pk autoincr"`
+	RepositoryID int64              `xorm:"INDEX NOT NULL"`
+	Oid          string             `xorm:"INDEX NOT NULL"`
+	UploadID     string             `xorm:"UNIQUE(s) NOT NULL"`
+	PartIndex    int                `xorm:"UNIQUE(s) NOT NULL"`
+	Size         int64              `xorm:"NOT NULL"`
+	Etag         string             `xorm:"NOT NULL"`
+	CreatedUnix  timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix  timeutil.TimeStamp `xorm:"INDEX updated"`
+}
+
+func init() {
+	db.RegisterModel(new(LFSMultipartPart))
+}
+
+// SaveLFSMultipartPart stores the given part, replacing a previously uploaded part with the same index
+func SaveLFSMultipartPart(ctx context.Context, part *LFSMultipartPart) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		if _, err := db.GetEngine(ctx).Where("upload_id = ? AND part_index = ?", part.UploadID, part.PartIndex).
+			Delete(new(LFSMultipartPart)); err != nil {
+			return err
+		}
+		return db.Insert(ctx, part)
+	})
+}
+
+// GetLFSMultipartParts returns the uploaded parts of a multipart upload ordered by their index
+func GetLFSMultipartParts(ctx context.Context, uploadID string) ([]*LFSMultipartPart, error) {
+	parts := make([]*LFSMultipartPart, 0, 10)
+	return parts, db.GetEngine(ctx).Where("upload_id = ?", uploadID).Asc("part_index").Find(&parts)
+}
+
+// GetLFSMultipartUploadID returns the id of the latest unfinished multipart upload of the object to the repository,
+// empty if there is none
+func GetLFSMultipartUploadID(ctx context.Context, repoID int64, oid string) (string, error) {
+	part := new(LFSMultipartPart)
+	has, err := db.GetEngine(ctx).Where("repository_id = ? AND oid = ?", repoID, oid).Desc("updated_unix", "id").Get(part)
+	if err != nil || !has {
+		return "", err
+	}
+	return part.UploadID, nil
+}
+
+// DeleteLFSMultipartParts removes all the tracked parts of a multipart upload
+func DeleteLFSMultipartParts(ctx context.Context, uploadID string) error {
+	_, err := db.GetEngine(ctx).Where("upload_id = ?", uploadID).Delete(new(LFSMultipartPart))
+	return err
+}
+
+// FindStaleLFSMultipartUploads returns the multipart uploads whose last part was uploaded before the given time,
+// one part with the upload id and the oid is returned per upload
+func FindStaleLFSMultipartUploads(ctx context.Context, before timeutil.TimeStamp) ([]*LFSMultipartPart, error) {
+	uploads := make([]*LFSMultipartPart, 0, 10)
+	return uploads, db.GetEngine(ctx).Table("lfs_multipart_part").
+		Select("upload_id, oid").
+		GroupBy("upload_id, oid").
+		Having(fmt.Sprintf("MAX(updated_unix) < %d", before)).
+		Find(&uploads)
+}
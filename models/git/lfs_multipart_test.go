@@ -0,0 +1,55 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package git_test
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	git_model "code.gitea.io/gitea/models/git"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindStaleLFSMultipartUploads(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	const oid = "0b8d8b5f15046343fd32f451df93acc2bdd9e6373be478b968e4cad6b6647351"
+	for _, index := range []int{1, 2} {
+		assert.NoError(t, git_model.SaveLFSMultipartPart(db.DefaultContext, &git_model.LFSMultipartPart{
+			RepositoryID: 54,
+			Oid:          oid,
+			UploadID:     "upload",
+			PartIndex:    index,
+			Size:         10,
+			Etag:         "etag",
+		}))
+	}
+
+	uploadID, err := git_model.GetLFSMultipartUploadID(db.DefaultContext, 54, oid)
+	assert.NoError(t, err)
+	assert.Equal(t, "upload", uploadID)
+	uploadID, err = git_model.GetLFSMultipartUploadID(db.DefaultContext, 1, oid)
+	assert.NoError(t, err)
+	assert.Empty(t, uploadID)
+
+	now := timeutil.TimeStampNow()
+	uploads, err := git_model.FindStaleLFSMultipartUploads(db.DefaultContext, now-10)
+	assert.NoError(t, err)
+	assert.Empty(t, uploads)
+
+	uploads, err = git_model.FindStaleLFSMultipartUploads(db.DefaultContext, now+10)
+	assert.NoError(t, err)
+	if assert.Len(t, uploads, 1) {
+		assert.Equal(t, "upload", uploads[0].UploadID)
+		assert.Equal(t, oid, uploads[0].Oid)
+	}
+
+	assert.NoError(t, git_model.DeleteLFSMultipartParts(db.DefaultContext, "upload"))
+	uploads, err = git_model.FindStaleLFSMultipartUploads(db.DefaultContext, now+10)
+	assert.NoError(t, err)
+	assert.Empty(t, uploads)
+}
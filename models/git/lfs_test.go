@@ -0,0 +1,33 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package git_test
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	git_model "code.gitea.io/gitea/models/git"
+	"code.gitea.io/gitea/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetLFSMetaObjectsByOids(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	oid := "0b8d8b5f15046343fd32f451df93acc2bdd9e6373be478b968e4cad6b6647351"
+	metas, err := git_model.GetLFSMetaObjectsByOids(db.DefaultContext, 54, []string{oid, "unknown"})
+	assert.NoError(t, err)
+	if assert.Len(t, metas, 1) {
+		assert.Equal(t, oid, metas[0].Oid)
+	}
+
+	metas, err = git_model.GetLFSMetaObjectsByOids(db.DefaultContext, 1, []string{oid})
+	assert.NoError(t, err)
+	assert.Empty(t, metas)
+
+	metas, err = git_model.GetLFSMetaObjectsByOids(db.DefaultContext, 54, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, metas)
+}
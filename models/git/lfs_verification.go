@@ -0,0 +1,75 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package git
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// LFSVerificationStatus is the outcome of the server side verification of an uploaded LFS object
+type LFSVerificationStatus int
+
+const (
+	// LFSVerificationSucceeded means the stored content matches the pointer
+	LFSVerificationSucceeded LFSVerificationStatus = iota + 1
+	// LFSVerificationSizeMismatch means the size of the stored content doesn't match the pointer, the content is deleted
+	LFSVerificationSizeMismatch
+	// LFSVerificationHashMismatch means the hash of the stored content doesn't match the pointer, the content is deleted
+	LFSVerificationHashMismatch
+	// LFSVerificationFailed means the content couldn't be assembled or read
+	LFSVerificationFailed
+)
+
+// String returns the name of the status
+func (s LFSVerificationStatus) String() string {
+	switch s {
+	case LFSVerificationSucceeded:
+		return "succeeded"
+	case LFSVerificationSizeMismatch:
+		return "size_mismatch"
+	case LFSVerificationHashMismatch:
+		return "hash_mismatch"
+	case LFSVerificationFailed:
+		return "failed"
+	}
+	return "unknown"
+}
+
+// LFSVerification records the outcome of the verification of an LFS object assembled from a multipart upload
+type LFSVerification struct {
+	ID           int64                 `xorm:"pk autoincr"`
+	RepositoryID int64                 `xorm:"INDEX NOT NULL"`
+	Oid          string                `xorm:"INDEX NOT NULL"`
+	Size         int64                 `xorm:"NOT NULL"`
+	DoerID       int64                 `xorm:"NOT NULL DEFAULT 0"`
+	Status       LFSVerificationStatus `xorm:"NOT NULL"`
+	CreatedUnix  timeutil.TimeStamp    `xorm:"INDEX created"`
+}
+
+func init() {
+	db.RegisterModel(new(LFSVerification))
+}
+
+// ErrLFSVerificationNotExist is returned when no verification of the object has been recorded
+var ErrLFSVerificationNotExist = db.ErrNotExist{Resource: "LFS verification"}
+
+// NewLFSVerification records the outcome of a verification
+func NewLFSVerification(ctx context.Context, v *LFSVerification) error {
+	return db.Insert(ctx, v)
+}
+
+// GetLatestLFSVerification returns the last recorded verification of the object in the repository
+func GetLatestLFSVerification(ctx context.Context, repoID int64, oid string) (*LFSVerification, error) {
+	v := &LFSVerification{}
+	has, err := db.GetEngine(ctx).Where("repository_id = ? AND oid = ?", repoID, oid).Desc("id").Get(v)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrLFSVerificationNotExist
+	}
+	return v, nil
+}
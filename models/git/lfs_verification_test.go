@@ -0,0 +1,35 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package git_test
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	git_model "code.gitea.io/gitea/models/git"
+	"code.gitea.io/gitea/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetLatestLFSVerification(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	const oid = "0b8d8b5f15046343fd32f451df93acc2bdd9e6373be478b968e4cad6b6647351"
+	_, err := git_model.GetLatestLFSVerification(db.DefaultContext, 54, oid)
+	assert.ErrorIs(t, err, git_model.ErrLFSVerificationNotExist)
+
+	for _, status := range []git_model.LFSVerificationStatus{git_model.LFSVerificationHashMismatch, git_model.LFSVerificationSucceeded} {
+		assert.NoError(t, git_model.NewLFSVerification(db.DefaultContext, &git_model.LFSVerification{
+			RepositoryID: 54,
+			Oid:          oid,
+			Size:         107,
+			Status:       status,
+		}))
+	}
+
+	v, err := git_model.GetLatestLFSVerification(db.DefaultContext, 54, oid)
+	assert.NoError(t, err)
+	assert.Equal(t, git_model.LFSVerificationSucceeded, v.Status)
+}
@@ -0,0 +1,182 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package git
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/container"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// PushPolicy overrides the policies evaluated when pushing to the repositories of an organization (RepoID is 0)
+// or to a single repository (OwnerID is 0). The zero values inherit the policies of the upper level, the other values
+// can only restrict them.
+type PushPolicy struct {
+	ID      int64
+	OwnerID int64 `xorm:"INDEX UNIQUE(owner_repo) NOT NULL DEFAULT 0"`
+	RepoID  int64 `xorm:"INDEX UNIQUE(owner_repo) NOT NULL DEFAULT 0"`
+	// Licenses are the allowed licenses of the model card front matter
+	Licenses []string `xorm:"JSON TEXT"`
+	// MaxFileSize is the max size in bytes of the pushed files
+	MaxFileSize int64
+	CreatedUnix timeutil.TimeStamp `xorm:"created NOT NULL"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated NOT NULL"`
+}
+
+func init() {
+	db.RegisterModel(new(PushPolicy))
+}
+
+// IsEmpty returns true if the policy inherits everything
+func (p *PushPolicy) IsEmpty() bool {
+	return len(p.Licenses) == 0 && p.MaxFileSize == 0
+}
+
+// GetPushPolicy returns the policy of the organization or the repository, an empty policy is returned if it is not set
+func GetPushPolicy(ctx context.Context, ownerID, repoID int64) (*PushPolicy, error) {
+	p := &PushPolicy{OwnerID: ownerID, RepoID: repoID}
+	if _, err := db.GetEngine(ctx).Where("owner_id = ? AND repo_id = ?", ownerID, repoID).Get(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// SetPushPolicy creates or updates the policy of the organization or the repository, an empty policy is removed
+func SetPushPolicy(ctx context.Context, p *PushPolicy) error {
+	if (p.OwnerID == 0) == (p.RepoID == 0) {
+		return fmt.Errorf("the push policy must be bound to either an owner or a repository")
+	}
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		e := db.GetEngine(ctx)
+		if p.IsEmpty() {
+			_, err := e.Where("owner_id = ? AND repo_id = ?", p.OwnerID, p.RepoID).Delete(new(PushPolicy))
+			p.ID = 0
+			return err
+		}
+		existing := new(PushPolicy)
+		has, err := e.Where("owner_id = ? AND repo_id = ?", p.OwnerID, p.RepoID).Get(existing)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return db.Insert(ctx, p)
+		}
+		p.ID = existing.ID
+		_, err = e.ID(p.ID).Cols("licenses", "max_file_size").Update(p)
+		return err
+	})
+}
+
+// DeletePushPolicies removes the policies of the organization or the repository
+func DeletePushPolicies(ctx context.Context, ownerID, repoID int64) error {
+	_, err := db.GetEngine(ctx).Where("owner_id = ? AND repo_id = ?", ownerID, repoID).Delete(new(PushPolicy))
+	return err
+}
+
+// the levels a policy is inherited from
+const (
+	PushPolicyLevelGlobal = "global"
+	PushPolicyLevelOrg    = "org"
+	PushPolicyLevelRepo   = "repo"
+)
+
+// EffectivePushPolicy is the policy evaluated at push time after the inheritance is resolved
+type EffectivePushPolicy struct {
+	// Licenses are the allowed licenses, any license is allowed if it is empty
+	Licenses      []string
+	LicensesLevel string
+	// MaxFileSize is the max size in bytes of the pushed files, there is no limit if it is 0
+	MaxFileSize      int64
+	MaxFileSizeLevel string
+}
+
+func globalPushPolicy() *EffectivePushPolicy {
+	return &EffectivePushPolicy{
+		Licenses:         setting.Metadata.Licenses,
+		LicensesLevel:    PushPolicyLevelGlobal,
+		MaxFileSize:      max(setting.CommonMaxFileSize, 0),
+		MaxFileSizeLevel: PushPolicyLevelGlobal,
+	}
+}
+
+// override restricts the policy with the policy of a lower level. The upper levels may have been restricted after
+// the policy was saved, so the licenses not allowed by them are dropped and a larger file size is ignored.
+func (e *EffectivePushPolicy) override(p *PushPolicy, level string) {
+	licenses := p.Licenses
+	if len(e.Licenses) > 0 && len(licenses) > 0 {
+		allowed := container.SetOf(e.Licenses...)
+		licenses = make([]string, 0, len(p.Licenses))
+		for _, license := range p.Licenses {
+			if allowed.Contains(license) {
+				licenses = append(licenses, license)
+			}
+		}
+	}
+	if len(licenses) > 0 {
+		e.Licenses, e.LicensesLevel = licenses, level
+	}
+	if p.MaxFileSize > 0 && (e.MaxFileSize == 0 || p.MaxFileSize <= e.MaxFileSize) {
+		e.MaxFileSize, e.MaxFileSizeLevel = p.MaxFileSize, level
+	}
+}
+
+// GetEffectivePushPolicy resolves the policy of the repository, which restricts the policy of its owner,
+// which restricts the global settings. If repoID is 0 the policy inherited by the repositories of the owner is returned.
+func GetEffectivePushPolicy(ctx context.Context, ownerID, repoID int64) (*EffectivePushPolicy, error) {
+	policies := make([]*PushPolicy, 0, 2)
+	cond := "owner_id = ? AND repo_id = 0"
+	args := []any{ownerID}
+	if repoID > 0 {
+		cond = "(owner_id = ? AND repo_id = 0) OR (owner_id = 0 AND repo_id = ?)"
+		args = append(args, repoID)
+	}
+	if err := db.GetEngine(ctx).Where(cond, args...).Find(&policies); err != nil {
+		return nil, err
+	}
+
+	effective := globalPushPolicy()
+	for _, level := range []string{PushPolicyLevelOrg, PushPolicyLevelRepo} {
+		for _, p := range policies {
+			if (p.RepoID == 0) == (level == PushPolicyLevelOrg) {
+				effective.override(p, level)
+			}
+		}
+	}
+	return effective, nil
+}
+
+// ErrPushPolicyNotPermitted represents a policy looser than the policy it overrides
+type ErrPushPolicyNotPermitted struct {
+	Field string
+}
+
+// IsErrPushPolicyNotPermitted checks if an error is a ErrPushPolicyNotPermitted
+func IsErrPushPolicyNotPermitted(err error) bool {
+	_, ok := err.(ErrPushPolicyNotPermitted)
+	return ok
+}
+
+func (err ErrPushPolicyNotPermitted) Error() string {
+	return fmt.Sprintf("the %s can not be looser than the inherited policy", err.Field)
+}
+
+// CheckOverride checks the policy only restricts the inherited policy
+func (e *EffectivePushPolicy) CheckOverride(p *PushPolicy) error {
+	if len(e.Licenses) > 0 && len(p.Licenses) > 0 {
+		allowed := container.SetOf(e.Licenses...)
+		for _, license := range p.Licenses {
+			if !allowed.Contains(license) {
+				return ErrPushPolicyNotPermitted{Field: "licenses"}
+			}
+		}
+	}
+	if e.MaxFileSize > 0 && p.MaxFileSize > e.MaxFileSize {
+		return ErrPushPolicyNotPermitted{Field: "max file size"}
+	}
+	return nil
+}
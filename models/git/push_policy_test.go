@@ -0,0 +1,74 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package git_test
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	git_model "code.gitea.io/gitea/models/git"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEffectivePushPolicy(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	defer test.MockVariableValue(&setting.Metadata.Licenses, []string{"mit", "apache-2.0", "gpl-3.0"})()
+	defer test.MockVariableValue(&setting.CommonMaxFileSize, int64(100))()
+
+	// the organization 3 owns the repository 3
+	policy, err := git_model.GetEffectivePushPolicy(db.DefaultContext, 3, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, &git_model.EffectivePushPolicy{
+		Licenses:         []string{"mit", "apache-2.0", "gpl-3.0"},
+		LicensesLevel:    git_model.PushPolicyLevelGlobal,
+		MaxFileSize:      100,
+		MaxFileSizeLevel: git_model.PushPolicyLevelGlobal,
+	}, policy)
+
+	orgPolicy := &git_model.PushPolicy{OwnerID: 3, Licenses: []string{"mit", "apache-2.0"}, MaxFileSize: 50}
+	assert.NoError(t, policy.CheckOverride(orgPolicy))
+	assert.NoError(t, git_model.SetPushPolicy(db.DefaultContext, orgPolicy))
+
+	repoPolicy := &git_model.PushPolicy{RepoID: 3, Licenses: []string{"mit", "gpl-3.0"}, MaxFileSize: 80}
+	inherited, err := git_model.GetEffectivePushPolicy(db.DefaultContext, 3, 0)
+	assert.NoError(t, err)
+	assert.True(t, git_model.IsErrPushPolicyNotPermitted(inherited.CheckOverride(repoPolicy)))
+	assert.True(t, git_model.IsErrPushPolicyNotPermitted(inherited.CheckOverride(&git_model.PushPolicy{RepoID: 3, Licenses: []string{"gpl-3.0"}})))
+
+	// the policy of the organization was restricted after the policy of the repository was saved,
+	// the policy of the repository can't loosen it
+	assert.NoError(t, git_model.SetPushPolicy(db.DefaultContext, repoPolicy))
+	policy, err = git_model.GetEffectivePushPolicy(db.DefaultContext, 3, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, &git_model.EffectivePushPolicy{
+		Licenses:         []string{"mit"},
+		LicensesLevel:    git_model.PushPolicyLevelRepo,
+		MaxFileSize:      50,
+		MaxFileSizeLevel: git_model.PushPolicyLevelOrg,
+	}, policy)
+
+	assert.NoError(t, git_model.SetPushPolicy(db.DefaultContext, &git_model.PushPolicy{RepoID: 3, Licenses: []string{"gpl-3.0"}, MaxFileSize: 20}))
+	policy, err = git_model.GetEffectivePushPolicy(db.DefaultContext, 3, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, &git_model.EffectivePushPolicy{
+		Licenses:         []string{"mit", "apache-2.0"},
+		LicensesLevel:    git_model.PushPolicyLevelOrg,
+		MaxFileSize:      20,
+		MaxFileSizeLevel: git_model.PushPolicyLevelRepo,
+	}, policy)
+
+	// the empty policy inherits everything and is removed
+	repoPolicy = &git_model.PushPolicy{RepoID: 3}
+	assert.NoError(t, git_model.SetPushPolicy(db.DefaultContext, repoPolicy))
+	unittest.AssertNotExistsBean(t, &git_model.PushPolicy{RepoID: 3})
+
+	policy, err = git_model.GetEffectivePushPolicy(db.DefaultContext, 3, 3)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 50, policy.MaxFileSize)
+	assert.Equal(t, git_model.PushPolicyLevelOrg, policy.MaxFileSizeLevel)
+}
@@ -550,6 +550,28 @@ var migrations = []Migration{
 	NewMigration("Add auth_token table", v1_22.CreateAuthTokenTable),
 	// v282 -> v283
 	NewMigration("Add Index to pull_auto_merge.doer_id", v1_22.AddIndexToPullAutoMergeDoerID),
+	// v283 -> v284
+	NewMigration("Add lfs_multipart_part table", v1_22.CreateLFSMultipartPartTable),
+	// v284 -> v285
+	NewMigration("Add moderation_record and moderation_allowlist tables", v1_22.CreateModerationRecordTables),
+	// v285 -> v286
+	NewMigration("Add push_policy table", v1_22.CreatePushPolicyTable),
+	// v286 -> v287
+	NewMigration("Add message_outbox table", v1_22.CreateMessageOutboxTable),
+	// v287 -> v288
+	NewMigration("Add repo_download_stat and repo_download_visitor tables", v1_22.CreateRepoDownloadStatTables),
+	// v288 -> v289
+	NewMigration("Add accessed_unix to lfs_meta_object and lfs_archived_object table", v1_22.AddLFSArchiveTiering),
+	// v289 -> v290
+	NewMigration("Add lfs_verification table", v1_22.CreateLFSVerificationTable),
+	// v290 -> v291
+	NewMigration("Add concurrency columns to action_run and action_run_job", v1_22.AddActionsConcurrency),
+	// v291 -> v292
+	NewMigration("Add action_artifact_retention table", v1_22.CreateActionArtifactRetentionTable),
+	// v292 -> v293
+	NewMigration("Remove the obsolete license and file size pre-receive hooks", v1_22.RemoveObsoletePreReceiveHooks),
+	// v293 -> v294
+	NewMigration("Add storage_migration_object table", v1_22.CreateStorageMigrationObjectTable),
 }
 
 // GetCurrentDBVersion returns the current db version
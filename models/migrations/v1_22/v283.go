@@ -0,0 +1,26 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_22 //nolint
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func CreateLFSMultipartPartTable(x *xorm.Engine) error {
+	type LFSMultipartPart struct {
+		ID           int64              `xorm:"pk autoincr"`
+		RepositoryID int64              `xorm:"INDEX NOT NULL"`
+		Oid          string             `xorm:"INDEX NOT NULL"`
+		UploadID     string             `xorm:"UNIQUE(s) NOT NULL"`
+		PartIndex    int                `xorm:"UNIQUE(s) NOT NULL"`
+		Size         int64              `xorm:"NOT NULL"`
+		Etag         string             `xorm:"NOT NULL"`
+		CreatedUnix  timeutil.TimeStamp `xorm:"created"`
+		UpdatedUnix  timeutil.TimeStamp `xorm:"INDEX updated"`
+	}
+
+	return x.Sync(new(LFSMultipartPart))
+}
@@ -0,0 +1,41 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_22 //nolint
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func CreateModerationRecordTables(x *xorm.Engine) error {
+	type ModerationRecord struct {
+		ID           int64 `xorm:"pk autoincr"`
+		RepoID       int64 `xorm:"INDEX"`
+		DoerID       int64 `xorm:"INDEX"`
+		RefName      string
+		OldCommitID  string
+		NewCommitID  string
+		Path         string `xorm:"TEXT"`
+		SegmentHash  string `xorm:"INDEX"`
+		Provider     string
+		Verdict      string
+		Status       int    `xorm:"INDEX"`
+		AppealReason string `xorm:"TEXT"`
+		ReviewerID   int64
+		CreatedUnix  timeutil.TimeStamp `xorm:"INDEX created"`
+		UpdatedUnix  timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	type ModerationAllowlist struct {
+		ID          int64  `xorm:"pk autoincr"`
+		Type        int    `xorm:"UNIQUE(s)"`
+		Hash        string `xorm:"UNIQUE(s)"`
+		RecordID    int64
+		ReviewerID  int64
+		CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	}
+
+	return x.Sync(new(ModerationRecord), new(ModerationAllowlist))
+}
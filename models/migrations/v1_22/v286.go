@@ -0,0 +1,27 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_22 //nolint
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func CreateMessageOutboxTable(x *xorm.Engine) error {
+	type MessageOutbox struct {
+		ID              int64              `xorm:"pk autoincr"`
+		Topic           string             `xorm:"VARCHAR(255) NOT NULL"`
+		Header          map[string]string  `xorm:"JSON TEXT"`
+		Body            string             `xorm:"LONGTEXT NOT NULL"`
+		Status          int                `xorm:"INDEX NOT NULL DEFAULT 1"`
+		Attempts        int                `xorm:"NOT NULL DEFAULT 0"`
+		LastError       string             `xorm:"TEXT"`
+		NextAttemptUnix timeutil.TimeStamp `xorm:"INDEX NOT NULL DEFAULT 0"`
+		CreatedUnix     timeutil.TimeStamp `xorm:"created NOT NULL"`
+		UpdatedUnix     timeutil.TimeStamp `xorm:"updated NOT NULL"`
+	}
+
+	return x.Sync(new(MessageOutbox))
+}
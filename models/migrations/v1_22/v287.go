@@ -0,0 +1,31 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_22 //nolint
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func CreateRepoDownloadStatTables(x *xorm.Engine) error {
+	type RepoDownloadStat struct {
+		ID          int64              `xorm:"pk autoincr"`
+		RepoID      int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		Kind        string             `xorm:"VARCHAR(16) UNIQUE(s) NOT NULL"`
+		Day         timeutil.TimeStamp `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		Count       int64              `xorm:"NOT NULL DEFAULT 0"`
+		UniqueCount int64              `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	type RepoDownloadVisitor struct {
+		ID     int64              `xorm:"pk autoincr"`
+		RepoID int64              `xorm:"UNIQUE(s) NOT NULL"`
+		Kind   string             `xorm:"VARCHAR(16) UNIQUE(s) NOT NULL"`
+		Day    timeutil.TimeStamp `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		Hash   string             `xorm:"VARCHAR(64) UNIQUE(s) NOT NULL"`
+	}
+
+	return x.Sync(new(RepoDownloadStat), new(RepoDownloadVisitor))
+}
@@ -0,0 +1,40 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_22 //nolint
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func AddLFSArchiveTiering(x *xorm.Engine) error {
+	type LFSMetaObject struct {
+		ID           int64              `xorm:"pk autoincr"`
+		Oid          string             `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		Size         int64              `xorm:"NOT NULL"`
+		RepositoryID int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		CreatedUnix  timeutil.TimeStamp `xorm:"created"`
+		UpdatedUnix  timeutil.TimeStamp `xorm:"INDEX updated"`
+		AccessedUnix timeutil.TimeStamp `xorm:"INDEX NOT NULL DEFAULT 0"`
+	}
+
+	type LFSArchivedObject struct {
+		ID          int64              `xorm:"pk autoincr"`
+		Oid         string             `xorm:"UNIQUE NOT NULL"`
+		Size        int64              `xorm:"NOT NULL"`
+		Status      int                `xorm:"INDEX NOT NULL"`
+		CreatedUnix timeutil.TimeStamp `xorm:"created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"INDEX"`
+	}
+
+	if err := x.Sync(new(LFSMetaObject), new(LFSArchivedObject)); err != nil {
+		return err
+	}
+
+	// the accesses before the migration are unknown, the existing objects are considered accessed now so they are
+	// only archived once they haven't been accessed for the whole archive period
+	_, err := x.Exec("UPDATE lfs_meta_object SET accessed_unix = ? WHERE accessed_unix = 0", timeutil.TimeStampNow())
+	return err
+}
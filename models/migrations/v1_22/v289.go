@@ -0,0 +1,24 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_22 //nolint
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func CreateLFSVerificationTable(x *xorm.Engine) error {
+	type LFSVerification struct {
+		ID           int64              `xorm:"pk autoincr"`
+		RepositoryID int64              `xorm:"INDEX NOT NULL"`
+		Oid          string             `xorm:"INDEX NOT NULL"`
+		Size         int64              `xorm:"NOT NULL"`
+		DoerID       int64              `xorm:"NOT NULL DEFAULT 0"`
+		Status       int                `xorm:"NOT NULL"`
+		CreatedUnix  timeutil.TimeStamp `xorm:"INDEX created"`
+	}
+
+	return x.Sync(new(LFSVerification))
+}
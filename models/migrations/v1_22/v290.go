@@ -0,0 +1,25 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_22 //nolint
+
+import (
+	"xorm.io/xorm"
+)
+
+func AddActionsConcurrency(x *xorm.Engine) error {
+	type ActionRun struct {
+		RawConcurrency    string `xorm:"TEXT"`
+		ConcurrencyGroup  string `xorm:"index"`
+		ConcurrencyCancel bool
+	}
+
+	type ActionRunJob struct {
+		RawConcurrency         string `xorm:"TEXT"`
+		IsConcurrencyEvaluated bool
+		ConcurrencyGroup       string `xorm:"index"`
+		ConcurrencyCancel      bool
+	}
+
+	return x.Sync(new(ActionRun), new(ActionRunJob))
+}
@@ -0,0 +1,24 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_22 //nolint
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func CreateActionArtifactRetentionTable(x *xorm.Engine) error {
+	type ActionArtifactRetention struct {
+		ID            int64
+		OwnerID       int64 `xorm:"INDEX UNIQUE(owner_repo) NOT NULL DEFAULT 0"`
+		RepoID        int64 `xorm:"INDEX UNIQUE(owner_repo) NOT NULL DEFAULT 0"`
+		RetentionDays int64
+		MaxSize       int64
+		CreatedUnix   timeutil.TimeStamp `xorm:"created NOT NULL"`
+		UpdatedUnix   timeutil.TimeStamp `xorm:"updated NOT NULL"`
+	}
+
+	return x.Sync(new(ActionArtifactRetention))
+}
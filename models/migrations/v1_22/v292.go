@@ -0,0 +1,60 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_22 //nolint
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+
+	"xorm.io/xorm"
+)
+
+// RemoveObsoletePreReceiveHooks removes the license and file size scripts from the pre-receive hooks of the existing
+// repositories, they are replaced by the push policies checked by the internal pre-receive hook
+func RemoveObsoletePreReceiveHooks(x *xorm.Engine) error {
+	type Repository struct {
+		ID        int64
+		OwnerName string
+		LowerName string
+	}
+
+	limit := setting.Database.IterateBufferSize
+	if limit <= 0 {
+		limit = 50
+	}
+
+	var lastID int64
+	for {
+		var repos []*Repository
+		if err := x.Table("repository").Select("id, owner_name, lower_name").
+			Where("id > ?", lastID).OrderBy("id").Limit(limit).Find(&repos); err != nil {
+			return err
+		}
+		if len(repos) == 0 {
+			return nil
+		}
+		lastID = repos[len(repos)-1].ID
+
+		for _, repo := range repos {
+			ownerPath := filepath.Join(setting.RepoRootPath, strings.ToLower(repo.OwnerName))
+			// the wiki repository has the same hooks as the repository
+			for _, repoPath := range []string{
+				filepath.Join(ownerPath, repo.LowerName+".git"),
+				filepath.Join(ownerPath, repo.LowerName+".wiki.git"),
+			} {
+				for _, name := range []string{"checkLicense", "fileSizeMax"} {
+					hookPath := filepath.Join(repoPath, "hooks", "pre-receive.d", name)
+					if err := util.Remove(hookPath); err != nil && !os.IsNotExist(err) {
+						return fmt.Errorf("unable to remove obsolete hook file '%s': %w", hookPath, err)
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_22 //nolint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"code.gitea.io/gitea/models/migrations/base"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RemoveObsoletePreReceiveHooks(t *testing.T) {
+	type Repository struct {
+		ID        int64 `xorm:"pk autoincr"`
+		OwnerName string
+		LowerName string `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	}
+
+	// Prepare and load the testing database
+	x, deferable := base.PrepareTestEnv(t, 0, new(Repository))
+	defer deferable()
+	if x == nil || t.Failed() {
+		return
+	}
+
+	// the hooks of an existing repository and of its wiki, written before the push policies
+	var hookDirs []string
+	for _, name := range []string{"repo1.git", "repo1.wiki.git"} {
+		hookDir := filepath.Join(setting.RepoRootPath, "user2", name, "hooks", "pre-receive.d")
+		assert.NoError(t, os.MkdirAll(hookDir, 0o755))
+		for _, hook := range []string{"gitea", "checkLicense", "fileSizeMax"} {
+			assert.NoError(t, os.WriteFile(filepath.Join(hookDir, hook), []byte("#!/usr/bin/env bash\n"), 0o755))
+		}
+		hookDirs = append(hookDirs, hookDir)
+	}
+
+	if err := RemoveObsoletePreReceiveHooks(x); err != nil {
+		assert.NoError(t, err)
+		return
+	}
+
+	for _, hookDir := range hookDirs {
+		assert.FileExists(t, filepath.Join(hookDir, "gitea"))
+		assert.NoFileExists(t, filepath.Join(hookDir, "checkLicense"))
+		assert.NoFileExists(t, filepath.Join(hookDir, "fileSizeMax"))
+	}
+
+	// the hooks which are already removed are ignored
+	assert.NoError(t, RemoveObsoletePreReceiveHooks(x))
+}
@@ -0,0 +1,24 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_22 //nolint
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func CreateStorageMigrationObjectTable(x *xorm.Engine) error {
+	type StorageMigrationObject struct {
+		ID          int64
+		Type        string             `xorm:"UNIQUE(type_path) NOT NULL"`
+		Path        string             `xorm:"UNIQUE(type_path) NOT NULL"`
+		Size        int64              `xorm:"NOT NULL DEFAULT 0"`
+		Checksum    string             `xorm:"VARCHAR(64)"`
+		Error       string             `xorm:"TEXT"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"updated NOT NULL"`
+	}
+
+	return x.Sync(new(StorageMigrationObject))
+}
@@ -0,0 +1,85 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package moderation
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// AllowType represents the kind of an allowed content
+type AllowType int
+
+const (
+	// AllowTypeBlob allows a file content by its blob SHA
+	AllowTypeBlob AllowType = iota + 1
+	// AllowTypeCommit allows the commit messages and file names of a push by its new commit id
+	AllowTypeCommit
+)
+
+// Allowlist is a content allowed by an admin after a manual review, it is skipped by the moderation
+type Allowlist struct {
+	ID          int64     `xorm:"pk autoincr"`
+	Type        AllowType `xorm:"UNIQUE(s)"`
+	Hash        string    `xorm:"UNIQUE(s)"`
+	RecordID    int64
+	ReviewerID  int64
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+}
+
+// TableName sets the table name of the moderation allowlist
+func (Allowlist) TableName() string {
+	return "moderation_allowlist"
+}
+
+func init() {
+	db.RegisterModel(new(Allowlist))
+}
+
+// AllowRecord allows the content of the record and marks the record as allowed
+func AllowRecord(ctx context.Context, r *Record, reviewerID int64) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		entry := &Allowlist{Type: AllowTypeCommit, Hash: r.NewCommitID}
+		if r.Path != "" {
+			entry = &Allowlist{Type: AllowTypeBlob, Hash: r.SegmentHash}
+		}
+		has, err := db.GetEngine(ctx).Exist(&Allowlist{Type: entry.Type, Hash: entry.Hash})
+		if err != nil {
+			return err
+		}
+		if !has {
+			entry.RecordID = r.ID
+			entry.ReviewerID = reviewerID
+			if err := db.Insert(ctx, entry); err != nil {
+				return err
+			}
+		}
+		r.Status = RecordStatusAllowed
+		r.ReviewerID = reviewerID
+		return UpdateRecordCols(ctx, r, "status", "reviewer_id")
+	})
+}
+
+// IsAllowed returns true if the content has been allowed
+func IsAllowed(ctx context.Context, tp AllowType, hash string) (bool, error) {
+	return db.GetEngine(ctx).Exist(&Allowlist{Type: tp, Hash: hash})
+}
+
+// GetAllowedHashes returns the allowed hashes among the given ones
+func GetAllowedHashes(ctx context.Context, tp AllowType, hashes []string) (map[string]bool, error) {
+	allowed := make(map[string]bool)
+	for i := 0; i < len(hashes); i += db.DefaultMaxInSize {
+		end := min(i+db.DefaultMaxInSize, len(hashes))
+		entries := make([]*Allowlist, 0, end-i)
+		if err := db.GetEngine(ctx).Where("type = ?", tp).In("hash", hashes[i:end]).Find(&entries); err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			allowed[entry.Hash] = true
+		}
+	}
+	return allowed, nil
+}
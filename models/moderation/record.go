@@ -0,0 +1,205 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package moderation
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+
+	"xorm.io/builder"
+)
+
+// RecordStatus represents the review status of a moderation record
+type RecordStatus int
+
+const (
+	// RecordStatusPassed the content passed the moderation
+	RecordStatusPassed RecordStatus = iota + 1
+	// RecordStatusRejected the content was rejected by the moderator
+	RecordStatusRejected
+	// RecordStatusAppealed the pusher appealed the rejection, waiting for an admin review
+	RecordStatusAppealed
+	// RecordStatusAllowed an admin allowed the content after a manual review
+	RecordStatusAllowed
+	// RecordStatusDismissed an admin confirmed the rejection after a manual review
+	RecordStatusDismissed
+)
+
+var recordStatusNames = map[RecordStatus]string{
+	RecordStatusPassed:    "passed",
+	RecordStatusRejected:  "rejected",
+	RecordStatusAppealed:  "appealed",
+	RecordStatusAllowed:   "allowed",
+	RecordStatusDismissed: "dismissed",
+}
+
+// String returns the name of the status
+func (s RecordStatus) String() string {
+	return recordStatusNames[s]
+}
+
+// ParseRecordStatus returns the status of the name, or 0 if the name is unknown
+func ParseRecordStatus(name string) RecordStatus {
+	for status, n := range recordStatusNames {
+		if n == name {
+			return status
+		}
+	}
+	return 0
+}
+
+// Record is an audit log entry of the content moderation of a push
+type Record struct {
+	ID          int64 `xorm:"pk autoincr"`
+	RepoID      int64 `xorm:"INDEX"`
+	DoerID      int64 `xorm:"INDEX"`
+	RefName     string
+	OldCommitID string
+	NewCommitID string
+	// Path is the path of the offending file, empty if the commit messages or file names are offending
+	Path string `xorm:"TEXT"`
+	// SegmentHash is the blob SHA of the offending file or the SHA256 of the offending text segment
+	SegmentHash  string `xorm:"INDEX"`
+	Provider     string
+	Verdict      string
+	Status       RecordStatus `xorm:"INDEX"`
+	AppealReason string       `xorm:"TEXT"`
+	ReviewerID   int64
+	CreatedUnix  timeutil.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix  timeutil.TimeStamp `xorm:"updated"`
+
+	Repo     *repo_model.Repository `xorm:"-"`
+	Doer     *user_model.User       `xorm:"-"`
+	Reviewer *user_model.User       `xorm:"-"`
+}
+
+// TableName sets the table name of the moderation record
+func (Record) TableName() string {
+	return "moderation_record"
+}
+
+func init() {
+	db.RegisterModel(new(Record))
+}
+
+// ErrRecordNotExist represents a "RecordNotExist" kind of error.
+type ErrRecordNotExist struct {
+	ID int64
+}
+
+// IsErrRecordNotExist checks if an error is a ErrRecordNotExist.
+func IsErrRecordNotExist(err error) bool {
+	_, ok := err.(ErrRecordNotExist)
+	return ok
+}
+
+func (err ErrRecordNotExist) Error() string {
+	return fmt.Sprintf("moderation record does not exist [id: %d]", err.ID)
+}
+
+func (err ErrRecordNotExist) Unwrap() error {
+	return util.ErrNotExist
+}
+
+// LoadAttributes loads the repository, the pusher and the reviewer of the record
+func (r *Record) LoadAttributes(ctx context.Context) (err error) {
+	if r.Repo == nil && r.RepoID > 0 {
+		if r.Repo, err = repo_model.GetRepositoryByID(ctx, r.RepoID); err != nil && !repo_model.IsErrRepoNotExist(err) {
+			return err
+		}
+	}
+	if r.Doer == nil {
+		if r.Doer, err = user_model.GetPossibleUserByID(ctx, r.DoerID); err != nil {
+			return err
+		}
+	}
+	if r.Reviewer == nil && r.ReviewerID > 0 {
+		if r.Reviewer, err = user_model.GetPossibleUserByID(ctx, r.ReviewerID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsReviewable returns true if an admin can allow or dismiss the record
+func (r *Record) IsReviewable() bool {
+	return r.Status == RecordStatusRejected || r.Status == RecordStatusAppealed
+}
+
+// InsertRecord inserts a moderation record
+func InsertRecord(ctx context.Context, r *Record) error {
+	return db.Insert(ctx, r)
+}
+
+// GetRecordByID returns the moderation record by its id
+func GetRecordByID(ctx context.Context, id int64) (*Record, error) {
+	r := new(Record)
+	has, err := db.GetEngine(ctx).ID(id).Get(r)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrRecordNotExist{ID: id}
+	}
+	return r, nil
+}
+
+// UpdateRecordCols updates the given columns of the record
+func UpdateRecordCols(ctx context.Context, r *Record, cols ...string) error {
+	_, err := db.GetEngine(ctx).ID(r.ID).Cols(cols...).Update(r)
+	return err
+}
+
+// FindRecordsOptions represents the options to find moderation records
+type FindRecordsOptions struct {
+	db.ListOptions
+	RepoID int64
+	DoerID int64
+	Status RecordStatus
+}
+
+// ToConds implements db.FindOptions
+func (opts FindRecordsOptions) ToConds() builder.Cond {
+	cond := builder.NewCond()
+	if opts.RepoID > 0 {
+		cond = cond.And(builder.Eq{"repo_id": opts.RepoID})
+	}
+	if opts.DoerID > 0 {
+		cond = cond.And(builder.Eq{"doer_id": opts.DoerID})
+	}
+	if opts.Status > 0 {
+		cond = cond.And(builder.Eq{"status": opts.Status})
+	}
+	return cond
+}
+
+// FindRecords returns the moderation records matching the options, most recent first
+func FindRecords(ctx context.Context, opts FindRecordsOptions) ([]*Record, int64, error) {
+	sess := db.GetEngine(ctx).Where(opts.ToConds()).Desc("id")
+	if opts.Page > 0 {
+		sess = db.SetSessionPagination(sess, &opts)
+	}
+	records := make([]*Record, 0, opts.PageSize)
+	count, err := sess.FindAndCount(&records)
+	return records, count, err
+}
+
+// DismissRecord confirms the rejection of the record after a manual review
+func DismissRecord(ctx context.Context, r *Record, reviewerID int64) error {
+	r.Status = RecordStatusDismissed
+	r.ReviewerID = reviewerID
+	return UpdateRecordCols(ctx, r, "status", "reviewer_id")
+}
+
+// AppealRecord asks the admins to review the rejection of the record
+func AppealRecord(ctx context.Context, r *Record, reason string) error {
+	r.Status = RecordStatusAppealed
+	r.AppealReason = reason
+	return UpdateRecordCols(ctx, r, "status", "appeal_reason")
+}
@@ -0,0 +1,71 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package moderation_test
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	moderation_model "code.gitea.io/gitea/models/moderation"
+	"code.gitea.io/gitea/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModerationRecords(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	blob := &moderation_model.Record{
+		RepoID:      1,
+		DoerID:      2,
+		RefName:     "refs/heads/master",
+		NewCommitID: "65f1bf27bc3bf70f64657658635e66094edbcb4d",
+		Path:        "README.md",
+		SegmentHash: "4b825dc642cb6eb9a060e54bf8d69288fbee4904",
+		Status:      moderation_model.RecordStatusRejected,
+	}
+	text := &moderation_model.Record{
+		RepoID:      1,
+		DoerID:      2,
+		NewCommitID: "2a47ca4b614a9f5a43abbd5ad851a54a616ffee6",
+		Status:      moderation_model.RecordStatusRejected,
+	}
+	passed := &moderation_model.Record{RepoID: 1, DoerID: 3, Status: moderation_model.RecordStatusPassed}
+	for _, r := range []*moderation_model.Record{blob, text, passed} {
+		assert.NoError(t, moderation_model.InsertRecord(db.DefaultContext, r))
+	}
+
+	records, count, err := moderation_model.FindRecords(db.DefaultContext, moderation_model.FindRecordsOptions{
+		DoerID: 2,
+		Status: moderation_model.RecordStatusRejected,
+	})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+	if assert.Len(t, records, 2) {
+		assert.Equal(t, text.ID, records[0].ID)
+		assert.Equal(t, blob.ID, records[1].ID)
+	}
+
+	assert.NoError(t, moderation_model.AppealRecord(db.DefaultContext, blob, "false positive"))
+	assert.NoError(t, moderation_model.AllowRecord(db.DefaultContext, blob, 1))
+	assert.NoError(t, moderation_model.AllowRecord(db.DefaultContext, text, 1))
+
+	r, err := moderation_model.GetRecordByID(db.DefaultContext, blob.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, moderation_model.RecordStatusAllowed, r.Status)
+	assert.Equal(t, "false positive", r.AppealReason)
+	assert.EqualValues(t, 1, r.ReviewerID)
+	assert.False(t, r.IsReviewable())
+
+	allowed, err := moderation_model.GetAllowedHashes(db.DefaultContext, moderation_model.AllowTypeBlob, []string{blob.SegmentHash, "unknown"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{blob.SegmentHash: true}, allowed)
+
+	has, err := moderation_model.IsAllowed(db.DefaultContext, moderation_model.AllowTypeCommit, text.NewCommitID)
+	assert.NoError(t, err)
+	assert.True(t, has)
+
+	_, err = moderation_model.GetRecordByID(db.DefaultContext, 1000)
+	assert.True(t, moderation_model.IsErrRecordNotExist(err))
+}
@@ -0,0 +1,187 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package outbox
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+
+	"xorm.io/builder"
+)
+
+// MessageStatus represents the delivery status of an outbox message
+type MessageStatus int
+
+const (
+	// MessageStatusPending the message waits to be published
+	MessageStatusPending MessageStatus = iota + 1
+	// MessageStatusStuck publishing the message failed too many times, it is only retried by an admin
+	MessageStatusStuck
+)
+
+var messageStatusNames = map[MessageStatus]string{
+	MessageStatusPending: "pending",
+	MessageStatusStuck:   "stuck",
+}
+
+// String returns the name of the status
+func (s MessageStatus) String() string {
+	return messageStatusNames[s]
+}
+
+// ParseMessageStatus returns the status of the name, 0 if the name is unknown
+func ParseMessageStatus(name string) MessageStatus {
+	for s, n := range messageStatusNames {
+		if n == name {
+			return s
+		}
+	}
+	return 0
+}
+
+// Message is a message written to the outbox in the transaction of the change it describes.
+// It is deleted once it has been published to the message queue.
+type Message struct {
+	ID              int64              `xorm:"pk autoincr"`
+	Topic           string             `xorm:"VARCHAR(255) NOT NULL"`
+	Header          map[string]string  `xorm:"JSON TEXT"`
+	Body            string             `xorm:"LONGTEXT NOT NULL"`
+	Status          MessageStatus      `xorm:"INDEX NOT NULL DEFAULT 1"`
+	Attempts        int                `xorm:"NOT NULL DEFAULT 0"`
+	LastError       string             `xorm:"TEXT"`
+	NextAttemptUnix timeutil.TimeStamp `xorm:"INDEX NOT NULL DEFAULT 0"`
+	CreatedUnix     timeutil.TimeStamp `xorm:"created NOT NULL"`
+	UpdatedUnix     timeutil.TimeStamp `xorm:"updated NOT NULL"`
+}
+
+func init() {
+	db.RegisterModel(new(Message))
+}
+
+// TableName sets the table name of the outbox messages
+func (Message) TableName() string {
+	return "message_outbox"
+}
+
+// ErrMessageNotExist represents a "MessageNotExist" kind of error.
+type ErrMessageNotExist struct {
+	ID int64
+}
+
+// IsErrMessageNotExist checks if an error is a ErrMessageNotExist.
+func IsErrMessageNotExist(err error) bool {
+	_, ok := err.(ErrMessageNotExist)
+	return ok
+}
+
+func (err ErrMessageNotExist) Error() string {
+	return fmt.Sprintf("outbox message does not exist [id: %d]", err.ID)
+}
+
+func (err ErrMessageNotExist) Unwrap() error {
+	return util.ErrNotExist
+}
+
+// InsertMessage writes a pending message to the outbox, the message is due at nextAttempt
+func InsertMessage(ctx context.Context, m *Message, nextAttempt timeutil.TimeStamp) error {
+	m.Status = MessageStatusPending
+	m.NextAttemptUnix = nextAttempt
+	return db.Insert(ctx, m)
+}
+
+// GetMessageByID returns the outbox message by the given id
+func GetMessageByID(ctx context.Context, id int64) (*Message, error) {
+	m := &Message{}
+	has, err := db.GetEngine(ctx).ID(id).Get(m)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrMessageNotExist{ID: id}
+	}
+	return m, nil
+}
+
+// DeleteMessage deletes a published or discarded message
+func DeleteMessage(ctx context.Context, id int64) error {
+	_, err := db.GetEngine(ctx).ID(id).Delete(&Message{})
+	return err
+}
+
+// LeaseDueMessages returns the ids of the pending messages due before now, and postpones them to leaseUntil,
+// so they are not picked up again while they are being published
+func LeaseDueMessages(ctx context.Context, now, leaseUntil timeutil.TimeStamp, limit int) ([]int64, error) {
+	var ids []int64
+	err := db.WithTx(ctx, func(ctx context.Context) error {
+		if err := db.GetEngine(ctx).Table("message_outbox").
+			Where(builder.Eq{"status": MessageStatusPending}.And(builder.Lte{"next_attempt_unix": now})).
+			Asc("next_attempt_unix").
+			Limit(limit).
+			Cols("id").
+			Find(&ids); err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		_, err := db.GetEngine(ctx).In("id", ids).Cols("next_attempt_unix").NoAutoTime().Update(&Message{NextAttemptUnix: leaseUntil})
+		return err
+	})
+	return ids, err
+}
+
+// RecordFailedAttempt records a failed publishing attempt, the message becomes stuck if it has reached maxAttempts
+func RecordFailedAttempt(ctx context.Context, m *Message, publishErr error, nextAttempt timeutil.TimeStamp, maxAttempts int) error {
+	m.Attempts++
+	m.LastError = publishErr.Error()
+	m.NextAttemptUnix = nextAttempt
+	if maxAttempts > 0 && m.Attempts >= maxAttempts {
+		m.Status = MessageStatusStuck
+	}
+	_, err := db.GetEngine(ctx).ID(m.ID).Cols("attempts", "last_error", "next_attempt_unix", "status").Update(m)
+	return err
+}
+
+// RetryMessage makes a stuck message pending again and due immediately
+func RetryMessage(ctx context.Context, id int64) error {
+	_, err := db.GetEngine(ctx).ID(id).Cols("status", "attempts", "next_attempt_unix").Update(&Message{
+		Status:          MessageStatusPending,
+		NextAttemptUnix: timeutil.TimeStampNow(),
+	})
+	return err
+}
+
+// FindMessagesOptions represents the options to find outbox messages
+type FindMessagesOptions struct {
+	db.ListOptions
+	Status MessageStatus
+	// Failed only returns the messages with at least one failed attempt
+	Failed bool
+}
+
+// ToConds implements db.FindOptions
+func (opts FindMessagesOptions) ToConds() builder.Cond {
+	cond := builder.NewCond()
+	if opts.Status > 0 {
+		cond = cond.And(builder.Eq{"status": opts.Status})
+	}
+	if opts.Failed {
+		cond = cond.And(builder.Gt{"attempts": 0})
+	}
+	return cond
+}
+
+// FindMessages returns the outbox messages matching the options, oldest first
+func FindMessages(ctx context.Context, opts FindMessagesOptions) ([]*Message, int64, error) {
+	sess := db.GetEngine(ctx).Where(opts.ToConds()).Asc("id")
+	if opts.Page > 0 {
+		sess = db.SetSessionPagination(sess, &opts)
+	}
+	messages := make([]*Message, 0, opts.PageSize)
+	count, err := sess.FindAndCount(&messages)
+	return messages, count, err
+}
@@ -0,0 +1,72 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package outbox_test
+
+import (
+	"errors"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/outbox"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutboxMessages(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	now := timeutil.TimeStampNow()
+	due := &outbox.Message{Topic: "gitea-push", Header: map[string]string{"gitea-event-type": "push"}, Body: "{}"}
+	assert.NoError(t, outbox.InsertMessage(db.DefaultContext, due, now-1))
+	later := &outbox.Message{Topic: "gitea-push", Body: "{}"}
+	assert.NoError(t, outbox.InsertMessage(db.DefaultContext, later, now+60))
+
+	ids, err := outbox.LeaseDueMessages(db.DefaultContext, now, now+10, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{due.ID}, ids)
+
+	// leased messages are not due any more
+	ids, err = outbox.LeaseDueMessages(db.DefaultContext, now, now+10, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, ids)
+
+	m, err := outbox.GetMessageByID(db.DefaultContext, due.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "push", m.Header["gitea-event-type"])
+	assert.Equal(t, now+10, m.NextAttemptUnix)
+
+	assert.NoError(t, outbox.RecordFailedAttempt(db.DefaultContext, m, errors.New("broker down"), now+20, 2))
+	m, err = outbox.GetMessageByID(db.DefaultContext, due.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, outbox.MessageStatusPending, m.Status)
+	assert.Equal(t, 1, m.Attempts)
+	assert.Equal(t, "broker down", m.LastError)
+
+	assert.NoError(t, outbox.RecordFailedAttempt(db.DefaultContext, m, errors.New("broker down"), now+40, 2))
+	stuck, total, err := outbox.FindMessages(db.DefaultContext, outbox.FindMessagesOptions{Status: outbox.MessageStatusStuck})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, total)
+	assert.Equal(t, due.ID, stuck[0].ID)
+
+	// stuck messages are never due
+	ids, err = outbox.LeaseDueMessages(db.DefaultContext, now+100, now+110, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{later.ID}, ids)
+
+	assert.NoError(t, outbox.RetryMessage(db.DefaultContext, due.ID))
+	m, err = outbox.GetMessageByID(db.DefaultContext, due.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, outbox.MessageStatusPending, m.Status)
+	assert.Zero(t, m.Attempts)
+
+	failed, _, err := outbox.FindMessages(db.DefaultContext, outbox.FindMessagesOptions{Failed: true})
+	assert.NoError(t, err)
+	assert.Empty(t, failed)
+
+	assert.NoError(t, outbox.DeleteMessage(db.DefaultContext, due.ID))
+	_, err = outbox.GetMessageByID(db.DefaultContext, due.ID)
+	assert.True(t, outbox.IsErrMessageNotExist(err))
+}
@@ -0,0 +1,208 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package repo
+
+import (
+	"context"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unit"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// DownloadKind represents what has been downloaded from a repository
+type DownloadKind string
+
+const (
+	// DownloadKindClone a clone or fetch of the git repository
+	DownloadKindClone DownloadKind = "clone"
+	// DownloadKindArchive a source archive download
+	DownloadKindArchive DownloadKind = "archive"
+	// DownloadKindRaw a raw file download
+	DownloadKindRaw DownloadKind = "raw"
+	// DownloadKindLFS a LFS object download
+	DownloadKindLFS DownloadKind = "lfs"
+)
+
+// DownloadKinds are all the kinds of downloads which are counted
+var DownloadKinds = []DownloadKind{DownloadKindClone, DownloadKindArchive, DownloadKindRaw, DownloadKindLFS}
+
+// IsValid checks if the kind is known
+func (k DownloadKind) IsValid() bool {
+	for _, kind := range DownloadKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// DownloadDay returns the start of the UTC day of t, the downloads are counted per day
+func DownloadDay(t time.Time) timeutil.TimeStamp {
+	t = t.UTC()
+	return timeutil.TimeStamp(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Unix())
+}
+
+// DownloadStat is the number of downloads of a kind from a repository on a day
+type DownloadStat struct {
+	ID     int64              `xorm:"pk autoincr"`
+	RepoID int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	Kind   DownloadKind       `xorm:"VARCHAR(16) UNIQUE(s) NOT NULL"`
+	Day    timeutil.TimeStamp `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	Count  int64              `xorm:"NOT NULL DEFAULT 0"`
+	// UniqueCount is the number of distinct requesters on the day
+	UniqueCount int64 `xorm:"NOT NULL DEFAULT 0"`
+}
+
+// TableName sets the table name of the download statistics
+func (DownloadStat) TableName() string {
+	return "repo_download_stat"
+}
+
+// DownloadVisitor records that a requester has been counted for a kind of download from a repository on a day.
+// Only a hash of the requester is stored, it is salted with the day so the requesters can't be followed
+// across days, and the rows are deleted once the day is over.
+type DownloadVisitor struct {
+	ID     int64              `xorm:"pk autoincr"`
+	RepoID int64              `xorm:"UNIQUE(s) NOT NULL"`
+	Kind   DownloadKind       `xorm:"VARCHAR(16) UNIQUE(s) NOT NULL"`
+	Day    timeutil.TimeStamp `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	Hash   string             `xorm:"VARCHAR(64) UNIQUE(s) NOT NULL"`
+}
+
+// TableName sets the table name of the download visitors
+func (DownloadVisitor) TableName() string {
+	return "repo_download_visitor"
+}
+
+func init() {
+	db.RegisterModel(new(DownloadStat))
+	db.RegisterModel(new(DownloadVisitor))
+}
+
+// AddDownloads adds count downloads of a kind from the repository on the day,
+// the hashes of the requesters are used to count the distinct requesters.
+// The downloads may be added concurrently, e.g. by the workers of the queue or by other instances, so the rows are
+// inserted if they don't exist yet and the counts are incremented by a single update.
+func AddDownloads(ctx context.Context, repoID int64, kind DownloadKind, day timeutil.TimeStamp, count int64, hashes []string) error {
+	var unique int64
+	for _, hash := range hashes {
+		added, err := insertIfNotExist(ctx, &DownloadVisitor{RepoID: repoID, Kind: kind, Day: day, Hash: hash})
+		if err != nil {
+			return err
+		}
+		if added {
+			unique++
+		}
+	}
+
+	if _, err := insertIfNotExist(ctx, &DownloadStat{RepoID: repoID, Kind: kind, Day: day}); err != nil {
+		return err
+	}
+	_, err := db.GetEngine(ctx).Where(builder.Eq{"repo_id": repoID, "kind": kind, "day": day}).
+		Incr("count", count).
+		Incr("unique_count", unique).
+		NoAutoTime().
+		Update(new(DownloadStat))
+	return err
+}
+
+// insertIfNotExist inserts the bean unless a row with its non-zero fields exists, it returns whether it has been inserted.
+// The insertion fails on the unique constraint if the row has been inserted concurrently, so the row is checked again.
+func insertIfNotExist(ctx context.Context, bean any) (bool, error) {
+	has, err := db.GetEngine(ctx).Exist(bean)
+	if err != nil || has {
+		return false, err
+	}
+	errInsert := db.Insert(ctx, bean)
+	if errInsert == nil {
+		return true, nil
+	}
+	if has, err = db.GetEngine(ctx).Exist(bean); err != nil {
+		return false, err
+	} else if !has {
+		return false, errInsert
+	}
+	return false, nil
+}
+
+// DeleteDownloadVisitorsBefore deletes the visitors of the days before the given one, they are only needed to count the distinct requesters of a day
+func DeleteDownloadVisitorsBefore(ctx context.Context, day timeutil.TimeStamp) error {
+	_, err := db.GetEngine(ctx).Where(builder.Lt{"day": day}).Delete(new(DownloadVisitor))
+	return err
+}
+
+// FindDownloadStatsOptions represents the options to find the download statistics
+type FindDownloadStatsOptions struct {
+	RepoID int64
+	Kind   DownloadKind
+	// Since and Until are the first and the last day included
+	Since timeutil.TimeStamp
+	Until timeutil.TimeStamp
+}
+
+// ToConds implements db.FindOptions
+func (opts FindDownloadStatsOptions) ToConds() builder.Cond {
+	cond := builder.NewCond()
+	if opts.RepoID > 0 {
+		cond = cond.And(builder.Eq{"`repo_download_stat`.repo_id": opts.RepoID})
+	}
+	if opts.Kind != "" {
+		cond = cond.And(builder.Eq{"`repo_download_stat`.kind": opts.Kind})
+	}
+	if opts.Since > 0 {
+		cond = cond.And(builder.Gte{"`repo_download_stat`.day": opts.Since})
+	}
+	if opts.Until > 0 {
+		cond = cond.And(builder.Lte{"`repo_download_stat`.day": opts.Until})
+	}
+	return cond
+}
+
+// FindDownloadStats returns the daily download statistics matching the options, ordered by day
+func FindDownloadStats(ctx context.Context, opts FindDownloadStatsOptions) ([]*DownloadStat, error) {
+	stats := make([]*DownloadStat, 0, 10)
+	return stats, db.GetEngine(ctx).Where(opts.ToConds()).Asc("day", "kind").Find(&stats)
+}
+
+// RepoDownloadCount is the number of downloads from a repository over a period
+type RepoDownloadCount struct {
+	RepoID int64
+	Count  int64
+	// UniqueCount is the sum of the distinct requesters of every day
+	UniqueCount int64
+}
+
+// TopDownloadsOptions represents the options to find the most downloaded repositories
+type TopDownloadsOptions struct {
+	db.ListOptions
+	FindDownloadStatsOptions
+	// Actor only gets the repositories it can read the code of
+	Actor *user_model.User
+}
+
+// FindTopDownloadedRepos returns the repositories with the most downloads matching the options, most downloaded first
+func FindTopDownloadedRepos(ctx context.Context, opts TopDownloadsOptions) ([]*RepoDownloadCount, error) {
+	cond := opts.ToConds()
+	if opts.Actor == nil || !opts.Actor.IsAdmin {
+		cond = cond.And(AccessibleRepositoryCondition(opts.Actor, unit.TypeCode))
+	}
+
+	sess := db.GetEngine(ctx).Table("repo_download_stat").
+		Join("INNER", "repository", "`repository`.id = `repo_download_stat`.repo_id").
+		Where(cond).
+		Select("`repo_download_stat`.repo_id AS repo_id, SUM(`repo_download_stat`.count) AS count, SUM(`repo_download_stat`.unique_count) AS unique_count").
+		GroupBy("`repo_download_stat`.repo_id").
+		OrderBy("SUM(`repo_download_stat`.count) DESC, `repo_download_stat`.repo_id ASC")
+	if opts.Page > 0 {
+		sess = db.SetSessionPagination(sess, &opts)
+	}
+
+	counts := make([]*RepoDownloadCount, 0, opts.PageSize)
+	return counts, sess.Find(&counts)
+}
@@ -0,0 +1,84 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package repo_test
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddDownloads(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	day := repo_model.DownloadDay(time.Date(2024, 3, 5, 17, 30, 0, 0, time.UTC))
+	assert.EqualValues(t, time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC).Unix(), day)
+
+	assert.NoError(t, repo_model.AddDownloads(db.DefaultContext, 1, repo_model.DownloadKindClone, day, 3, []string{"a", "b", "a"}))
+	assert.NoError(t, repo_model.AddDownloads(db.DefaultContext, 1, repo_model.DownloadKindClone, day, 2, []string{"b", "c"}))
+	assert.NoError(t, repo_model.AddDownloads(db.DefaultContext, 1, repo_model.DownloadKindRaw, day, 1, []string{"a"}))
+	assert.NoError(t, repo_model.AddDownloads(db.DefaultContext, 1, repo_model.DownloadKindClone, day.AddDuration(24*time.Hour), 1, []string{"a"}))
+
+	stats, err := repo_model.FindDownloadStats(db.DefaultContext, repo_model.FindDownloadStatsOptions{RepoID: 1, Since: day, Until: day})
+	assert.NoError(t, err)
+	if assert.Len(t, stats, 2) {
+		assert.Equal(t, repo_model.DownloadKindClone, stats[0].Kind)
+		assert.EqualValues(t, 5, stats[0].Count)
+		assert.EqualValues(t, 3, stats[0].UniqueCount)
+		assert.Equal(t, repo_model.DownloadKindRaw, stats[1].Kind)
+		assert.EqualValues(t, 1, stats[1].Count)
+		assert.EqualValues(t, 1, stats[1].UniqueCount)
+	}
+
+	stats, err = repo_model.FindDownloadStats(db.DefaultContext, repo_model.FindDownloadStatsOptions{RepoID: 1, Kind: repo_model.DownloadKindClone})
+	assert.NoError(t, err)
+	if assert.Len(t, stats, 2) {
+		assert.Equal(t, day, stats[0].Day)
+		assert.EqualValues(t, 1, stats[1].UniqueCount)
+	}
+
+	assert.NoError(t, repo_model.DeleteDownloadVisitorsBefore(db.DefaultContext, day.AddDuration(24*time.Hour)))
+	unittest.AssertCount(t, &repo_model.DownloadVisitor{Day: day}, 0)
+	unittest.AssertCount(t, &repo_model.DownloadVisitor{}, 1)
+}
+
+func TestFindTopDownloadedRepos(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	day := repo_model.DownloadDay(time.Now())
+	// repository 2 is private
+	assert.NoError(t, repo_model.AddDownloads(db.DefaultContext, 2, repo_model.DownloadKindArchive, day, 10, []string{"a"}))
+	assert.NoError(t, repo_model.AddDownloads(db.DefaultContext, 1, repo_model.DownloadKindArchive, day, 2, []string{"a", "b"}))
+	assert.NoError(t, repo_model.AddDownloads(db.DefaultContext, 1, repo_model.DownloadKindLFS, day, 3, []string{"a"}))
+	assert.NoError(t, repo_model.AddDownloads(db.DefaultContext, 4, repo_model.DownloadKindArchive, day, 4, []string{"a"}))
+
+	opts := repo_model.TopDownloadsOptions{
+		ListOptions:              db.ListOptions{Page: 1, PageSize: 10},
+		FindDownloadStatsOptions: repo_model.FindDownloadStatsOptions{Since: day, Until: day},
+	}
+	counts, err := repo_model.FindTopDownloadedRepos(db.DefaultContext, opts)
+	assert.NoError(t, err)
+	if assert.Len(t, counts, 2) {
+		assert.EqualValues(t, 1, counts[0].RepoID)
+		assert.EqualValues(t, 5, counts[0].Count)
+		assert.EqualValues(t, 3, counts[0].UniqueCount)
+		assert.EqualValues(t, 4, counts[1].RepoID)
+	}
+
+	opts.Kind = repo_model.DownloadKindArchive
+	opts.Actor = unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+	counts, err = repo_model.FindTopDownloadedRepos(db.DefaultContext, opts)
+	assert.NoError(t, err)
+	if assert.Len(t, counts, 3) {
+		assert.EqualValues(t, 2, counts[0].RepoID)
+		assert.EqualValues(t, 4, counts[1].RepoID)
+		assert.EqualValues(t, 1, counts[2].RepoID)
+	}
+}
@@ -166,6 +166,9 @@ func (cfg *PullRequestsConfig) GetDefaultMergeStyle() MergeStyle {
 
 type ActionsConfig struct {
 	DisabledWorkflows []string
+	// ShareWorkflows allows the repositories of other owners to call the reusable workflows of the repository,
+	// they are always callable by the repositories of the same owner and by any repository if it is public
+	ShareWorkflows bool
 }
 
 func (cfg *ActionsConfig) EnableWorkflow(file string) {
@@ -0,0 +1,82 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package system
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// StorageMigrationObject is an object of a storage type migrated online to another storage: the size and the checksum
+// of its verified copy, or the error which kept it from being migrated
+type StorageMigrationObject struct {
+	ID          int64
+	Type        string             `xorm:"UNIQUE(type_path) NOT NULL"`
+	Path        string             `xorm:"UNIQUE(type_path) NOT NULL"`
+	Size        int64              `xorm:"NOT NULL DEFAULT 0"`
+	Checksum    string             `xorm:"VARCHAR(64)"`
+	Error       string             `xorm:"TEXT"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated NOT NULL"`
+}
+
+func init() {
+	db.RegisterModel(new(StorageMigrationObject))
+}
+
+// IsVerified returns whether the copy of the object has been verified
+func (o *StorageMigrationObject) IsVerified() bool {
+	return o.Error == "" && o.Checksum != ""
+}
+
+// GetStorageMigrationObject returns the migrated object of the storage type with the path, nil if it hasn't been
+// migrated yet
+func GetStorageMigrationObject(ctx context.Context, typ, path string) (*StorageMigrationObject, error) {
+	o := &StorageMigrationObject{}
+	has, err := db.GetEngine(ctx).Where("type = ? AND path = ?", typ, path).Get(o)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+	return o, nil
+}
+
+// SaveStorageMigrationObject saves the verified copy or the error of the migration of an object
+func SaveStorageMigrationObject(ctx context.Context, o *StorageMigrationObject) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		e := db.GetEngine(ctx)
+		updated, err := e.Where("type = ? AND path = ?", o.Type, o.Path).Cols("size", "checksum", "error", "updated_unix").Update(o)
+		if err != nil || updated != 0 {
+			return err
+		}
+		_, err = e.Insert(o)
+		return err
+	})
+}
+
+// FindStorageMigrationFailures returns up to limit objects of the storage type which couldn't be migrated, with an id
+// greater than afterID, in the order of their ids
+func FindStorageMigrationFailures(ctx context.Context, typ string, afterID int64, limit int) ([]*StorageMigrationObject, error) {
+	objects := make([]*StorageMigrationObject, 0, limit)
+	return objects, db.GetEngine(ctx).
+		Where(builder.Eq{"type": typ}.And(builder.Neq{"error": ""}).And(builder.Gt{"id": afterID})).
+		OrderBy("id").
+		Limit(limit).
+		Find(&objects)
+}
+
+// CountStorageMigrationFailures returns the number of objects of the storage type which couldn't be migrated
+func CountStorageMigrationFailures(ctx context.Context, typ string) (int64, error) {
+	return db.GetEngine(ctx).Where(builder.Eq{"type": typ}.And(builder.Neq{"error": ""})).Count(new(StorageMigrationObject))
+}
+
+// DeleteStorageMigrationObjects deletes the migrated objects of the storage type, so they are all migrated again
+func DeleteStorageMigrationObjects(ctx context.Context, typ string) error {
+	_, err := db.GetEngine(ctx).Where("type = ?", typ).Delete(new(StorageMigrationObject))
+	return err
+}
@@ -0,0 +1,112 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"fmt"
+
+	"github.com/nektos/act/pkg/exprparser"
+	"github.com/nektos/act/pkg/jobparser"
+	"github.com/nektos/act/pkg/model"
+	"gopkg.in/yaml.v3"
+)
+
+// Concurrency is an evaluated `concurrency` configuration of a workflow or a job,
+// see https://docs.github.com/en/actions/using-jobs/using-concurrency
+type Concurrency struct {
+	Group            string `yaml:"group"`
+	CancelInProgress bool   `yaml:"cancel-in-progress"`
+}
+
+// GetConcurrency returns the raw `concurrency` configurations of a workflow and of its jobs (keyed by job id).
+// They are kept unevaluated since their expressions can only be evaluated once the run has been created.
+func GetConcurrency(content []byte) (string, map[string]string, error) {
+	var workflow struct {
+		Concurrency yaml.Node `yaml:"concurrency"`
+		Jobs        map[string]struct {
+			Concurrency yaml.Node `yaml:"concurrency"`
+		} `yaml:"jobs"`
+	}
+	if err := yaml.Unmarshal(content, &workflow); err != nil {
+		return "", nil, err
+	}
+
+	raw, err := marshalConcurrency(&workflow.Concurrency)
+	if err != nil {
+		return "", nil, err
+	}
+	jobs := make(map[string]string, len(workflow.Jobs))
+	for id, job := range workflow.Jobs {
+		jobRaw, err := marshalConcurrency(&job.Concurrency)
+		if err != nil {
+			return "", nil, fmt.Errorf("job %s: %w", id, err)
+		}
+		if jobRaw != "" {
+			jobs[id] = jobRaw
+		}
+	}
+	return raw, jobs, nil
+}
+
+func marshalConcurrency(node *yaml.Node) (string, error) {
+	if node.IsZero() {
+		return "", nil
+	}
+	switch node.Kind {
+	case yaml.ScalarNode, yaml.MappingNode:
+	default:
+		return "", fmt.Errorf("invalid concurrency at line %d", node.Line)
+	}
+	bs, err := yaml.Marshal(node)
+	if err != nil {
+		return "", err
+	}
+	return string(bs), nil
+}
+
+// EvaluateConcurrency evaluates the expressions of a raw concurrency configuration in the given environment.
+// It returns nil if the concurrency group is empty.
+func EvaluateConcurrency(raw, jobID string, env *exprparser.EvaluationEnvironment) (*Concurrency, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(raw), &doc); err != nil {
+		return nil, err
+	}
+	node := &doc
+	if node.Kind == yaml.DocumentNode && len(node.Content) == 1 {
+		node = node.Content[0]
+	}
+
+	evaluator := jobparser.NewExpressionEvaluator(exprparser.NewInterpeter(env, exprparser.Config{
+		Run: &model.Run{
+			Workflow: &model.Workflow{Jobs: map[string]*model.Job{}},
+			JobID:    jobID,
+		},
+		Context: "job",
+	}))
+	if err := evaluator.EvaluateYamlNode(node); err != nil {
+		return nil, fmt.Errorf("evaluate concurrency: %w", err)
+	}
+
+	concurrency := &Concurrency{}
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if err := node.Decode(&concurrency.Group); err != nil {
+			return nil, err
+		}
+	case yaml.MappingNode:
+		if err := node.Decode(concurrency); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("invalid concurrency at line %d", node.Line)
+	}
+	if concurrency.Group == "" {
+		return nil, nil
+	}
+	return concurrency, nil
+}
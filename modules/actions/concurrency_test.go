@@ -0,0 +1,84 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	"github.com/nektos/act/pkg/exprparser"
+	"github.com/nektos/act/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetConcurrency(t *testing.T) {
+	raw, jobs, err := GetConcurrency([]byte(`
+on: push
+concurrency:
+  group: ${{ github.workflow }}-${{ github.ref }}
+  cancel-in-progress: ${{ github.ref != 'refs/heads/main' }}
+jobs:
+  train:
+    concurrency: gpu-${{ matrix.size }}
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo train
+  lint:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo lint
+`))
+	assert.NoError(t, err)
+	assert.Contains(t, raw, "group: ${{ github.workflow }}-${{ github.ref }}")
+	assert.Len(t, jobs, 1)
+	assert.Contains(t, jobs["train"], "gpu-${{ matrix.size }}")
+
+	raw, jobs, err = GetConcurrency([]byte("on: push\njobs:\n  lint:\n    runs-on: ubuntu-latest\n"))
+	assert.NoError(t, err)
+	assert.Empty(t, raw)
+	assert.Empty(t, jobs)
+
+	_, _, err = GetConcurrency([]byte("on: push\nconcurrency: [a, b]\njobs: {}\n"))
+	assert.Error(t, err)
+}
+
+func TestEvaluateConcurrency(t *testing.T) {
+	env := &exprparser.EvaluationEnvironment{
+		Github: &model.GithubContext{
+			Workflow: "train.yaml",
+			Ref:      "refs/heads/feature",
+		},
+		Inputs: map[string]any{"model": "llama"},
+		Matrix: map[string]any{"size": "large"},
+	}
+
+	for _, c := range []struct {
+		raw  string
+		want *Concurrency
+	}{
+		{
+			raw:  "group: ${{ github.workflow }}-${{ github.ref }}\ncancel-in-progress: ${{ github.ref != 'refs/heads/main' }}\n",
+			want: &Concurrency{Group: "train.yaml-refs/heads/feature", CancelInProgress: true},
+		},
+		{
+			raw:  "group: eval-${{ inputs.model }}\ncancel-in-progress: false\n",
+			want: &Concurrency{Group: "eval-llama"},
+		},
+		{
+			raw:  "gpu-${{ matrix.size }}\n",
+			want: &Concurrency{Group: "gpu-large"},
+		},
+		{
+			raw:  "group: ${{ inputs.missing }}\n",
+			want: nil,
+		},
+		{
+			raw:  "",
+			want: nil,
+		},
+	} {
+		concurrency, err := EvaluateConcurrency(c.raw, "train", env)
+		assert.NoError(t, err, c.raw)
+		assert.EqualValues(t, c.want, concurrency, c.raw)
+	}
+}
@@ -22,6 +22,8 @@ const (
 	GithubEventRelease                  = "release"
 	GithubEventPullRequestComment       = "pull_request_comment"
 	GithubEventGollum                   = "gollum"
+	GithubEventWorkflowDispatch         = "workflow_dispatch"
+	GithubEventWorkflowCall             = "workflow_call"
 )
 
 // canGithubEventMatch check if the input Github event can match any Gitea event.
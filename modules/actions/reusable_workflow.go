@@ -0,0 +1,458 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maxReusableWorkflowDepth is the maximum depth of nested reusable workflows, the same as GitHub
+const maxReusableWorkflowDepth = 4
+
+// ReusableWorkflow is a reusable workflow referenced by the `uses` of a job,
+// see https://docs.github.com/en/actions/using-workflows/reusing-workflows
+type ReusableWorkflow struct {
+	// OwnerName and RepoName are empty for a workflow of the calling repository, at the same commit
+	OwnerName string
+	RepoName  string
+	Path      string
+	Ref       string
+}
+
+// IsLocal returns whether the workflow is in the calling repository
+func (w *ReusableWorkflow) IsLocal() bool {
+	return w.OwnerName == ""
+}
+
+func (w *ReusableWorkflow) String() string {
+	if w.IsLocal() {
+		return "./" + w.Path
+	}
+	return fmt.Sprintf("%s/%s/%s@%s", w.OwnerName, w.RepoName, w.Path, w.Ref)
+}
+
+// ParseReusableWorkflow parses the `uses` of a job, which is either `./.gitea/workflows/x.yml`
+// for a workflow of the same repository or `owner/repo/.gitea/workflows/x.yml@ref` for a workflow of another repository of the instance.
+func ParseReusableWorkflow(uses string) (*ReusableWorkflow, error) {
+	if path, ok := strings.CutPrefix(uses, "./"); ok {
+		if !IsWorkflow(path) {
+			return nil, fmt.Errorf("%q is not a workflow file", uses)
+		}
+		return &ReusableWorkflow{Path: path}, nil
+	}
+
+	repoPath, ref, _ := strings.Cut(uses, "@")
+	parts := strings.SplitN(repoPath, "/", 3)
+	if ref == "" || len(parts) != 3 || parts[0] == "" || parts[1] == "" || !IsWorkflow(parts[2]) {
+		return nil, fmt.Errorf("invalid reusable workflow %q, it should be like owner/repo/.gitea/workflows/workflow.yml@ref", uses)
+	}
+	return &ReusableWorkflow{OwnerName: parts[0], RepoName: parts[1], Path: parts[2], Ref: ref}, nil
+}
+
+// ReusableWorkflowFetcher returns the content of a reusable workflow
+type ReusableWorkflowFetcher func(workflow *ReusableWorkflow) ([]byte, error)
+
+// ExpandReusableWorkflows replaces the jobs calling reusable workflows with the jobs of the called workflows.
+// A called job gets the id `<caller>.<job>` and the needs of the caller, the inputs and the secrets passed by the caller are
+// substituted in its expressions. The caller is kept as a job needing all the called jobs, its outputs are evaluated from them,
+// and it's never picked by a runner.
+// The content is returned as it is if no job calls a reusable workflow.
+func ExpandReusableWorkflows(content []byte, fetch ReusableWorkflowFetcher) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+	expanded, err := expandReusableWorkflows(&doc, nil, fetch, 0)
+	if err != nil || !expanded {
+		return content, err
+	}
+	return yaml.Marshal(&doc)
+}
+
+func expandReusableWorkflows(doc *yaml.Node, parent *ReusableWorkflow, fetch ReusableWorkflowFetcher, depth int) (bool, error) {
+	jobs := mappingValue(documentRoot(doc), "jobs")
+	if jobs == nil || jobs.Kind != yaml.MappingNode {
+		return false, nil
+	}
+
+	expanded := false
+	content := make([]*yaml.Node, 0, len(jobs.Content))
+	for i := 0; i+1 < len(jobs.Content); i += 2 {
+		id, job := jobs.Content[i], jobs.Content[i+1]
+		uses := mappingValue(job, "uses")
+		if uses == nil {
+			content = append(content, id, job)
+			continue
+		}
+
+		workflow, err := ParseReusableWorkflow(uses.Value)
+		if err != nil {
+			return false, fmt.Errorf("job %s: %w", id.Value, err)
+		}
+		if depth >= maxReusableWorkflowDepth {
+			return false, fmt.Errorf("job %s: reusable workflows can't be nested more than %d levels", id.Value, maxReusableWorkflowDepth)
+		}
+		if workflow.IsLocal() && parent != nil && !parent.IsLocal() {
+			// a local workflow called by a workflow of another repository is in that repository
+			workflow.OwnerName, workflow.RepoName, workflow.Ref = parent.OwnerName, parent.RepoName, parent.Ref
+		}
+
+		calledContent, err := fetch(workflow)
+		if err != nil {
+			return false, fmt.Errorf("job %s: fetch %s: %w", id.Value, workflow, err)
+		}
+		var called yaml.Node
+		if err := yaml.Unmarshal(calledContent, &called); err != nil {
+			return false, fmt.Errorf("job %s: parse %s: %w", id.Value, workflow, err)
+		}
+		if _, err := expandReusableWorkflows(&called, workflow, fetch, depth+1); err != nil {
+			return false, fmt.Errorf("job %s: %w", id.Value, err)
+		}
+
+		calledJobs, err := callReusableWorkflow(id.Value, job, documentRoot(&called))
+		if err != nil {
+			return false, fmt.Errorf("job %s: call %s: %w", id.Value, workflow, err)
+		}
+		content = append(content, calledJobs...)
+		expanded = true
+	}
+	jobs.Content = content
+	return expanded, nil
+}
+
+// callReusableWorkflow returns the id and job nodes of the jobs of the called workflow, followed by the calling job
+func callReusableWorkflow(callerID string, caller, called *yaml.Node) ([]*yaml.Node, error) {
+	call, ok := workflowCallTrigger(mappingValue(called, "on"))
+	if !ok {
+		return nil, fmt.Errorf("the workflow isn't triggered by workflow_call")
+	}
+	if mappingValue(caller, "strategy") != nil {
+		return nil, fmt.Errorf("strategy isn't supported by jobs calling reusable workflows")
+	}
+
+	replacements := map[string]map[string]string{"inputs": {}}
+
+	with := map[string]*yaml.Node{}
+	if node := mappingValue(caller, "with"); node != nil && node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			with[node.Content[i].Value] = node.Content[i+1]
+		}
+	}
+	if inputs := mappingValue(call, "inputs"); inputs != nil && inputs.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(inputs.Content); i += 2 {
+			name, def := inputs.Content[i].Value, inputs.Content[i+1]
+			inputType := "string"
+			if node := mappingValue(def, "type"); node != nil {
+				inputType = node.Value
+			}
+			value, ok := with[name]
+			delete(with, name)
+			if !ok {
+				value = mappingValue(def, "default")
+			}
+			if value == nil {
+				if node := mappingValue(def, "required"); node != nil && node.Value == "true" {
+					return nil, fmt.Errorf("input %q is required", name)
+				}
+				value = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}
+			}
+			expr, err := inputToExpression(value, inputType)
+			if err != nil {
+				return nil, fmt.Errorf("input %q: %w", name, err)
+			}
+			replacements["inputs"][name] = expr
+		}
+	}
+	for name := range with {
+		return nil, fmt.Errorf("unexpected input %q", name)
+	}
+
+	if secrets := mappingValue(caller, "secrets"); secrets == nil || secrets.Value != "inherit" {
+		// without `secrets: inherit`, the called workflow only gets the secrets passed explicitly
+		replacements["secrets"] = map[string]string{}
+		if secrets != nil && secrets.Kind == yaml.MappingNode {
+			for i := 0; i+1 < len(secrets.Content); i += 2 {
+				expr, err := inputToExpression(secrets.Content[i+1], "string")
+				if err != nil {
+					return nil, fmt.Errorf("secret %q: %w", secrets.Content[i].Value, err)
+				}
+				replacements["secrets"][secrets.Content[i].Value] = expr
+			}
+		}
+	}
+
+	callerName := callerID
+	if node := mappingValue(caller, "name"); node != nil && node.Value != "" {
+		callerName = node.Value
+	}
+	callerNeeds := stringsOfNode(mappingValue(caller, "needs"))
+	callerIf := ""
+	if node := mappingValue(caller, "if"); node != nil {
+		callerIf = unwrapExpression(node.Value)
+	}
+
+	jobs := mappingValue(called, "jobs")
+	if jobs == nil || jobs.Kind != yaml.MappingNode || len(jobs.Content) == 0 {
+		return nil, fmt.Errorf("the workflow has no jobs")
+	}
+	ret := make([]*yaml.Node, 0, len(jobs.Content)+2)
+	ids := make([]string, 0, len(jobs.Content)/2)
+	for i := 0; i+1 < len(jobs.Content); i += 2 {
+		id, job := callerID+"."+jobs.Content[i].Value, jobs.Content[i+1]
+		if job.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("invalid job %s", jobs.Content[i].Value)
+		}
+		rewriteExpressions(job, replacements)
+
+		name := jobs.Content[i].Value
+		if node := mappingValue(job, "name"); node != nil && node.Value != "" {
+			name = node.Value
+		}
+		setMappingValue(job, "name", scalarNode(callerName+" / "+name))
+
+		needs := stringsOfNode(mappingValue(job, "needs"))
+		for j := range needs {
+			needs[j] = callerID + "." + needs[j]
+		}
+		needs = append(needs, callerNeeds...)
+		if len(needs) > 0 {
+			setMappingValue(job, "needs", sequenceNode(needs))
+		}
+
+		if callerIf != "" {
+			if node := mappingValue(job, "if"); node != nil && node.Value != "" {
+				setMappingValue(job, "if", scalarNode(fmt.Sprintf("(%s) && (%s)", callerIf, unwrapExpression(node.Value))))
+			} else {
+				setMappingValue(job, "if", scalarNode(callerIf))
+			}
+		}
+
+		ret = append(ret, scalarNode(id), job)
+		ids = append(ids, id)
+	}
+
+	placeholder := &yaml.Node{Kind: yaml.MappingNode}
+	setMappingValue(placeholder, "name", scalarNode(callerName))
+	setMappingValue(placeholder, "needs", sequenceNode(ids))
+	setMappingValue(placeholder, "uses", scalarNode(mappingValue(caller, "uses").Value))
+	if outputs := mappingValue(call, "outputs"); outputs != nil && outputs.Kind == yaml.MappingNode {
+		values := &yaml.Node{Kind: yaml.MappingNode}
+		for i := 0; i+1 < len(outputs.Content); i += 2 {
+			value := mappingValue(outputs.Content[i+1], "value")
+			if value == nil {
+				continue
+			}
+			rewriteExpressions(value, replacements)
+			setMappingValue(values, outputs.Content[i].Value, scalarNode(value.Value))
+		}
+		setMappingValue(placeholder, "outputs", values)
+	}
+	ret = append(ret, scalarNode(callerID), placeholder)
+
+	return ret, nil
+}
+
+// workflowCallTrigger returns the configuration of the workflow_call trigger of an `on` node
+func workflowCallTrigger(on *yaml.Node) (*yaml.Node, bool) {
+	if on == nil {
+		return nil, false
+	}
+	switch on.Kind {
+	case yaml.ScalarNode:
+		return nil, on.Value == GithubEventWorkflowCall
+	case yaml.SequenceNode:
+		for _, node := range on.Content {
+			if node.Value == GithubEventWorkflowCall {
+				return nil, true
+			}
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(on.Content); i += 2 {
+			if on.Content[i].Value == GithubEventWorkflowCall {
+				return on.Content[i+1], true
+			}
+		}
+	}
+	return nil, false
+}
+
+var (
+	expressionPattern        = regexp.MustCompile(`(?s)\$\{\{(.*?)\}\}`)
+	contextPropertyPattern   = regexp.MustCompile(`(^|[^\w.-])(inputs|secrets)\.([\w-]+)`)
+	formatPlaceholderEscaper = strings.NewReplacer("{", "{{", "}", "}}")
+)
+
+// rewriteExpressions replaces the properties of the contexts by the given expressions in all the expressions of the node
+func rewriteExpressions(node *yaml.Node, replacements map[string]map[string]string) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			value := node.Content[i+1]
+			if node.Content[i].Value == "if" && value.Kind == yaml.ScalarNode && !strings.Contains(value.Value, "${{") {
+				// conditions are expressions even without ${{ }}
+				value.Value = rewriteExpression(value.Value, replacements)
+				continue
+			}
+			rewriteExpressions(value, replacements)
+		}
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			rewriteExpressions(item, replacements)
+		}
+	case yaml.ScalarNode:
+		node.Value = expressionPattern.ReplaceAllStringFunc(node.Value, func(s string) string {
+			return "${{" + rewriteExpression(s[3:len(s)-2], replacements) + "}}"
+		})
+	}
+}
+
+func rewriteExpression(expr string, replacements map[string]map[string]string) string {
+	var sb strings.Builder
+	last := 0
+	for _, m := range contextPropertyPattern.FindAllStringSubmatchIndex(expr, -1) {
+		context, property := expr[m[4]:m[5]], expr[m[6]:m[7]]
+		properties, ok := replacements[context]
+		if !ok {
+			continue
+		}
+		replacement, ok := properties[property]
+		if !ok {
+			if context != "secrets" || strings.EqualFold(property, "GITHUB_TOKEN") || strings.EqualFold(property, "GITEA_TOKEN") {
+				continue
+			}
+			// the secrets of the caller which haven't been passed are empty, the token is always available
+			replacement = "''"
+		}
+		sb.WriteString(expr[last:m[4]])
+		sb.WriteString("(" + replacement + ")")
+		last = m[1]
+	}
+	sb.WriteString(expr[last:])
+	return sb.String()
+}
+
+// inputToExpression converts the value of an input passed by `with` to an expression,
+// which is evaluated in the context of the called jobs
+func inputToExpression(node *yaml.Node, inputType string) (string, error) {
+	if node.Kind != yaml.ScalarNode {
+		return "", fmt.Errorf("only scalar values are supported")
+	}
+	switch {
+	case node.Tag == "!!null":
+		switch inputType {
+		case "boolean":
+			return "false", nil
+		case "number":
+			return "0", nil
+		}
+		return "''", nil
+	case node.Tag == "!!bool" || inputType == "boolean" && (node.Value == "true" || node.Value == "false"):
+		return strings.ToLower(node.Value), nil
+	case node.Tag == "!!int" || node.Tag == "!!float":
+		return node.Value, nil
+	case inputType == "number":
+		if _, err := strconv.ParseFloat(node.Value, 64); err == nil {
+			return node.Value, nil
+		}
+	}
+
+	matches := expressionPattern.FindAllStringSubmatchIndex(node.Value, -1)
+	if len(matches) == 0 {
+		return quoteExpressionString(node.Value), nil
+	}
+	if len(matches) == 1 && matches[0][0] == 0 && matches[0][1] == len(node.Value) {
+		return strings.TrimSpace(node.Value[matches[0][2]:matches[0][3]]), nil
+	}
+	// interpolate the expressions in the string
+	var format strings.Builder
+	args := make([]string, 0, len(matches))
+	last := 0
+	for i, m := range matches {
+		format.WriteString(formatPlaceholderEscaper.Replace(node.Value[last:m[0]]))
+		format.WriteString("{" + strconv.Itoa(i) + "}")
+		args = append(args, strings.TrimSpace(node.Value[m[2]:m[3]]))
+		last = m[1]
+	}
+	format.WriteString(formatPlaceholderEscaper.Replace(node.Value[last:]))
+	return fmt.Sprintf("format(%s, %s)", quoteExpressionString(format.String()), strings.Join(args, ", ")), nil
+}
+
+func quoteExpressionString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// unwrapExpression returns the expression of a condition, which may be wrapped in ${{ }}
+func unwrapExpression(s string) string {
+	s = strings.TrimSpace(s)
+	if m := expressionPattern.FindStringSubmatchIndex(s); m != nil && m[0] == 0 && m[1] == len(s) {
+		return strings.TrimSpace(s[m[2]:m[3]])
+	}
+	return s
+}
+
+func documentRoot(doc *yaml.Node) *yaml.Node {
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) == 1 {
+		return doc.Content[0]
+	}
+	return doc
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func setMappingValue(node *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content[i+1] = value
+			return
+		}
+	}
+	node.Content = append(node.Content, scalarNode(key), value)
+}
+
+func scalarNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+func sequenceNode(values []string) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.SequenceNode}
+	for _, v := range values {
+		node.Content = append(node.Content, scalarNode(v))
+	}
+	return node
+}
+
+// stringsOfNode returns the values of a scalar or a sequence node, like `needs`
+func stringsOfNode(node *yaml.Node) []string {
+	if node == nil {
+		return nil
+	}
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if node.Value != "" {
+			return []string{node.Value}
+		}
+	case yaml.SequenceNode:
+		values := make([]string, 0, len(node.Content))
+		for _, item := range node.Content {
+			values = append(values, item.Value)
+		}
+		return values
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/nektos/act/pkg/jobparser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseReusableWorkflow(t *testing.T) {
+	for _, c := range []struct {
+		uses string
+		want *ReusableWorkflow
+	}{
+		{
+			uses: "./.gitea/workflows/build.yml",
+			want: &ReusableWorkflow{Path: ".gitea/workflows/build.yml"},
+		},
+		{
+			uses: "org/shared/.github/workflows/deploy.yaml@v1",
+			want: &ReusableWorkflow{OwnerName: "org", RepoName: "shared", Path: ".github/workflows/deploy.yaml", Ref: "v1"},
+		},
+		{uses: "./build.yml"},
+		{uses: "org/shared/.github/workflows/deploy.yaml"},
+		{uses: "org/.github/workflows/deploy.yaml@v1"},
+		{uses: "actions/checkout@v4"},
+	} {
+		workflow, err := ParseReusableWorkflow(c.uses)
+		if c.want == nil {
+			assert.Error(t, err, c.uses)
+			continue
+		}
+		assert.NoError(t, err, c.uses)
+		assert.EqualValues(t, c.want, workflow, c.uses)
+		assert.Equal(t, c.uses, workflow.String())
+	}
+}
+
+func TestExpandReusableWorkflows(t *testing.T) {
+	workflows := map[string]string{
+		"./.gitea/workflows/build.yml": `
+on:
+  workflow_call:
+    inputs:
+      target:
+        type: string
+        required: true
+      release:
+        type: boolean
+        default: false
+    secrets:
+      token:
+    outputs:
+      artifact:
+        value: ${{ jobs.package.outputs.name }}
+jobs:
+  compile:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make ${{ inputs.target }} RELEASE=${{ inputs.release }} TOKEN=${{ secrets.token }} OTHER=${{ secrets.other }} ${{ secrets.GITEA_TOKEN }}
+  package:
+    needs: compile
+    if: inputs.release
+    runs-on: ubuntu-latest
+    outputs:
+      name: ${{ steps.pack.outputs.name }}
+    steps:
+      - id: pack
+        run: echo "name=${{ inputs.target }}.tar.gz" >> $GITHUB_OUTPUT
+`,
+		"org/shared/.github/workflows/notify.yml@main": `
+on: workflow_call
+jobs:
+  notify:
+    uses: ./.github/workflows/send.yml
+    secrets: inherit
+`,
+		"org/shared/.github/workflows/send.yml@main": `
+on: [workflow_call]
+jobs:
+  send:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo ${{ secrets.webhook }}
+`,
+		"./.gitea/workflows/push.yml": `
+on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+`,
+	}
+	fetch := func(workflow *ReusableWorkflow) ([]byte, error) {
+		if content, ok := workflows[workflow.String()]; ok {
+			return []byte(content), nil
+		}
+		return nil, fmt.Errorf("%s not found", workflow)
+	}
+
+	content := []byte(`
+on: push
+jobs:
+  lint:
+    runs-on: ubuntu-latest
+  build:
+    name: Build
+    needs: lint
+    if: github.ref == 'refs/heads/main'
+    uses: ./.gitea/workflows/build.yml
+    with:
+      target: linux-${{ github.ref_name }}
+      release: true
+    secrets:
+      token: ${{ secrets.BUILD_TOKEN }}
+  notify:
+    needs: build
+    uses: org/shared/.github/workflows/notify.yml@main
+    secrets: inherit
+`)
+	expanded, err := ExpandReusableWorkflows(content, fetch)
+	assert.NoError(t, err)
+
+	jobs, err := jobparser.Parse(expanded)
+	assert.NoError(t, err)
+	got := map[string]*jobparser.Job{}
+	for _, swf := range jobs {
+		id, job := swf.Job()
+		got[id] = job
+	}
+	assert.Len(t, got, 7)
+
+	compile := got["build.compile"]
+	if assert.NotNil(t, compile) {
+		assert.Equal(t, "Build / compile", compile.Name)
+		assert.Equal(t, []string{"lint"}, compile.Needs())
+		assert.Equal(t, "github.ref == 'refs/heads/main'", compile.If.Value)
+		assert.Contains(t, compile.Steps[0].Run, "make ${{ (format('linux-{0}', github.ref_name)) }} RELEASE=${{ (true) }} TOKEN=${{ (secrets.BUILD_TOKEN) }} OTHER=${{ ('') }} ${{ secrets.GITEA_TOKEN }}")
+	}
+	pack := got["build.package"]
+	if assert.NotNil(t, pack) {
+		assert.Equal(t, []string{"build.compile", "lint"}, pack.Needs())
+		assert.Equal(t, "(github.ref == 'refs/heads/main') && ((true))", pack.If.Value)
+	}
+	build := got["build"]
+	if assert.NotNil(t, build) {
+		assert.Equal(t, "./.gitea/workflows/build.yml", build.Uses)
+		assert.Equal(t, []string{"build.compile", "build.package"}, build.Needs())
+		assert.Equal(t, map[string]string{"artifact": "${{ jobs.package.outputs.name }}"}, build.Outputs)
+	}
+	send := got["notify.notify.send"]
+	if assert.NotNil(t, send) {
+		assert.Equal(t, "notify / notify / send", send.Name)
+		assert.Equal(t, []string{"build"}, send.Needs())
+		assert.Equal(t, "echo ${{ secrets.webhook }}", send.Steps[0].Run)
+	}
+	assert.Equal(t, []string{"notify.notify.send", "build"}, got["notify.notify"].Needs())
+	assert.Equal(t, []string{"notify.notify.send", "notify.notify"}, got["notify"].Needs())
+
+	// the content isn't changed without reusable workflows
+	content = []byte("on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n")
+	expanded, err = ExpandReusableWorkflows(content, fetch)
+	assert.NoError(t, err)
+	assert.Equal(t, content, expanded)
+
+	for _, invalid := range []string{
+		// missing required input
+		"on: push\njobs:\n  build:\n    uses: ./.gitea/workflows/build.yml\n",
+		// unexpected input
+		"on: push\njobs:\n  build:\n    uses: ./.gitea/workflows/build.yml\n    with:\n      target: linux\n      arch: amd64\n",
+		// not triggered by workflow_call
+		"on: push\njobs:\n  test:\n    uses: ./.gitea/workflows/push.yml\n",
+		// not found
+		"on: push\njobs:\n  test:\n    uses: ./.gitea/workflows/missing.yml\n",
+		// strategy
+		"on: push\njobs:\n  build:\n    strategy:\n      matrix:\n        target: [linux]\n    uses: ./.gitea/workflows/build.yml\n    with:\n      target: ${{ matrix.target }}\n",
+	} {
+		_, err := ExpandReusableWorkflows([]byte(invalid), fetch)
+		assert.Error(t, err, invalid)
+	}
+}
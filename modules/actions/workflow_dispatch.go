@@ -0,0 +1,142 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"bytes"
+	"slices"
+	"strconv"
+
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/nektos/act/pkg/model"
+	"gopkg.in/yaml.v3"
+)
+
+// Types of the workflow_dispatch inputs
+const (
+	WorkflowDispatchInputString      = "string"
+	WorkflowDispatchInputChoice      = "choice"
+	WorkflowDispatchInputBoolean     = "boolean"
+	WorkflowDispatchInputNumber      = "number"
+	WorkflowDispatchInputEnvironment = "environment"
+)
+
+// WorkflowDispatchInput is an input of a workflow triggered manually
+type WorkflowDispatchInput struct {
+	Name        string
+	Description string
+	Required    bool
+	Default     string
+	Type        string
+	Options     []string
+}
+
+// WorkflowDispatch is the workflow_dispatch trigger of a workflow, its inputs are in the order of the workflow
+type WorkflowDispatch struct {
+	Inputs []*WorkflowDispatchInput
+}
+
+// GetWorkflowDispatch returns the workflow_dispatch trigger of the workflow, nil if it can't be triggered manually
+func GetWorkflowDispatch(content []byte) (*WorkflowDispatch, error) {
+	workflow, err := model.ReadWorkflow(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+
+	rawOn := &workflow.RawOn
+	switch rawOn.Kind {
+	case yaml.ScalarNode:
+		if rawOn.Value == GithubEventWorkflowDispatch {
+			return &WorkflowDispatch{}, nil
+		}
+	case yaml.SequenceNode:
+		for _, node := range rawOn.Content {
+			if node.Value == GithubEventWorkflowDispatch {
+				return &WorkflowDispatch{}, nil
+			}
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(rawOn.Content); i += 2 {
+			if rawOn.Content[i].Value == GithubEventWorkflowDispatch {
+				return parseWorkflowDispatch(rawOn.Content[i+1])
+			}
+		}
+	}
+	return nil, nil
+}
+
+func parseWorkflowDispatch(node *yaml.Node) (*WorkflowDispatch, error) {
+	dispatch := &WorkflowDispatch{}
+	if node.Kind != yaml.MappingNode {
+		return dispatch, nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value != "inputs" || node.Content[i+1].Kind != yaml.MappingNode {
+			continue
+		}
+		inputs := node.Content[i+1].Content
+		for j := 0; j+1 < len(inputs); j += 2 {
+			var input model.WorkflowDispatchInput
+			if err := inputs[j+1].Decode(&input); err != nil {
+				return nil, err
+			}
+			if input.Type == "" {
+				input.Type = WorkflowDispatchInputString
+			}
+			dispatch.Inputs = append(dispatch.Inputs, &WorkflowDispatchInput{
+				Name:        inputs[j].Value,
+				Description: input.Description,
+				Required:    input.Required,
+				Default:     input.Default,
+				Type:        input.Type,
+				Options:     input.Options,
+			})
+		}
+	}
+	return dispatch, nil
+}
+
+// ResolveInputs checks the given values against the types of the inputs and returns the values of all the inputs,
+// the missing values are set to the defaults
+func (d *WorkflowDispatch) ResolveInputs(values map[string]string) (map[string]string, error) {
+	resolved := make(map[string]string, len(d.Inputs))
+	for _, input := range d.Inputs {
+		value, ok := values[input.Name]
+		if !ok || value == "" {
+			value = input.Default
+		}
+
+		switch input.Type {
+		case WorkflowDispatchInputBoolean:
+			if value == "" {
+				value = "false"
+			}
+			if _, err := strconv.ParseBool(value); err != nil {
+				return nil, util.NewInvalidArgumentErrorf("input %q must be a boolean", input.Name)
+			}
+		case WorkflowDispatchInputChoice:
+			if value != "" && !slices.Contains(input.Options, value) {
+				return nil, util.NewInvalidArgumentErrorf("input %q must be one of %v", input.Name, input.Options)
+			}
+		case WorkflowDispatchInputNumber:
+			if value != "" {
+				if _, err := strconv.ParseFloat(value, 64); err != nil {
+					return nil, util.NewInvalidArgumentErrorf("input %q must be a number", input.Name)
+				}
+			}
+		}
+		if input.Required && value == "" {
+			return nil, util.NewInvalidArgumentErrorf("input %q is required", input.Name)
+		}
+		resolved[input.Name] = value
+	}
+
+	for name := range values {
+		if _, ok := resolved[name]; !ok {
+			return nil, util.NewInvalidArgumentErrorf("unexpected input %q", name)
+		}
+	}
+	return resolved, nil
+}
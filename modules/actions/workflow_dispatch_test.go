@@ -0,0 +1,98 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const workflowDispatchContent = `
+name: evaluate
+on:
+  push:
+    branches: [main]
+  workflow_dispatch:
+    inputs:
+      model:
+        description: Model to evaluate
+        required: true
+      precision:
+        type: choice
+        options: [fp16, fp32]
+        default: fp16
+      dry_run:
+        type: boolean
+        default: "true"
+      epochs:
+        type: number
+jobs:
+  evaluate:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo ${{ inputs.model }}
+`
+
+func TestGetWorkflowDispatch(t *testing.T) {
+	dispatch, err := GetWorkflowDispatch([]byte(workflowDispatchContent))
+	assert.NoError(t, err)
+	if assert.NotNil(t, dispatch) && assert.Len(t, dispatch.Inputs, 4) {
+		assert.EqualValues(t, &WorkflowDispatchInput{Name: "model", Description: "Model to evaluate", Required: true, Type: "string"}, dispatch.Inputs[0])
+		assert.EqualValues(t, &WorkflowDispatchInput{Name: "precision", Default: "fp16", Type: "choice", Options: []string{"fp16", "fp32"}}, dispatch.Inputs[1])
+		assert.EqualValues(t, "dry_run", dispatch.Inputs[2].Name)
+		assert.EqualValues(t, "boolean", dispatch.Inputs[2].Type)
+		assert.EqualValues(t, "epochs", dispatch.Inputs[3].Name)
+	}
+
+	for _, content := range []string{
+		"on: workflow_dispatch\njobs: {}",
+		"on: [push, workflow_dispatch]\njobs: {}",
+		"on:\n  workflow_dispatch:\njobs: {}",
+	} {
+		dispatch, err = GetWorkflowDispatch([]byte(content))
+		assert.NoError(t, err)
+		if assert.NotNil(t, dispatch, content) {
+			assert.Empty(t, dispatch.Inputs)
+		}
+	}
+
+	dispatch, err = GetWorkflowDispatch([]byte("on: push\njobs: {}"))
+	assert.NoError(t, err)
+	assert.Nil(t, dispatch)
+
+	// the other triggers of the workflow are still detected
+	events, err := GetEventsFromContent([]byte(workflowDispatchContent))
+	assert.NoError(t, err)
+	if assert.Len(t, events, 2) {
+		assert.EqualValues(t, "push", events[0].Name)
+		assert.EqualValues(t, "workflow_dispatch", events[1].Name)
+	}
+}
+
+func TestWorkflowDispatchResolveInputs(t *testing.T) {
+	dispatch, err := GetWorkflowDispatch([]byte(workflowDispatchContent))
+	assert.NoError(t, err)
+
+	inputs, err := dispatch.ResolveInputs(map[string]string{"model": "llama", "epochs": "3"})
+	assert.NoError(t, err)
+	assert.EqualValues(t, map[string]string{"model": "llama", "precision": "fp16", "dry_run": "true", "epochs": "3"}, inputs)
+
+	inputs, err = dispatch.ResolveInputs(map[string]string{"model": "llama", "precision": "fp32", "dry_run": "false"})
+	assert.NoError(t, err)
+	assert.EqualValues(t, map[string]string{"model": "llama", "precision": "fp32", "dry_run": "false", "epochs": ""}, inputs)
+
+	for _, values := range []map[string]string{
+		{},
+		{"model": "llama", "precision": "int8"},
+		{"model": "llama", "dry_run": "maybe"},
+		{"model": "llama", "epochs": "many"},
+		{"model": "llama", "unknown": "value"},
+	} {
+		_, err = dispatch.ResolveInputs(values)
+		assert.ErrorIs(t, err, util.ErrInvalidArgument, values)
+	}
+}
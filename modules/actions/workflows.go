@@ -6,6 +6,7 @@ package actions
 import (
 	"bytes"
 	"io"
+	"slices"
 	"strings"
 
 	"code.gitea.io/gitea/modules/git"
@@ -87,7 +88,7 @@ func GetEventsFromContent(content []byte) ([]*jobparser.Event, error) {
 	if err != nil {
 		return nil, err
 	}
-	events, err := jobparser.ParseRawOn(&workflow.RawOn)
+	events, err := jobparser.ParseRawOn(withoutEventInputs(&workflow.RawOn))
 	if err != nil {
 		return nil, err
 	}
@@ -95,6 +96,26 @@ func GetEventsFromContent(content []byte) ([]*jobparser.Event, error) {
 	return events, nil
 }
 
+// withoutEventInputs returns the on node without the inputs of its workflow_dispatch and workflow_call events,
+// which jobparser can't parse since they aren't activity types, see GetWorkflowDispatch and ExpandReusableWorkflows
+func withoutEventInputs(rawOn *yaml.Node) *yaml.Node {
+	if rawOn.Kind != yaml.MappingNode {
+		return rawOn
+	}
+	node := rawOn
+	for i := 0; i+1 < len(rawOn.Content); i += 2 {
+		if v := rawOn.Content[i].Value; v == GithubEventWorkflowDispatch || v == GithubEventWorkflowCall {
+			if node == rawOn {
+				clone := *rawOn
+				clone.Content = slices.Clone(rawOn.Content)
+				node = &clone
+			}
+			node.Content[i+1] = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}
+		}
+	}
+	return node
+}
+
 func DetectWorkflows(
 	gitRepo *git.Repository,
 	commit *git.Commit,
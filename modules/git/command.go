@@ -20,6 +20,7 @@ import (
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/process"
 	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/utils"
 )
 
 // TrustedCmdArgs returns the trusted arguments for git command.
@@ -311,6 +312,9 @@ func (c *Command) Run(opts *RunOpts) error {
 	}
 	defer finished()
 
+	ctx, span := c.startSpan(ctx, opts)
+	defer span.End()
+
 	startTime := time.Now()
 
 	cmd := exec.CommandContext(ctx, c.prog, c.args...)
@@ -322,12 +326,14 @@ func (c *Command) Run(opts *RunOpts) error {
 
 	process.SetSysProcAttribute(cmd)
 	cmd.Env = append(cmd.Env, CommonGitCmdEnvs()...)
+	// the hooks run by git continue the trace of the command
+	cmd.Env = append(cmd.Env, utils.TraceEnv(ctx)...)
 	cmd.Dir = opts.Dir
 	cmd.Stdout = opts.Stdout
 	cmd.Stderr = opts.Stderr
 	cmd.Stdin = opts.Stdin
 	if err := cmd.Start(); err != nil {
-		return err
+		return endSpan(span, err)
 	}
 
 	if opts.PipelineFunc != nil {
@@ -335,21 +341,23 @@ func (c *Command) Run(opts *RunOpts) error {
 		if err != nil {
 			cancel()
 			_ = cmd.Wait()
-			return err
+			c.recordDuration(ctx, startTime, err)
+			return endSpan(span, err)
 		}
 	}
 
 	err := cmd.Wait()
+	c.recordDuration(ctx, startTime, err)
 	elapsed := time.Since(startTime)
 	if elapsed > time.Second {
 		log.Debug("slow git.Command.Run: %s (%s)", c, elapsed)
 	}
 
 	if err != nil && ctx.Err() != context.DeadlineExceeded {
-		return err
+		return endSpan(span, err)
 	}
 
-	return ctx.Err()
+	return endSpan(span, ctx.Err())
 }
 
 type RunStdError interface {
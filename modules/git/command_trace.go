@@ -0,0 +1,63 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package git
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	tracerName = "gitea_git_tracer"
+	meterName  = "gitea_git_meter"
+)
+
+var commandDuration, _ = otel.Meter(meterName).Float64Histogram("gitea.git.command.duration",
+	metric.WithDescription("Duration of the git commands"),
+	metric.WithUnit("s"),
+)
+
+// subCommand returns the git sub command run by the command, e.g. "rev-list", ignoring the global arguments
+func (c *Command) subCommand() string {
+	if len(c.args) > c.globalArgsLength {
+		return c.args[c.globalArgsLength]
+	}
+	return ""
+}
+
+// startSpan starts the span of a run of the command, the arguments are sanitized as they may contain credentials
+func (c *Command) startSpan(ctx context.Context, opts *RunOpts) (context.Context, trace.Span) {
+	sub := c.subCommand()
+	return otel.Tracer(tracerName).Start(ctx, "git "+sub,
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(
+			attribute.String("git.command", sub),
+			attribute.String("git.args", c.toString(true)),
+			attribute.String("git.dir", opts.Dir),
+		),
+	)
+}
+
+// endSpan records the error of the run on its span and returns it
+func endSpan(span trace.Span, err error) error {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
+
+// recordDuration records the duration of a run of the command by sub command and outcome
+func (c *Command) recordDuration(ctx context.Context, start time.Time, err error) {
+	commandDuration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(
+		attribute.String("git.command", c.subCommand()),
+		attribute.Bool("error", err != nil),
+	))
+}
@@ -0,0 +1,38 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package git
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestCommandRunSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	oldProvider := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(oldProvider)
+
+	dir := t.TempDir()
+	assert.NoError(t, NewCommand(context.Background(), "version").Run(&RunOpts{Dir: dir}))
+	assert.Error(t, NewCommand(context.Background(), "rev-parse", "HEAD").Run(&RunOpts{Dir: dir}))
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 2) {
+		assert.Equal(t, "git version", spans[0].Name())
+		assert.Contains(t, spans[0].Attributes(), attribute.String("git.command", "version"))
+		assert.Contains(t, spans[0].Attributes(), attribute.String("git.dir", dir))
+		assert.Equal(t, codes.Unset, spans[0].Status().Code)
+
+		assert.Equal(t, "git rev-parse", spans[1].Name())
+		assert.Equal(t, codes.Error, spans[1].Status().Code)
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package git
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+)
+
+const (
+	// pktLineHeaderLength is the length of the hex encoded length prefixing every pkt-line
+	pktLineHeaderLength = 4
+	// pktLinePrefixLength is the number of bytes of the payloads of the pkt-lines which are checked for a pack:
+	// the "packfile" section of the protocol version 2 or the pack signature in the side-band 1
+	pktLinePrefixLength = len("packfile\n")
+)
+
+var (
+	packSignature      = []byte("PACK")
+	sideBandPackPrefix = []byte("\x01PACK")
+	packfileSection    = []byte("packfile\n")
+)
+
+// PackDetector writes the output of git upload-pack to the wrapped writer and detects whether it contains a pack, so
+// the fetches and the clones can be told from the listings of the refs like ls-remote, which don't send any pack.
+// It follows the pkt-lines of the output until the pack is found or the output can't be parsed.
+type PackDetector struct {
+	w io.Writer
+
+	found  bool
+	failed bool
+	// buf holds the header or the beginning of the payload of the current pkt-line
+	buf []byte
+	// remaining is the number of bytes of the payload of the current pkt-line which haven't been written yet,
+	// -1 while its header is being read
+	remaining int
+	// prefix is the number of bytes of the beginning of the payload to check
+	prefix int
+}
+
+// NewPackDetector returns a PackDetector writing to w
+func NewPackDetector(w io.Writer) *PackDetector {
+	return &PackDetector{w: w, remaining: -1}
+}
+
+// Write writes p to the wrapped writer and looks for the pack in it
+func (d *PackDetector) Write(p []byte) (int, error) {
+	d.detect(p)
+	return d.w.Write(p)
+}
+
+// PackSent returns whether a pack has been written
+func (d *PackDetector) PackSent() bool {
+	return d.found
+}
+
+func (d *PackDetector) detect(p []byte) {
+	for len(p) > 0 && !d.found && !d.failed {
+		if d.remaining < 0 {
+			n := min(pktLineHeaderLength-len(d.buf), len(p))
+			d.buf, p = append(d.buf, p[:n]...), p[n:]
+			if len(d.buf) < pktLineHeaderLength {
+				return
+			}
+			length, err := strconv.ParseUint(string(d.buf), 16, 16)
+			switch {
+			case err != nil:
+				// the pack is sent without side-band after the acknowledgements
+				d.found = bytes.Equal(d.buf, packSignature)
+				d.failed = !d.found
+			case length > pktLineHeaderLength:
+				d.remaining = int(length) - pktLineHeaderLength
+				d.prefix = min(d.remaining, pktLinePrefixLength)
+			}
+			// the flush, delimiter and response end packets have no payload
+			d.buf = d.buf[:0]
+			continue
+		}
+
+		n := min(d.remaining, len(p))
+		if k := min(d.prefix-len(d.buf), n); k > 0 {
+			d.buf = append(d.buf, p[:k]...)
+			if len(d.buf) == d.prefix {
+				d.found = bytes.Equal(d.buf, packfileSection) || bytes.HasPrefix(d.buf, sideBandPackPrefix)
+			}
+		}
+		d.remaining -= n
+		p = p[n:]
+		if d.remaining == 0 {
+			d.remaining = -1
+			d.buf = d.buf[:0]
+		}
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackDetector(t *testing.T) {
+	pktLine := func(s string) string {
+		return fmt.Sprintf("%04x", len(s)+4) + s
+	}
+
+	cases := []struct {
+		name   string
+		output string
+		sent   bool
+	}{
+		{
+			name:   "ls-refs",
+			output: pktLine("0000000000000000000000000000000000000000 HEAD\n") + pktLine("1111111111111111111111111111111111111111 refs/heads/main\n") + "0000",
+		},
+		{
+			name:   "negotiation",
+			output: pktLine("ACK 1111111111111111111111111111111111111111 common\n") + pktLine("NAK\n"),
+		},
+		{
+			name:   "side-band pack",
+			output: pktLine("NAK\n") + pktLine("\x02Enumerating objects: 3, done.\n") + pktLine("\x01PACK\x00\x00\x00\x02") + "0000",
+			sent:   true,
+		},
+		{
+			name:   "pack without side-band",
+			output: pktLine("NAK\n") + "PACK\x00\x00\x00\x02",
+			sent:   true,
+		},
+		{
+			name:   "protocol version 2 packfile section",
+			output: pktLine("acknowledgments\n") + pktLine("ready\n") + "0001" + pktLine("packfile\n") + pktLine("\x01PACK\x00\x00\x00\x02") + "0000",
+			sent:   true,
+		},
+		{
+			name:   "protocol version 2 acknowledgments only",
+			output: pktLine("acknowledgments\n") + pktLine("NAK\n") + "0000",
+		},
+		{
+			name:   "garbage",
+			output: "not a pkt-line",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			// the output is written byte by byte and at once
+			for _, size := range []int{1, len(c.output)} {
+				var out bytes.Buffer
+				d := NewPackDetector(&out)
+				for i := 0; i < len(c.output); i += size {
+					_, err := d.Write([]byte(c.output[i:min(i+size, len(c.output))]))
+					assert.NoError(t, err)
+				}
+				assert.Equal(t, c.sent, d.PackSent())
+				assert.Equal(t, c.output, out.String())
+			}
+		})
+	}
+}
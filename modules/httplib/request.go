@@ -100,7 +100,7 @@ func (r *Request) Param(key, value string) *Request {
 }
 
 // Body adds request raw body.
-// it supports string and []byte.
+// it supports string, []byte and io.Reader, the latter is streamed with an unknown length.
 func (r *Request) Body(data any) *Request {
 	switch t := data.(type) {
 	case string:
@@ -111,6 +111,9 @@ func (r *Request) Body(data any) *Request {
 		bf := bytes.NewBuffer(t)
 		r.req.Body = io.NopCloser(bf)
 		r.req.ContentLength = int64(len(t))
+	case io.Reader:
+		r.req.Body = io.NopCloser(t)
+		r.req.ContentLength = -1
 	}
 	return r
 }
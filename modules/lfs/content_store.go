@@ -11,6 +11,7 @@ import (
 	"io"
 	"os"
 
+	"code.gitea.io/gitea/modules/json"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/storage"
 
@@ -95,8 +96,10 @@ func (s *ContentStore) Exists(pointer Pointer) (bool, error) {
 func (s *ContentStore) PointersExists(pointers []Pointer) (map[string]bool, error) {
 	// Create a map to track the existence of pointers.
 	existenceMap := make(map[string]bool)
+	relativePaths := make(map[string]string)
 	for _, pointer := range pointers {
 		existenceMap[pointer.Oid] = false
+		relativePaths[pointer.RelativePath()] = pointer.Oid
 	}
 
 	// Iterate through all objects in the storage.
@@ -105,6 +108,8 @@ func (s *ContentStore) PointersExists(pointers []Pointer) (map[string]bool, erro
 		// Check if the object's SHA256 is one of the pointers.
 		if _, exists := existenceMap[p]; exists {
 			existenceMap[p] = true
+		} else if oid, exists := relativePaths[p]; exists {
+			existenceMap[oid] = true
 		}
 		return nil
 	}); err != nil {
@@ -129,40 +134,182 @@ func (s *ContentStore) Verify(pointer Pointer) (bool, error) {
 	return true, nil
 }
 
-// CommitAndVerify returns true if the object exists in the content store and size is correct.
+// CommitAndVerify assembles the multipart upload of the object and returns true if the object exists in the content store.
+// The content is hashed to check it matches the pointer, while it is assembled if the storage supports it or once it is
+// stored otherwise, the object is not kept and ErrSizeMismatch or ErrHashMismatch is returned if it doesn't match. The objects are content addressed and shared by the repositories, so the
+// upload is discarded without replacing the object if a valid copy is already stored.
 func (s *ContentStore) CommitAndVerify(pointer Pointer, commitParameter string) (bool, error) {
 	p := pointer.RelativePath()
-	err := s.ObjectStorage.CommitUpload(p, commitParameter)
+	valid, err := s.isValid(pointer)
+	if err != nil {
+		log.Error("lfs[multipart] Unable to verify the stored file: %s for LFS OID[%s] Error: %v", p, pointer.Oid, err)
+		return false, err
+	} else if valid {
+		log.Trace("lfs[multipart] LFS OID[%s] is already stored, the upload is discarded", pointer.Oid)
+		s.abortUpload(p, commitParameter)
+		return true, nil
+	}
+
+	if committer, ok := storage.As[storage.VerifyingCommitter](s.ObjectStorage); ok {
+		// the content is hashed while the object is assembled, so a mismatching object is never stored
+		hw := &hashingWriter{hash: sha256.New()}
+		err = committer.CommitUploadVerified(p, commitParameter, hw, func() error {
+			return hw.check(pointer)
+		})
+		if errors.Is(err, ErrSizeMismatch) || errors.Is(err, ErrHashMismatch) {
+			log.Warn("lfs[multipart] Assembled object does not match LFS OID[%s]: %v", pointer.Oid, err)
+			return false, err
+		} else if err == nil {
+			return true, nil
+		} else if !errors.Is(err, storage.ErrVerifiedCommitNotSupported) {
+			log.Error("lfs[multipart] Unable commit file: %s for LFS OID[%s] Error: %v", p, pointer.Oid, err)
+			return false, err
+		}
+	}
+
+	err = s.ObjectStorage.CommitUpload(p, commitParameter)
 	if err != nil {
 		log.Error("lfs[multipart] Unable commit file: %s for LFS OID[%s] Error: %v", p, pointer.Oid, err)
 		return false, err
 	}
 	fi, err := s.ObjectStorage.Stat(p)
-	if os.IsNotExist(err) || (err == nil && fi.Size() != pointer.Size) {
-		if err != nil {
-			log.Warn("lfs[multipart] Object does not exist ")
-		} else {
-			log.Warn("lfs[multipart] Object size does not match ")
-		}
+	if os.IsNotExist(err) {
+		log.Warn("lfs[multipart] Object does not exist ")
 		return false, nil
 	} else if err != nil {
 		log.Error("lfs[multipart] Unable stat file: %s for LFS OID[%s] Error: %v", p, pointer.Oid, err)
 		return false, err
 	}
+
+	err = ErrSizeMismatch
+	if fi.Size() == pointer.Size {
+		err = s.verifyContent(pointer)
+	}
+	if errors.Is(err, ErrSizeMismatch) || errors.Is(err, ErrHashMismatch) {
+		// there was no valid object before the commit, so only the content written by this upload is deleted
+		log.Warn("lfs[multipart] Assembled object does not match LFS OID[%s]: %v", pointer.Oid, err)
+		if errDel := s.Delete(p); errDel != nil {
+			log.Error("lfs[multipart] Cleaning the LFS OID[%s] failed: %v", pointer.Oid, errDel)
+		}
+		return false, err
+	} else if err != nil {
+		log.Error("lfs[multipart] Unable to verify file: %s for LFS OID[%s] Error: %v", p, pointer.Oid, err)
+		return false, err
+	}
 	return true, nil
 }
 
+// isValid returns true if the object is stored with the size and the hash of the pointer
+func (s *ContentStore) isValid(pointer Pointer) (bool, error) {
+	ok, err := s.Verify(pointer)
+	if err != nil || !ok {
+		return false, err
+	}
+	err = s.verifyContent(pointer)
+	if errors.Is(err, ErrSizeMismatch) || errors.Is(err, ErrHashMismatch) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// abortUpload removes the parts of the multipart upload described by the commit parameter, if the storage supports it
+func (s *ContentStore) abortUpload(p, commitParameter string) {
+	aborter, ok := storage.As[interface{ AbortUpload(path, uploadID string) error }](s.ObjectStorage)
+	if !ok {
+		return
+	}
+	var param storage.MultiPartCommitUpload
+	if err := json.Unmarshal([]byte(commitParameter), &param); err != nil || param.UploadID == "" {
+		log.Warn("lfs[multipart] Unable to decode the upload id of the discarded upload of %s: %v", p, err)
+		return
+	}
+	if err := aborter.AbortUpload(p, param.UploadID); err != nil {
+		log.Warn("lfs[multipart] Unable to remove the parts of the discarded upload %s of %s: %v", param.UploadID, p, err)
+	}
+}
+
+// verifyContent streams the stored object to check its size and hash match the pointer
+func (s *ContentStore) verifyContent(pointer Pointer) error {
+	f, err := s.Open(pointer.RelativePath())
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	rd := newHashingReader(pointer.Size, pointer.Oid, f)
+	if _, err := io.Copy(io.Discard, rd); err != nil {
+		return err
+	}
+	if rd.currentSize != pointer.Size {
+		return ErrSizeMismatch
+	}
+	return nil
+}
+
 func (s *ContentStore) GenerateMultipartParts(pointer Pointer) (parts []*structs.MultipartObjectPart, abort *structs.MultipartEndpoint, verify *structs.MultipartEndpoint, err error) {
 	p := pointer.RelativePath()
 	return s.ObjectStorage.GenerateMultipartParts(p, pointer.Size)
 }
 
+// UploadPart stores a part of a multipart upload served by Gitea and returns its etag
+func (s *ContentStore) UploadPart(pointer Pointer, uploadID string, index int, r io.Reader) (string, error) {
+	uploader, ok := storage.As[storage.MultipartUploader](s.ObjectStorage)
+	if !ok {
+		return "", storage.ErrMultipartNotSupported
+	}
+	size := storage.MultipartPartSize(pointer.Size, index)
+	if size < 0 {
+		return "", ErrSizeMismatch
+	}
+	return uploader.UploadPart(pointer.RelativePath(), uploadID, index, r, size)
+}
+
+// StatPart returns the information of a stored part of a multipart upload served by Gitea
+func (s *ContentStore) StatPart(pointer Pointer, uploadID string, index int) (os.FileInfo, error) {
+	uploader, ok := storage.As[storage.MultipartUploader](s.ObjectStorage)
+	if !ok {
+		return nil, storage.ErrMultipartNotSupported
+	}
+	return uploader.StatPart(pointer.RelativePath(), uploadID, index)
+}
+
+// AbortUpload removes the stored parts of a multipart upload
+func (s *ContentStore) AbortUpload(pointer Pointer, uploadID string) error {
+	uploader, ok := storage.As[storage.MultipartUploader](s.ObjectStorage)
+	if !ok {
+		return storage.ErrMultipartNotSupported
+	}
+	return uploader.AbortUpload(pointer.RelativePath(), uploadID)
+}
+
 // ReadMetaObject will read a git_model.LFSMetaObject and return a reader
 func ReadMetaObject(pointer Pointer) (io.ReadSeekCloser, error) {
 	contentStore := NewContentStore()
 	return contentStore.Get(pointer)
 }
 
+// hashingWriter computes the size and the hash of the content written to it
+type hashingWriter struct {
+	size int64
+	hash hash.Hash
+}
+
+func (w *hashingWriter) Write(b []byte) (int, error) {
+	w.size += int64(len(b))
+	return w.hash.Write(b)
+}
+
+// check returns ErrSizeMismatch or ErrHashMismatch if the written content doesn't match the pointer
+func (w *hashingWriter) check(pointer Pointer) error {
+	if w.size != pointer.Size {
+		return ErrSizeMismatch
+	}
+	if hex.EncodeToString(w.hash.Sum(nil)) != pointer.Oid {
+		return ErrHashMismatch
+	}
+	return nil
+}
+
 type hashingReader struct {
 	internal     io.Reader
 	currentSize  int64
@@ -0,0 +1,58 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package lfs
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContentStoreCommitAndVerify(t *testing.T) {
+	l, err := storage.NewStorage(setting.LocalStorageType, &setting.Storage{Path: t.TempDir()})
+	assert.NoError(t, err)
+	contentStore := &ContentStore{ObjectStorage: l}
+
+	upload := func(p Pointer, content string) string {
+		uploadID, err := storage.NewMultipartUploadID(p.RelativePath())
+		assert.NoError(t, err)
+		etag, err := contentStore.UploadPart(p, uploadID, 1, strings.NewReader(content))
+		assert.NoError(t, err)
+		param, _ := json.Marshal(storage.MultiPartCommitUpload{
+			UploadID: uploadID,
+			PartIDs:  []storage.MultipartPartID{{Index: 1, Etag: etag}},
+		})
+		return string(param)
+	}
+
+	p, _ := GeneratePointer(strings.NewReader("gitea"))
+	ok, err := contentStore.CommitAndVerify(p, upload(p, "gitea"))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	p, _ = GeneratePointer(strings.NewReader("tea"))
+	ok, err = contentStore.CommitAndVerify(p, upload(p, "ate"))
+	assert.ErrorIs(t, err, ErrHashMismatch)
+	assert.False(t, ok)
+	exists, err := contentStore.Exists(p)
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	// a bogus upload of a stored object doesn't replace nor delete it
+	p, _ = GeneratePointer(strings.NewReader("gitea"))
+	ok, err = contentStore.CommitAndVerify(p, upload(p, "aetig"))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	f, err := contentStore.Get(p)
+	assert.NoError(t, err)
+	content, _ := io.ReadAll(f)
+	f.Close()
+	assert.Equal(t, "gitea", string(content))
+}
@@ -11,6 +11,12 @@ import (
 const (
 	// MediaType contains the media type for LFS server requests
 	MediaType = "application/vnd.git-lfs+json"
+
+	// ObjectStatusPending is the status of the objects being restored from the archive storage,
+	// they can be downloaded once the restore is done
+	ObjectStatusPending = "pending"
+	// ObjectStatusMissing is the status of the objects referenced by a repository but not uploaded to the storage
+	ObjectStatusMissing = "missing"
 )
 
 // BatchRequest contains multiple requests processed in one batch operation.
@@ -47,6 +53,7 @@ type ObjectResponse struct {
 	Pointer
 	Actions map[string]*Link `json:"actions,omitempty"`
 	Error   *ObjectError     `json:"error,omitempty"`
+	Status  string           `json:"status,omitempty"`
 }
 
 // Link provides a structure with information about how to access a object.
@@ -84,15 +91,22 @@ type ObjectResponseWithMultipart struct {
 	Pointer
 	Actions ObjectResponseActionWithMultipart `json:"actions,omitempty"`
 	Error   *ObjectError                      `json:"error,omitempty"`
+	Status  string                            `json:"status,omitempty"`
 }
 
 type ObjectDirectUrls struct {
+	// Commit is the commit whose tree the objects are resolved from
+	Commit  string             `json:"commit,omitempty"`
 	Objects []*ObjectDirectUrl `json:"objects"`
 }
 
 type ObjectDirectUrl struct {
 	Pointer
-	URL string `json:"url"`
+	// Path is the path of the pointer file in the tree of the commit
+	Path      string     `json:"path,omitempty"`
+	URL       string     `json:"url"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Status    string     `json:"status,omitempty"`
 }
 
 type ObjectResponseActionWithMultipart struct {
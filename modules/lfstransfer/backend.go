@@ -0,0 +1,63 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package lfstransfer
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	lfs_module "code.gitea.io/gitea/modules/lfs"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// Backend stores the objects and the locks served through the transfer protocol
+type Backend interface {
+	// Batch returns the objects of the operation which have to be transferred, the others are skipped by the client
+	Batch(operation, ref string, pointers []lfs_module.Pointer) ([]*lfs_module.ObjectResponse, error)
+	// Upload stores the content of the object
+	Upload(p lfs_module.Pointer, r io.Reader) error
+	// Verify checks the object has been stored
+	Verify(p lfs_module.Pointer) error
+	// Download returns the content of the object and its size
+	Download(p lfs_module.Pointer) (io.ReadCloser, int64, error)
+
+	// Lock locks the path, a StatusError with the existing lock is returned if it is already locked
+	Lock(path, ref string) (*api.LFSLock, error)
+	// ListLocks returns a page of the locks matching the options
+	ListLocks(opts *ListLocksOptions) (*api.LFSLockList, error)
+	// Unlock removes the lock
+	Unlock(id, ref string, force bool) (*api.LFSLock, error)
+	// IsOwner returns whether the lock is owned by the user of the session
+	IsOwner(lock *api.LFSLock) bool
+}
+
+// ListLocksOptions are the options of the listing of the locks
+type ListLocksOptions struct {
+	Path   string
+	ID     string
+	Cursor string
+	Limit  string
+	Ref    string
+}
+
+// StatusError is an error reported to the client with a status code
+type StatusError struct {
+	Code    int
+	Message string
+	// Lock is the conflicting lock of a lock request
+	Lock *api.LFSLock
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%d %s", e.Code, e.Message)
+}
+
+// newStatusError returns a StatusError with the default message of the code if message is empty
+func newStatusError(code int, message string) *StatusError {
+	if message == "" {
+		message = http.StatusText(code)
+	}
+	return &StatusError{Code: code, Message: message}
+}
@@ -0,0 +1,232 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package lfstransfer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"code.gitea.io/gitea/modules/httplib"
+	"code.gitea.io/gitea/modules/json"
+	lfs_module "code.gitea.io/gitea/modules/lfs"
+	"code.gitea.io/gitea/modules/private"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// httpBackend serves the objects and the locks through the LFS server of Gitea, so they are stored and checked the
+// same way as the objects transferred over HTTP
+type httpBackend struct {
+	ctx           context.Context
+	lfsURL        string
+	authorization string
+	username      string
+}
+
+// NewHTTPBackend returns a backend calling the LFS server of the repository on the local url of Gitea,
+// the requests are authenticated by the authorization header and made on behalf of the user
+func NewHTTPBackend(ctx context.Context, ownerName, repoName, authorization, username string) Backend {
+	return &httpBackend{
+		ctx:           ctx,
+		lfsURL:        fmt.Sprintf("%s%s/%s.git/info/lfs", setting.LocalURL, url.PathEscape(ownerName), url.PathEscape(repoName)),
+		authorization: authorization,
+		username:      username,
+	}
+}
+
+// newRequest returns a request to the url with the headers of its link, the urls of Gitea itself are requested on
+// its local url and authenticated as the session
+func (b *httpBackend) newRequest(method, href string, header map[string]string) *httplib.Request {
+	var req *httplib.Request
+	local := true
+	if strings.HasPrefix(href, setting.AppURL) {
+		req = private.NewLocalRequest(b.ctx, setting.LocalURL+strings.TrimPrefix(href, setting.AppURL), method)
+	} else if strings.HasPrefix(href, setting.LocalURL) {
+		req = private.NewLocalRequest(b.ctx, href, method)
+	} else {
+		// the objects served directly by the storage
+		req = httplib.NewRequest(href, method).SetContext(b.ctx)
+		local = false
+	}
+	// the objects may be large, the request is only bounded by the session
+	req.SetReadWriteTimeout(0)
+	if local {
+		req.Header("Accept", lfs_module.MediaType)
+		req.Header("Authorization", b.authorization)
+	}
+	for k, v := range header {
+		req.Header(k, v)
+	}
+	return req
+}
+
+// doJSON sends the request with the json body and decodes the json response into result if the status is expected
+func (b *httpBackend) doJSON(method, href string, body, result any, expected ...int) (int, error) {
+	req := b.newRequest(method, href, nil)
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return 0, err
+		}
+		req.Header("Content-Type", lfs_module.MediaType)
+		req.Body(data)
+	}
+	resp, err := req.Response()
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	for _, code := range expected {
+		if resp.StatusCode == code {
+			if result == nil {
+				return resp.StatusCode, nil
+			}
+			return resp.StatusCode, json.NewDecoder(resp.Body).Decode(result)
+		}
+	}
+	return resp.StatusCode, responseError(resp)
+}
+
+// responseError returns the error of an unexpected response
+func responseError(resp *http.Response) error {
+	var e struct {
+		Message string       `json:"message"`
+		Lock    *api.LFSLock `json:"lock"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err := json.Unmarshal(data, &e); err != nil || e.Message == "" {
+		e.Message = strings.TrimSpace(string(data))
+	}
+	statusErr := newStatusError(resp.StatusCode, e.Message)
+	statusErr.Lock = e.Lock
+	return statusErr
+}
+
+func (b *httpBackend) Batch(operation, ref string, pointers []lfs_module.Pointer) ([]*lfs_module.ObjectResponse, error) {
+	br := &lfs_module.BatchRequest{
+		Operation: operation,
+		Transfers: []string{"basic"},
+		Objects:   pointers,
+	}
+	if ref != "" {
+		br.Ref = &lfs_module.Reference{Name: ref}
+	}
+	var resp lfs_module.BatchResponse
+	if _, err := b.doJSON(http.MethodPost, b.lfsURL+"/objects/batch", br, &resp, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return resp.Objects, nil
+}
+
+// action returns the link of the action of the object, nil if there is nothing to do
+func (b *httpBackend) action(operation string, p lfs_module.Pointer) (*lfs_module.Link, error) {
+	objects, err := b.Batch(operation, "", []lfs_module.Pointer{p})
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range objects {
+		if o.Oid != p.Oid {
+			continue
+		}
+		if o.Error != nil {
+			return nil, newStatusError(o.Error.Code, o.Error.Message)
+		}
+		return o.Actions[operation], nil
+	}
+	return nil, newStatusError(http.StatusNotFound, "")
+}
+
+func (b *httpBackend) Upload(p lfs_module.Pointer, r io.Reader) error {
+	link, err := b.action(OperationUpload, p)
+	if err != nil {
+		return err
+	}
+	if link == nil {
+		// the object already exists
+		return nil
+	}
+	req := b.newRequest(http.MethodPut, link.Href, link.Header)
+	req.Header("Content-Type", "application/octet-stream")
+	req.Body(r)
+	resp, err := req.Response()
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return responseError(resp)
+	}
+	return nil
+}
+
+func (b *httpBackend) Verify(p lfs_module.Pointer) error {
+	_, err := b.doJSON(http.MethodPost, b.lfsURL+"/verify", p, nil, http.StatusOK)
+	return err
+}
+
+func (b *httpBackend) Download(p lfs_module.Pointer) (io.ReadCloser, int64, error) {
+	link, err := b.action(OperationDownload, p)
+	if err != nil {
+		return nil, 0, err
+	}
+	if link == nil {
+		return nil, 0, newStatusError(http.StatusNotFound, "")
+	}
+	resp, err := b.newRequest(http.MethodGet, link.Href, link.Header).Response()
+	if err != nil {
+		return nil, 0, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, 0, responseError(resp)
+	}
+	if resp.ContentLength < 0 {
+		resp.Body.Close()
+		return nil, 0, fmt.Errorf("unknown size of LFS OID[%s]", p.Oid)
+	}
+	return resp.Body, resp.ContentLength, nil
+}
+
+func (b *httpBackend) Lock(path, _ string) (*api.LFSLock, error) {
+	var resp api.LFSLockResponse
+	if _, err := b.doJSON(http.MethodPost, b.lfsURL+"/locks", &api.LFSLockRequest{Path: path}, &resp, http.StatusCreated); err != nil {
+		return nil, err
+	}
+	return resp.Lock, nil
+}
+
+func (b *httpBackend) ListLocks(opts *ListLocksOptions) (*api.LFSLockList, error) {
+	query := url.Values{}
+	for k, v := range map[string]string{"path": opts.Path, "id": opts.ID, "cursor": opts.Cursor, "limit": opts.Limit, "refspec": opts.Ref} {
+		if v != "" {
+			query.Set(k, v)
+		}
+	}
+	href := b.lfsURL + "/locks"
+	if len(query) > 0 {
+		href += "?" + query.Encode()
+	}
+	var resp api.LFSLockList
+	if _, err := b.doJSON(http.MethodGet, href, nil, &resp, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (b *httpBackend) Unlock(id, _ string, force bool) (*api.LFSLock, error) {
+	var resp api.LFSLockResponse
+	if _, err := b.doJSON(http.MethodPost, b.lfsURL+"/locks/"+url.PathEscape(id)+"/unlock", &api.LFSLockDeleteRequest{Force: force}, &resp, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return resp.Lock, nil
+}
+
+func (b *httpBackend) IsOwner(lock *api.LFSLock) bool {
+	return lock.Owner != nil && strings.EqualFold(lock.Owner.Name, b.username)
+}
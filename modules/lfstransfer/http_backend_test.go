@@ -0,0 +1,176 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package lfstransfer
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/json"
+	lfs_module "code.gitea.io/gitea/modules/lfs"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPBackend(t *testing.T) {
+	content := "gitea"
+	p, _ := lfs_module.GeneratePointer(strings.NewReader(content))
+	restoring, _ := lfs_module.GeneratePointer(strings.NewReader("restoring"))
+	missing := lfs_module.Pointer{Oid: strings.Repeat("0", 64), Size: 1}
+
+	lock := &api.LFSLock{ID: "1", Path: "a.bin", LockedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), Owner: &api.LFSLockOwner{Name: "User2"}}
+	uploaded := map[string]string{}
+	prefix := "/user2/repo1.git/info/lfs"
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(prefix+"/objects/batch", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		var br lfs_module.BatchRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&br))
+		resp := lfs_module.BatchResponse{}
+		for _, o := range br.Objects {
+			obj := &lfs_module.ObjectResponse{Pointer: o, Actions: map[string]*lfs_module.Link{}}
+			switch {
+			case o.Oid == restoring.Oid:
+				obj.Error = &lfs_module.ObjectError{Code: http.StatusServiceUnavailable, Message: "being restored"}
+			case br.Operation == OperationUpload:
+				obj.Actions[br.Operation] = &lfs_module.Link{Href: setting.AppURL + "user2/repo1.git/info/lfs/objects/" + o.Oid, Header: map[string]string{"X-Test": "upload"}}
+			case o.Oid == p.Oid:
+				// the urls of Gitea itself are requested on its local url
+				obj.Actions[br.Operation] = &lfs_module.Link{Href: setting.AppURL + "user2/repo1.git/info/lfs/objects/" + o.Oid}
+			default:
+				obj.Error = &lfs_module.ObjectError{Code: http.StatusNotFound, Message: "Not found"}
+			}
+			resp.Objects = append(resp.Objects, obj)
+		}
+		w.Header().Set("Content-Type", lfs_module.MediaType)
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+	mux.HandleFunc(prefix+"/objects/", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+			_, _ = io.WriteString(w, content)
+		case http.MethodPut:
+			assert.Equal(t, "upload", r.Header.Get("X-Test"))
+			data, _ := io.ReadAll(r.Body)
+			uploaded[strings.TrimPrefix(r.URL.Path, prefix+"/objects/")] = string(data)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+	mux.HandleFunc(prefix+"/verify", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		var vp lfs_module.Pointer
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&vp))
+		if _, ok := uploaded[vp.Oid]; !ok {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = io.WriteString(w, `{"message":"Object is not stored"}`)
+		}
+	})
+	mux.HandleFunc(prefix+"/locks", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			assert.Equal(t, "path=a.bin&refspec=refs%2Fheads%2Fmain", r.URL.RawQuery)
+			_ = json.NewEncoder(w).Encode(api.LFSLockList{Locks: []*api.LFSLock{lock}, Next: "2"})
+			return
+		}
+		var req api.LFSLockRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		if req.Path == lock.Path {
+			w.WriteHeader(http.StatusConflict)
+			_ = json.NewEncoder(w).Encode(api.LFSLockError{Message: "already created lock", Lock: lock})
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(api.LFSLockResponse{Lock: &api.LFSLock{ID: "2", Path: req.Path}})
+	})
+	mux.HandleFunc(prefix+"/locks/", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.True(t, strings.HasSuffix(r.URL.Path, "/unlock"))
+		var req api.LFSLockDeleteRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		if !req.Force {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = io.WriteString(w, "forbidden")
+			return
+		}
+		_ = json.NewEncoder(w).Encode(api.LFSLockResponse{Lock: lock})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	defer test.MockVariableValue(&setting.AppURL, "https://gitea.example.com/")()
+	defer test.MockVariableValue(&setting.LocalURL, server.URL+"/")()
+
+	b := NewHTTPBackend(context.Background(), "user2", "repo1", "Bearer token", "user2")
+
+	t.Run("Batch", func(t *testing.T) {
+		objects, err := b.Batch(OperationDownload, "refs/heads/main", []lfs_module.Pointer{p, restoring, missing})
+		assert.NoError(t, err)
+		if assert.Len(t, objects, 3) {
+			assert.NotNil(t, objects[0].Actions[OperationDownload])
+			assert.Equal(t, http.StatusServiceUnavailable, objects[1].Error.Code)
+			assert.Equal(t, http.StatusNotFound, objects[2].Error.Code)
+		}
+	})
+
+	t.Run("Download", func(t *testing.T) {
+		rd, size, err := b.Download(p)
+		assert.NoError(t, err)
+		data, _ := io.ReadAll(rd)
+		rd.Close()
+		assert.EqualValues(t, len(content), size)
+		assert.Equal(t, content, string(data))
+
+		_, _, err = b.Download(restoring)
+		assert.Equal(t, &StatusError{Code: http.StatusServiceUnavailable, Message: "being restored"}, err)
+		_, _, err = b.Download(missing)
+		assert.Equal(t, &StatusError{Code: http.StatusNotFound, Message: "Not found"}, err)
+	})
+
+	t.Run("UploadAndVerify", func(t *testing.T) {
+		err := b.Verify(p)
+		assert.Equal(t, &StatusError{Code: http.StatusUnprocessableEntity, Message: "Object is not stored"}, err)
+
+		assert.NoError(t, b.Upload(p, strings.NewReader(content)))
+		assert.Equal(t, content, uploaded[p.Oid])
+		assert.NoError(t, b.Verify(p))
+	})
+
+	t.Run("Locks", func(t *testing.T) {
+		created, err := b.Lock("b.bin", "")
+		assert.NoError(t, err)
+		assert.Equal(t, "2", created.ID)
+
+		_, err = b.Lock("a.bin", "")
+		var statusErr *StatusError
+		if assert.ErrorAs(t, err, &statusErr) {
+			assert.Equal(t, http.StatusConflict, statusErr.Code)
+			assert.Equal(t, "already created lock", statusErr.Message)
+			assert.Equal(t, "1", statusErr.Lock.ID)
+		}
+
+		list, err := b.ListLocks(&ListLocksOptions{Path: "a.bin", Ref: "refs/heads/main"})
+		assert.NoError(t, err)
+		assert.Equal(t, "2", list.Next)
+		if assert.Len(t, list.Locks, 1) {
+			assert.True(t, b.IsOwner(list.Locks[0]))
+		}
+
+		_, err = b.Unlock("1", "", false)
+		assert.Equal(t, &StatusError{Code: http.StatusForbidden, Message: "forbidden"}, err)
+		unlocked, err := b.Unlock("1", "", true)
+		assert.NoError(t, err)
+		assert.Equal(t, "a.bin", unlocked.Path)
+	})
+}
@@ -0,0 +1,145 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package lfstransfer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+const (
+	// maxPacketData is the maximum length of the data of a packet, the 4 bytes of the length prefix are included in
+	// the 65520 bytes limit of a pkt-line
+	maxPacketData = 65516
+
+	flushPacket = "0000"
+	delimPacket = "0001"
+)
+
+type packetType int
+
+const (
+	packetData packetType = iota
+	packetFlush
+	packetDelim
+)
+
+var errUnexpectedPacket = errors.New("unexpected packet")
+
+// pktline reads and writes the pkt-line framing used by the git-lfs-transfer protocol
+type pktline struct {
+	r *bufio.Reader
+	w *bufio.Writer
+}
+
+func newPktline(r io.Reader, w io.Writer) *pktline {
+	return &pktline{r: bufio.NewReader(r), w: bufio.NewWriter(w)}
+}
+
+// readPacket reads a packet, the data is only returned for the data packets
+func (pl *pktline) readPacket() ([]byte, packetType, error) {
+	var prefix [4]byte
+	if _, err := io.ReadFull(pl.r, prefix[:]); err != nil {
+		return nil, packetData, err
+	}
+	length, err := strconv.ParseUint(string(prefix[:]), 16, 16)
+	if err != nil {
+		return nil, packetData, fmt.Errorf("invalid packet length %q", prefix)
+	}
+	switch {
+	case length == 0:
+		return nil, packetFlush, nil
+	case length == 1:
+		return nil, packetDelim, nil
+	case length <= 4 || length > maxPacketData+4:
+		return nil, packetData, fmt.Errorf("invalid packet length %d", length)
+	}
+	data := make([]byte, length-4)
+	if _, err := io.ReadFull(pl.r, data); err != nil {
+		return nil, packetData, err
+	}
+	return data, packetData, nil
+}
+
+// readLine reads a text packet and strips its trailing new line
+func (pl *pktline) readLine() (string, packetType, error) {
+	data, typ, err := pl.readPacket()
+	if err != nil || typ != packetData {
+		return "", typ, err
+	}
+	if n := len(data); n > 0 && data[n-1] == '\n' {
+		data = data[:n-1]
+	}
+	return string(data), packetData, nil
+}
+
+func (pl *pktline) writePacket(data []byte) error {
+	for len(data) > 0 {
+		n := min(len(data), maxPacketData)
+		if _, err := fmt.Fprintf(pl.w, "%04x", n+4); err != nil {
+			return err
+		}
+		if _, err := pl.w.Write(data[:n]); err != nil {
+			return err
+		}
+		data = data[n:]
+	}
+	return nil
+}
+
+func (pl *pktline) writeLine(line string) error {
+	return pl.writePacket([]byte(line + "\n"))
+}
+
+func (pl *pktline) writeDelim() error {
+	_, err := pl.w.WriteString(delimPacket)
+	return err
+}
+
+// writeFlush ends a message and sends it
+func (pl *pktline) writeFlush() error {
+	if _, err := pl.w.WriteString(flushPacket); err != nil {
+		return err
+	}
+	return pl.w.Flush()
+}
+
+// dataReader reads the data packets of a message until its flush packet
+type dataReader struct {
+	pl   *pktline
+	buf  []byte
+	done bool
+}
+
+func (d *dataReader) Read(p []byte) (int, error) {
+	for len(d.buf) == 0 {
+		if d.done {
+			return 0, io.EOF
+		}
+		data, typ, err := d.pl.readPacket()
+		if err != nil {
+			return 0, err
+		}
+		switch typ {
+		case packetFlush:
+			d.done = true
+		case packetDelim:
+			return 0, errUnexpectedPacket
+		default:
+			d.buf = data
+		}
+	}
+	n := copy(p, d.buf)
+	d.buf = d.buf[n:]
+	return n, nil
+}
+
+// drain reads the remaining data of the message so the next message can be read
+func (d *dataReader) drain() error {
+	_, err := io.Copy(io.Discard, d)
+	return err
+}
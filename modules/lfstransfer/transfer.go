@@ -0,0 +1,420 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+// Package lfstransfer implements the server side of the git-lfs-transfer protocol which transfers the LFS objects
+// over the SSH connection instead of HTTP.
+// https://github.com/git-lfs/git-lfs/blob/main/docs/proposals/ssh_adapter.md
+package lfstransfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	lfs_module "code.gitea.io/gitea/modules/lfs"
+	"code.gitea.io/gitea/modules/log"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+const (
+	// OperationUpload is the operation of the sessions started by pushes
+	OperationUpload = "upload"
+	// OperationDownload is the operation of the sessions started by fetches
+	OperationDownload = "download"
+
+	protocolVersion = "1"
+	hashAlgo        = "sha256"
+)
+
+// request is a command sent by the client
+type request struct {
+	command string
+	// arg is the argument of the command line, e.g. the oid of put-object
+	arg  string
+	args map[string]string
+	// data reads the data following the arguments, it is nil if the request has no data
+	data *dataReader
+}
+
+// brokenSessionError is an error occurring once a response has been partially written, it can't be reported to
+// the client so the session is ended
+type brokenSessionError struct {
+	err error
+}
+
+func (e *brokenSessionError) Error() string {
+	return e.err.Error()
+}
+
+// session serves the commands of a client
+type session struct {
+	ctx       context.Context
+	pl        *pktline
+	backend   Backend
+	operation string
+	// sizes are the sizes of the objects announced by the batch requests, get-object only sends the oid
+	sizes map[string]int64
+}
+
+// Serve serves the git-lfs-transfer protocol of the operation until the client quits
+func Serve(ctx context.Context, r io.Reader, w io.Writer, backend Backend, operation string) error {
+	if operation != OperationUpload && operation != OperationDownload {
+		return fmt.Errorf("unknown operation %q", operation)
+	}
+	s := &session{ctx: ctx, pl: newPktline(r, w), backend: backend, operation: operation, sizes: map[string]int64{}}
+	if err := s.handshake(); err != nil {
+		return err
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		req, err := s.readRequest()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if req.command == "quit" {
+			return s.writeStatus(http.StatusOK, nil, nil)
+		}
+		if err := s.handle(req); err != nil {
+			return err
+		}
+	}
+}
+
+// handshake advertises the capabilities of the server and negotiates the version
+func (s *session) handshake() error {
+	if err := s.pl.writeLine("version=" + protocolVersion); err != nil {
+		return err
+	}
+	if err := s.pl.writeFlush(); err != nil {
+		return err
+	}
+	req, err := s.readRequest()
+	if err != nil {
+		return err
+	}
+	if req.command != "version" || req.arg != protocolVersion {
+		// the session can't go on without a version, it is ended once the client has been told why
+		statusErr := newStatusError(http.StatusBadRequest, "unsupported version "+req.arg)
+		if err := s.writeError(statusErr); err != nil {
+			return err
+		}
+		return statusErr
+	}
+	return s.writeStatus(http.StatusOK, nil, nil)
+}
+
+// readRequest reads the command line and the arguments of a request, the data is left to the handler
+func (s *session) readRequest() (*request, error) {
+	line, typ, err := s.pl.readLine()
+	if err != nil {
+		return nil, err
+	} else if typ != packetData {
+		return nil, errUnexpectedPacket
+	}
+	req := &request{args: map[string]string{}}
+	req.command, req.arg, _ = strings.Cut(line, " ")
+	for {
+		line, typ, err := s.pl.readLine()
+		if err != nil {
+			return nil, err
+		}
+		switch typ {
+		case packetFlush:
+			return req, nil
+		case packetDelim:
+			req.data = &dataReader{pl: s.pl}
+			return req, nil
+		}
+		k, v, _ := strings.Cut(line, "=")
+		req.args[k] = v
+	}
+}
+
+func (s *session) handle(req *request) error {
+	var err error
+	switch req.command {
+	case "batch":
+		err = s.batch(req)
+	case "put-object":
+		err = s.putObject(req)
+	case "verify-object":
+		err = s.verifyObject(req)
+	case "get-object":
+		err = s.getObject(req)
+	case "lock":
+		err = s.lock(req)
+	case "list-lock":
+		err = s.listLock(req)
+	case "unlock":
+		err = s.unlock(req)
+	default:
+		err = newStatusError(http.StatusBadRequest, "unknown command "+req.command)
+	}
+
+	if req.data != nil {
+		if errDrain := req.data.drain(); errDrain != nil {
+			return errDrain
+		}
+	}
+	var statusErr *StatusError
+	var brokenErr *brokenSessionError
+	if errors.As(err, &brokenErr) {
+		return brokenErr.err
+	} else if errors.As(err, &statusErr) {
+		return s.writeError(statusErr)
+	} else if err != nil {
+		log.Error("lfs[transfer] %s failed: %v", req.command, err)
+		return s.writeError(newStatusError(http.StatusInternalServerError, ""))
+	}
+	return nil
+}
+
+// writeStatus writes a response with the arguments and the data lines
+func (s *session) writeStatus(code int, args, lines []string) error {
+	if err := s.pl.writeLine("status " + strconv.Itoa(code)); err != nil {
+		return err
+	}
+	for _, arg := range args {
+		if err := s.pl.writeLine(arg); err != nil {
+			return err
+		}
+	}
+	if lines != nil {
+		if err := s.pl.writeDelim(); err != nil {
+			return err
+		}
+		for _, line := range lines {
+			if err := s.pl.writeLine(line); err != nil {
+				return err
+			}
+		}
+	}
+	return s.pl.writeFlush()
+}
+
+func (s *session) writeError(err *StatusError) error {
+	var args []string
+	if err.Lock != nil {
+		args = lockArgs(err.Lock)
+	}
+	return s.writeStatus(err.Code, args, []string{err.Message})
+}
+
+// pointer returns the pointer of the object of the request
+func (req *request) pointer() (lfs_module.Pointer, error) {
+	p := lfs_module.Pointer{Oid: req.arg}
+	if size, ok := req.args["size"]; ok {
+		var err error
+		if p.Size, err = strconv.ParseInt(size, 10, 64); err != nil {
+			return p, newStatusError(http.StatusBadRequest, "invalid size "+size)
+		}
+	}
+	if !p.IsValid() {
+		return p, newStatusError(http.StatusBadRequest, "invalid object "+req.arg)
+	}
+	return p, nil
+}
+
+func (s *session) batch(req *request) error {
+	if algo, ok := req.args["hash-algo"]; ok && algo != hashAlgo {
+		return newStatusError(http.StatusConflict, "unsupported hash algorithm "+algo)
+	}
+	if transfer, ok := req.args["transfer"]; ok && transfer != "basic" {
+		return newStatusError(http.StatusConflict, "unsupported transfer "+transfer)
+	}
+	if req.data == nil {
+		return newStatusError(http.StatusBadRequest, "missing objects")
+	}
+
+	var pointers []lfs_module.Pointer
+	content, err := io.ReadAll(req.data)
+	if err != nil {
+		return err
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		} else if len(fields) < 2 {
+			return newStatusError(http.StatusBadRequest, "invalid object "+line)
+		}
+		size, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return newStatusError(http.StatusBadRequest, "invalid object "+line)
+		}
+		pointers = append(pointers, lfs_module.Pointer{Oid: fields[0], Size: size})
+		s.sizes[fields[0]] = size
+	}
+
+	objects, err := s.backend.Batch(s.operation, req.args["refname"], pointers)
+	if err != nil {
+		return err
+	}
+	lines := make([]string, 0, len(objects))
+	for _, o := range objects {
+		action := "noop"
+		if _, ok := o.Actions[s.operation]; ok || o.Error != nil {
+			// the protocol has no error per object in the batch, the errors of the objects, e.g. missing or
+			// being restored from the archive, are reported by their get-object or put-object so the other
+			// objects are still transferred
+			action = s.operation
+		}
+		lines = append(lines, fmt.Sprintf("%s %d %s", o.Oid, o.Size, action))
+	}
+	return s.writeStatus(http.StatusOK, []string{"hash-algo=" + hashAlgo}, lines)
+}
+
+func (s *session) putObject(req *request) error {
+	if s.operation != OperationUpload {
+		return newStatusError(http.StatusForbidden, "upload is not allowed in a download session")
+	}
+	p, err := req.pointer()
+	if err != nil {
+		return err
+	}
+	if req.data == nil {
+		return newStatusError(http.StatusBadRequest, "missing object content")
+	}
+	if err := s.backend.Upload(p, req.data); err != nil {
+		return err
+	}
+	return s.writeStatus(http.StatusOK, nil, nil)
+}
+
+func (s *session) verifyObject(req *request) error {
+	p, err := req.pointer()
+	if err != nil {
+		return err
+	}
+	if err := s.backend.Verify(p); err != nil {
+		return err
+	}
+	return s.writeStatus(http.StatusOK, nil, nil)
+}
+
+func (s *session) getObject(req *request) error {
+	p, err := req.pointer()
+	if err != nil {
+		return err
+	}
+	if _, ok := req.args["size"]; !ok {
+		p.Size = s.sizes[p.Oid]
+	}
+	rd, size, err := s.backend.Download(p)
+	if err != nil {
+		return err
+	}
+	defer rd.Close()
+
+	if err := s.pl.writeLine("status " + strconv.Itoa(http.StatusOK)); err != nil {
+		return err
+	}
+	if err := s.pl.writeLine("size=" + strconv.FormatInt(size, 10)); err != nil {
+		return err
+	}
+	if err := s.pl.writeDelim(); err != nil {
+		return err
+	}
+	// the status can't be changed once the content is being sent, the session is broken by the errors instead
+	buf := make([]byte, maxPacketData)
+	var written int64
+	for {
+		n, err := rd.Read(buf)
+		if n > 0 {
+			written += int64(n)
+			if err := s.pl.writePacket(buf[:n]); err != nil {
+				return err
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return &brokenSessionError{fmt.Errorf("read LFS OID[%s]: %w", p.Oid, err)}
+		}
+	}
+	if written != size {
+		return &brokenSessionError{fmt.Errorf("LFS OID[%s] size mismatch: expected %d, got %d", p.Oid, size, written)}
+	}
+	return s.pl.writeFlush()
+}
+
+// lockArgs returns the arguments describing the lock in the responses of lock and unlock
+func lockArgs(lock *api.LFSLock) []string {
+	args := []string{
+		"id=" + lock.ID,
+		"path=" + lock.Path,
+		"locked-at=" + lock.LockedAt.UTC().Format(time.RFC3339),
+	}
+	if lock.Owner != nil {
+		args = append(args, "ownername="+lock.Owner.Name)
+	}
+	return args
+}
+
+func (s *session) lock(req *request) error {
+	path := req.args["path"]
+	if path == "" {
+		return newStatusError(http.StatusBadRequest, "missing path")
+	}
+	lock, err := s.backend.Lock(path, req.args["refname"])
+	if err != nil {
+		return err
+	}
+	return s.writeStatus(http.StatusCreated, lockArgs(lock), nil)
+}
+
+func (s *session) listLock(req *request) error {
+	list, err := s.backend.ListLocks(&ListLocksOptions{
+		Path:   req.args["path"],
+		ID:     req.args["id"],
+		Cursor: req.args["cursor"],
+		Limit:  req.args["limit"],
+		Ref:    req.args["refname"],
+	})
+	if err != nil {
+		return err
+	}
+
+	var args []string
+	if list.Next != "" {
+		args = append(args, "next-cursor="+list.Next)
+	}
+	lines := make([]string, 0, len(list.Locks)*5)
+	for _, lock := range list.Locks {
+		lines = append(lines,
+			"lock "+lock.ID,
+			fmt.Sprintf("path %s %s", lock.ID, lock.Path),
+			fmt.Sprintf("locked-at %s %s", lock.ID, lock.LockedAt.UTC().Format(time.RFC3339)),
+		)
+		if lock.Owner != nil {
+			lines = append(lines, fmt.Sprintf("ownername %s %s", lock.ID, lock.Owner.Name))
+		}
+		owner := "theirs"
+		if s.backend.IsOwner(lock) {
+			owner = "ours"
+		}
+		lines = append(lines, fmt.Sprintf("owner %s %s", lock.ID, owner))
+	}
+	return s.writeStatus(http.StatusOK, args, lines)
+}
+
+func (s *session) unlock(req *request) error {
+	if req.arg == "" {
+		return newStatusError(http.StatusBadRequest, "missing lock id")
+	}
+	lock, err := s.backend.Unlock(req.arg, req.args["refname"], req.args["force"] == "true")
+	if err != nil {
+		return err
+	}
+	return s.writeStatus(http.StatusOK, lockArgs(lock), nil)
+}
@@ -0,0 +1,262 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package lfstransfer
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	lfs_module "code.gitea.io/gitea/modules/lfs"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryBackend struct {
+	objects map[string][]byte
+	locks   []*api.LFSLock
+	// unavailable are the objects which can't be transferred for now, e.g. being restored
+	unavailable map[string]bool
+}
+
+func (b *memoryBackend) Batch(operation, _ string, pointers []lfs_module.Pointer) ([]*lfs_module.ObjectResponse, error) {
+	objects := make([]*lfs_module.ObjectResponse, 0, len(pointers))
+	for _, p := range pointers {
+		o := &lfs_module.ObjectResponse{Pointer: p, Actions: map[string]*lfs_module.Link{}}
+		_, exists := b.objects[p.Oid]
+		if b.unavailable[p.Oid] {
+			o.Error = &lfs_module.ObjectError{Code: http.StatusServiceUnavailable, Message: "unavailable"}
+		} else if operation == OperationUpload && !exists {
+			o.Actions[operation] = &lfs_module.Link{}
+		} else if operation == OperationDownload && exists {
+			o.Actions[operation] = &lfs_module.Link{}
+		} else if operation == OperationDownload {
+			o.Error = &lfs_module.ObjectError{Code: http.StatusNotFound, Message: "not found"}
+		}
+		objects = append(objects, o)
+	}
+	return objects, nil
+}
+
+func (b *memoryBackend) Upload(p lfs_module.Pointer, r io.Reader) error {
+	if b.unavailable[p.Oid] {
+		return newStatusError(http.StatusServiceUnavailable, "unavailable")
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	b.objects[p.Oid] = data
+	return nil
+}
+
+func (b *memoryBackend) Verify(p lfs_module.Pointer) error {
+	if int64(len(b.objects[p.Oid])) != p.Size {
+		return newStatusError(http.StatusNotFound, "")
+	}
+	return nil
+}
+
+func (b *memoryBackend) Download(p lfs_module.Pointer) (io.ReadCloser, int64, error) {
+	if b.unavailable[p.Oid] {
+		return nil, 0, newStatusError(http.StatusServiceUnavailable, "unavailable")
+	}
+	data, ok := b.objects[p.Oid]
+	if !ok {
+		return nil, 0, newStatusError(http.StatusNotFound, "")
+	}
+	return io.NopCloser(bytes.NewReader(data)), int64(len(data)), nil
+}
+
+func (b *memoryBackend) Lock(path, _ string) (*api.LFSLock, error) {
+	for _, lock := range b.locks {
+		if lock.Path == path {
+			return nil, &StatusError{Code: http.StatusConflict, Message: "already locked", Lock: lock}
+		}
+	}
+	lock := &api.LFSLock{ID: "1", Path: path, LockedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), Owner: &api.LFSLockOwner{Name: "user2"}}
+	b.locks = append(b.locks, lock)
+	return lock, nil
+}
+
+func (b *memoryBackend) ListLocks(_ *ListLocksOptions) (*api.LFSLockList, error) {
+	return &api.LFSLockList{Locks: b.locks}, nil
+}
+
+func (b *memoryBackend) Unlock(id, _ string, _ bool) (*api.LFSLock, error) {
+	for i, lock := range b.locks {
+		if lock.ID == id {
+			b.locks = append(b.locks[:i], b.locks[i+1:]...)
+			return lock, nil
+		}
+	}
+	return nil, newStatusError(http.StatusNotFound, "")
+}
+
+func (b *memoryBackend) IsOwner(lock *api.LFSLock) bool {
+	return lock.Owner.Name == "user2"
+}
+
+// pkt encodes the messages of the client, the lines are terminated with a new line and "0000" and "0001" are
+// written as flush and delim packets
+func pkt(lines ...string) string {
+	var sb strings.Builder
+	for _, line := range lines {
+		if line == flushPacket || line == delimPacket {
+			sb.WriteString(line)
+			continue
+		}
+		pl := newPktline(nil, &sb)
+		_ = pl.writeLine(line)
+		_ = pl.w.Flush()
+	}
+	return sb.String()
+}
+
+// binaryPkt encodes data sent without a trailing new line
+func binaryPkt(data string) string {
+	var sb strings.Builder
+	pl := newPktline(nil, &sb)
+	_ = pl.writePacket([]byte(data))
+	_ = pl.w.Flush()
+	return sb.String()
+}
+
+// readResponses decodes the output of the server, one string per message
+func readResponses(t *testing.T, output string) []string {
+	pl := newPktline(strings.NewReader(output), nil)
+	var responses []string
+	var sb strings.Builder
+	for {
+		data, typ, err := pl.readPacket()
+		if err == io.EOF {
+			return responses
+		}
+		assert.NoError(t, err)
+		switch typ {
+		case packetFlush:
+			responses = append(responses, sb.String())
+			sb.Reset()
+		case packetDelim:
+			sb.WriteString("--\n")
+		default:
+			sb.Write(data)
+		}
+	}
+}
+
+func TestServe(t *testing.T) {
+	backend := &memoryBackend{objects: map[string][]byte{}}
+	content := "gitea"
+	p, _ := lfs_module.GeneratePointer(strings.NewReader(content))
+	size := "5"
+
+	input := pkt("version 1", flushPacket,
+		"batch", "hash-algo=sha256", "transfer=basic", delimPacket, p.Oid+" "+size, flushPacket,
+		"put-object "+p.Oid, "size="+size, delimPacket) + binaryPkt(content)
+	input += pkt(flushPacket,
+		"verify-object "+p.Oid, "size="+size, flushPacket,
+		"batch", delimPacket, p.Oid+" "+size, flushPacket,
+		"lock", "path=a.bin", flushPacket,
+		"lock", "path=a.bin", flushPacket,
+		"list-lock", flushPacket,
+		"unlock 1", flushPacket,
+		"quit", flushPacket)
+
+	var output bytes.Buffer
+	assert.NoError(t, Serve(context.Background(), strings.NewReader(input), &output, backend, OperationUpload))
+	assert.Equal(t, content, string(backend.objects[p.Oid]))
+
+	lockArgs := "id=1\npath=a.bin\nlocked-at=2024-01-02T03:04:05Z\nownername=user2\n"
+	assert.Equal(t, []string{
+		"version=1\n",
+		"status 200\n",
+		"status 200\nhash-algo=sha256\n--\n" + p.Oid + " 5 upload\n",
+		"status 200\n",
+		"status 200\n",
+		"status 200\nhash-algo=sha256\n--\n" + p.Oid + " 5 noop\n",
+		"status 201\n" + lockArgs,
+		"status 409\n" + lockArgs + "--\nalready locked\n",
+		"status 200\n--\nlock 1\npath 1 a.bin\nlocked-at 1 2024-01-02T03:04:05Z\nownername 1 user2\nowner 1 ours\n",
+		"status 200\n" + lockArgs,
+		"status 200\n",
+	}, readResponses(t, output.String()))
+
+	input = pkt("version 1", flushPacket,
+		"put-object "+p.Oid, "size="+size, flushPacket,
+		"get-object "+p.Oid, flushPacket,
+		"get-object 0000000000000000000000000000000000000000000000000000000000000000", flushPacket)
+	output.Reset()
+	assert.NoError(t, Serve(context.Background(), strings.NewReader(input), &output, backend, OperationDownload))
+	assert.Equal(t, []string{
+		"version=1\n",
+		"status 200\n",
+		"status 403\n--\nupload is not allowed in a download session\n",
+		"status 200\nsize=5\n--\ngitea",
+		"status 404\n--\nNot Found\n",
+	}, readResponses(t, output.String()))
+}
+
+func TestServeUnsupportedVersion(t *testing.T) {
+	backend := &memoryBackend{objects: map[string][]byte{}}
+	input := pkt("version 2", flushPacket, "list-lock", flushPacket, "quit", flushPacket)
+
+	var output bytes.Buffer
+	err := Serve(context.Background(), strings.NewReader(input), &output, backend, OperationDownload)
+	var statusErr *StatusError
+	if assert.ErrorAs(t, err, &statusErr) {
+		assert.Equal(t, http.StatusBadRequest, statusErr.Code)
+	}
+	// the session ends without serving the following commands
+	assert.Equal(t, []string{
+		"version=1\n",
+		"status 400\n--\nunsupported version 2\n",
+	}, readResponses(t, output.String()))
+}
+
+func TestServeObjectErrors(t *testing.T) {
+	p1, _ := lfs_module.GeneratePointer(strings.NewReader("gitea"))
+	p2, _ := lfs_module.GeneratePointer(strings.NewReader("lfs"))
+	backend := &memoryBackend{
+		objects:     map[string][]byte{p1.Oid: []byte("gitea"), p2.Oid: []byte("lfs")},
+		unavailable: map[string]bool{p2.Oid: true},
+	}
+
+	// the error of an object doesn't fail the whole batch, it is reported by the transfer of that object
+	input := pkt("version 1", flushPacket,
+		"batch", delimPacket, p1.Oid+" 5", p2.Oid+" 3", flushPacket,
+		"get-object "+p1.Oid, flushPacket,
+		"get-object "+p2.Oid, flushPacket,
+		"quit", flushPacket)
+	var output bytes.Buffer
+	assert.NoError(t, Serve(context.Background(), strings.NewReader(input), &output, backend, OperationDownload))
+	assert.Equal(t, []string{
+		"version=1\n",
+		"status 200\n",
+		"status 200\nhash-algo=sha256\n--\n" + p1.Oid + " 5 download\n" + p2.Oid + " 3 download\n",
+		"status 200\nsize=5\n--\ngitea",
+		"status 503\n--\nunavailable\n",
+		"status 200\n",
+	}, readResponses(t, output.String()))
+
+	delete(backend.objects, p1.Oid)
+	input = pkt("version 1", flushPacket,
+		"batch", delimPacket, p1.Oid+" 5", p2.Oid+" 3", flushPacket,
+		"put-object "+p2.Oid, "size=3", delimPacket) + binaryPkt("lfs")
+	input += pkt(flushPacket, "quit", flushPacket)
+	output.Reset()
+	assert.NoError(t, Serve(context.Background(), strings.NewReader(input), &output, backend, OperationUpload))
+	assert.Equal(t, []string{
+		"version=1\n",
+		"status 200\n",
+		"status 200\nhash-algo=sha256\n--\n" + p1.Oid + " 5 upload\n" + p2.Oid + " 3 upload\n",
+		"status 503\n--\nunavailable\n",
+		"status 200\n",
+	}, readResponses(t, output.String()))
+}
@@ -26,6 +26,10 @@ type Event struct {
 	msgArgs   []any  // they are discarded before the event is passed to the writer's channel
 
 	Stacktrace string
+
+	// TraceID and SpanID are the span of the process logging the event, if it is traced
+	TraceID string
+	SpanID  string
 }
 
 type EventFormatted struct {
@@ -0,0 +1,123 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package log
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	otellog "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/global"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// eventWriterOTLP emits the events as OpenTelemetry log records through the global logger provider,
+// which exports them to the collector once the tracing is initialized
+type eventWriterOTLP struct {
+	*EventWriterBaseImpl
+	logger otellog.Logger
+}
+
+var _ EventWriter = (*eventWriterOTLP)(nil)
+
+func NewEventWriterOTLP(writerName string, writerMode WriterMode) EventWriter {
+	return &eventWriterOTLP{
+		EventWriterBaseImpl: NewEventWriterBase(writerName, "otlp", writerMode),
+		logger:              global.Logger("code.gitea.io/gitea/modules/log"),
+	}
+}
+
+func init() {
+	RegisterEventWriter("otlp", NewEventWriterOTLP)
+}
+
+var otlpSeverities = map[Level]otellog.Severity{
+	TRACE: otellog.SeverityTrace,
+	DEBUG: otellog.SeverityDebug,
+	INFO:  otellog.SeverityInfo,
+	WARN:  otellog.SeverityWarn,
+	ERROR: otellog.SeverityError,
+	FATAL: otellog.SeverityFatal,
+}
+
+// Run emits the events of the queue, the messages are sent without the prefix, flags and colors of the text writers
+func (w *eventWriterOTLP) Run(ctx context.Context) {
+	var exprRegexp *regexp.Regexp
+	if w.Mode.Expression != "" {
+		var err error
+		if exprRegexp, err = regexp.Compile(w.Mode.Expression); err != nil {
+			FallbackErrorf("unable to compile expression %q for writer %q: %v", w.Mode.Expression, w.Name, err)
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-w.Queue:
+			if !ok {
+				return
+			}
+
+			if pause := w.GetPauseChan(); pause != nil {
+				select {
+				case <-pause:
+				case <-ctx.Done():
+				}
+			}
+
+			if exprRegexp != nil {
+				fileLineCaller := fmt.Sprintf("%s:%d:%s", event.Origin.Filename, event.Origin.Line, event.Origin.Caller)
+				if !exprRegexp.MatchString(fileLineCaller) && !exprRegexp.MatchString(event.Origin.MsgSimpleText) {
+					continue
+				}
+			}
+
+			w.emit(ctx, event.Origin)
+		}
+	}
+}
+
+func (w *eventWriterOTLP) emit(ctx context.Context, event *Event) {
+	var record otellog.Record
+	record.SetTimestamp(event.Time)
+	record.SetObservedTimestamp(time.Now())
+	record.SetSeverity(otlpSeverities[event.Level])
+	record.SetSeverityText(event.Level.String())
+	record.SetBody(otellog.StringValue(event.MsgSimpleText))
+	record.AddAttributes(
+		otellog.String("code.function", event.Caller),
+		otellog.String("code.filepath", event.Filename),
+		otellog.Int("code.lineno", event.Line),
+	)
+	if event.GoroutinePid != "" {
+		record.AddAttributes(otellog.String("gitea.pid", event.GoroutinePid))
+	}
+	if event.Stacktrace != "" {
+		record.AddAttributes(otellog.String("exception.stacktrace", event.Stacktrace))
+	}
+
+	// the logger provider takes the trace and span IDs of the record from the span context
+	w.logger.Emit(eventSpanContext(ctx, event), record)
+}
+
+// eventSpanContext returns ctx with the span of the process which logged the event
+func eventSpanContext(ctx context.Context, event *Event) context.Context {
+	traceID, err := trace.TraceIDFromHex(event.TraceID)
+	if err != nil {
+		return ctx
+	}
+	spanID, err := trace.SpanIDFromHex(event.SpanID)
+	if err != nil {
+		return ctx
+	}
+	return trace.ContextWithSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	}))
+}
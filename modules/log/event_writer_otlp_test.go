@@ -0,0 +1,74 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package log
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	otellog "go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+type recordingProcessor struct {
+	mu      sync.Mutex
+	records []sdklog.Record
+}
+
+func (p *recordingProcessor) OnEmit(_ context.Context, r sdklog.Record) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.records = append(p.records, r)
+	return nil
+}
+
+func (p *recordingProcessor) Enabled(context.Context, sdklog.Record) bool {
+	return true
+}
+
+func (p *recordingProcessor) Shutdown(context.Context) error {
+	return nil
+}
+
+func (p *recordingProcessor) ForceFlush(context.Context) error {
+	return nil
+}
+
+func TestOTLPLogger(t *testing.T) {
+	processor := &recordingProcessor{}
+	w := NewEventWriterOTLP("test-otlp", WriterMode{Level: INFO}).(*eventWriterOTLP)
+	w.logger = sdklog.NewLoggerProvider(sdklog.WithProcessor(processor)).Logger("test")
+
+	logger := NewLoggerWithWriters(context.Background(), "test", w)
+	logger.SendLogEvent(&Event{
+		Time:          time.Date(2024, time.January, 2, 3, 4, 5, 0, time.UTC),
+		Level:         WARN,
+		MsgSimpleText: "traced message",
+		Caller:        "CALLER",
+		Filename:      "FILENAME",
+		Line:          1,
+		TraceID:       "0102030405060708090a0b0c0d0e0f10",
+		SpanID:        "0102030405060708",
+	})
+	logger.SendLogEvent(&Event{Level: ERROR, MsgSimpleText: "untraced message"})
+	logger.Close()
+
+	processor.mu.Lock()
+	defer processor.mu.Unlock()
+	if assert.Len(t, processor.records, 2) {
+		r := processor.records[0]
+		assert.Equal(t, "traced message", r.Body().AsString())
+		assert.Equal(t, otellog.SeverityWarn, r.Severity())
+		assert.Equal(t, "0102030405060708090a0b0c0d0e0f10", r.TraceID().String())
+		assert.Equal(t, "0102030405060708", r.SpanID().String())
+
+		r = processor.records[1]
+		assert.Equal(t, "untraced message", r.Body().AsString())
+		assert.Equal(t, otellog.SeverityError, r.Severity())
+		assert.False(t, r.TraceID().IsValid())
+	}
+}
@@ -203,6 +203,8 @@ func (l *LoggerImpl) Log(skip int, level Level, format string, logArgs ...any) {
 	labels := getGoroutineLabels()
 	if labels != nil {
 		event.GoroutinePid = labels["pid"]
+		event.TraceID = labels["trace_id"]
+		event.SpanID = labels["span_id"]
 	}
 
 	// get a simple text message without color
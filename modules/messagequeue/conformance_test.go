@@ -0,0 +1,163 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package messagequeue
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/alicebob/miniredis/v2"
+	kfklib "github.com/opensourceways/kafka-lib/agent"
+	"github.com/opensourceways/kafka-lib/mq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const conformanceTimeout = 5 * time.Second
+
+// testMessageQueueConformance checks the behavior every MessageQueue implementation has to provide
+func testMessageQueueConformance(t *testing.T, q MessageQueue) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	subscribe := func(ctx context.Context, topic string) chan *Message {
+		received := make(chan *Message, 10)
+		require.NoError(t, q.Subscribe(ctx, topic, func(_ context.Context, msg *Message) error {
+			received <- msg
+			return nil
+		}))
+		return received
+	}
+	receive := func(received chan *Message) *Message {
+		select {
+		case msg := <-received:
+			return msg
+		case <-time.After(conformanceTimeout):
+			t.Fatal("message not received")
+			return nil
+		}
+	}
+
+	events := subscribe(ctx, "gitea-events")
+	others := subscribe(ctx, "gitea-others")
+	stoppedCtx, stop := context.WithCancel(ctx)
+	stopped := subscribe(stoppedCtx, "gitea-events")
+	stop()
+
+	t.Run("PublishOrder", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			header := map[string]string{
+				"gitea-event-id": strconv.Itoa(i),
+				"traceparent":    "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			}
+			require.NoError(t, q.Publish(ctx, "gitea-events", header, []byte(fmt.Sprintf(`{"n":%d}`, i))))
+		}
+		for i := 0; i < 3; i++ {
+			msg := receive(events)
+			assert.Equal(t, "gitea-events", msg.Topic)
+			assert.Equal(t, strconv.Itoa(i), msg.Header["gitea-event-id"])
+			assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", msg.Header["traceparent"])
+			assert.JSONEq(t, fmt.Sprintf(`{"n":%d}`, i), msg.Body)
+		}
+	})
+
+	t.Run("TopicIsolation", func(t *testing.T) {
+		require.NoError(t, q.Publish(ctx, "gitea-others", nil, []byte(`{}`)))
+		msg := receive(others)
+		assert.Equal(t, "gitea-others", msg.Topic)
+		assert.JSONEq(t, `{}`, msg.Body)
+		assert.Empty(t, events)
+	})
+
+	t.Run("StoppedSubscription", func(t *testing.T) {
+		assert.Empty(t, stopped)
+	})
+
+	t.Run("Close", func(t *testing.T) {
+		require.NoError(t, q.Close())
+		assert.Error(t, q.Publish(ctx, "gitea-events", nil, []byte(`{}`)))
+	})
+}
+
+func TestMemoryQueue(t *testing.T) {
+	testMessageQueueConformance(t, NewMemoryQueue())
+}
+
+func TestFileQueue(t *testing.T) {
+	q, err := NewFileQueue(t.TempDir())
+	require.NoError(t, err)
+	testMessageQueueConformance(t, q)
+}
+
+func TestRedisQueue(t *testing.T) {
+	server := miniredis.RunT(t)
+	testMessageQueueConformance(t, NewRedisQueue("redis://"+server.Addr()+"/0", 1000))
+}
+
+func TestNATSQueue(t *testing.T) {
+	server := runNATSStandIn(t)
+	q, err := NewNATSQueue(setting.MQConfig{ServerAddr: "nats://" + server.Addr()})
+	require.NoError(t, err)
+	testMessageQueueConformance(t, q)
+}
+
+// memoryKafkaAgent stands in for the kafka library, it hands the messages over through a memory queue
+type memoryKafkaAgent struct {
+	q *MemoryQueue
+}
+
+func (a memoryKafkaAgent) Publish(topic string, header map[string]string, body []byte, _ ...mq.PublishOption) error {
+	return a.q.Publish(context.Background(), topic, header, body)
+}
+
+func (a memoryKafkaAgent) Subscribe(_ string, h kfklib.Handler, topics []string) error {
+	for _, topic := range topics {
+		if err := a.q.Subscribe(context.Background(), topic, func(_ context.Context, msg *Message) error {
+			return h([]byte(msg.Body), msg.Header)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a memoryKafkaAgent) Exit() {
+	_ = a.q.Close()
+}
+
+func TestKafkaQueue(t *testing.T) {
+	testMessageQueueConformance(t, &kafkaQueue{agent: memoryKafkaAgent{q: NewMemoryQueue()}, group: "gitea-test"})
+}
+
+// TestKafkaCluster needs a kafka cluster, e.g. TEST_KAFKA_ADDR=127.0.0.1:9092
+func TestKafkaCluster(t *testing.T) {
+	addr := os.Getenv("TEST_KAFKA_ADDR")
+	if addr == "" {
+		t.Skip("TEST_KAFKA_ADDR is not set")
+	}
+	q, err := NewMessageQueue(setting.MQConfig{MessageType: TypeKafka, ServerAddr: addr, Group: "gitea-test"})
+	require.NoError(t, err)
+	testMessageQueueConformance(t, q)
+}
+
+func TestPublish(t *testing.T) {
+	q := NewMemoryQueue()
+	defer SetMessageQueue(q)()
+
+	assert.NoError(t, Publish(context.Background(), "gitea-events", map[string]string{"type": "push"}, map[string]string{"k": "v"}))
+	messages := q.Messages("gitea-events")
+	if assert.Len(t, messages, 1) {
+		assert.Equal(t, "v", messages[0].Header["k"])
+		assert.JSONEq(t, `{"type":"push"}`, messages[0].Body)
+	}
+
+	defer SetMessageQueue(nil)()
+	assert.ErrorIs(t, Publish(context.Background(), "gitea-events", nil, nil), ErrNotInitialized)
+}
@@ -0,0 +1,151 @@
+package messagequeue
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+)
+
+// filePollInterval is how often the subscriptions check the files for new messages
+const filePollInterval = 100 * time.Millisecond
+
+// FileQueue appends the published messages as JSON lines to a file per topic,
+// so the events can be inspected, replayed or consumed by tailing the files without a running broker
+type FileQueue struct {
+	mu     sync.Mutex
+	dir    string
+	closed chan struct{}
+}
+
+var _ MessageQueue = &FileQueue{}
+
+// NewFileQueue creates a message queue writing to dir
+func NewFileQueue(dir string) (*FileQueue, error) {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("unable to create message queue directory %s: %w", dir, err)
+	}
+	return &FileQueue{dir: dir, closed: make(chan struct{})}, nil
+}
+
+func (q *FileQueue) isClosed() bool {
+	select {
+	case <-q.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// Publish appends the message to the file of the topic
+func (q *FileQueue) Publish(_ context.Context, topic string, header map[string]string, body []byte) error {
+	if q.isClosed() {
+		return ErrClosed
+	}
+	line, err := json.Marshal(&Message{Topic: topic, Header: header, Body: string(body)})
+	if err != nil {
+		return err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	f, err := os.OpenFile(q.topicPath(topic), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(append(line, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Subscribe tails the file of the topic
+func (q *FileQueue) Subscribe(ctx context.Context, topic string, handler Handler) error {
+	if q.isClosed() {
+		return ErrClosed
+	}
+
+	q.mu.Lock()
+	f, err := os.OpenFile(q.topicPath(topic), os.O_CREATE|os.O_RDONLY, 0o644)
+	if err == nil {
+		// only the messages published from now on are handed to the handler
+		_, err = f.Seek(0, io.SeekEnd)
+	}
+	q.mu.Unlock()
+	if err != nil {
+		if f != nil {
+			_ = f.Close()
+		}
+		return err
+	}
+
+	go func() {
+		defer f.Close()
+		ticker := time.NewTicker(filePollInterval)
+		defer ticker.Stop()
+
+		var pending []byte
+		buf := make([]byte, 32*1024)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-q.closed:
+				return
+			case <-ticker.C:
+			}
+
+			for {
+				n, err := f.Read(buf)
+				pending = append(pending, buf[:n]...)
+				if err != nil || n == 0 {
+					break
+				}
+			}
+			for {
+				i := bytes.IndexByte(pending, '\n')
+				if i < 0 {
+					break
+				}
+				line := pending[:i]
+				pending = pending[i+1:]
+
+				if ctx.Err() != nil {
+					return
+				}
+				var msg Message
+				if err := json.Unmarshal(line, &msg); err != nil {
+					log.Error("Unable to decode a message of topic %s: %v", topic, err)
+					continue
+				}
+				if err := handler(ctx, &msg); err != nil {
+					log.Error("Unable to handle the message of topic %s: %v", topic, err)
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// Close stops all subscriptions
+func (q *FileQueue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !q.isClosed() {
+		close(q.closed)
+	}
+	return nil
+}
+
+func (q *FileQueue) topicPath(topic string) string {
+	return filepath.Join(q.dir, filepath.Base(filepath.Clean("/"+topic))+".jsonl")
+}
@@ -2,7 +2,7 @@ package messagequeue
 
 import (
 	"context"
-	"encoding/json"
+	"sync"
 
 	kfklib "github.com/opensourceways/kafka-lib/agent"
 	"github.com/sirupsen/logrus"
@@ -13,12 +13,70 @@ import (
 
 const queueName = "gitea-kafka-queue"
 
-func Publish(ctx context.Context, topic string, v interface{}, header map[string]string) error {
-	body, err := json.Marshal(v)
-	if err != nil {
-		return err
+// kafkaAgent is the part of the kafka library used by the queue, the library keeps a single global client
+type kafkaAgent interface {
+	Publish(topic string, header map[string]string, body []byte, opts ...mq.PublishOption) error
+	Subscribe(group string, h kfklib.Handler, topics []string) error
+	Exit()
+}
+
+// libAgent is the kafka library itself
+type libAgent struct{}
+
+func (libAgent) Publish(topic string, header map[string]string, body []byte, opts ...mq.PublishOption) error {
+	return kfklib.Publish(topic, header, body, opts...)
+}
+
+func (libAgent) Subscribe(group string, h kfklib.Handler, topics []string) error {
+	return kfklib.Subscribe(group, h, topics)
+}
+
+func (libAgent) Exit() {
+	kfklib.Exit()
+}
+
+type kafkaQueue struct {
+	agent kafkaAgent
+	group string
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+func (q *kafkaQueue) Publish(ctx context.Context, topic string, header map[string]string, body []byte) error {
+	// the client of the library is released on exit, it must not be used anymore
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.closed {
+		return ErrClosed
+	}
+	return q.agent.Publish(topic, header, body, mq.PublishContext(ctx))
+}
+
+// Subscribe joins the consumer group of the configuration, the kafka library cannot end a single subscription,
+// so the messages received after ctx is done are acknowledged without being handled
+func (q *kafkaQueue) Subscribe(ctx context.Context, topic string, handler Handler) error {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.closed {
+		return ErrClosed
 	}
-	return kfklib.Publish(topic, header, body, mq.PublishContext(ctx))
+	return q.agent.Subscribe(q.group, func(body []byte, header map[string]string) error {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return handler(ctx, &Message{Topic: topic, Header: header, Body: string(body)})
+	}, []string{topic})
+}
+
+func (q *kafkaQueue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !q.closed {
+		q.closed = true
+		q.agent.Exit()
+	}
+	return nil
 }
 
 func retriveConfig(cfg setting.MQConfig) kfklib.Config {
@@ -35,10 +93,13 @@ func retriveConfig(cfg setting.MQConfig) kfklib.Config {
 }
 
 // newKafkaMessageQueue sets up a new Kafka message queue
-func newKafkaMessageQueue(cfg setting.MQConfig) error {
+func newKafkaMessageQueue(cfg setting.MQConfig) (MessageQueue, error) {
 	v := retriveConfig(cfg)
 
 	mqLog := logrus.NewEntry(logrus.StandardLogger())
 
-	return kfklib.Init(&v, mqLog, nil, queueName, true)
+	if err := kfklib.Init(&v, mqLog, nil, queueName, true); err != nil {
+		return nil, err
+	}
+	return &kafkaQueue{agent: libAgent{}, group: cfg.Group}, nil
 }
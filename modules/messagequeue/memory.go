@@ -0,0 +1,121 @@
+package messagequeue
+
+import (
+	"context"
+	"sync"
+
+	"code.gitea.io/gitea/modules/log"
+)
+
+// MemoryQueue keeps the published messages in memory and hands them to the subscriptions of the same process,
+// it is meant for the tests and the local development
+type MemoryQueue struct {
+	mu            sync.Mutex
+	messages      []*Message
+	subscriptions map[string][]*memorySubscription
+	closed        bool
+}
+
+type memorySubscription struct {
+	ctx     context.Context
+	handler Handler
+	// the messages are handed to the handler in a goroutine, so publishing never waits for a slow handler
+	ch chan *Message
+}
+
+var _ MessageQueue = &MemoryQueue{}
+
+// NewMemoryQueue creates a new in-process message queue
+func NewMemoryQueue() *MemoryQueue {
+	return &MemoryQueue{subscriptions: map[string][]*memorySubscription{}}
+}
+
+// Publish keeps the message and hands it to the subscriptions of the topic
+func (q *MemoryQueue) Publish(_ context.Context, topic string, header map[string]string, body []byte) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return ErrClosed
+	}
+
+	msg := &Message{Topic: topic, Header: header, Body: string(body)}
+	q.messages = append(q.messages, msg)
+	for _, s := range q.subscriptions[topic] {
+		select {
+		case s.ch <- msg:
+		case <-s.ctx.Done():
+		}
+	}
+	return nil
+}
+
+// Subscribe hands the messages published to the topic to the handler
+func (q *MemoryQueue) Subscribe(ctx context.Context, topic string, handler Handler) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return ErrClosed
+	}
+
+	s := &memorySubscription{ctx: ctx, handler: handler, ch: make(chan *Message, 100)}
+	q.subscriptions[topic] = append(q.subscriptions[topic], s)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				q.unsubscribe(topic, s)
+				return
+			case msg, ok := <-s.ch:
+				if !ok || ctx.Err() != nil {
+					return
+				}
+				if err := handler(ctx, msg); err != nil {
+					log.Error("Unable to handle the message of topic %s: %v", topic, err)
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+func (q *MemoryQueue) unsubscribe(topic string, s *memorySubscription) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	subscriptions := q.subscriptions[topic]
+	for i := range subscriptions {
+		if subscriptions[i] == s {
+			q.subscriptions[topic] = append(subscriptions[:i], subscriptions[i+1:]...)
+			return
+		}
+	}
+}
+
+// Close stops all subscriptions
+func (q *MemoryQueue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return nil
+	}
+	q.closed = true
+	for _, subscriptions := range q.subscriptions {
+		for _, s := range subscriptions {
+			close(s.ch)
+		}
+	}
+	q.subscriptions = nil
+	return nil
+}
+
+// Messages returns the messages published to the topic in publishing order, all messages if topic is empty
+func (q *MemoryQueue) Messages(topic string) []*Message {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	messages := make([]*Message, 0, len(q.messages))
+	for _, m := range q.messages {
+		if topic == "" || m.Topic == topic {
+			messages = append(messages, m)
+		}
+	}
+	return messages
+}
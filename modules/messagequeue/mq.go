@@ -1,10 +1,57 @@
 package messagequeue
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
 )
 
+// ErrNotInitialized is returned when a message is published before the message queue is initialized
+var ErrNotInitialized = errors.New("message queue is not initialized")
+
+// ErrClosed is returned when the message queue is used after it has been closed
+var ErrClosed = errors.New("message queue is closed")
+
+// Message is a message published to a topic
+type Message struct {
+	Topic  string            `json:"topic"`
+	Header map[string]string `json:"header,omitempty"`
+	Body   string            `json:"body"`
+}
+
+// Handler handles a message received by a subscription
+type Handler func(ctx context.Context, msg *Message) error
+
+// MessageQueue is a broker the repository events are published to
+type MessageQueue interface {
+	// Publish publishes a message to the topic, it only returns once the broker has accepted the message
+	Publish(ctx context.Context, topic string, header map[string]string, body []byte) error
+	// Subscribe calls the handler for the messages published to the topic after Subscribe has returned,
+	// in publishing order, until ctx is done or the message queue is closed
+	Subscribe(ctx context.Context, topic string, handler Handler) error
+	// Close releases the connections and stops the subscriptions
+	Close() error
+}
+
+// The supported message queue types
+const (
+	TypeKafka  = "kafka"
+	TypeRedis  = "redis"
+	TypeNATS   = "nats"
+	TypeMemory = "memory"
+	TypeFile   = "file"
+)
+
+var messageQueue MessageQueue
+
 // Init the message queen, (ex: ActiveMQ、RocketMQ、RabbitMQ、Kafka)
 func Init() (err error) {
 	if setting.MQ == nil {
@@ -12,5 +59,66 @@ func Init() (err error) {
 	}
 	log.Info("Initialising message queen with type: %s", setting.MQ.MessageType)
 
-	return newKafkaMessageQueue(*setting.MQ)
+	messageQueue, err = NewMessageQueue(*setting.MQ)
+	return err
+}
+
+// NewMessageQueue creates the message queue of the type of the configuration
+func NewMessageQueue(cfg setting.MQConfig) (MessageQueue, error) {
+	switch strings.ToLower(cfg.MessageType) {
+	case "", TypeKafka:
+		return newKafkaMessageQueue(cfg)
+	case TypeRedis:
+		return NewRedisQueue(cfg.ServerAddr, cfg.MaxLen), nil
+	case TypeNATS:
+		return NewNATSQueue(cfg)
+	case TypeMemory:
+		return NewMemoryQueue(), nil
+	case TypeFile:
+		return NewFileQueue(cfg.DataDir)
+	}
+	return nil, fmt.Errorf("unsupported message queue type: %q", cfg.MessageType)
+}
+
+// SetMessageQueue replaces the message queue the messages are published to, it returns a function to restore the previous one
+func SetMessageQueue(q MessageQueue) func() {
+	old := messageQueue
+	messageQueue = q
+	return func() {
+		messageQueue = old
+	}
+}
+
+// Publish publishes v as JSON to the topic, the trace context of ctx is propagated in the header
+func Publish(ctx context.Context, topic string, v interface{}, header map[string]string) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return PublishMessage(ctx, topic, WithTraceContext(ctx, header), body)
+}
+
+// PublishMessage publishes an already encoded message to the topic
+func PublishMessage(ctx context.Context, topic string, header map[string]string, body []byte) error {
+	if messageQueue == nil {
+		return ErrNotInitialized
+	}
+	return messageQueue.Publish(ctx, topic, header, body)
+}
+
+// Subscribe subscribes the handler to the topic of the message queue
+func Subscribe(ctx context.Context, topic string, handler Handler) error {
+	if messageQueue == nil {
+		return ErrNotInitialized
+	}
+	return messageQueue.Subscribe(ctx, topic, handler)
+}
+
+// WithTraceContext adds the W3C trace context of ctx to the header, so the consumers can continue the trace
+func WithTraceContext(ctx context.Context, header map[string]string) map[string]string {
+	if header == nil {
+		header = make(map[string]string)
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(header))
+	return header
 }
@@ -0,0 +1,98 @@
+package messagequeue
+
+import (
+	"context"
+	"crypto/tls"
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/nats-io/nats.go"
+)
+
+// natsFlushTimeout is how long a publishing waits for the server to acknowledge the messages if ctx has no deadline
+const natsFlushTimeout = 10 * time.Second
+
+// NATSQueue publishes the messages to the NATS subjects named after the topics, the header is sent as NATS headers
+type NATSQueue struct {
+	conn *nats.Conn
+}
+
+var _ MessageQueue = &NATSQueue{}
+
+// NewNATSQueue connects to the NATS servers of the configuration
+func NewNATSQueue(cfg setting.MQConfig) (*NATSQueue, error) {
+	opts := []nats.Option{nats.Name("gitea")}
+	if cfg.Username != "" {
+		opts = append(opts, nats.UserInfo(cfg.Username, cfg.Password))
+	}
+	if cfg.SkipCertVerify {
+		opts = append(opts, nats.Secure(&tls.Config{InsecureSkipVerify: true}))
+	}
+	conn, err := nats.Connect(cfg.ServerAddr, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &NATSQueue{conn: conn}, nil
+}
+
+// Publish publishes the message and waits until the server has received it
+func (q *NATSQueue) Publish(ctx context.Context, topic string, header map[string]string, body []byte) error {
+	msg := nats.NewMsg(topic)
+	msg.Data = body
+	for k, v := range header {
+		msg.Header[k] = []string{v}
+	}
+	if err := q.conn.PublishMsg(msg); err != nil {
+		return err
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, natsFlushTimeout)
+		defer cancel()
+	}
+	return q.conn.FlushWithContext(ctx)
+}
+
+// Subscribe subscribes to the subject of the topic
+func (q *NATSQueue) Subscribe(ctx context.Context, topic string, handler Handler) error {
+	sub, err := q.conn.Subscribe(topic, func(m *nats.Msg) {
+		if ctx.Err() != nil {
+			return
+		}
+		msg := &Message{Topic: topic, Body: string(m.Data)}
+		if len(m.Header) > 0 {
+			msg.Header = make(map[string]string, len(m.Header))
+			for k := range m.Header {
+				msg.Header[k] = m.Header.Get(k)
+			}
+		}
+		if err := handler(ctx, msg); err != nil {
+			log.Error("Unable to handle the message of topic %s: %v", topic, err)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	// make sure the server knows the subscription before returning
+	if err := q.conn.Flush(); err != nil {
+		_ = sub.Unsubscribe()
+		return err
+	}
+
+	go func() {
+		<-ctx.Done()
+		if err := sub.Unsubscribe(); err != nil && !q.conn.IsClosed() {
+			log.Error("Unable to unsubscribe from the NATS subject %s: %v", topic, err)
+		}
+	}()
+	return nil
+}
+
+// Close closes the connection, which ends all subscriptions
+func (q *NATSQueue) Close() error {
+	q.conn.Close()
+	return nil
+}
@@ -0,0 +1,139 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package messagequeue
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// natsStandIn is a minimal NATS server speaking the core protocol with headers,
+// it is enough to run the conformance tests without a nats-server binary
+type natsStandIn struct {
+	listener net.Listener
+
+	mu   sync.Mutex
+	subs map[string]map[*natsStandInConn][]string // subject -> connection -> sids
+}
+
+type natsStandInConn struct {
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func (c *natsStandInConn) write(s string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, _ = io.WriteString(c.conn, s)
+}
+
+func runNATSStandIn(t *testing.T) *natsStandIn {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &natsStandIn{listener: listener, subs: map[string]map[*natsStandInConn][]string{}}
+	t.Cleanup(func() { _ = listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(&natsStandInConn{conn: conn})
+		}
+	}()
+	return s
+}
+
+func (s *natsStandIn) Addr() string {
+	return s.listener.Addr().String()
+}
+
+func (s *natsStandIn) serve(c *natsStandInConn) {
+	defer c.conn.Close()
+	defer s.unsubscribeAll(c)
+
+	c.write(`INFO {"server_id":"standin","version":"2.10.0","proto":1,"headers":true,"max_payload":1048576}` + "\r\n")
+	r := bufio.NewReader(c.conn)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch strings.ToUpper(fields[0]) {
+		case "PING":
+			c.write("PONG\r\n")
+		case "SUB":
+			// SUB <subject> [queue] <sid>
+			s.subscribe(c, fields[1], fields[len(fields)-1])
+		case "UNSUB":
+			s.unsubscribe(c, fields[1])
+		case "PUB", "HPUB":
+			// PUB <subject> [reply] <#bytes>, HPUB <subject> [reply] <#header bytes> <#total bytes>
+			total, _ := strconv.Atoi(fields[len(fields)-1])
+			payload := make([]byte, total+2)
+			if _, err := io.ReadFull(r, payload); err != nil {
+				return
+			}
+			s.deliver(fields, payload[:total])
+		}
+	}
+}
+
+func (s *natsStandIn) subscribe(c *natsStandInConn, subject, sid string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.subs[subject] == nil {
+		s.subs[subject] = map[*natsStandInConn][]string{}
+	}
+	s.subs[subject][c] = append(s.subs[subject][c], sid)
+}
+
+func (s *natsStandIn) unsubscribe(c *natsStandInConn, sid string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, conns := range s.subs {
+		sids := conns[c]
+		for i := range sids {
+			if sids[i] == sid {
+				conns[c] = append(sids[:i], sids[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+func (s *natsStandIn) unsubscribeAll(c *natsStandInConn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, conns := range s.subs {
+		delete(conns, c)
+	}
+}
+
+func (s *natsStandIn) deliver(fields []string, payload []byte) {
+	subject := fields[1]
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for c, sids := range s.subs[subject] {
+		for _, sid := range sids {
+			if strings.ToUpper(fields[0]) == "HPUB" {
+				c.write(fmt.Sprintf("HMSG %s %s %s %s\r\n%s\r\n", subject, sid, fields[len(fields)-2], fields[len(fields)-1], payload))
+			} else {
+				c.write(fmt.Sprintf("MSG %s %s %s\r\n%s\r\n", subject, sid, fields[len(fields)-1], payload))
+			}
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package messagequeue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/nosql"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisBlockTimeout is how long a subscription waits for new messages before it checks whether it has been stopped
+const redisBlockTimeout = time.Second
+
+// RedisQueue publishes the messages to a redis stream per topic,
+// the header is kept as JSON in the "header" field and the body in the "body" field of the entries
+type RedisQueue struct {
+	connection string
+	client     redis.UniversalClient
+	maxLen     int64
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+var _ MessageQueue = &RedisQueue{}
+
+// NewRedisQueue creates a message queue for the redis connection string, the streams are trimmed to about maxLen entries if it is positive
+func NewRedisQueue(connection string, maxLen int64) *RedisQueue {
+	return &RedisQueue{
+		connection: connection,
+		client:     nosql.GetManager().GetRedisClient(connection),
+		maxLen:     maxLen,
+		closed:     make(chan struct{}),
+	}
+}
+
+// Publish adds the message to the stream of the topic
+func (q *RedisQueue) Publish(ctx context.Context, topic string, header map[string]string, body []byte) error {
+	h, err := json.Marshal(header)
+	if err != nil {
+		return err
+	}
+	args := &redis.XAddArgs{
+		Stream: topic,
+		Values: map[string]any{"header": string(h), "body": string(body)},
+	}
+	if q.maxLen > 0 {
+		args.MaxLen = q.maxLen
+		args.Approx = true
+	}
+	return q.client.XAdd(ctx, args).Err()
+}
+
+// Subscribe reads the stream of the topic from its current end
+func (q *RedisQueue) Subscribe(ctx context.Context, topic string, handler Handler) error {
+	lastID := "0-0"
+	entries, err := q.client.XRevRangeN(ctx, topic, "+", "-", 1).Result()
+	if err != nil {
+		return err
+	}
+	if len(entries) > 0 {
+		lastID = entries[0].ID
+	}
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-q.closed:
+				return
+			default:
+			}
+
+			streams, err := q.client.XRead(ctx, &redis.XReadArgs{
+				Streams: []string{topic, lastID},
+				Count:   100,
+				Block:   redisBlockTimeout,
+			}).Result()
+			if err != nil {
+				if !errors.Is(err, redis.Nil) && ctx.Err() == nil {
+					log.Error("Unable to read the redis stream %s: %v", topic, err)
+					time.Sleep(redisBlockTimeout)
+				}
+				continue
+			}
+			for _, stream := range streams {
+				for _, entry := range stream.Messages {
+					lastID = entry.ID
+					if ctx.Err() != nil {
+						return
+					}
+					msg := &Message{Topic: topic}
+					if h, ok := entry.Values["header"].(string); ok && h != "" {
+						if err := json.Unmarshal([]byte(h), &msg.Header); err != nil {
+							log.Error("Unable to decode the header of the entry %s of the redis stream %s: %v", entry.ID, topic, err)
+						}
+					}
+					msg.Body, _ = entry.Values["body"].(string)
+					if err := handler(ctx, msg); err != nil {
+						log.Error("Unable to handle the message of topic %s: %v", topic, err)
+					}
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// Close stops the subscriptions and releases the redis client
+func (q *RedisQueue) Close() (err error) {
+	q.closeOnce.Do(func() {
+		close(q.closed)
+		err = nosql.GetManager().CloseRedisClient(q.connection)
+	})
+	return err
+}
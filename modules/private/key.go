@@ -19,6 +19,14 @@ func UpdatePublicKeyInRepo(ctx context.Context, keyID, repoID int64) error {
 	return extra.Error
 }
 
+// RecordSSHClone counts a clone or a fetch of the repository over SSH which has received a pack
+func RecordSSHClone(ctx context.Context, keyID, repoID int64) error {
+	reqURL := setting.LocalURL + fmt.Sprintf("api/internal/ssh/%d/clone/%d", keyID, repoID)
+	req := newInternalRequest(ctx, reqURL, "POST")
+	_, extra := requestJSONResp(req, &responseText{})
+	return extra.Error
+}
+
 // AuthorizedPublicKeyByContent searches content as prefix (leak e-mail part)
 // and returns public key found.
 func AuthorizedPublicKeyByContent(ctx context.Context, content string) (string, ResponseExtra) {
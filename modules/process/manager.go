@@ -11,6 +11,8 @@ import (
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 // TODO: This packages still uses a singleton for the Manager.
@@ -37,6 +39,12 @@ const PPIDPProfLabel = "ppid"
 // ProcessTypePProfLabel is a label set on goroutines that have a process attached
 const ProcessTypePProfLabel = "process-type"
 
+// TraceIDPProfLabel is a label set on goroutines that have a process attached within a sampled trace
+const TraceIDPProfLabel = "trace_id"
+
+// SpanIDPProfLabel is a label set on goroutines that have a process attached within a sampled trace
+const SpanIDPProfLabel = "span_id"
+
 // IDType is a pid type
 type IDType string
 
@@ -187,7 +195,12 @@ func (pm *Manager) Add(ctx context.Context, description string, cancel context.C
 
 	Trace(true, pid, description, parentPID, processType)
 
-	pprofCtx := pprof.WithLabels(ctx, pprof.Labels(DescriptionPProfLabel, description, PPIDPProfLabel, string(parentPID), PIDPProfLabel, string(pid), ProcessTypePProfLabel, processType))
+	labels := []string{DescriptionPProfLabel, description, PPIDPProfLabel, string(parentPID), PIDPProfLabel, string(pid), ProcessTypePProfLabel, processType}
+	// the logger stamps the events with the span of the process
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		labels = append(labels, TraceIDPProfLabel, sc.TraceID().String(), SpanIDPProfLabel, sc.SpanID().String())
+	}
+	pprofCtx := pprof.WithLabels(ctx, pprof.Labels(labels...))
 	if currentlyRunning {
 		pprof.SetGoroutineLabels(pprofCtx)
 	}
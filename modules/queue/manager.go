@@ -10,6 +10,10 @@ import (
 
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 )
 
 // Manager is a manager for the queues created by "CreateXxxQueue" functions, these queues are called "managed queues".
@@ -44,6 +48,33 @@ func init() {
 	manager = &Manager{
 		Queues: make(map[int64]ManagedWorkerPoolQueue),
 	}
+	manager.registerMetrics()
+}
+
+// registerMetrics observes the number of items and active workers of the managed queues when the metrics are collected
+func (m *Manager) registerMetrics() {
+	meter := otel.Meter(meterName)
+	length, err := meter.Int64ObservableGauge("gitea.queue.length", metric.WithDescription("Number of items waiting in the queue"))
+	if err != nil {
+		log.Error("Unable to create the queue length gauge: %v", err)
+		return
+	}
+	workers, err := meter.Int64ObservableGauge("gitea.queue.workers.active", metric.WithDescription("Number of workers handling items of the queue"))
+	if err != nil {
+		log.Error("Unable to create the queue workers gauge: %v", err)
+		return
+	}
+	_, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		for _, q := range m.ManagedQueues() {
+			attrs := metric.WithAttributes(attribute.String("queue.name", q.GetName()), attribute.String("queue.type", q.GetType()))
+			o.ObserveInt64(length, int64(q.GetQueueItemNumber()), attrs)
+			o.ObserveInt64(workers, int64(q.GetWorkerActiveNumber()), attrs)
+		}
+		return nil
+	}, length, workers)
+	if err != nil {
+		log.Error("Unable to register the queue metrics: %v", err)
+	}
 }
 
 func GetManager() *Manager {
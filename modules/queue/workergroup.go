@@ -5,12 +5,23 @@ package queue
 
 import (
 	"context"
+	"fmt"
 	"runtime/pprof"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"code.gitea.io/gitea/modules/log"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	tracerName = "gitea_queue_tracer"
+	meterName  = "gitea_queue_meter"
 )
 
 var (
@@ -95,7 +106,21 @@ func (q *WorkerPoolQueue[T]) doWorkerHandle(batch []T) {
 		q.workerNumMu.Unlock()
 	}()
 
+	_, span := otel.Tracer(tracerName).Start(q.ctxRun, "queue "+q.GetName(),
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("queue.name", q.GetName()),
+			attribute.String("queue.type", q.GetType()),
+			attribute.Int("queue.batch_size", len(batch)),
+		),
+	)
 	unhandled := q.safeHandler(batch...)
+	span.SetAttributes(attribute.Int("queue.unhandled", len(unhandled)))
+	if len(unhandled) > 0 {
+		span.SetStatus(codes.Error, fmt.Sprintf("%d of %d items unhandled", len(unhandled), len(batch)))
+	}
+	span.End()
+
 	// if none of the items were handled, it should back-off for a few seconds
 	// in this case the handler (eg: document indexer) may have encountered some errors/failures
 	if len(unhandled) == len(batch) && unhandledItemRequeueDuration.Load() != 0 {
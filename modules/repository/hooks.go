@@ -10,7 +10,6 @@ import (
 	"runtime"
 
 	"code.gitea.io/gitea/modules/git"
-	"code.gitea.io/gitea/modules/repository/configHooks"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/util"
 )
@@ -115,18 +114,10 @@ func CreateDelegateHooks(repoPath string) (err error) {
 	hookNames, hookTpls, giteaHookTpls := getHookTemplates()
 	hookDir := filepath.Join(repoPath, "hooks")
 
-	checkLicenseHook := &configHooks.CheckLicense{
-		Name:"checkLicense",
-	}
-	fileSizeMaxHook := &configHooks.FileSizeLimit{
-		Name:"fileSizeMax",
-	}
 	registry, err = NewHookRegistry()
 	if err != nil {
 		return err
 	}
-	registry.RegisterHook("pre-receive", checkLicenseHook)
-	registry.RegisterHook("pre-receive", fileSizeMaxHook)
 
 	for i, hookName := range hookNames {
 		oldHookPath := filepath.Join(hookDir, hookName)
@@ -159,6 +150,9 @@ func CreateDelegateHooks(repoPath string) (err error) {
 			return fmt.Errorf("Unable to set %s executable. Error %w", oldHookPath, err)
 		}
 
+		if err = removeObsoleteConfigHooks(hookDir, hookName); err != nil {
+			return err
+		}
 		registry.RunCreateConfigHooks(hookDir, hookName)
 
 	}
@@ -166,6 +160,22 @@ func CreateDelegateHooks(repoPath string) (err error) {
 	return nil
 }
 
+// obsoleteConfigHooks are the config hooks replaced by the checks of the internal hook API
+var obsoleteConfigHooks = map[string][]string{
+	// the license and the file size are validated with the push policies in the pre-receive hook
+	"pre-receive": {"checkLicense", "fileSizeMax"},
+}
+
+func removeObsoleteConfigHooks(hookDir, hookName string) error {
+	for _, name := range obsoleteConfigHooks[hookName] {
+		hookPath := filepath.Join(hookDir, hookName+".d", name)
+		if err := util.Remove(hookPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("unable to remove obsolete hook file '%s': %w", hookPath, err)
+		}
+	}
+	return nil
+}
+
 func checkExecutable(filename string) bool {
 	// windows has no concept of a executable bit
 	if runtime.GOOS == "windows" {
@@ -17,6 +17,7 @@ var (
 		LogStorage            *Storage // how the created logs should be stored
 		ArtifactStorage       *Storage // how the created artifacts should be stored
 		ArtifactRetentionDays int64    `ini:"ARTIFACT_RETENTION_DAYS"`
+		ArtifactMaxSize       int64    `ini:"-"` // max total size in bytes of the artifacts of a repository, 0 for no limit
 		Enabled               bool
 		DefaultActionsURL     defaultActionsURL `ini:"DEFAULT_ACTIONS_URL"`
 		ZombieTaskTimeout     time.Duration     `ini:"ZOMBIE_TASK_TIMEOUT"`
@@ -85,6 +86,7 @@ func loadActionsFrom(rootCfg ConfigProvider) error {
 	if Actions.ArtifactRetentionDays <= 0 {
 		Actions.ArtifactRetentionDays = 90
 	}
+	Actions.ArtifactMaxSize = max(mustBytes(sec, "ARTIFACT_MAX_SIZE"), 0)
 
 	Actions.ZombieTaskTimeout = sec.Key("ZOMBIE_TASK_TIMEOUT").MustDuration(10 * time.Minute)
 	Actions.EndlessTaskTimeout = sec.Key("ENDLESS_TASK_TIMEOUT").MustDuration(3 * time.Hour)
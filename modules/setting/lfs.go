@@ -22,6 +22,8 @@ var LFS = struct {
 	LocksPagingNum  int           `ini:"LFS_LOCKS_PAGING_NUM"`
 
 	Storage *Storage
+	// ArchiveStorage stores the objects which haven't been accessed for a while, see the archive_lfs_objects cron task
+	ArchiveStorage *Storage
 }{}
 
 func loadLFSFrom(rootCfg ConfigProvider) error {
@@ -50,6 +52,12 @@ func loadLFSFrom(rootCfg ConfigProvider) error {
 		return err
 	}
 
+	lfsArchiveSec, _ := rootCfg.GetSection("lfs_archive")
+	LFS.ArchiveStorage, err = getStorage(rootCfg, "lfs_archive", "", lfsArchiveSec)
+	if err != nil {
+		return err
+	}
+
 	// Rest of LFS service settings
 	if LFS.LocksPagingNum == 0 {
 		LFS.LocksPagingNum = 50
@@ -99,3 +99,22 @@ STORAGE_TYPE = minio
 	assert.EqualValues(t, "gitea", LFS.Storage.MinioConfig.Bucket)
 	assert.EqualValues(t, "lfs/", LFS.Storage.MinioConfig.BasePath)
 }
+
+func Test_LFSArchiveStorage(t *testing.T) {
+	iniStr := `
+[storage]
+STORAGE_TYPE = minio
+MINIO_STORAGE_CLASS = STANDARD
+[lfs_archive]
+MINIO_STORAGE_CLASS = GLACIER
+`
+	cfg, err := NewConfigProviderFromData(iniStr)
+	assert.NoError(t, err)
+	assert.NoError(t, loadLFSFrom(cfg))
+
+	assert.EqualValues(t, "minio", LFS.Storage.Type)
+	assert.EqualValues(t, "STANDARD", LFS.Storage.MinioConfig.StorageClass)
+	assert.EqualValues(t, "minio", LFS.ArchiveStorage.Type)
+	assert.EqualValues(t, "lfs_archive/", LFS.ArchiveStorage.MinioConfig.BasePath)
+	assert.EqualValues(t, "GLACIER", LFS.ArchiveStorage.MinioConfig.StorageClass)
+}
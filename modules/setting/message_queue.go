@@ -1,6 +1,11 @@
 package setting
 
-import "fmt"
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
 
 const mqSectionName = "message"
 
@@ -19,6 +24,12 @@ type MQConfig struct {
 	Algorithm      string `ini:"ALGORITHM"           json:",omitempty"`
 	SkipCertVerify bool   `ini:"SKIP_CERT_VERIFY"    json:",omitempty"`
 	OTEL           bool   `ini:"OTEL"                json:",omitempty"`
+	// Group is the consumer group of the kafka subscriptions
+	Group string `ini:"GROUP" json:",omitempty"`
+	// MaxLen caps the length of the redis streams approximately, 0 keeps all messages
+	MaxLen int64 `ini:"MAX_LEN" json:",omitempty"`
+	// DataDir is the directory of the file message queue
+	DataDir string `ini:"DATA_DIR" json:",omitempty"`
 }
 
 // loadMQFrom loads message queue configuration from the given root configuration provider
@@ -28,6 +39,7 @@ func loadMQFrom(rootCfg ConfigProvider) error {
 		fmt.Printf("Section: %v, message publish functionability will be disabled\n", err)
 		return nil
 	}
+	loadMQEventsFrom(rootCfg)
 
 	if MQ == nil {
 		MQ = new(MQConfig)
@@ -36,6 +48,66 @@ func loadMQFrom(rootCfg ConfigProvider) error {
 	if err := sec.MapTo(MQ); err != nil {
 		return fmt.Errorf("failed to map message queue settings: %v", err)
 	}
+	if MQ.Group == "" {
+		MQ.Group = "gitea"
+	}
+	if MQ.DataDir == "" {
+		MQ.DataDir = "messagequeue"
+	}
+	if !filepath.IsAbs(MQ.DataDir) {
+		MQ.DataDir = filepath.Join(AppDataPath, MQ.DataDir)
+	}
+	loadMQOutboxFrom(rootCfg)
 
 	return nil
 }
+
+// MQEventCategories are the categories of the repository activity events, every category may be published to its own topic
+var MQEventCategories = []string{"push", "repository", "release", "issue", "pull_request", "lfs", "package"}
+
+// MQEvents represents the configuration of the repository activity events published to the message queue
+var MQEvents = struct {
+	Enabled bool
+	// Topics maps the event categories to their topics, the events of a category without a topic are not published
+	Topics map[string]string
+}{
+	Topics: map[string]string{},
+}
+
+func loadMQEventsFrom(rootCfg ConfigProvider) {
+	sec := rootCfg.Section(mqSectionName + ".events")
+	MQEvents.Enabled = sec.Key("ENABLED").MustBool(false)
+	defaultTopic := sec.Key("DEFAULT_TOPIC").String()
+
+	MQEvents.Topics = make(map[string]string, len(MQEventCategories))
+	for _, category := range MQEventCategories {
+		if topic := sec.Key(strings.ToUpper(category) + "_TOPIC").MustString(defaultTopic); topic != "" {
+			MQEvents.Topics[category] = topic
+		}
+	}
+}
+
+// MQOutbox represents the configuration of the outbox the messages are written to before they are published
+var MQOutbox = struct {
+	// MaxAttempts is the number of failed publishing attempts after which a message is considered stuck
+	MaxAttempts int
+	// RetryDelay is the delay after the first failed attempt, it doubles with every following one
+	RetryDelay time.Duration
+	// MaxRetryDelay caps the delay between two attempts
+	MaxRetryDelay time.Duration
+	// PollInterval is how often the outbox is checked for the messages due to be retried
+	PollInterval time.Duration
+}{
+	MaxAttempts:   10,
+	RetryDelay:    10 * time.Second,
+	MaxRetryDelay: time.Hour,
+	PollInterval:  30 * time.Second,
+}
+
+func loadMQOutboxFrom(rootCfg ConfigProvider) {
+	sec := rootCfg.Section(mqSectionName + ".outbox")
+	MQOutbox.MaxAttempts = sec.Key("MAX_ATTEMPTS").MustInt(10)
+	MQOutbox.RetryDelay = sec.Key("RETRY_DELAY").MustDuration(10 * time.Second)
+	MQOutbox.MaxRetryDelay = sec.Key("MAX_RETRY_DELAY").MustDuration(time.Hour)
+	MQOutbox.PollInterval = sec.Key("POLL_INTERVAL").MustDuration(30 * time.Second)
+}
@@ -0,0 +1,24 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package setting
+
+// Metadata settings of the YAML front matter of the model cards (README.md)
+var Metadata = struct {
+	// Enabled validates the front matter of the README.md pushed to the repositories
+	Enabled bool
+	// Licenses are the allowed values of the license field by default, they may be overridden per organization
+	// or repository by the push policies. Any license is allowed if it is empty.
+	Licenses []string
+	// SchemaFile is a YAML file describing the rules of the front matter fields
+	SchemaFile string
+}{
+	Enabled: true,
+}
+
+func loadMetadataFrom(rootCfg ConfigProvider) {
+	sec := rootCfg.Section("merlin")
+	Metadata.Enabled = sec.Key("METADATA_CHECK").MustBool(true)
+	Metadata.Licenses = sec.Key("LICENSE").Strings(",")
+	Metadata.SchemaFile = sec.Key("METADATA_SCHEMA_FILE").String()
+}
@@ -1,11 +1,40 @@
 package setting
 
 import (
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+
 	"gitee.com/modelers/moderation-server-sdk/httpclient"
 )
 
 var MaxTextLen int
 
+// Moderation settings
+var Moderation = struct {
+	// Provider is the registered moderator used to check the texts: sdk, dictionary, webhook or none
+	Provider string
+	// FailOpen accepts the content when the moderator can not be reached, otherwise the content is rejected
+	FailOpen bool
+	// ContentReview enables the moderation of the text files pushed to the repositories
+	ContentReview bool
+	// ContentReviewMaxSize is the max size of the text files to be moderated, larger files are skipped
+	ContentReviewMaxSize int64
+
+	DictionaryFile     string
+	DictionaryKeywords []string
+
+	WebhookURL         string
+	WebhookToken       string
+	WebhookTokenHeader string
+	WebhookTimeout     time.Duration
+}{
+	Provider:             "sdk",
+	ContentReview:        true,
+	ContentReviewMaxSize: 1024 * 1024,
+}
+
 // Init is for http client init
 func loadModerationFrom(rootCfg ConfigProvider) {
 	sec := rootCfg.Section("moderation_server")
@@ -18,7 +47,28 @@ func loadModerationFrom(rootCfg ConfigProvider) {
 		}
 		httpclient.Init(cfg)
 	}
-	MaxTextLen = rootCfg.Section("merlin").Key("MAX_TEXT_LEN").MustInt()
+
+	merlinSec := rootCfg.Section("merlin")
+	MaxTextLen = merlinSec.Key("MAX_TEXT_LEN").MustInt(1500)
+	Moderation.Provider = strings.ToLower(merlinSec.Key("MODERATOR").MustString("sdk"))
+	switch policy := strings.ToLower(merlinSec.Key("MODERATION_FAILURE_POLICY").MustString("closed")); policy {
+	case "open":
+		Moderation.FailOpen = true
+	case "closed":
+		Moderation.FailOpen = false
+	default:
+		log.Fatal("Invalid [merlin] MODERATION_FAILURE_POLICY %q, it should be open or closed", policy)
+	}
+
+	Moderation.ContentReview = merlinSec.Key("CONTENT_REVIEW").MustBool(true)
+	Moderation.ContentReviewMaxSize = merlinSec.Key("CONTENT_REVIEW_MAX_SIZE").MustInt64(1024 * 1024)
+
+	Moderation.DictionaryFile = sec.Key("DICTIONARY_FILE").String()
+	Moderation.DictionaryKeywords = sec.Key("DICTIONARY_KEYWORDS").Strings(",")
+	Moderation.WebhookURL = sec.Key("WEBHOOK_URL").String()
+	Moderation.WebhookToken = sec.Key("WEBHOOK_TOKEN").String()
+	Moderation.WebhookTokenHeader = sec.Key("WEBHOOK_TOKEN_HEADER").MustString("Authorization")
+	Moderation.WebhookTimeout = sec.Key("WEBHOOK_TIMEOUT").MustDuration(10 * time.Second)
 }
 
 // Config is for http client config
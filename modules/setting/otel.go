@@ -1,11 +1,65 @@
 package setting
 
+import (
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+)
+
+// The exporters of the telemetry
+const (
+	OtelExporterOTLP   = "otlp"
+	OtelExporterStdout = "stdout"
+	OtelExporterFile   = "file"
+)
+
+// The protocols of the OTLP exporter
+const (
+	OtelProtocolGRPC = "grpc"
+	OtelProtocolHTTP = "http/protobuf"
+)
+
+// OtelSamplingRule is the sampling ratio of the traces started by the requests to a route
+type OtelSamplingRule struct {
+	// Route is a glob matching the path of the requests, e.g. /api/v1/repos/*/git/*
+	Route string
+	Ratio float64
+}
+
 // Otel settings
 type OtelConfig struct {
 	Enabled   bool
 	Endpoint  string
 	Name      string
 	Fractions float64
+
+	// Exporter is "otlp" to export to a collector, "stdout" or "file" to write the telemetry as JSON for debugging
+	Exporter string
+	// Protocol of the OTLP exporter, "grpc" or "http/protobuf"
+	Protocol string
+	// Insecure disables the TLS of the OTLP exporter
+	Insecure       bool
+	CACertFile     string
+	ClientCertFile string
+	ClientKeyFile  string
+	// Headers are sent with every export, e.g. for the authentication to the collector
+	Headers map[string]string
+	// FilePath is the file written by the "file" exporter
+	FilePath string
+
+	// SamplingRules are the sampling ratios of the traces started by the requests, the first rule matching the path
+	// of the request applies and Fractions applies to the other traces. The children spans follow their parent.
+	SamplingRules []OtelSamplingRule
+
+	// MetricsEnabled exports the metrics to the collector every MetricsInterval
+	MetricsEnabled  bool
+	MetricsInterval time.Duration
+	// LogsEndpoint is the OTLP/HTTP endpoint receiving the logs of the "otlp" log writer
+	LogsEndpoint string
 }
 
 var Otel = OtelConfig{}
@@ -17,5 +71,63 @@ func loadOtelFrom(rootCfg ConfigProvider) {
 		Otel.Endpoint = sec.Key("ENDPOINT").String()
 		Otel.Name = sec.Key("NAME").MustString("gitea")
 		Otel.Fractions = sec.Key("FRACTIONS").MustFloat64()
+		Otel.Exporter = sec.Key("EXPORTER").In(OtelExporterOTLP, []string{OtelExporterOTLP, OtelExporterStdout, OtelExporterFile})
+		Otel.Protocol = sec.Key("PROTOCOL").In(OtelProtocolGRPC, []string{OtelProtocolGRPC, OtelProtocolHTTP})
+		// the exporter has always connected without TLS, it stays the default for the existing configurations
+		Otel.Insecure = sec.Key("INSECURE").MustBool(true)
+		Otel.CACertFile = sec.Key("CA_CERT_FILE").String()
+		Otel.ClientCertFile = sec.Key("CLIENT_CERT_FILE").String()
+		Otel.ClientKeyFile = sec.Key("CLIENT_KEY_FILE").String()
+		Otel.Headers = parseOtelHeaders(sec.Key("HEADERS").String())
+		Otel.FilePath = sec.Key("FILE_PATH").MustString(filepath.Join(Log.RootPath, "otel.json"))
+		if !filepath.IsAbs(Otel.FilePath) {
+			Otel.FilePath = filepath.Join(Log.RootPath, Otel.FilePath)
+		}
+		Otel.MetricsEnabled = sec.Key("METRICS_ENABLED").MustBool(true)
+		Otel.MetricsInterval = sec.Key("METRICS_INTERVAL").MustDuration(time.Minute)
+		defaultLogsEndpoint := Otel.Endpoint
+		if Otel.Protocol == OtelProtocolGRPC {
+			defaultLogsEndpoint = defaultOtelLogsEndpoint(Otel.Endpoint)
+		}
+		Otel.LogsEndpoint = sec.Key("LOGS_ENDPOINT").MustString(defaultLogsEndpoint)
+		Otel.SamplingRules = loadOtelSamplingRules(rootCfg)
+	}
+}
+
+// defaultOtelLogsEndpoint returns the endpoint of the collector with the default OTLP/HTTP port,
+// the collectors receive the traces over gRPC on 4317 and the logs over HTTP on 4318
+func defaultOtelLogsEndpoint(endpoint string) string {
+	host, _, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		host = endpoint
+	}
+	return net.JoinHostPort(host, "4318")
+}
+
+// parseOtelHeaders parses the headers in the "key1=value1,key2=value2" format of OTEL_EXPORTER_OTLP_HEADERS
+func parseOtelHeaders(s string) map[string]string {
+	headers := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		k, v, ok := strings.Cut(pair, "=")
+		if k = strings.TrimSpace(k); !ok || k == "" {
+			continue
+		}
+		headers[k] = strings.TrimSpace(v)
+	}
+	return headers
+}
+
+// loadOtelSamplingRules loads the [otel.sampling] section, its keys are the route globs and its values the ratios
+func loadOtelSamplingRules(rootCfg ConfigProvider) []OtelSamplingRule {
+	keys := rootCfg.Section("otel.sampling").Keys()
+	rules := make([]OtelSamplingRule, 0, len(keys))
+	for _, key := range keys {
+		ratio, err := strconv.ParseFloat(key.Value(), 64)
+		if err != nil || ratio < 0 || ratio > 1 {
+			log.Error("Invalid sampling ratio %q of the route %q in [otel.sampling], it must be between 0 and 1", key.Value(), key.Name())
+			continue
+		}
+		rules = append(rules, OtelSamplingRule{Route: key.Name(), Ratio: ratio})
 	}
+	return rules
 }
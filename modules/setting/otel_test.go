@@ -0,0 +1,63 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package setting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadOtelFrom(t *testing.T) {
+	oldOtel := Otel
+	defer func() {
+		Otel = oldOtel
+	}()
+
+	cfg, err := NewConfigProviderFromData(`
+[otel]
+ENABLED = true
+ENDPOINT = collector:4317
+`)
+	assert.NoError(t, err)
+	loadOtelFrom(cfg)
+	assert.Equal(t, OtelExporterOTLP, Otel.Exporter)
+	assert.Equal(t, OtelProtocolGRPC, Otel.Protocol)
+	assert.True(t, Otel.Insecure)
+	assert.Empty(t, Otel.Headers)
+	assert.Empty(t, Otel.SamplingRules)
+	assert.Equal(t, time.Minute, Otel.MetricsInterval)
+	assert.Equal(t, "collector:4318", Otel.LogsEndpoint)
+
+	cfg, err = NewConfigProviderFromData(`
+[otel]
+ENABLED = true
+ENDPOINT = collector:4318
+PROTOCOL = http/protobuf
+INSECURE = false
+CA_CERT_FILE = /etc/ssl/ca.pem
+HEADERS = authorization=Bearer token, x-tenant = gitea,invalid
+FRACTIONS = 0.1
+
+[otel.sampling]
+/api/v1/repos/*/*/git/** = 0.01
+/user/events = 0
+/api/** = 2
+/** = 1
+`)
+	assert.NoError(t, err)
+	loadOtelFrom(cfg)
+	assert.Equal(t, OtelProtocolHTTP, Otel.Protocol)
+	assert.False(t, Otel.Insecure)
+	assert.Equal(t, "/etc/ssl/ca.pem", Otel.CACertFile)
+	assert.Equal(t, map[string]string{"authorization": "Bearer token", "x-tenant": "gitea"}, Otel.Headers)
+	assert.Equal(t, "collector:4318", Otel.LogsEndpoint)
+	// the rule with an invalid ratio is ignored, the order of the others is kept
+	assert.Equal(t, []OtelSamplingRule{
+		{Route: "/api/v1/repos/*/*/git/**", Ratio: 0.01},
+		{Route: "/user/events", Ratio: 0},
+		{Route: "/**", Ratio: 1},
+	}, Otel.SamplingRules)
+}
@@ -98,6 +98,11 @@ var (
 			DefaultPagingNum int
 		} `ini:"repository.release"`
 
+		// DownloadStats settings, the clones and downloads are counted per repository and day
+		DownloadStats struct {
+			Enabled bool
+		} `ini:"repository.download_stats"`
+
 		Signing struct {
 			SigningKey        string
 			SigningName       string
@@ -242,6 +247,12 @@ var (
 			DefaultPagingNum: 10,
 		},
 
+		DownloadStats: struct {
+			Enabled bool
+		}{
+			Enabled: true,
+		},
+
 		// Signing settings
 		Signing: struct {
 			SigningKey        string
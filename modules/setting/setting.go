@@ -188,6 +188,13 @@ func BlankCfg() {
 	} else {
 		log.Info("successfully blanking moderation_server.TOKEN_SERVER to config")
 	}
+
+	saveCfg.Section("moderation_server").Key("WEBHOOK_TOKEN").SetValue("")
+	if err := saveCfg.Save(); err != nil {
+		log.Error("Unable to blanking moderation_server.WEBHOOK_TOKEN to config %q: %v\nYou should set it manually, otherwise there might be bugs when accessing the git repositories.", CustomConf, err)
+	} else {
+		log.Info("successfully blanking moderation_server.WEBHOOK_TOKEN to config")
+	}
 }
 
 func InitCfgProvider(file string, extraConfigs ...string) {
@@ -260,6 +267,7 @@ func loadCommonSettingsFrom(cfg ConfigProvider) error {
 	loadMarkupFrom(cfg)
 	loadOtherFrom(cfg)
 	loadModerationFrom(cfg)
+	loadMetadataFrom(cfg)
 	return nil
 }
 
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 // StorageType is a type of Storage
@@ -50,15 +51,32 @@ type MinioStorageConfig struct {
 	UseSSL             bool   `ini:"MINIO_USE_SSL"`
 	InsecureSkipVerify bool   `ini:"MINIO_INSECURE_SKIP_VERIFY"`
 	ChecksumAlgorithm  string `ini:"MINIO_CHECKSUM_ALGORITHM" json:",omitempty"`
+	StorageClass       string `ini:"MINIO_STORAGE_CLASS" json:",omitempty"`
 	ServeDirect        bool   `ini:"SERVE_DIRECT"`
 }
 
+// CDNConfig represents the configuration of the CDN serving the objects of a storage
+type CDNConfig struct {
+	// URL is the base url of the CDN, the path of the objects in the storage is appended to it
+	URL string `json:",omitempty"`
+	// SigningKey is the secret shared with the CDN to sign the urls
+	SigningKey string `json:",omitempty"`
+	// URLExpiry is the default validity of the signed urls
+	URLExpiry time.Duration
+	// PurgeURL receives the urls of the deleted objects to invalidate them in the cache of the CDN
+	PurgeURL   string `json:",omitempty"`
+	PurgeToken string `json:",omitempty"`
+}
+
 // Storage represents configuration of storages
 type Storage struct {
 	Type          StorageType        // local or minio
 	Path          string             `json:",omitempty"` // for local type
 	TemporaryPath string             `json:",omitempty"`
 	MinioConfig   MinioStorageConfig // for minio type
+	CDN           CDNConfig
+	// DualWrite is the storage the new objects are also written to while the storage is migrated to it
+	DualWrite *Storage `json:",omitempty"`
 }
 
 func (storage *Storage) ToShadowCopy() Storage {
@@ -69,9 +87,24 @@ func (storage *Storage) ToShadowCopy() Storage {
 	if shadowStorage.MinioConfig.SecretAccessKey != "" {
 		shadowStorage.MinioConfig.SecretAccessKey = "******"
 	}
+	if shadowStorage.CDN.SigningKey != "" {
+		shadowStorage.CDN.SigningKey = "******"
+	}
+	if shadowStorage.CDN.PurgeToken != "" {
+		shadowStorage.CDN.PurgeToken = "******"
+	}
+	if shadowStorage.DualWrite != nil {
+		dualWrite := shadowStorage.DualWrite.ToShadowCopy()
+		shadowStorage.DualWrite = &dualWrite
+	}
 	return shadowStorage
 }
 
+// ServeDirect returns whether the objects are downloaded from the storage or its CDN instead of through Gitea
+func (storage *Storage) ServeDirect() bool {
+	return storage.MinioConfig.ServeDirect || storage.CDN.URL != ""
+}
+
 const storageSectionName = "storage"
 
 func getDefaultStorageSection(rootCfg ConfigProvider) ConfigSection {
@@ -92,6 +125,32 @@ func getDefaultStorageSection(rootCfg ConfigProvider) ConfigSection {
 // getStorage will find target section and extra special section first and then read override
 // items from extra section
 func getStorage(rootCfg ConfigProvider, name, typ string, sec ConfigSection) (*Storage, error) {
+	storage, err := getSingleStorage(rootCfg, name, typ, sec)
+	if err != nil {
+		return nil, err
+	}
+
+	dualWriteType := ConfigSectionKeyString(sec, "DUAL_WRITE_STORAGE_TYPE")
+	if dualWriteType == "" {
+		nameSec, _ := rootCfg.GetSection(storageSectionName + "." + name)
+		dualWriteType = ConfigSectionKeyString(nameSec, "DUAL_WRITE_STORAGE_TYPE")
+	}
+	if dualWriteType != "" {
+		if storage.DualWrite, err = getSingleStorage(rootCfg, name, dualWriteType, nil); err != nil {
+			return nil, fmt.Errorf("dual write storage %q of %s: %w", dualWriteType, name, err)
+		}
+	}
+	return storage, nil
+}
+
+// GetStorageByType returns the settings of the named storage (e.g. lfs) stored in the storage of the given type,
+// which is either a storage type or the name of a [storage.xxx] section
+func GetStorageByType(name, typ string) (*Storage, error) {
+	return getSingleStorage(CfgProvider, name, typ, nil)
+}
+
+// getSingleStorage returns the settings of the named storage without its dual write storage
+func getSingleStorage(rootCfg ConfigProvider, name, typ string, sec ConfigSection) (*Storage, error) {
 	if name == "" {
 		return nil, errors.New("no name for storage")
 	}
@@ -103,15 +162,55 @@ func getStorage(rootCfg ConfigProvider, name, typ string, sec ConfigSection) (*S
 
 	overrideSec := getStorageOverrideSection(rootCfg, targetSec, sec, tp, name)
 
+	var storage *Storage
 	targetType := targetSec.Key("STORAGE_TYPE").String()
 	switch targetType {
 	case string(LocalStorageType):
-		return getStorageForLocal(targetSec, overrideSec, tp, name)
+		storage, err = getStorageForLocal(targetSec, overrideSec, tp, name)
 	case string(MinioStorageType):
-		return getStorageForMinio(targetSec, overrideSec, tp, name)
+		storage, err = getStorageForMinio(targetSec, overrideSec, tp, name)
 	default:
 		return nil, fmt.Errorf("unsupported storage type %q", targetType)
 	}
+	if err != nil {
+		return nil, err
+	}
+
+	if storage.CDN, err = getStorageCDNConfig(targetSec, overrideSec); err != nil {
+		return nil, err
+	}
+	return storage, nil
+}
+
+// getStorageCDNConfig reads the CDN configuration of the target section, overridden by the override section
+func getStorageCDNConfig(targetSec, overrideSec ConfigSection) (CDNConfig, error) {
+	var cfg CDNConfig
+	var expiry string
+	for _, sec := range []ConfigSection{targetSec, overrideSec} {
+		cfg.URL = ConfigSectionKeyString(sec, "CDN_URL", cfg.URL)
+		cfg.SigningKey = ConfigSectionKeyString(sec, "CDN_SIGNING_KEY", cfg.SigningKey)
+		cfg.PurgeURL = ConfigSectionKeyString(sec, "CDN_PURGE_URL", cfg.PurgeURL)
+		cfg.PurgeToken = ConfigSectionKeyString(sec, "CDN_PURGE_TOKEN", cfg.PurgeToken)
+		expiry = ConfigSectionKeyString(sec, "CDN_URL_EXPIRY", expiry)
+	}
+	if cfg.URL == "" {
+		return cfg, nil
+	}
+
+	if !strings.HasSuffix(cfg.URL, "/") {
+		cfg.URL += "/"
+	}
+	if cfg.SigningKey == "" {
+		return cfg, fmt.Errorf("CDN_SIGNING_KEY is required with CDN_URL %q", cfg.URL)
+	}
+	cfg.URLExpiry = time.Hour
+	if expiry != "" {
+		var err error
+		if cfg.URLExpiry, err = time.ParseDuration(expiry); err != nil || cfg.URLExpiry <= 0 {
+			return cfg, fmt.Errorf("invalid CDN_URL_EXPIRY %q", expiry)
+		}
+	}
+	return cfg, nil
 }
 
 type targetSecType int
@@ -270,6 +369,7 @@ func getStorageForMinio(targetSec, overrideSec ConfigSection, tp targetSecType,
 		storage.MinioConfig.ServeDirect = ConfigSectionKeyBool(overrideSec, "SERVE_DIRECT", storage.MinioConfig.ServeDirect)
 		storage.MinioConfig.BasePath = ConfigSectionKeyString(overrideSec, "MINIO_BASE_PATH", defaultPath)
 		storage.MinioConfig.Bucket = ConfigSectionKeyString(overrideSec, "MINIO_BUCKET", storage.MinioConfig.Bucket)
+		storage.MinioConfig.StorageClass = ConfigSectionKeyString(overrideSec, "MINIO_STORAGE_CLASS", storage.MinioConfig.StorageClass)
 	} else {
 		storage.MinioConfig.BasePath = defaultPath
 	}
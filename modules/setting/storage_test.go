@@ -6,6 +6,7 @@ package setting
 import (
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -465,3 +466,89 @@ MINIO_BASE_PATH = /lfs
 	assert.EqualValues(t, true, LFS.Storage.MinioConfig.UseSSL)
 	assert.EqualValues(t, "/lfs", LFS.Storage.MinioConfig.BasePath)
 }
+
+func Test_getStorageCDNConfiguration(t *testing.T) {
+	cfg, err := NewConfigProviderFromData(`
+[storage]
+CDN_URL = https://cdn.example.com/files
+CDN_SIGNING_KEY = my_signing_key
+
+[storage.repo-archive]
+CDN_URL_EXPIRY = 30m
+CDN_PURGE_URL = https://cdn.example.com/purge
+CDN_PURGE_TOKEN = my_purge_token
+`)
+	assert.NoError(t, err)
+	assert.NoError(t, loadRepoArchiveFrom(cfg))
+	assert.True(t, RepoArchive.Storage.ServeDirect())
+	assert.EqualValues(t, "https://cdn.example.com/files/", RepoArchive.Storage.CDN.URL)
+	assert.EqualValues(t, "my_signing_key", RepoArchive.Storage.CDN.SigningKey)
+	assert.EqualValues(t, 30*time.Minute, RepoArchive.Storage.CDN.URLExpiry)
+	assert.EqualValues(t, "https://cdn.example.com/purge", RepoArchive.Storage.CDN.PurgeURL)
+	assert.EqualValues(t, "my_purge_token", RepoArchive.Storage.CDN.PurgeToken)
+
+	shadow := RepoArchive.Storage.ToShadowCopy()
+	assert.EqualValues(t, "******", shadow.CDN.SigningKey)
+	assert.EqualValues(t, "******", shadow.CDN.PurgeToken)
+
+	assert.NoError(t, loadLFSFrom(cfg))
+	assert.EqualValues(t, time.Hour, LFS.Storage.CDN.URLExpiry)
+	assert.Empty(t, LFS.Storage.CDN.PurgeURL)
+
+	cfg, err = NewConfigProviderFromData(`
+[storage.repo-archive]
+CDN_URL = https://cdn.example.com/
+`)
+	assert.NoError(t, err)
+	assert.Error(t, loadRepoArchiveFrom(cfg))
+
+	cfg, err = NewConfigProviderFromData(`
+[storage.repo-archive]
+CDN_URL = https://cdn.example.com/
+CDN_SIGNING_KEY = my_signing_key
+CDN_URL_EXPIRY = never
+`)
+	assert.NoError(t, err)
+	assert.Error(t, loadRepoArchiveFrom(cfg))
+
+	cfg, err = NewConfigProviderFromData(``)
+	assert.NoError(t, err)
+	assert.NoError(t, loadRepoArchiveFrom(cfg))
+	assert.False(t, RepoArchive.Storage.ServeDirect())
+}
+
+func Test_getStorageDualWriteConfiguration(t *testing.T) {
+	cfg, err := NewConfigProviderFromData(`
+[storage]
+STORAGE_TYPE = minio
+MINIO_BUCKET = gitea
+
+[storage.obs]
+STORAGE_TYPE = minio
+MINIO_ENDPOINT = obs.example.com
+MINIO_BUCKET = gitea-obs
+MINIO_BUCKET_DOMAIN = gitea-obs.obs.example.com
+
+[lfs]
+DUAL_WRITE_STORAGE_TYPE = obs
+`)
+	assert.NoError(t, err)
+	assert.NoError(t, loadLFSFrom(cfg))
+	assert.EqualValues(t, "gitea", LFS.Storage.MinioConfig.Bucket)
+	assert.NotNil(t, LFS.Storage.DualWrite)
+	assert.EqualValues(t, "minio", LFS.Storage.DualWrite.Type)
+	assert.EqualValues(t, "obs.example.com", LFS.Storage.DualWrite.MinioConfig.Endpoint)
+	assert.EqualValues(t, "gitea-obs", LFS.Storage.DualWrite.MinioConfig.Bucket)
+	assert.EqualValues(t, "lfs/", LFS.Storage.DualWrite.MinioConfig.BasePath)
+	assert.Nil(t, LFS.Storage.DualWrite.DualWrite)
+
+	assert.NoError(t, loadRepoArchiveFrom(cfg))
+	assert.Nil(t, RepoArchive.Storage.DualWrite)
+
+	cfg, err = NewConfigProviderFromData(`
+[lfs]
+DUAL_WRITE_STORAGE_TYPE = unknown
+`)
+	assert.NoError(t, err)
+	assert.Error(t, loadLFSFrom(cfg))
+}
@@ -0,0 +1,201 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/proxy"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+)
+
+const (
+	cdnPurgeTimeout = 10 * time.Second
+	// cdnPurgeInterval is how long the urls of the deleted objects are collected to be purged together
+	cdnPurgeInterval = time.Second
+	// cdnPurgeBatchSize is the maximum number of urls purged together
+	cdnPurgeBatchSize = 100
+	// cdnPurgeQueueLength is the number of urls waiting to be purged, the urls deleted beyond are not purged
+	cdnPurgeQueueLength = 10000
+)
+
+// cdnPurgeClient is the client of the purge requests, it uses the proxy of Gitea
+var cdnPurgeClient = sync.OnceValue(func() *http.Client {
+	return &http.Client{
+		Timeout: cdnPurgeTimeout,
+		Transport: &http.Transport{
+			Proxy: proxy.Proxy(),
+		},
+	}
+})
+
+// CDNPurgeHook is called with the urls of the objects deleted from a storage served by a CDN,
+// so they can be invalidated in the cache of the CDN
+type CDNPurgeHook func(ctx context.Context, cfg *setting.CDNConfig, urls []string) error
+
+var (
+	cdnPurgeHooksMu sync.RWMutex
+	cdnPurgeHooks   = []CDNPurgeHook{purgeCDNByWebhook}
+)
+
+// RegisterCDNPurgeHook adds a hook called when objects are deleted from the storages served by a CDN
+func RegisterCDNPurgeHook(hook CDNPurgeHook) {
+	cdnPurgeHooksMu.Lock()
+	defer cdnPurgeHooksMu.Unlock()
+	cdnPurgeHooks = append(cdnPurgeHooks, hook)
+}
+
+// cdn signs the urls of the objects served by the CDN in front of a storage and invalidates the deleted objects
+type cdn struct {
+	ctx context.Context
+	cfg setting.CDNConfig
+
+	purgeOnce  sync.Once
+	purgeQueue chan string
+}
+
+// newCDN returns the CDN of the storage, nil if the storage isn't served by a CDN
+func newCDN(ctx context.Context, cfg *setting.Storage) *cdn {
+	if cfg.CDN.URL == "" {
+		return nil
+	}
+	return &cdn{ctx: ctx, cfg: cfg.CDN}
+}
+
+// objectURL returns the unsigned url of the object with the given path in the storage. The key of the object in the
+// urls is its path relative to the storage, whatever the type of the storage: the base path of an object storage isn't
+// part of it, so CDN_URL is the url of the root of the storage, which includes the bucket and the MINIO_BASE_PATH of
+// an object storage.
+func (c *cdn) objectURL(path string) string {
+	return c.cfg.URL + (&url.URL{Path: util.PathJoinRelX(path)}).EscapedPath()
+}
+
+// URL returns the url of the object with the given path in the storage, signed for the expiry or the default validity
+// if zero
+func (c *cdn) URL(path, name string, expiry time.Duration) (*url.URL, error) {
+	if expiry <= 0 {
+		expiry = c.cfg.URLExpiry
+	}
+	u, err := url.Parse(c.objectURL(path))
+	if err != nil {
+		return nil, err
+	}
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(time.Now().Add(expiry).Unix(), 10))
+	if name != "" {
+		query.Set("filename", name)
+	}
+	query.Set("signature", signCDNURL(c.cfg.SigningKey, u.EscapedPath(), query))
+	u.RawQuery = query.Encode()
+	return u, nil
+}
+
+// signCDNURL returns the hex encoded HMAC-SHA256 of the escaped path and the sorted query of an url,
+// the CDN computes it the same way to check the url
+func signCDNURL(key, escapedPath string, query url.Values) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	_, _ = mac.Write([]byte(escapedPath + "?" + query.Encode()))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Purge queues the invalidation of the object with the given path in the storage in the cache of the CDN, so the
+// deletion doesn't wait for the CDN, it does nothing if c is nil
+func (c *cdn) Purge(path string) {
+	if c == nil {
+		return
+	}
+	c.purgeOnce.Do(func() {
+		c.purgeQueue = make(chan string, cdnPurgeQueueLength)
+		go c.runPurge()
+	})
+
+	u := c.objectURL(path)
+	select {
+	case c.purgeQueue <- u:
+	default:
+		log.Error("Unable to purge %s from the CDN: too many urls are waiting to be purged", u)
+	}
+}
+
+// runPurge purges the queued urls, the urls queued within cdnPurgeInterval of each other are purged together
+func (c *cdn) runPurge() {
+	for {
+		var urls []string
+		select {
+		case <-c.ctx.Done():
+			return
+		case u := <-c.purgeQueue:
+			urls = append(urls, u)
+		}
+
+		timer := time.NewTimer(cdnPurgeInterval)
+	collect:
+		for len(urls) < cdnPurgeBatchSize {
+			select {
+			case u := <-c.purgeQueue:
+				urls = append(urls, u)
+			case <-timer.C:
+				break collect
+			}
+		}
+		timer.Stop()
+
+		c.purge(urls)
+	}
+}
+
+// purge invalidates the urls in the cache of the CDN by calling the purge hooks
+func (c *cdn) purge(urls []string) {
+	ctx, cancel := context.WithTimeout(c.ctx, cdnPurgeTimeout)
+	defer cancel()
+
+	cdnPurgeHooksMu.RLock()
+	defer cdnPurgeHooksMu.RUnlock()
+	for _, hook := range cdnPurgeHooks {
+		if err := hook(ctx, &c.cfg, urls); err != nil {
+			log.Error("Unable to purge %v from the CDN: %v", urls, err)
+		}
+	}
+}
+
+// purgeCDNByWebhook posts the urls to the CDN_PURGE_URL of the storage
+func purgeCDNByWebhook(ctx context.Context, cfg *setting.CDNConfig, urls []string) error {
+	if cfg.PurgeURL == "" {
+		return nil
+	}
+	body, err := json.Marshal(map[string][]string{"urls": urls})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.PurgeURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if cfg.PurgeToken != "" {
+		req.Header.Set("Authorization", "Bearer "+cfg.PurgeToken)
+	}
+	resp, err := cdnPurgeClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
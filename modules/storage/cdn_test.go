@@ -0,0 +1,120 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package storage
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCDNURL(t *testing.T) {
+	c := newCDN(context.Background(), &setting.Storage{CDN: setting.CDNConfig{
+		URL:        "https://cdn.example.com/files/",
+		SigningKey: "secret",
+		URLExpiry:  time.Hour,
+	}})
+
+	u, err := c.URL("repo-archive/1/a b.zip", "repo.zip", 0)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "cdn.example.com", u.Host)
+	assert.EqualValues(t, "/files/repo-archive/1/a%20b.zip", u.EscapedPath())
+
+	query := u.Query()
+	assert.EqualValues(t, "repo.zip", query.Get("filename"))
+	expires, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+	assert.NoError(t, err)
+	assert.InDelta(t, time.Now().Add(time.Hour).Unix(), expires, 5)
+
+	signature := query.Get("signature")
+	query.Del("signature")
+	assert.EqualValues(t, signCDNURL("secret", u.EscapedPath(), query), signature)
+	assert.NotEqualValues(t, signCDNURL("other", u.EscapedPath(), query), signature)
+
+	u, err = c.URL("lfs/ab/cd", "", time.Minute)
+	assert.NoError(t, err)
+	assert.False(t, u.Query().Has("filename"))
+	expires, err = strconv.ParseInt(u.Query().Get("expires"), 10, 64)
+	assert.NoError(t, err)
+	assert.InDelta(t, time.Now().Add(time.Minute).Unix(), expires, 5)
+
+	assert.Nil(t, newCDN(context.Background(), &setting.Storage{}))
+}
+
+func TestCDNKey(t *testing.T) {
+	cfg := setting.CDNConfig{URL: "https://cdn.example.com/", SigningKey: "secret", URLExpiry: time.Hour}
+	local, err := NewLocalStorage(context.Background(), &setting.Storage{Path: t.TempDir(), CDN: cfg})
+	assert.NoError(t, err)
+	minio := &MinioStorage{basePath: "base/", cdn: newCDN(context.Background(), &setting.Storage{CDN: cfg})}
+
+	// the key of an object is its path relative to the storage, whatever the type of the storage
+	for _, s := range []ObjectStorage{local, minio} {
+		u, err := s.URL("/lfs/../a/b", "b")
+		assert.NoError(t, err)
+		assert.EqualValues(t, "/a/b", u.EscapedPath())
+	}
+}
+
+func TestCDNPurge(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests int
+		purged   []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.EqualValues(t, "Bearer token", r.Header.Get("Authorization"))
+		body, _ := io.ReadAll(r.Body)
+		var req struct {
+			URLs []string `json:"urls"`
+		}
+		assert.NoError(t, json.Unmarshal(body, &req))
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+		purged = append(purged, req.URLs...)
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	l, err := NewLocalStorage(context.Background(), &setting.Storage{Path: dir, CDN: setting.CDNConfig{
+		URL:        "https://cdn.example.com/",
+		SigningKey: "secret",
+		URLExpiry:  time.Hour,
+		PurgeURL:   srv.URL,
+		PurgeToken: "token",
+	}})
+	assert.NoError(t, err)
+
+	_, err = l.Save("a/b", strings.NewReader("content"), -1)
+	assert.NoError(t, err)
+	_, err = l.Save("a/c", strings.NewReader("content"), -1)
+	assert.NoError(t, err)
+	u, err := l.URL("a/b", "b")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(u.String(), "https://cdn.example.com/a/b?"))
+
+	// the objects deleted together are purged by one request, after the deletion
+	assert.NoError(t, l.Delete("a/b"))
+	assert.NoError(t, l.Delete("a/c"))
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(purged) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	assert.EqualValues(t, 1, requests)
+	assert.EqualValues(t, []string{"https://cdn.example.com/a/b", "https://cdn.example.com/a/c"}, purged)
+}
@@ -0,0 +1,100 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package storage
+
+import (
+	"errors"
+	"io"
+	"os"
+
+	"code.gitea.io/gitea/modules/log"
+)
+
+// DualWriteStorage reads the objects from a primary storage and writes them to both the primary storage and the
+// storage it is being migrated to, so the objects created during an online migration aren't missed by it.
+// Failing to write to the secondary storage is only logged, the migration reports the objects it lacks.
+type DualWriteStorage struct {
+	ObjectStorage
+	secondary ObjectStorage
+}
+
+// NewDualWriteStorage returns a storage writing to both the primary and secondary storages
+func NewDualWriteStorage(primary, secondary ObjectStorage) *DualWriteStorage {
+	return &DualWriteStorage{ObjectStorage: primary, secondary: secondary}
+}
+
+// Unwrap returns the primary storage
+func (s *DualWriteStorage) Unwrap() ObjectStorage {
+	return s.ObjectStorage
+}
+
+// Secondary returns the storage the objects are also written to
+func (s *DualWriteStorage) Secondary() ObjectStorage {
+	return s.secondary
+}
+
+// Save stores the object in the primary storage then copies it to the secondary storage
+func (s *DualWriteStorage) Save(path string, r io.Reader, size int64) (int64, error) {
+	n, err := s.ObjectStorage.Save(path, r, size)
+	if err != nil {
+		return n, err
+	}
+	s.replicate(path)
+	return n, nil
+}
+
+// CommitUpload completes the multipart upload in the primary storage then copies the object to the secondary storage
+func (s *DualWriteStorage) CommitUpload(path, additionalParameter string) error {
+	if err := s.ObjectStorage.CommitUpload(path, additionalParameter); err != nil {
+		return err
+	}
+	s.replicate(path)
+	return nil
+}
+
+// CommitUploadVerified completes and verifies the multipart upload in the primary storage then copies the object to the
+// secondary storage, ErrVerifiedCommitNotSupported is returned if the primary storage cannot verify it
+func (s *DualWriteStorage) CommitUploadVerified(path, additionalParameter string, w io.Writer, verify func() error) error {
+	committer, ok := As[VerifyingCommitter](s.ObjectStorage)
+	if !ok {
+		return ErrVerifiedCommitNotSupported
+	}
+	if err := committer.CommitUploadVerified(path, additionalParameter, w, verify); err != nil {
+		return err
+	}
+	s.replicate(path)
+	return nil
+}
+
+// Delete removes the object from both storages
+func (s *DualWriteStorage) Delete(path string) error {
+	if err := s.ObjectStorage.Delete(path); err != nil {
+		return err
+	}
+	if err := s.secondary.Delete(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Error("Unable to delete %s from the dual write storage: %v", path, err)
+	}
+	return nil
+}
+
+func (s *DualWriteStorage) replicate(path string) {
+	if _, err := Copy(s.secondary, path, s.ObjectStorage, path); err != nil {
+		log.Error("Unable to copy %s to the dual write storage: %v", path, err)
+	}
+}
+
+// As returns the first storage implementing T among the storage and the storages it wraps
+func As[T any](s ObjectStorage) (T, bool) {
+	for {
+		if t, ok := s.(T); ok {
+			return t, true
+		}
+		wrapper, ok := s.(interface{ Unwrap() ObjectStorage })
+		if !ok {
+			var zero T
+			return zero, false
+		}
+		s = wrapper.Unwrap()
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package storage
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDualWriteStorage(t *testing.T) {
+	primaryDir, secondaryDir := t.TempDir(), t.TempDir()
+	s, err := NewStorage(setting.LocalStorageType, &setting.Storage{
+		Path:      primaryDir,
+		DualWrite: &setting.Storage{Type: setting.LocalStorageType, Path: secondaryDir},
+	})
+	assert.NoError(t, err)
+	dualWrite, ok := s.(*DualWriteStorage)
+	assert.True(t, ok)
+
+	_, err = s.Save("a/b", strings.NewReader("content"), -1)
+	assert.NoError(t, err)
+	for _, st := range []ObjectStorage{dualWrite.Unwrap(), dualWrite.Secondary()} {
+		f, err := st.Open("a/b")
+		assert.NoError(t, err)
+		b, _ := io.ReadAll(f)
+		f.Close()
+		assert.EqualValues(t, "content", string(b))
+	}
+
+	assert.NoError(t, s.Delete("a/b"))
+	_, err = dualWrite.Secondary().Stat("a/b")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	// the optional interfaces of the primary storage are still found
+	_, ok = As[MultipartUploader](s)
+	assert.True(t, ok)
+	_, ok = As[Restorer](s)
+	assert.False(t, ok)
+
+	s, err = NewStorage(setting.LocalStorageType, &setting.Storage{Path: primaryDir})
+	assert.NoError(t, err)
+	_, ok = s.(*LocalStorage)
+	assert.True(t, ok)
+}
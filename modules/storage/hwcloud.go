@@ -10,10 +10,13 @@ import (
 	"fmt"
 	"github.com/huaweicloud/huaweicloud-sdk-go-obs/obs"
 	"github.com/minio/minio-go/v7"
+	"io"
 	"net/http"
 	"net/url"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
 const multipart_chunk_size int64 = 20000000
@@ -50,6 +53,8 @@ func NewHWCloudStorage(ctx context.Context, cfg *setting.Storage) (ObjectStorage
 	}, nil
 }
 
+var _ MultipartLister = &HWCloudStorage{}
+
 type HWCloudStorage struct {
 	hwclient     *obs.ObsClient
 	bucketDomain string
@@ -61,58 +66,42 @@ func (hwc *HWCloudStorage) GenerateMultipartParts(path string, size int64) (part
 	objectKey := hwc.buildMinioPath(path)
 	taskParts := map[int64]obs.Part{}
 	uploadID := ""
-	//1. list all the multipart tasks
-	listMultipart := &obs.ListMultipartUploadsInput{}
-	listMultipart.Prefix = objectKey
-	listMultipart.Bucket = hwc.bucket
-	listResult, err := hwc.hwclient.ListMultipartUploads(listMultipart)
+	//1. list all the multipart tasks of the object
+	uploads, err := hwc.listMultipartUploads(objectKey)
 	if err != nil {
-		log.Error("lfs[multipart] Failed to list existing multipart task %s and %s", hwc.bucket, objectKey)
+		log.Error("lfs[multipart] Failed to list existing multipart task %s and %s: %v", hwc.bucket, objectKey, err)
 		return nil, nil, nil, err
 	}
-	if len(listResult.Uploads) != 0 {
-		//remove all unfinished tasks if multiple tasks are found
-		if len(listResult.Uploads) > 1 {
-			for _, task := range listResult.Uploads {
-				abortRequest := &obs.AbortMultipartUploadInput{}
-				abortRequest.Key = objectKey
-				abortRequest.Bucket = hwc.bucket
-				abortRequest.UploadId = task.UploadId
-				_, err = hwc.hwclient.AbortMultipartUpload(abortRequest)
-				if err != nil {
-					log.Error("lfs[multipart] Failed to abort existing multipart task %s and %s %s", hwc.bucket, objectKey, task.UploadId)
-					return nil, nil, nil, err
-				}
-			}
-		} else {
-			//find out all finished tasks
-			partRequest := &obs.ListPartsInput{}
-			partRequest.Key = objectKey
-			partRequest.Bucket = hwc.bucket
-			partRequest.UploadId = listResult.Uploads[0].UploadId
-			uploadID = listResult.Uploads[0].UploadId
-			parts, err := hwc.hwclient.ListParts(partRequest)
-			if err != nil {
-				log.Error("lfs[multipart] Failed to get existing multipart task part %s and %s %s", hwc.bucket, objectKey)
-			}
-			for _, content := range parts.Parts {
-				taskParts[int64(content.PartNumber)] = content
+	if len(uploads) != 0 {
+		//resume the latest task, the others have been abandoned by their clients and are aborted
+		sort.Slice(uploads, func(i, j int) bool {
+			return uploads[i].Initiated.After(uploads[j].Initiated)
+		})
+		for _, task := range uploads[1:] {
+			if err := hwc.AbortUpload(path, task.UploadId); err != nil {
+				log.Error("lfs[multipart] Failed to abort existing multipart task %s and %s %s: %v", hwc.bucket, objectKey, task.UploadId, err)
+				return nil, nil, nil, err
 			}
 		}
+		//2. find out all finished parts of the resumed task
+		uploadID = uploads[0].UploadId
+		taskParts, err = hwc.listParts(objectKey, uploadID)
+		if err != nil {
+			log.Error("lfs[multipart] Failed to get existing multipart task part %s and %s %s: %v", hwc.bucket, objectKey, uploadID, err)
+			return nil, nil, nil, err
+		}
 	}
-	//2. get and return all unfinished tasks, clean up the task if needed
-	//TODO
 	//3. Initialize multipart task
 	if uploadID == "" {
 		log.Trace("lfs[multipart] Starting to create multipart task %s and %s", hwc.bucket, objectKey)
 		upload := obs.InitiateMultipartUploadInput{}
-		upload.Key = hwc.buildMinioPath(path)
+		upload.Key = objectKey
 		upload.Bucket = hwc.bucket
 		multipart, err := hwc.hwclient.InitiateMultipartUpload(&upload)
-		uploadID = multipart.UploadId
 		if err != nil {
 			return nil, nil, nil, err
 		}
+		uploadID = multipart.UploadId
 	}
 	//generate part
 	currentPart := int64(0)
@@ -152,8 +141,8 @@ func (hwc *HWCloudStorage) GenerateMultipartParts(path string, size int64) (part
 				"uploadId":   uploadID,
 			},
 		}
-		result, errorMessage := hwc.hwclient.CreateSignedUrl(&request)
-		if errorMessage != nil {
+		result, err := hwc.hwclient.CreateSignedUrl(&request)
+		if err != nil {
 			return nil, nil, nil, err
 		}
 		var part = &structs.MultipartObjectPart{
@@ -188,6 +177,91 @@ func (hwc *HWCloudStorage) GenerateMultipartParts(path string, size int64) (part
 	return parts, nil, verify, nil
 }
 
+// listMultipartUploads returns the unfinished multipart tasks of the object
+func (hwc *HWCloudStorage) listMultipartUploads(objectKey string) ([]obs.Upload, error) {
+	var uploads []obs.Upload
+	err := hwc.iterateMultipartUploads(objectKey, func(upload obs.Upload) error {
+		//the prefix may match other objects
+		if upload.Key == objectKey {
+			uploads = append(uploads, upload)
+		}
+		return nil
+	})
+	return uploads, err
+}
+
+// iterateMultipartUploads iterates across the unfinished multipart tasks whose key starts with the prefix
+func (hwc *HWCloudStorage) iterateMultipartUploads(prefix string, fn func(upload obs.Upload) error) error {
+	input := &obs.ListMultipartUploadsInput{}
+	input.Bucket = hwc.bucket
+	input.Prefix = prefix
+	for {
+		output, err := hwc.hwclient.ListMultipartUploads(input)
+		if err != nil {
+			return err
+		}
+		for _, upload := range output.Uploads {
+			if err := fn(upload); err != nil {
+				return err
+			}
+		}
+		if !output.IsTruncated {
+			return nil
+		}
+		input.KeyMarker = output.NextKeyMarker
+		input.UploadIdMarker = output.NextUploadIdMarker
+	}
+}
+
+// listParts returns the uploaded parts of a multipart task by their part number
+func (hwc *HWCloudStorage) listParts(objectKey, uploadID string) (map[int64]obs.Part, error) {
+	parts := map[int64]obs.Part{}
+	input := &obs.ListPartsInput{}
+	input.Bucket = hwc.bucket
+	input.Key = objectKey
+	input.UploadId = uploadID
+	for {
+		output, err := hwc.hwclient.ListParts(input)
+		if err != nil {
+			return nil, err
+		}
+		for _, part := range output.Parts {
+			parts[int64(part.PartNumber)] = part
+		}
+		//a task has up to 10000 parts, which are listed 1000 at most at a time
+		if !output.IsTruncated {
+			return parts, nil
+		}
+		input.PartNumberMarker = output.NextPartNumberMarker
+	}
+}
+
+// IterateMultipartUploads iterates across the unfinished multipart tasks of the storage
+func (hwc *HWCloudStorage) IterateMultipartUploads(fn func(upload *MultipartUpload) error) error {
+	return hwc.iterateMultipartUploads(hwc.buildMinioDirPrefix(""), func(upload obs.Upload) error {
+		return fn(&MultipartUpload{
+			Path:      strings.TrimPrefix(upload.Key, hwc.buildMinioDirPrefix("")),
+			UploadID:  upload.UploadId,
+			Initiated: upload.Initiated,
+		})
+	})
+}
+
+// LastPartModified returns when the newest part of the multipart task was uploaded
+func (hwc *HWCloudStorage) LastPartModified(path, uploadID string) (time.Time, error) {
+	parts, err := hwc.listParts(hwc.buildMinioPath(path), uploadID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var modified time.Time
+	for _, part := range parts {
+		if part.LastModified.After(modified) {
+			modified = part.LastModified
+		}
+	}
+	return modified, nil
+}
+
 func (hwc *HWCloudStorage) CommitUpload(path, additionalParameter string) error {
 	var param MultiPartCommitUpload
 	err := json.Unmarshal([]byte(additionalParameter), &param)
@@ -227,8 +301,35 @@ func (hwc *HWCloudStorage) CommitUpload(path, additionalParameter string) error
 
 }
 
-// URL gets the redirect URL to a file. The presigned link is valid for 5 minutes.
+// UploadPart is not supported, the parts are uploaded to OBS directly with the signed urls
+func (hwc *HWCloudStorage) UploadPart(path, uploadID string, index int, r io.Reader, size int64) (string, error) {
+	return "", ErrMultipartNotSupported
+}
+
+// AbortUpload aborts the OBS multipart upload task
+func (hwc *HWCloudStorage) AbortUpload(path, uploadID string) error {
+	abortRequest := &obs.AbortMultipartUploadInput{}
+	abortRequest.Key = hwc.buildMinioPath(path)
+	abortRequest.Bucket = hwc.bucket
+	abortRequest.UploadId = uploadID
+	_, err := hwc.hwclient.AbortMultipartUpload(abortRequest)
+	return err
+}
+
+// URL gets the redirect URL to a file. The presigned link is valid for a day, or for the CDN_URL_EXPIRY if the
+// storage is served by a CDN.
 func (hwc *HWCloudStorage) URL(path, name string) (*url.URL, error) {
+	if hwc.cdn != nil {
+		return hwc.MinioStorage.URL(path, name)
+	}
+	return hwc.SignedURL(path, name, time.Duration(default_expire)*time.Second)
+}
+
+// SignedURL gets the redirect URL to a file through the bucket domain, the presigned link is valid for the given duration
+func (hwc *HWCloudStorage) SignedURL(path, name string, expiry time.Duration) (*url.URL, error) {
+	if hwc.cdn != nil {
+		return hwc.MinioStorage.SignedURL(path, name, expiry)
+	}
 	//NOTE: we url.PathEscape instead of url.QueryEscape is used here due to we need to convert space to %20 rather than +
 	queryParameter := map[string]string{"response-content-disposition": "attachment; filename=\"" + url.PathEscape(name) + "\""}
 	input := &obs.CreateSignedUrlInput{}
@@ -236,7 +337,7 @@ func (hwc *HWCloudStorage) URL(path, name string) (*url.URL, error) {
 	input.Method = obs.HttpMethodGet
 	input.Bucket = hwc.bucket
 	input.Key = hwc.buildMinioPath(path)
-	input.Expires = default_expire
+	input.Expires = int(expiry.Seconds())
 	input.QueryParams = queryParameter
 	output, err := hwc.hwclient.CreateSignedUrl(input)
 	if err != nil {
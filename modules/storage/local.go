@@ -6,11 +6,16 @@ package storage
 import (
 	"code.gitea.io/gitea/modules/structs"
 	"context"
+	"crypto/md5"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
@@ -24,14 +29,151 @@ type LocalStorage struct {
 	ctx    context.Context
 	dir    string
 	tmpdir string
+	cdn    *cdn
 }
 
+var (
+	_ MultipartUploader  = &LocalStorage{}
+	_ VerifyingCommitter = &LocalStorage{}
+	_ SignedURLer        = &LocalStorage{}
+)
+
+// GenerateMultipartParts generates the parts of a multipart upload, the parts are uploaded through Gitea
 func (l *LocalStorage) GenerateMultipartParts(path string, size int64) (parts []*structs.MultipartObjectPart, abort *structs.MultipartEndpoint, verify *structs.MultipartEndpoint, err error) {
-	panic("not implemented")
+	parts, verify, err = generateServedMultipartParts(path, size)
+	return parts, nil, verify, err
+}
+
+func (l *LocalStorage) buildMultipartDir(uploadID string) string {
+	return util.FilePathJoinAbs(l.tmpdir, "multipart", uploadID)
+}
+
+// UploadPart stores a part of a multipart upload in the temporary directory
+func (l *LocalStorage) UploadPart(path, uploadID string, index int, r io.Reader, size int64) (string, error) {
+	if !IsMultipartUploadIDOf(path, uploadID) {
+		return "", fmt.Errorf("invalid upload id %q", uploadID)
+	}
+	dir := l.buildMultipartDir(uploadID)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return "", err
+	}
+	tmp, err := os.CreateTemp(dir, "part-*")
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = util.Remove(tmp.Name())
+	}()
+
+	hash := md5.New()
+	n, err := io.Copy(tmp, io.TeeReader(r, hash))
+	if err != nil {
+		_ = tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if size >= 0 && n != size {
+		return "", fmt.Errorf("part %d size mismatch: expected %d, got %d", index, size, n)
+	}
+	if err := util.Rename(tmp.Name(), filepath.Join(dir, strconv.Itoa(index))); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// StatPart returns the information of a stored part of a multipart upload
+func (l *LocalStorage) StatPart(path, uploadID string, index int) (os.FileInfo, error) {
+	if !IsMultipartUploadIDOf(path, uploadID) {
+		return nil, fmt.Errorf("invalid upload id %q", uploadID)
+	}
+	return os.Stat(filepath.Join(l.buildMultipartDir(uploadID), strconv.Itoa(index)))
 }
 
+// AbortUpload removes the stored parts of a multipart upload
+func (l *LocalStorage) AbortUpload(path, uploadID string) error {
+	if !IsMultipartUploadIDOf(path, uploadID) {
+		return fmt.Errorf("invalid upload id %q", uploadID)
+	}
+	return util.RemoveAll(l.buildMultipartDir(uploadID))
+}
+
+// CommitUpload assembles the uploaded parts into the object and removes the parts
 func (l *LocalStorage) CommitUpload(path, additionalParameter string) error {
-	panic("not implemented")
+	return l.CommitUploadVerified(path, additionalParameter, io.Discard, nil)
+}
+
+// CommitUploadVerified assembles the uploaded parts into the object, verifies it before it is moved in place and removes the parts
+func (l *LocalStorage) CommitUploadVerified(path, additionalParameter string, w io.Writer, verify func() error) error {
+	param, err := parseServedMultipartCommit(path, additionalParameter)
+	if err != nil {
+		log.Error("lfs[multipart] unable to decode additional parameter %s: %v", additionalParameter, err)
+		return err
+	}
+	dir := l.buildMultipartDir(param.UploadID)
+
+	p := l.buildLocalPath(path)
+	if err := os.MkdirAll(filepath.Dir(p), os.ModePerm); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(l.tmpdir, "upload-*")
+	if err != nil {
+		return err
+	}
+	tmpRemoved := false
+	defer func() {
+		if !tmpRemoved {
+			_ = util.Remove(tmp.Name())
+		}
+	}()
+
+	appendPart := func(part MultipartPartID) error {
+		f, err := os.Open(filepath.Join(dir, strconv.Itoa(part.Index)))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		hash := md5.New()
+		if _, err := io.Copy(io.MultiWriter(tmp, w), io.TeeReader(f, hash)); err != nil {
+			return err
+		}
+		if etag := hex.EncodeToString(hash.Sum(nil)); etag != strings.Trim(part.Etag, "\"") {
+			return fmt.Errorf("part %d etag mismatch: expected %s, got %s", part.Index, part.Etag, etag)
+		}
+		return nil
+	}
+	for _, part := range param.PartIDs {
+		if err := appendPart(part); err != nil {
+			_ = tmp.Close()
+			return err
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if verify != nil {
+		if err := verify(); err != nil {
+			if errRemove := util.RemoveAll(dir); errRemove != nil {
+				log.Warn("lfs[multipart] unable to remove parts of upload %s: %v", param.UploadID, errRemove)
+			}
+			return err
+		}
+	}
+
+	if err := util.Rename(tmp.Name(), p); err != nil {
+		return err
+	}
+	if err := util.ApplyUmask(p, os.ModePerm&0o666); err != nil {
+		return err
+	}
+	tmpRemoved = true
+
+	if err := util.RemoveAll(dir); err != nil {
+		log.Warn("lfs[multipart] unable to remove parts of upload %s: %v", param.UploadID, err)
+	}
+	return nil
 }
 
 // NewLocalStorage returns a local files
@@ -55,6 +197,7 @@ func NewLocalStorage(ctx context.Context, config *setting.Storage) (ObjectStorag
 		ctx:    ctx,
 		dir:    config.Path,
 		tmpdir: config.TemporaryPath,
+		cdn:    newCDN(ctx, config),
 	}, nil
 }
 
@@ -119,12 +262,24 @@ func (l *LocalStorage) Stat(path string) (os.FileInfo, error) {
 
 // Delete delete a file
 func (l *LocalStorage) Delete(path string) error {
-	return util.Remove(l.buildLocalPath(path))
+	if err := util.Remove(l.buildLocalPath(path)); err != nil {
+		return err
+	}
+	l.cdn.Purge(path)
+	return nil
 }
 
-// URL gets the redirect URL to a file
+// URL gets the redirect URL to a file, it is only supported if the storage is served by a CDN
 func (l *LocalStorage) URL(path, name string) (*url.URL, error) {
-	return nil, ErrURLNotSupported
+	return l.SignedURL(path, name, 0)
+}
+
+// SignedURL gets the redirect URL to a file through the CDN, valid for the given duration
+func (l *LocalStorage) SignedURL(path, name string, expiry time.Duration) (*url.URL, error) {
+	if l.cdn == nil {
+		return nil, ErrURLNotSupported
+	}
+	return l.cdn.URL(path, name, expiry)
 }
 
 // IterateObjects iterates across the objects in the local storage
@@ -158,8 +313,27 @@ func (l *LocalStorage) IterateObjects(dirName string, fn func(path string, obj O
 	})
 }
 
-func (l *LocalStorage) IterateObjectsKeyOnly(path string, fn func(path string) error) error {
-	panic("not implemented")
+// IterateObjectsKeyOnly iterates across the objects' name only in the local storage
+func (l *LocalStorage) IterateObjectsKeyOnly(dirName string, fn func(path string) error) error {
+	dir := l.buildLocalPath(dirName)
+	return filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		select {
+		case <-l.ctx.Done():
+			return l.ctx.Err()
+		default:
+		}
+		if path == l.dir || d.IsDir() {
+			return nil
+		}
+		relPath, err := filepath.Rel(l.dir, path)
+		if err != nil {
+			return err
+		}
+		return fn(filepath.ToSlash(relPath))
+	})
 }
 
 func init() {
@@ -4,8 +4,11 @@
 package storage
 
 import (
+	"encoding/json"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"code.gitea.io/gitea/modules/setting"
@@ -59,3 +62,100 @@ func TestLocalStorageIterator(t *testing.T) {
 	dir := filepath.Join(os.TempDir(), "TestLocalStorageIteratorTestDir")
 	testStorageIterator(t, setting.LocalStorageType, &setting.Storage{Path: dir})
 }
+
+func TestLocalStorageMultipartUpload(t *testing.T) {
+	dir := t.TempDir()
+	l, err := NewStorage(setting.LocalStorageType, &setting.Storage{Path: dir})
+	assert.NoError(t, err)
+
+	const p = "ab/cd/ef"
+	content := strings.Repeat("a", int(multipart_chunk_size)) + "bcd"
+	size := int64(len(content))
+
+	parts, _, verify, err := l.GenerateMultipartParts(p, size)
+	assert.NoError(t, err)
+	assert.Len(t, parts, 2)
+	assert.EqualValues(t, multipart_chunk_size, parts[0].Size)
+	assert.EqualValues(t, 3, parts[1].Size)
+	assert.Empty(t, parts[1].Href)
+	uploadID := (*verify.Params)["upload_id"]
+	assert.True(t, IsMultipartUploadIDOf(p, uploadID))
+	assert.False(t, IsMultipartUploadIDOf("ab/cd/eg", uploadID))
+	// every upload has its own id, so concurrent uploads of the same path don't share their parts
+	_, _, other, err := l.GenerateMultipartParts(p, size)
+	assert.NoError(t, err)
+	assert.NotEqual(t, uploadID, (*other.Params)["upload_id"])
+
+	uploader := l.(MultipartUploader)
+	_, err = uploader.UploadPart(p, "invalid", 1, strings.NewReader("a"), 1)
+	assert.Error(t, err)
+	_, err = uploader.UploadPart(p, uploadID+"/../x", 1, strings.NewReader("a"), 1)
+	assert.Error(t, err)
+	_, err = uploader.StatPart(p, uploadID, 1)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	commit := MultiPartCommitUpload{UploadID: uploadID}
+	for _, part := range parts {
+		etag, err := uploader.UploadPart(p, uploadID, part.Index, strings.NewReader(content[part.Pos:part.Pos+part.Size]), part.Size)
+		assert.NoError(t, err)
+		commit.PartIDs = append(commit.PartIDs, MultipartPartID{Index: part.Index, Etag: etag})
+
+		info, err := uploader.StatPart(p, uploadID, part.Index)
+		assert.NoError(t, err)
+		assert.EqualValues(t, part.Size, info.Size())
+	}
+
+	missing, _ := json.Marshal(MultiPartCommitUpload{UploadID: uploadID, PartIDs: commit.PartIDs[1:]})
+	assert.Error(t, l.CommitUpload(p, string(missing)))
+
+	param, _ := json.Marshal(commit)
+	assert.NoError(t, l.CommitUpload(p, string(param)))
+
+	f, err := l.Open(p)
+	assert.NoError(t, err)
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	assert.NoError(t, err)
+	assert.Equal(t, content, string(data))
+
+	_, err = os.Stat(filepath.Join(dir, "tmp", "multipart", uploadID))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestLocalStorageMultipartUploadVerified(t *testing.T) {
+	dir := t.TempDir()
+	l, err := NewStorage(setting.LocalStorageType, &setting.Storage{Path: dir})
+	assert.NoError(t, err)
+
+	const p = "ab/cd/ef"
+	upload := func() string {
+		_, _, verify, err := l.GenerateMultipartParts(p, 3)
+		assert.NoError(t, err)
+		uploadID := (*verify.Params)["upload_id"]
+		etag, err := l.(MultipartUploader).UploadPart(p, uploadID, 1, strings.NewReader("abc"), 3)
+		assert.NoError(t, err)
+		param, _ := json.Marshal(MultiPartCommitUpload{UploadID: uploadID, PartIDs: []MultipartPartID{{Index: 1, Etag: etag}}})
+		return string(param)
+	}
+	committer := l.(VerifyingCommitter)
+
+	// the object is not stored if the verification fails, the parts are removed
+	var read strings.Builder
+	assert.ErrorIs(t, committer.CommitUploadVerified(p, upload(), &read, func() error {
+		return os.ErrInvalid
+	}), os.ErrInvalid)
+	assert.Equal(t, "abc", read.String())
+	_, err = l.Stat(p)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	entries, err := os.ReadDir(filepath.Join(dir, "tmp", "multipart"))
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+
+	read.Reset()
+	assert.NoError(t, committer.CommitUploadVerified(p, upload(), &read, func() error {
+		assert.Equal(t, "abc", read.String())
+		return nil
+	}))
+	_, err = l.Stat(p)
+	assert.NoError(t, err)
+}
@@ -13,6 +13,7 @@ import (
 	"net/url"
 	"os"
 	"path"
+	"strconv"
 	"strings"
 	"time"
 
@@ -25,7 +26,10 @@ import (
 )
 
 var (
-	_ ObjectStorage = &MinioStorage{}
+	_ ObjectStorage      = &MinioStorage{}
+	_ MultipartUploader  = &MinioStorage{}
+	_ VerifyingCommitter = &MinioStorage{}
+	_ SignedURLer        = &MinioStorage{}
 
 	quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
 )
@@ -50,6 +54,7 @@ type MinioStorage struct {
 	client   *minio.Client
 	bucket   string
 	basePath string
+	cdn      *cdn
 }
 
 func convertMinioErr(err error) error {
@@ -142,15 +147,133 @@ func NewMinioStorage(ctx context.Context, cfg *setting.Storage) (ObjectStorage,
 		client:   minioClient,
 		bucket:   config.Bucket,
 		basePath: config.BasePath,
+		cdn:      newCDN(ctx, cfg),
 	}, nil
 }
 
+// GenerateMultipartParts generates the parts of a multipart upload, the parts are uploaded through Gitea
 func (m *MinioStorage) GenerateMultipartParts(path string, size int64) (parts []*structs.MultipartObjectPart, abort *structs.MultipartEndpoint, verify *structs.MultipartEndpoint, err error) {
-	panic("not implemented")
+	parts, verify, err = generateServedMultipartParts(path, size)
+	return parts, nil, verify, err
 }
 
+// buildMinioPartPath returns the key of a stored part, parts are kept outside the base path so they are never iterated
+func (m *MinioStorage) buildMinioPartPath(uploadID string, index int) string {
+	return strings.TrimPrefix(util.PathJoinRelX(".multipart", m.basePath, uploadID, strconv.Itoa(index)), "/")
+}
+
+// UploadPart stores a part of a multipart upload as a temporary object
+func (m *MinioStorage) UploadPart(path, uploadID string, index int, r io.Reader, size int64) (string, error) {
+	if !IsMultipartUploadIDOf(path, uploadID) {
+		return "", fmt.Errorf("invalid upload id %q", uploadID)
+	}
+	uploadInfo, err := m.client.PutObject(
+		m.ctx,
+		m.bucket,
+		m.buildMinioPartPath(uploadID, index),
+		r,
+		size,
+		minio.PutObjectOptions{
+			ContentType:    "application/octet-stream",
+			SendContentMd5: m.cfg.ChecksumAlgorithm == "md5",
+		},
+	)
+	if err != nil {
+		return "", convertMinioErr(err)
+	}
+	return uploadInfo.ETag, nil
+}
+
+// StatPart returns the information of a stored part of a multipart upload
+func (m *MinioStorage) StatPart(path, uploadID string, index int) (os.FileInfo, error) {
+	if !IsMultipartUploadIDOf(path, uploadID) {
+		return nil, fmt.Errorf("invalid upload id %q", uploadID)
+	}
+	info, err := m.client.StatObject(m.ctx, m.bucket, m.buildMinioPartPath(uploadID, index), minio.StatObjectOptions{})
+	if err != nil {
+		return nil, convertMinioErr(err)
+	}
+	return &minioFileInfo{info}, nil
+}
+
+// readPart writes the content of a stored part to w, the part must still have the etag it was uploaded with
+func (m *MinioStorage) readPart(uploadID string, part MultipartPartID, w io.Writer) error {
+	opts := minio.GetObjectOptions{}
+	if err := opts.SetMatchETag(strings.Trim(part.Etag, "\"")); err != nil {
+		return err
+	}
+	obj, err := m.client.GetObject(m.ctx, m.bucket, m.buildMinioPartPath(uploadID, part.Index), opts)
+	if err != nil {
+		return convertMinioErr(err)
+	}
+	defer obj.Close()
+	if _, err := io.Copy(w, obj); err != nil {
+		return convertMinioErr(err)
+	}
+	return nil
+}
+
+// AbortUpload removes the stored parts of a multipart upload
+func (m *MinioStorage) AbortUpload(path, uploadID string) error {
+	if !IsMultipartUploadIDOf(path, uploadID) {
+		return fmt.Errorf("invalid upload id %q", uploadID)
+	}
+	prefix := strings.TrimPrefix(util.PathJoinRelX(".multipart", m.basePath, uploadID), "/") + "/"
+	for obj := range m.client.ListObjects(m.ctx, m.bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if obj.Err != nil {
+			return convertMinioErr(obj.Err)
+		}
+		if err := m.client.RemoveObject(m.ctx, m.bucket, obj.Key, minio.RemoveObjectOptions{}); err != nil {
+			return convertMinioErr(err)
+		}
+	}
+	return nil
+}
+
+// CommitUpload composes the uploaded parts into the object and removes the parts
 func (m *MinioStorage) CommitUpload(path, additionalParameter string) error {
-	panic("not implemented")
+	return m.CommitUploadVerified(path, additionalParameter, io.Discard, nil)
+}
+
+// CommitUploadVerified reads the uploaded parts to verify them, then composes them into the object and removes the parts
+func (m *MinioStorage) CommitUploadVerified(path, additionalParameter string, w io.Writer, verify func() error) error {
+	param, err := parseServedMultipartCommit(path, additionalParameter)
+	if err != nil {
+		log.Error("lfs[multipart] unable to decode additional parameter %s: %v", additionalParameter, err)
+		return err
+	}
+	if verify != nil {
+		for _, p := range param.PartIDs {
+			if err := m.readPart(param.UploadID, p, w); err != nil {
+				return err
+			}
+		}
+		if err := verify(); err != nil {
+			if errAbort := m.AbortUpload(path, param.UploadID); errAbort != nil {
+				log.Warn("lfs[multipart] unable to remove parts of upload %s: %v", param.UploadID, errAbort)
+			}
+			return err
+		}
+	}
+	srcs := make([]minio.CopySrcOptions, 0, len(param.PartIDs))
+	for _, p := range param.PartIDs {
+		srcs = append(srcs, minio.CopySrcOptions{
+			Bucket:    m.bucket,
+			Object:    m.buildMinioPartPath(param.UploadID, p.Index),
+			MatchETag: strings.Trim(p.Etag, "\""),
+		})
+	}
+	log.Trace("lfs[multipart] Start to compose multipart task %s and %s", m.bucket, m.buildMinioPath(path))
+	if _, err := m.client.ComposeObject(m.ctx, minio.CopyDestOptions{
+		Bucket: m.bucket,
+		Object: m.buildMinioPath(path),
+	}, srcs...); err != nil {
+		return convertMinioErr(err)
+	}
+	if err := m.AbortUpload(path, param.UploadID); err != nil {
+		log.Warn("lfs[multipart] unable to remove parts of upload %s: %v", param.UploadID, err)
+	}
+	return nil
 }
 
 func (m *MinioStorage) buildMinioPath(p string) string {
@@ -195,6 +318,7 @@ func (m *MinioStorage) Save(path string, r io.Reader, size int64) (int64, error)
 			// * https://www.backblaze.com/b2/docs/s3_compatible_api.html
 			// do not support "x-amz-checksum-algorithm" header, so use legacy MD5 checksum
 			SendContentMd5: m.cfg.ChecksumAlgorithm == "md5",
+			StorageClass:   m.cfg.StorageClass,
 		},
 	)
 	if err != nil {
@@ -248,16 +372,31 @@ func (m *MinioStorage) Stat(path string) (os.FileInfo, error) {
 // Delete delete a file
 func (m *MinioStorage) Delete(path string) error {
 	err := m.client.RemoveObject(m.ctx, m.bucket, m.buildMinioPath(path), minio.RemoveObjectOptions{})
-
-	return convertMinioErr(err)
+	if err != nil {
+		return convertMinioErr(err)
+	}
+	m.cdn.Purge(path)
+	return nil
 }
 
-// URL gets the redirect URL to a file. The presigned link is valid for 5 minutes.
+// URL gets the redirect URL to a file. The presigned link is valid for 5 minutes, or for the CDN_URL_EXPIRY if the
+// storage is served by a CDN.
 func (m *MinioStorage) URL(path, name string) (*url.URL, error) {
+	if m.cdn != nil {
+		return m.cdn.URL(path, name, 0)
+	}
+	return m.SignedURL(path, name, 5*time.Minute)
+}
+
+// SignedURL gets the redirect URL to a file, the presigned link is valid for the given duration
+func (m *MinioStorage) SignedURL(path, name string, expiry time.Duration) (*url.URL, error) {
+	if m.cdn != nil {
+		return m.cdn.URL(path, name, expiry)
+	}
 	reqParams := make(url.Values)
 	// TODO it may be good to embed images with 'inline' like ServeData does, but we don't want to have to read the file, do we?
 	reqParams.Set("response-content-disposition", "attachment; filename=\""+quoteEscaper.Replace(name)+"\"")
-	u, err := m.client.PresignedGetObject(m.ctx, m.bucket, m.buildMinioPath(path), 5*time.Minute, reqParams)
+	u, err := m.client.PresignedGetObject(m.ctx, m.bucket, m.buildMinioPath(path), expiry, reqParams)
 	return u, convertMinioErr(err)
 }
 
@@ -282,8 +421,20 @@ func (m *MinioStorage) IterateObjects(dirName string, fn func(path string, obj O
 	return nil
 }
 
-func (m *MinioStorage) IterateObjectsKeyOnly(path string, fn func(path string) error) error {
-	panic("not implemented")
+// IterateObjectsKeyOnly iterates across the objects' name only in the miniostorage
+func (m *MinioStorage) IterateObjectsKeyOnly(dirName string, fn func(path string) error) error {
+	for mObjInfo := range m.client.ListObjects(m.ctx, m.bucket, minio.ListObjectsOptions{
+		Prefix:    m.buildMinioDirPrefix(dirName),
+		Recursive: true,
+	}) {
+		if mObjInfo.Err != nil {
+			return convertMinioErr(mObjInfo.Err)
+		}
+		if err := fn(strings.TrimPrefix(mObjInfo.Key, m.basePath)); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func init() {
@@ -0,0 +1,162 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package storage
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// ErrMultipartNotSupported is returned when parts must not be uploaded through Gitea
+var ErrMultipartNotSupported = errors.New("multipart part upload is not supported by this storage")
+
+// MultipartUploader is implemented by storages whose multipart parts are uploaded through Gitea itself
+// instead of directly to the storage backend. The parts generated by such storages have an empty Href
+// which is expected to be filled with a Gitea endpoint by the caller.
+type MultipartUploader interface {
+	// UploadPart stores a single part of the multipart upload and returns its etag
+	UploadPart(path, uploadID string, index int, r io.Reader, size int64) (string, error)
+	// StatPart returns the information of a stored part of the multipart upload, os.ErrNotExist if it is missing
+	StatPart(path, uploadID string, index int) (os.FileInfo, error)
+	// AbortUpload removes all the stored parts of the multipart upload
+	AbortUpload(path, uploadID string) error
+}
+
+// ErrVerifiedCommitNotSupported is returned when the content of a multipart upload cannot be verified while it is assembled
+var ErrVerifiedCommitNotSupported = errors.New("multipart upload cannot be verified while it is assembled by this storage")
+
+// VerifyingCommitter is implemented by storages which read the parts of a multipart upload to assemble the object,
+// so its content can be checked on the way instead of being read again once it is stored
+type VerifyingCommitter interface {
+	// CommitUploadVerified assembles the object like CommitUpload, its content is written to w while it is assembled and
+	// verify is called before the object is stored. The object isn't stored and the parts are removed if verify fails.
+	CommitUploadVerified(path, additionalParameter string, w io.Writer, verify func() error) error
+}
+
+// MultipartUpload is an unfinished multipart upload of a storage
+type MultipartUpload struct {
+	// Path is the path of the uploaded object relative to the storage
+	Path      string
+	UploadID  string
+	Initiated time.Time
+}
+
+// MultipartLister is implemented by storages which keep track of their unfinished multipart uploads themselves,
+// so the uploads abandoned by their clients can be found and aborted
+type MultipartLister interface {
+	// IterateMultipartUploads iterates across the unfinished multipart uploads
+	IterateMultipartUploads(fn func(upload *MultipartUpload) error) error
+	// LastPartModified returns when the newest part of the multipart upload was uploaded, zero if it has no part
+	LastPartModified(path, uploadID string) (time.Time, error)
+	// AbortUpload aborts the multipart upload and removes its uploaded parts
+	AbortUpload(path, uploadID string) error
+}
+
+// multipartNonceLength is the number of random bytes of the upload id of a Gitea served multipart upload
+const multipartNonceLength = 16
+
+// NewMultipartUploadID returns a new upload id for a Gitea served multipart upload of the path.
+// The id is made of the hash of the path, so it is only accepted for the parts of that path,
+// and of a random nonce, so concurrent uploads of the same path never share their parts.
+func NewMultipartUploadID(path string) (string, error) {
+	nonce, err := util.CryptoRandomBytes(multipartNonceLength)
+	if err != nil {
+		return "", err
+	}
+	sum := sha1.Sum([]byte(path))
+	return hex.EncodeToString(sum[:]) + "-" + hex.EncodeToString(nonce), nil
+}
+
+// IsMultipartUploadIDOf reports whether the upload id has been generated by NewMultipartUploadID for the path
+func IsMultipartUploadIDOf(path, uploadID string) bool {
+	sum := sha1.Sum([]byte(path))
+	nonce, ok := strings.CutPrefix(uploadID, hex.EncodeToString(sum[:])+"-")
+	if !ok || len(nonce) != 2*multipartNonceLength {
+		return false
+	}
+	_, err := hex.DecodeString(nonce)
+	return err == nil
+}
+
+// MultipartPartSize returns the expected size of the part with the given index, or -1 if the index is out of range
+func MultipartPartSize(size int64, index int) int64 {
+	pos := int64(index-1) * multipart_chunk_size
+	if index < 1 || pos >= size {
+		return -1
+	}
+	if size-pos > multipart_chunk_size {
+		return multipart_chunk_size
+	}
+	return size - pos
+}
+
+// generateServedMultipartParts generates the parts and verify endpoint of a multipart upload whose parts are uploaded through Gitea
+func generateServedMultipartParts(path string, size int64) (parts []*structs.MultipartObjectPart, verify *structs.MultipartEndpoint, err error) {
+	uploadID, err := NewMultipartUploadID(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	for index := 1; ; index++ {
+		partSize := MultipartPartSize(size, index)
+		if partSize < 0 {
+			break
+		}
+		parts = append(parts, &structs.MultipartObjectPart{
+			Index: index,
+			Pos:   int64(index-1) * multipart_chunk_size,
+			Size:  partSize,
+			MultipartEndpoint: &structs.MultipartEndpoint{
+				ExpiresIn: default_expire,
+				Method:    http.MethodPut,
+			},
+		})
+	}
+	verify = &structs.MultipartEndpoint{
+		Params: &map[string]string{
+			"upload_id": uploadID,
+		},
+		AggregationParams: &map[string]string{
+			"key":  "part_ids",
+			"type": "array",
+			"item": "index,etag",
+		},
+	}
+	return parts, verify, nil
+}
+
+// parseServedMultipartCommit decodes the commit parameter of a multipart upload served by Gitea
+// and returns the parts sorted by their index
+func parseServedMultipartCommit(path, additionalParameter string) (*MultiPartCommitUpload, error) {
+	var param MultiPartCommitUpload
+	if err := json.Unmarshal([]byte(additionalParameter), &param); err != nil {
+		return nil, err
+	}
+	if !IsMultipartUploadIDOf(path, param.UploadID) {
+		return nil, fmt.Errorf("invalid upload id %q", param.UploadID)
+	}
+	if len(param.PartIDs) == 0 {
+		return nil, errors.New("parameter is empty")
+	}
+	sort.Slice(param.PartIDs, func(i, j int) bool {
+		return param.PartIDs[i].Index < param.PartIDs[j].Index
+	})
+	for i, p := range param.PartIDs {
+		if p.Index != i+1 {
+			return nil, fmt.Errorf("missing part %d", i+1)
+		}
+	}
+	return &param, nil
+}
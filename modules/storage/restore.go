@@ -0,0 +1,44 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package storage
+
+import (
+	"code.gitea.io/gitea/modules/container"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// coldStorageClasses are the storage classes whose objects must be restored before they can be read
+var coldStorageClasses = container.SetOf("GLACIER", "DEEP_ARCHIVE", "COLD")
+
+// Restorer is implemented by the storages whose objects may be stored in a cold storage class
+type Restorer interface {
+	// Restore requests a readable copy of the object kept for the given days if it is in a cold storage class,
+	// it returns true once the object can be read
+	Restore(path string, days int) (bool, error)
+}
+
+var _ Restorer = &MinioStorage{}
+
+// Restore requests the restore of an object stored in a cold storage class
+func (m *MinioStorage) Restore(path string, days int) (bool, error) {
+	info, err := m.client.StatObject(m.ctx, m.bucket, m.buildMinioPath(path), minio.StatObjectOptions{})
+	if err != nil {
+		return false, convertMinioErr(err)
+	}
+	if info.Restore != nil {
+		return !info.Restore.OngoingRestore, nil
+	}
+	if !coldStorageClasses.Contains(info.StorageClass) {
+		return true, nil
+	}
+
+	req := minio.RestoreRequest{}
+	req.SetDays(days)
+	req.SetGlacierJobParameters(minio.GlacierJobParameters{Tier: minio.TierStandard})
+	if err := m.client.RestoreObject(m.ctx, m.bucket, m.buildMinioPath(path), "", req); err != nil {
+		return false, convertMinioErr(err)
+	}
+	return false, nil
+}
@@ -0,0 +1,27 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package storage
+
+import (
+	"net/url"
+	"time"
+)
+
+// MaxSignedURLExpiry is the longest validity of a presigned url accepted by the S3 compatible storages
+const MaxSignedURLExpiry = 7 * 24 * time.Hour
+
+// SignedURLer is implemented by the storages whose redirect urls are presigned with a chosen validity
+type SignedURLer interface {
+	// SignedURL gets the redirect URL to a file, valid for the given duration
+	SignedURL(path, name string, expiry time.Duration) (*url.URL, error)
+}
+
+// SignedURL gets the redirect URL to a file valid for the expiry if the storage supports it,
+// or with the default validity of the storage otherwise
+func SignedURL(s ObjectStorage, path, name string, expiry time.Duration) (*url.URL, error) {
+	if signer, ok := As[SignedURLer](s); ok && expiry > 0 {
+		return signer.SignedURL(path, name, expiry)
+	}
+	return s.URL(path, name)
+}
@@ -119,6 +119,8 @@ var (
 
 	// LFS represents lfs storage
 	LFS ObjectStorage = uninitializedStorage
+	// LFSArchive represents the storage of the lfs objects which haven't been accessed for a while
+	LFSArchive ObjectStorage = uninitializedStorage
 
 	// Avatars represents user avatars storage
 	Avatars ObjectStorage = uninitializedStorage
@@ -165,7 +167,16 @@ func NewStorage(typStr Type, cfg *setting.Storage) (ObjectStorage, error) {
 		return nil, fmt.Errorf("Unsupported storage type: %s", typStr)
 	}
 
-	return fn(context.Background(), cfg)
+	s, err := fn(context.Background(), cfg)
+	if err != nil || cfg.DualWrite == nil {
+		return s, err
+	}
+	log.Info("Initialising dual write storage with type: %s", cfg.DualWrite.Type)
+	secondary, err := NewStorage(cfg.DualWrite.Type, cfg.DualWrite)
+	if err != nil {
+		return nil, err
+	}
+	return NewDualWriteStorage(s, secondary), nil
 }
 
 func initAvatars() (err error) {
@@ -187,10 +198,15 @@ func initAttachments() (err error) {
 func initLFS() (err error) {
 	if !setting.LFS.StartServer {
 		LFS = discardStorage("LFS isn't enabled")
+		LFSArchive = discardStorage("LFS isn't enabled")
 		return nil
 	}
 	log.Info("Initialising LFS storage with type: %s", setting.LFS.Storage.Type)
-	LFS, err = NewStorage(setting.LFS.Storage.Type, setting.LFS.Storage)
+	if LFS, err = NewStorage(setting.LFS.Storage.Type, setting.LFS.Storage); err != nil {
+		return err
+	}
+	log.Info("Initialising LFS archive storage with type: %s", setting.LFS.ArchiveStorage.Type)
+	LFSArchive, err = NewStorage(setting.LFS.ArchiveStorage.Type, setting.LFS.ArchiveStorage)
 	return err
 }
 
@@ -495,3 +495,17 @@ type PackagePayload struct {
 func (p *PackagePayload) JSONPayload() ([]byte, error) {
 	return json.MarshalIndent(p, "", "  ")
 }
+
+// WorkflowDispatchPayload represents the payload of a workflow run triggered manually
+type WorkflowDispatchPayload struct {
+	Workflow   string            `json:"workflow"`
+	Ref        string            `json:"ref"`
+	Inputs     map[string]string `json:"inputs"`
+	Repository *Repository       `json:"repository"`
+	Sender     *User             `json:"sender"`
+}
+
+// JSONPayload implements Payload
+func (p *WorkflowDispatchPayload) JSONPayload() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
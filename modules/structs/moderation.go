@@ -0,0 +1,43 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package structs
+
+import "time"
+
+// ModerationRecord represents the content moderation of a push
+// swagger:model
+type ModerationRecord struct {
+	ID int64 `json:"id"`
+	// the id of the pushed repository
+	RepoID int64 `json:"repo_id"`
+	// the full name of the pushed repository, empty if it has been deleted
+	RepoName string `json:"repo_name"`
+	Pusher   *User  `json:"pusher"`
+	Ref      string `json:"ref"`
+	Before   string `json:"before"`
+	After    string `json:"after"`
+	// the path of the offending file, empty if the commit messages or file names are offending
+	Path string `json:"path"`
+	// the blob SHA of the offending file or the SHA256 of the offending text segment
+	SegmentHash string `json:"segment_hash"`
+	Provider    string `json:"provider"`
+	Verdict     string `json:"verdict"`
+	// enum: passed,rejected,appealed,allowed,dismissed
+	Status       string `json:"status"`
+	AppealReason string `json:"appeal_reason"`
+	Reviewer     *User  `json:"reviewer"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+}
+
+// AppealModerationRecordOption options when appealing a rejected push
+// swagger:model
+type AppealModerationRecordOption struct {
+	// the reason why the content should be allowed
+	//
+	// required: true
+	Reason string `json:"reason" binding:"Required;MaxSize(1000)"`
+}
@@ -0,0 +1,149 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package structs
+
+import (
+	"strings"
+	"time"
+)
+
+// MQEventVersion is the schema version of the events published to the message queue.
+// It is increased whenever a field is removed or changes its meaning, new fields keep the version.
+const MQEventVersion = "1"
+
+// MQEventType is the type of an event published to the message queue
+type MQEventType string
+
+// The types of the events published to the message queue
+const (
+	MQEventPush               MQEventType = "push"
+	MQEventRefCreate          MQEventType = "push.ref_create"
+	MQEventRefDelete          MQEventType = "push.ref_delete"
+	MQEventRepositoryCreate   MQEventType = "repository.create"
+	MQEventRepositoryDelete   MQEventType = "repository.delete"
+	MQEventRepositoryTransfer MQEventType = "repository.transfer"
+	MQEventRepositoryRename   MQEventType = "repository.rename"
+	MQEventReleasePublish     MQEventType = "release.publish"
+	MQEventReleaseUpdate      MQEventType = "release.update"
+	MQEventReleaseDelete      MQEventType = "release.delete"
+	MQEventIssueOpen          MQEventType = "issue.open"
+	MQEventIssueClose         MQEventType = "issue.close"
+	MQEventIssueReopen        MQEventType = "issue.reopen"
+	MQEventIssueDelete        MQEventType = "issue.delete"
+	MQEventPullRequestOpen    MQEventType = "pull_request.open"
+	MQEventPullRequestClose   MQEventType = "pull_request.close"
+	MQEventPullRequestReopen  MQEventType = "pull_request.reopen"
+	MQEventPullRequestMerge   MQEventType = "pull_request.merge"
+	MQEventPullRequestSync    MQEventType = "pull_request.synchronize"
+	MQEventLFSObjectUpload    MQEventType = "lfs.upload"
+	MQEventPackagePublish     MQEventType = "package.publish"
+	MQEventPackageDelete      MQEventType = "package.delete"
+)
+
+// Category returns the category of the event type, the events of a category are published to the same topic
+func (t MQEventType) Category() string {
+	category, _, _ := strings.Cut(string(t), ".")
+	return category
+}
+
+// MQEvent is the envelope of every event published to the message queue
+type MQEvent struct {
+	// the unique id of the event, consumers may use it to drop the duplicates
+	ID      string      `json:"id"`
+	Type    MQEventType `json:"type"`
+	Version string      `json:"version"`
+	// the time when the event happened
+	Created    time.Time          `json:"created_at"`
+	Actor      *MQEventUser       `json:"actor,omitempty"`
+	Repository *MQEventRepository `json:"repository,omitempty"`
+	// one of the MQEvent*Payload types, depending on the category of the event
+	Payload any `json:"payload,omitempty"`
+}
+
+// MQEventUser is the user who triggered an event
+type MQEventUser struct {
+	ID    int64  `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email,omitempty"`
+}
+
+// MQEventRepository is the repository an event happened in
+type MQEventRepository struct {
+	ID       int64  `json:"id"`
+	Owner    string `json:"owner"`
+	Name     string `json:"name"`
+	FullName string `json:"full_name"`
+	Private  bool   `json:"private"`
+}
+
+// MQEventCommit is a commit of a push event
+type MQEventCommit struct {
+	ID      string    `json:"id"`
+	Message string    `json:"message"`
+	Author  string    `json:"author"`
+	Time    time.Time `json:"timestamp"`
+}
+
+// MQEventPushPayload is the payload of the push, push.ref_create and push.ref_delete events,
+// they all belong to the push category
+type MQEventPushPayload struct {
+	Ref    string `json:"ref"`
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+	// only the latest commits are included, TotalCommits is the number of all pushed commits
+	Commits      []*MQEventCommit `json:"commits,omitempty"`
+	TotalCommits int              `json:"total_commits"`
+	// true if the push was done by a mirror synchronization
+	Mirror bool `json:"mirror,omitempty"`
+}
+
+// MQEventRepositoryPayload is the payload of the repository events
+type MQEventRepositoryPayload struct {
+	// how a created repository came to be: create, adopt, migrate or fork
+	Origin string `json:"origin,omitempty"`
+	// the full name of the repository a fork was created from
+	ForkOf string `json:"fork_of,omitempty"`
+	// the owner before a transfer
+	OldOwner string `json:"old_owner,omitempty"`
+	// the name before a rename
+	OldName string `json:"old_name,omitempty"`
+}
+
+// MQEventReleasePayload is the payload of the release events
+type MQEventReleasePayload struct {
+	ID           int64  `json:"id"`
+	TagName      string `json:"tag_name"`
+	Target       string `json:"target"`
+	Title        string `json:"title"`
+	CommitID     string `json:"commit_id"`
+	IsDraft      bool   `json:"draft"`
+	IsPrerelease bool   `json:"prerelease"`
+}
+
+// MQEventIssuePayload is the payload of the issue and pull_request events
+type MQEventIssuePayload struct {
+	ID     int64  `json:"id"`
+	Number int64  `json:"number"`
+	Title  string `json:"title"`
+	Closed bool   `json:"closed"`
+	// the following fields are only set for the pull requests
+	HeadBranch     string `json:"head_branch,omitempty"`
+	BaseBranch     string `json:"base_branch,omitempty"`
+	MergedCommitID string `json:"merged_commit_id,omitempty"`
+}
+
+// MQEventLFSPayload is the payload of the lfs events
+type MQEventLFSPayload struct {
+	Oid  string `json:"oid"`
+	Size int64  `json:"size"`
+}
+
+// MQEventPackagePayload is the payload of the package events
+type MQEventPackagePayload struct {
+	ID      int64  `json:"id"`
+	Owner   string `json:"owner"`
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
@@ -0,0 +1,26 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package structs
+
+// PushPolicy represents the push policy of an organization or a repository
+// swagger:model
+type PushPolicy struct {
+	// the allowed licenses of the model card front matter, empty inherits the upper level
+	Licenses []string `json:"licenses"`
+	// the max size in bytes of the pushed files, 0 inherits the upper level
+	MaxFileSize int64 `json:"max_file_size"`
+	// the allowed licenses after the inheritance is resolved, any license is allowed if it is empty
+	EffectiveLicenses []string `json:"effective_licenses"`
+	// the max file size after the inheritance is resolved, there is no limit if it is 0
+	EffectiveMaxFileSize int64 `json:"effective_max_file_size"`
+}
+
+// EditPushPolicyOption options when editing the push policy of an organization or a repository
+// swagger:model
+type EditPushPolicyOption struct {
+	// the allowed licenses of the model card front matter, empty inherits the upper level
+	Licenses []string `json:"licenses"`
+	// the max size in bytes of the pushed files, 0 inherits the upper level
+	MaxFileSize int64 `json:"max_file_size" binding:"Range(0,1099511627776)"`
+}
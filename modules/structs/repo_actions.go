@@ -0,0 +1,139 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package structs
+
+import "time"
+
+// CreateActionWorkflowDispatchOption options when triggering a workflow manually
+// swagger:model
+type CreateActionWorkflowDispatchOption struct {
+	// branch or tag to run the workflow on
+	//
+	// required: true
+	// example: main
+	Ref string `json:"ref" binding:"Required"`
+	// values of the workflow_dispatch inputs of the workflow, the defaults are used for the missing inputs
+	Inputs map[string]string `json:"inputs"`
+}
+
+// ActionWorkflowRun represents a run of a workflow
+// swagger:model
+type ActionWorkflowRun struct {
+	ID int64 `json:"id"`
+	// the title of the run, the first line of the commit message for most events
+	DisplayTitle string `json:"display_title"`
+	// the file name of the workflow
+	WorkflowID string `json:"workflow_id"`
+	// the number of the run in the repository, it's used in the urls of the web pages
+	RunNumber int64  `json:"run_number"`
+	Event     string `json:"event"`
+	// one of queued, waiting, in_progress and completed
+	Status string `json:"status"`
+	// one of success, failure, cancelled and skipped, only when the run is completed
+	Conclusion      string `json:"conclusion,omitempty"`
+	HeadBranch      string `json:"head_branch"`
+	HeadSha         string `json:"head_sha"`
+	URL             string `json:"url"`
+	HTMLURL         string `json:"html_url"`
+	JobsURL         string `json:"jobs_url"`
+	ArtifactsURL    string `json:"artifacts_url"`
+	CancelURL       string `json:"cancel_url"`
+	RerunURL        string `json:"rerun_url"`
+	TriggeringActor *User  `json:"triggering_actor"`
+	// swagger:strfmt date-time
+	CreatedAt time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	UpdatedAt time.Time `json:"updated_at"`
+	// swagger:strfmt date-time
+	RunStartedAt *time.Time `json:"run_started_at,omitempty"`
+}
+
+// ActionWorkflowRunsResponse returns workflow runs
+// swagger:model
+type ActionWorkflowRunsResponse struct {
+	TotalCount   int64                `json:"total_count"`
+	WorkflowRuns []*ActionWorkflowRun `json:"workflow_runs"`
+}
+
+// ActionWorkflowJob represents a job of a workflow run
+// swagger:model
+type ActionWorkflowJob struct {
+	ID      int64  `json:"id"`
+	RunID   int64  `json:"run_id"`
+	RunURL  string `json:"run_url"`
+	HeadSha string `json:"head_sha"`
+	URL     string `json:"url"`
+	HTMLURL string `json:"html_url"`
+	Name    string `json:"name"`
+	// one of queued, waiting, in_progress and completed
+	Status string `json:"status"`
+	// one of success, failure, cancelled and skipped, only when the job is completed
+	Conclusion string `json:"conclusion,omitempty"`
+	// the number of times the job has been run
+	RunAttempt int64 `json:"run_attempt"`
+	// the labels of the runners which can run the job
+	Labels     []string              `json:"labels"`
+	RunnerID   int64                 `json:"runner_id,omitempty"`
+	RunnerName string                `json:"runner_name,omitempty"`
+	Steps      []*ActionWorkflowStep `json:"steps"`
+	// swagger:strfmt date-time
+	CreatedAt time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	// swagger:strfmt date-time
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// ActionWorkflowStep represents a step of a job
+// swagger:model
+type ActionWorkflowStep struct {
+	Name       string `json:"name"`
+	Number     int64  `json:"number"`
+	Status     string `json:"status"`
+	Conclusion string `json:"conclusion,omitempty"`
+	// swagger:strfmt date-time
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	// swagger:strfmt date-time
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// ActionWorkflowJobsResponse returns the jobs of a workflow run
+// swagger:model
+type ActionWorkflowJobsResponse struct {
+	TotalCount int64                `json:"total_count"`
+	Jobs       []*ActionWorkflowJob `json:"jobs"`
+}
+
+// ActionArtifact represents an artifact uploaded by a workflow run
+// swagger:model
+type ActionArtifact struct {
+	ID                 int64  `json:"id"`
+	Name               string `json:"name"`
+	SizeInBytes        int64  `json:"size_in_bytes"`
+	URL                string `json:"url"`
+	ArchiveDownloadURL string `json:"archive_download_url"`
+	// whether the files of the artifact have been removed
+	Expired     bool                      `json:"expired"`
+	WorkflowRun *ActionWorkflowRunSummary `json:"workflow_run"`
+	// swagger:strfmt date-time
+	CreatedAt time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	UpdatedAt time.Time `json:"updated_at"`
+	// swagger:strfmt date-time
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ActionWorkflowRunSummary represents the workflow run an artifact belongs to
+type ActionWorkflowRunSummary struct {
+	ID         int64  `json:"id"`
+	HeadBranch string `json:"head_branch"`
+	HeadSha    string `json:"head_sha"`
+}
+
+// ActionArtifactsResponse returns artifacts
+// swagger:model
+type ActionArtifactsResponse struct {
+	TotalCount int64             `json:"total_count"`
+	Artifacts  []*ActionArtifact `json:"artifacts"`
+}
@@ -0,0 +1,23 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package structs
+
+// RepoDownloadStat represents the downloads of a kind from a repository on a day
+type RepoDownloadStat struct {
+	// the UTC day, formatted as YYYY-MM-DD
+	Date string `json:"date"`
+	// enum: clone,archive,raw,lfs
+	Kind  string `json:"kind"`
+	Count int64  `json:"count"`
+	// number of distinct requesters on the day
+	Unique int64 `json:"unique"`
+}
+
+// RepoDownloadCount represents the downloads from a repository over a period
+type RepoDownloadCount struct {
+	Repository *Repository `json:"repository"`
+	Count      int64       `json:"count"`
+	// sum of the distinct requesters of every day
+	Unique int64 `json:"unique"`
+}
@@ -0,0 +1,273 @@
+package opentelemetry
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	otellog "go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// exporters are the trace, metric and log exporters of the config sharing the same connection or writer
+type exporters struct {
+	trace  sdktrace.SpanExporter
+	metric sdkmetric.Exporter
+	log    sdklog.Exporter
+	// close releases the connection or the file once the providers are shut down
+	close func() error
+}
+
+// newTLSConfig returns the TLS config of the OTLP exporter from the CA and client certificates of the config
+func newTLSConfig(config *setting.OtelConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if config.CACertFile != "" {
+		pem, err := os.ReadFile(config.CACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the CA certificate: %w", err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in %s", config.CACertFile)
+		}
+	}
+	if config.ClientCertFile != "" || config.ClientKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(config.ClientCertFile, config.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// newExporters creates the exporters of the config
+func newExporters(ctx context.Context, config *setting.OtelConfig) (*exporters, error) {
+	switch config.Exporter {
+	case setting.OtelExporterStdout:
+		return newWriterExporters(nopCloser{os.Stdout})
+	case setting.OtelExporterFile:
+		if err := os.MkdirAll(filepath.Dir(config.FilePath), os.ModePerm); err != nil {
+			return nil, fmt.Errorf("failed to create the directory of %s: %w", config.FilePath, err)
+		}
+		f, err := os.OpenFile(config.FilePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", config.FilePath, err)
+		}
+		return newWriterExporters(f)
+	}
+
+	var tlsConfig *tls.Config
+	if !config.Insecure {
+		var err error
+		if tlsConfig, err = newTLSConfig(config); err != nil {
+			return nil, err
+		}
+	}
+	if config.Protocol == setting.OtelProtocolHTTP {
+		return newHTTPExporters(ctx, config, tlsConfig)
+	}
+	return newGRPCExporters(ctx, config, tlsConfig)
+}
+
+// Initialize a gRPC connection to be used by both the tracer and meter
+// providers.
+func initConn(config *setting.OtelConfig, tlsConfig *tls.Config) (*grpc.ClientConn, error) {
+	creds := insecure.NewCredentials()
+	if tlsConfig != nil {
+		creds = credentials.NewTLS(tlsConfig)
+	}
+	conn, err := grpc.NewClient(config.Endpoint, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gRPC connection to collector: %w", err)
+	}
+
+	return conn, err
+}
+
+func newGRPCExporters(ctx context.Context, config *setting.OtelConfig, tlsConfig *tls.Config) (*exporters, error) {
+	conn, err := initConn(config, tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	traceExporter, err := otlptracegrpc.New(ctx, otlptracegrpc.WithGRPCConn(conn), otlptracegrpc.WithHeaders(config.Headers))
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("failed to create trace exporter: %w", err)
+	}
+	metricExporter, err := otlpmetricgrpc.New(ctx, otlpmetricgrpc.WithGRPCConn(conn), otlpmetricgrpc.WithHeaders(config.Headers))
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("failed to create metrics exporter: %w", err)
+	}
+	// there is no gRPC log exporter for this version of the SDK, the logs are always sent over HTTP
+	logExporter, err := newHTTPLogExporter(ctx, config, tlsConfig)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	return &exporters{trace: traceExporter, metric: metricExporter, log: logExporter, close: conn.Close}, nil
+}
+
+func newHTTPExporters(ctx context.Context, config *setting.OtelConfig, tlsConfig *tls.Config) (*exporters, error) {
+	traceOpts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(config.Endpoint), otlptracehttp.WithHeaders(config.Headers)}
+	metricOpts := []otlpmetrichttp.Option{otlpmetrichttp.WithEndpoint(config.Endpoint), otlpmetrichttp.WithHeaders(config.Headers)}
+	if tlsConfig != nil {
+		traceOpts = append(traceOpts, otlptracehttp.WithTLSClientConfig(tlsConfig))
+		metricOpts = append(metricOpts, otlpmetrichttp.WithTLSClientConfig(tlsConfig))
+	} else {
+		traceOpts = append(traceOpts, otlptracehttp.WithInsecure())
+		metricOpts = append(metricOpts, otlpmetrichttp.WithInsecure())
+	}
+
+	traceExporter, err := otlptracehttp.New(ctx, traceOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace exporter: %w", err)
+	}
+	metricExporter, err := otlpmetrichttp.New(ctx, metricOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create metrics exporter: %w", err)
+	}
+	logExporter, err := newHTTPLogExporter(ctx, config, tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &exporters{trace: traceExporter, metric: metricExporter, log: logExporter, close: func() error { return nil }}, nil
+}
+
+func newHTTPLogExporter(ctx context.Context, config *setting.OtelConfig, tlsConfig *tls.Config) (sdklog.Exporter, error) {
+	if config.LogsEndpoint == "" {
+		return nil, nil
+	}
+	opts := []otlploghttp.Option{otlploghttp.WithEndpoint(config.LogsEndpoint), otlploghttp.WithHeaders(config.Headers)}
+	if tlsConfig != nil {
+		opts = append(opts, otlploghttp.WithTLSClientConfig(tlsConfig))
+	} else {
+		opts = append(opts, otlploghttp.WithInsecure())
+	}
+	logExporter, err := otlploghttp.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create log exporter: %w", err)
+	}
+	return logExporter, nil
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
+
+// syncWriter serializes the writes of the exporters sharing the writer so the JSON documents don't interleave
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (w *syncWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.Write(p)
+}
+
+// newWriterExporters creates the exporters writing the telemetry as JSON documents to w, for local debugging
+func newWriterExporters(w io.WriteCloser) (*exporters, error) {
+	sw := &syncWriter{w: w}
+	traceExporter, err := stdouttrace.New(stdouttrace.WithWriter(sw))
+	if err != nil {
+		_ = w.Close()
+		return nil, fmt.Errorf("failed to create trace exporter: %w", err)
+	}
+	metricExporter, err := stdoutmetric.New(stdoutmetric.WithWriter(sw))
+	if err != nil {
+		_ = w.Close()
+		return nil, fmt.Errorf("failed to create metrics exporter: %w", err)
+	}
+	return &exporters{trace: traceExporter, metric: metricExporter, log: &writerLogExporter{enc: json.NewEncoder(sw)}, close: w.Close}, nil
+}
+
+// writerLogExporter writes the log records as JSON documents, the SDK has no stdout log exporter for this version
+type writerLogExporter struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+var _ sdklog.Exporter = &writerLogExporter{}
+
+type jsonLogRecord struct {
+	Timestamp    time.Time         `json:"Timestamp"`
+	Severity     otellog.Severity  `json:"Severity"`
+	SeverityText string            `json:"SeverityText"`
+	Body         string            `json:"Body"`
+	Attributes   map[string]string `json:"Attributes,omitempty"`
+	TraceID      string            `json:"TraceID,omitempty"`
+	SpanID       string            `json:"SpanID,omitempty"`
+	Scope        string            `json:"Scope"`
+}
+
+func (e *writerLogExporter) Export(ctx context.Context, records []sdklog.Record) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for i := range records {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		r := &records[i]
+		rec := jsonLogRecord{
+			Timestamp:    r.Timestamp(),
+			Severity:     r.Severity(),
+			SeverityText: r.SeverityText(),
+			Body:         r.Body().String(),
+			Scope:        r.InstrumentationScope().Name,
+		}
+		if r.AttributesLen() > 0 {
+			rec.Attributes = make(map[string]string, r.AttributesLen())
+			r.WalkAttributes(func(kv otellog.KeyValue) bool {
+				rec.Attributes[kv.Key] = kv.Value.String()
+				return true
+			})
+		}
+		if r.TraceID().IsValid() {
+			rec.TraceID = r.TraceID().String()
+		}
+		if r.SpanID().IsValid() {
+			rec.SpanID = r.SpanID().String()
+		}
+		if err := e.enc.Encode(rec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *writerLogExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+func (e *writerLogExporter) ForceFlush(context.Context) error {
+	return nil
+}
@@ -0,0 +1,43 @@
+package opentelemetry
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	otellog "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/global"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInitFileExporter(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "otel", "otel.json")
+	shutdown, err := Init(context.Background(), &setting.OtelConfig{
+		Name:      "gitea",
+		Fractions: 1,
+		Exporter:  setting.OtelExporterFile,
+		FilePath:  filePath,
+	})
+	assert.NoError(t, err)
+
+	ctx, span := otel.Tracer("test").Start(context.Background(), "test span")
+	var record otellog.Record
+	record.SetBody(otellog.StringValue("test record"))
+	record.AddAttributes(otellog.String("repo.name", "owner/repo"))
+	global.Logger("test").Emit(ctx, record)
+	span.End()
+
+	assert.NoError(t, shutdown(context.Background()))
+
+	data, err := os.ReadFile(filePath)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"Name":"test span"`)
+	assert.Contains(t, string(data), `"Body":"test record"`)
+	assert.Contains(t, string(data), `"repo.name":"owner/repo"`)
+	assert.Contains(t, string(data), `"TraceID":"`+span.SpanContext().TraceID().String()+`"`)
+}
@@ -0,0 +1,23 @@
+package opentelemetry
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/log/global"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// Configures the logger provider exporting to the log exporter, it is used by the "otlp" writer of modules/log.
+func initLoggerProvider(
+	res *resource.Resource,
+	logExporter sdklog.Exporter) func(context.Context) error {
+	loggerProvider := sdklog.NewLoggerProvider(
+		sdklog.WithProcessor(sdklog.NewBatchProcessor(logExporter)),
+		sdklog.WithResource(res),
+	)
+	global.SetLoggerProvider(loggerProvider)
+
+	// Shutdown will flush any remaining records and shut down the exporter.
+	return loggerProvider.Shutdown
+}
@@ -0,0 +1,26 @@
+package opentelemetry
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// Configures the meter provider exporting to the metric exporter every interval.
+// The instruments of the packages are created from the global meter provider, they export once it is set.
+func initMeterProvider(
+	res *resource.Resource,
+	metricExporter sdkmetric.Exporter,
+	interval time.Duration) func(context.Context) error {
+	meterProvider := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricExporter, sdkmetric.WithInterval(interval))),
+		sdkmetric.WithResource(res),
+	)
+	otel.SetMeterProvider(meterProvider)
+
+	// Shutdown will export the last metrics and shut down the exporter.
+	return meterProvider.Shutdown
+}
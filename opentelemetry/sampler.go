@@ -0,0 +1,75 @@
+package opentelemetry
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gobwas/glob"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"code.gitea.io/gitea/modules/setting"
+)
+
+type routeSamplingRule struct {
+	route   glob.Glob
+	sampler sdktrace.Sampler
+}
+
+// routeSampler samples the traces started by the requests with the ratio of the first rule matching their path,
+// the other traces are sampled by the fallback sampler
+type routeSampler struct {
+	rules    []routeSamplingRule
+	fallback sdktrace.Sampler
+}
+
+var _ sdktrace.Sampler = &routeSampler{}
+
+// newSampler returns the sampler of the config, the spans whose parent is known follow the decision of the parent
+// so a trace is either complete or dropped
+func newSampler(config *setting.OtelConfig) (sdktrace.Sampler, error) {
+	sampler := &routeSampler{
+		rules:    make([]routeSamplingRule, 0, len(config.SamplingRules)),
+		fallback: sdktrace.TraceIDRatioBased(config.Fractions),
+	}
+	for _, rule := range config.SamplingRules {
+		g, err := glob.Compile(rule.Route, '/')
+		if err != nil {
+			return nil, fmt.Errorf("invalid sampling route %q: %w", rule.Route, err)
+		}
+		sampler.rules = append(sampler.rules, routeSamplingRule{route: g, sampler: sdktrace.TraceIDRatioBased(rule.Ratio)})
+	}
+	return sdktrace.ParentBased(sampler), nil
+}
+
+// requestPath returns the path of the request of a server span from the attributes set by otelhttp
+func requestPath(p sdktrace.SamplingParameters) string {
+	if p.Kind != trace.SpanKindServer {
+		return ""
+	}
+	for _, attr := range p.Attributes {
+		switch attr.Key {
+		case "url.path":
+			return attr.Value.AsString()
+		case "http.target":
+			path, _, _ := strings.Cut(attr.Value.AsString(), "?")
+			return path
+		}
+	}
+	return ""
+}
+
+func (s *routeSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	if path := requestPath(p); path != "" {
+		for _, rule := range s.rules {
+			if rule.route.Match(path) {
+				return rule.sampler.ShouldSample(p)
+			}
+		}
+	}
+	return s.fallback.ShouldSample(p)
+}
+
+func (s *routeSampler) Description() string {
+	return fmt.Sprintf("RouteSampler{rules:%d,fallback:%s}", len(s.rules), s.fallback.Description())
+}
@@ -0,0 +1,41 @@
+package opentelemetry
+
+import (
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouteSampler(t *testing.T) {
+	sampler, err := newSampler(&setting.OtelConfig{
+		Fractions: 1,
+		SamplingRules: []setting.OtelSamplingRule{
+			{Route: "/api/v1/repos/*/*/git/**", Ratio: 0},
+			{Route: "/user/events", Ratio: 0},
+		},
+	})
+	assert.NoError(t, err)
+
+	traceID := trace.TraceID{1}
+	sample := func(kind trace.SpanKind, attrs ...attribute.KeyValue) sdktrace.SamplingDecision {
+		return sampler.ShouldSample(sdktrace.SamplingParameters{TraceID: traceID, Kind: kind, Attributes: attrs}).Decision
+	}
+
+	assert.Equal(t, sdktrace.Drop, sample(trace.SpanKindServer, semconv.HTTPTargetKey.String("/api/v1/repos/owner/repo/git/refs/heads")))
+	assert.Equal(t, sdktrace.Drop, sample(trace.SpanKindServer, attribute.String("url.path", "/user/events")))
+	assert.Equal(t, sdktrace.RecordAndSample, sample(trace.SpanKindServer, semconv.HTTPTargetKey.String("/api/v1/repos/owner/repo/issues?git=1")))
+	// "*" doesn't match the separators
+	assert.Equal(t, sdktrace.RecordAndSample, sample(trace.SpanKindServer, semconv.HTTPTargetKey.String("/api/v1/repos/org/sub/repo/git/refs")))
+	// only the requests are sampled by route
+	assert.Equal(t, sdktrace.RecordAndSample, sample(trace.SpanKindInternal, semconv.HTTPTargetKey.String("/user/events")))
+
+	_, err = newSampler(&setting.OtelConfig{SamplingRules: []setting.OtelSamplingRule{{Route: "/api/[", Ratio: 1}}})
+	assert.Error(t, err)
+}
@@ -2,49 +2,27 @@ package opentelemetry
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"os"
 	"os/signal"
 
 	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 
 	"code.gitea.io/gitea/modules/setting"
 	"github.com/sirupsen/logrus"
 )
 
-// Initialize a gRPC connection to be used by both the tracer and meter
-// providers.
-func initConn(config *setting.OtelConfig) (*grpc.ClientConn, error) {
-	conn, err := grpc.NewClient(config.Endpoint,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create gRPC connection to collector: %w", err)
-	}
-
-	return conn, err
-}
-
-// Initializes an OTLP exporter, and configures the corresponding trace provider.
+// Configures the trace provider exporting to the trace exporter.
 func initTracerProvider(
-	ctx context.Context,
 	res *resource.Resource,
-	conn *grpc.ClientConn,
-	fractions float64) (func(context.Context) error, error) {
-	traceExporter, err := otlptracegrpc.New(ctx, otlptracegrpc.WithGRPCConn(conn))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create trace exporter: %w", err)
-	}
-
+	traceExporter sdktrace.SpanExporter,
+	sampler sdktrace.Sampler) func(context.Context) error {
 	tracerProvider := sdktrace.NewTracerProvider(
-		sdktrace.WithSampler(sdktrace.TraceIDRatioBased(fractions)),
+		sdktrace.WithSampler(sampler),
 		sdktrace.WithResource(res),
 		sdktrace.WithBatcher(traceExporter),
 	)
@@ -54,7 +32,51 @@ func initTracerProvider(
 		propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
 
 	// Shutdown will flush any remaining spans and shut down the exporter.
-	return tracerProvider.Shutdown, nil
+	return tracerProvider.Shutdown
+}
+
+// Init configures the tracer, meter and logger providers exporting with the exporter of the config without logging
+// anything, it is used by the sub commands whose output goes to the git client. The returned function flushes them.
+func Init(ctx context.Context, config *setting.OtelConfig) (func(context.Context) error, error) {
+	sampler, err := newSampler(config)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithAttributes(
+			// The service name used to display traces in backends
+			semconv.ServiceNameKey.String(config.Name),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	exps, err := newExporters(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	shutdownFuncs := make([]func(context.Context) error, 0, 3)
+	shutdownFuncs = append(shutdownFuncs, initTracerProvider(res, exps.trace, sampler))
+	if config.MetricsEnabled {
+		shutdownFuncs = append(shutdownFuncs, initMeterProvider(res, exps.metric, config.MetricsInterval))
+	}
+	if exps.log != nil {
+		shutdownFuncs = append(shutdownFuncs, initLoggerProvider(res, exps.log))
+	}
+
+	// the providers are shut down before the connection or the file their exporters write to
+	shutdown := func(ctx context.Context) error {
+		var errs []error
+		for _, fn := range shutdownFuncs {
+			errs = append(errs, fn(ctx))
+		}
+		errs = append(errs, exps.close())
+		return errors.Join(errs...)
+	}
+	return shutdown, nil
 }
 
 func InitTrace(config *setting.OtelConfig) func() {
@@ -72,33 +94,19 @@ func InitTrace(config *setting.OtelConfig) func() {
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer cancel()
 
-	conn, err := initConn(&setting.Otel)
-	if err != nil {
-		logrus.Fatal(err)
-	}
-
-	res, err := resource.New(ctx,
-		resource.WithAttributes(
-			// The service name used to display traces in backends
-			semconv.ServiceNameKey.String(config.Name),
-		),
-	)
-	if err != nil {
-		logrus.Fatal(err)
-	}
-
-	shutdownTracerProvider, err := initTracerProvider(ctx, res, conn, config.Fractions)
+	shutdownTracerProvider, err := Init(ctx, config)
 	if err != nil {
 		logrus.Fatal(err)
 	}
 
 	shutdown := func() {
 		if err := shutdownTracerProvider(ctx); err != nil {
-			logrus.Fatalf("failed to shutdown TracerProvider: %s", err)
+			logrus.Fatalf("failed to shutdown the OpenTelemetry providers: %s", err)
 		}
 	}
 
-	logrus.Infof("Tracing: %v enabled successfully", config)
+	// the headers are not logged, they usually carry the credentials of the collector
+	logrus.Infof("Tracing: enabled successfully, exporter: %s, protocol: %s, endpoint: %s", config.Exporter, config.Protocol, config.Endpoint)
 
 	return shutdown
 }
@@ -78,6 +78,7 @@ import (
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/web"
 	web_types "code.gitea.io/gitea/modules/web/types"
+	actions_service "code.gitea.io/gitea/services/actions"
 )
 
 const artifactRouteBase = "/_apis/pipelines/workflows/{run_id}/artifacts"
@@ -226,16 +227,23 @@ func (ar artifactRoutes) uploadArtifact(ctx *ArtifactContext) {
 		return
 	}
 
-	// get artifact retention days
-	expiredDays := setting.Actions.ArtifactRetentionDays
+	// get artifact retention days, the requested days are honored within the retention of the repository
+	var retentionDays int64
 	if queryRetentionDays := ctx.Req.URL.Query().Get("retentionDays"); queryRetentionDays != "" {
-		expiredDays, err = strconv.ParseInt(queryRetentionDays, 10, 64)
+		retentionDays, err = strconv.ParseInt(queryRetentionDays, 10, 64)
 		if err != nil {
 			log.Error("Error parse retention days: %v", err)
 			ctx.Error(http.StatusBadRequest, "Error parse retention days")
 			return
 		}
 	}
+	retention, err := actions.GetEffectiveArtifactRetention(ctx, task.OwnerID, task.RepoID)
+	if err != nil {
+		log.Error("Error get artifact retention: %v", err)
+		ctx.Error(http.StatusInternalServerError, "Error get artifact retention")
+		return
+	}
+	expiredDays := retention.ExpiredDays(retentionDays)
 	log.Debug("[artifact] upload chunk, name: %s, path: %s, size: %d, retention days: %d",
 		artifactName, artifactPath, fileRealTotalSize, expiredDays)
 
@@ -277,7 +285,7 @@ func (ar artifactRoutes) uploadArtifact(ctx *ArtifactContext) {
 // comfirmUploadArtifact comfirm upload artifact.
 // if all chunks are uploaded, merge them to one file.
 func (ar artifactRoutes) comfirmUploadArtifact(ctx *ArtifactContext) {
-	_, runID, ok := validateRunID(ctx)
+	task, runID, ok := validateRunID(ctx)
 	if !ok {
 		return
 	}
@@ -292,6 +300,14 @@ func (ar artifactRoutes) comfirmUploadArtifact(ctx *ArtifactContext) {
 		ctx.Error(http.StatusInternalServerError, "Error merge chunks")
 		return
 	}
+	// the oldest artifacts are removed by a queue to make room for the uploaded artifact, unless it alone is too large
+	if fits, err := actions_service.CheckUploadedArtifactSize(ctx, task.OwnerID, task.RepoID, runID, artifactName); err != nil {
+		log.Error("Error check artifact size: %v", err)
+	} else if !fits {
+		log.Error("Error artifact %s exceeds the max artifacts size", artifactName)
+		ctx.Error(http.StatusRequestEntityTooLarge, "Error artifact exceeds the max artifacts size")
+		return
+	}
 	ctx.JSON(http.StatusOK, map[string]string{
 		"message": "success",
 	})
@@ -6,6 +6,7 @@ package runner
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	actions_model "code.gitea.io/gitea/models/actions"
 	secret_model "code.gitea.io/gitea/models/secret"
@@ -205,24 +206,39 @@ func findTaskNeeds(ctx context.Context, task *actions_model.ActionTask) (map[str
 		return nil, fmt.Errorf("FindRunJobs: %w", err)
 	}
 
+	// a job of a called reusable workflow refers to the other jobs of the workflow by their ids in it
+	callerPrefix := ""
+	if i := strings.LastIndex(task.Job.JobID, "."); i >= 0 {
+		callerPrefix = task.Job.JobID[:i+1]
+	}
+
 	ret := make(map[string]*runnerv1.TaskNeed, len(needs))
 	for _, job := range jobs {
 		if !needs.Contains(job.JobID) {
 			continue
 		}
-		if job.TaskID == 0 || !job.Status.IsDone() {
+		if !job.Status.IsDone() {
 			// it shouldn't happen, or the job has been rerun
 			continue
 		}
 		outputs := make(map[string]string)
-		got, err := actions_model.FindTaskOutputByTaskID(ctx, job.TaskID)
-		if err != nil {
-			return nil, fmt.Errorf("FindTaskOutputByTaskID: %w", err)
-		}
-		for _, v := range got {
-			outputs[v.OutputKey] = v.OutputValue
+		if job.TaskID != 0 {
+			got, err := actions_model.FindTaskOutputByTaskID(ctx, job.TaskID)
+			if err != nil {
+				return nil, fmt.Errorf("FindTaskOutputByTaskID: %w", err)
+			}
+			for _, v := range got {
+				outputs[v.OutputKey] = v.OutputValue
+			}
+		} else if job.IsReusableWorkflowCall() {
+			if outputs, err = actions.EvaluateReusableWorkflowOutputs(ctx, job, jobs); err != nil {
+				return nil, fmt.Errorf("EvaluateReusableWorkflowOutputs: %w", err)
+			}
+		} else {
+			// it shouldn't happen, or the job has been rerun
+			continue
 		}
-		ret[job.JobID] = &runnerv1.TaskNeed{
+		ret[strings.TrimPrefix(job.JobID, callerPrefix)] = &runnerv1.TaskNeed{
 			Outputs: outputs,
 			Result:  runnerv1.Result(job.Status),
 		}
@@ -0,0 +1,178 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package admin
+
+import (
+	"net/http"
+
+	moderation_model "code.gitea.io/gitea/models/moderation"
+	"code.gitea.io/gitea/modules/context"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+	"code.gitea.io/gitea/services/convert"
+)
+
+// ListModerationRecords api for listing the content moderation records
+func ListModerationRecords(ctx *context.APIContext) {
+	// swagger:operation GET /admin/moderation/records admin adminListModerationRecords
+	// ---
+	// summary: List the content moderation records of the pushes
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: status
+	//   in: query
+	//   description: filter the records by their status
+	//   type: string
+	//   enum: [passed, rejected, appealed, allowed, dismissed]
+	// - name: repo_id
+	//   in: query
+	//   description: filter the records by the pushed repository
+	//   type: integer
+	//   format: int64
+	// - name: pusher_id
+	//   in: query
+	//   description: filter the records by the pusher
+	//   type: integer
+	//   format: int64
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ModerationRecordList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	opts := moderation_model.FindRecordsOptions{
+		ListOptions: utils.GetListOptions(ctx),
+		RepoID:      ctx.FormInt64("repo_id"),
+		DoerID:      ctx.FormInt64("pusher_id"),
+	}
+	if status := ctx.FormString("status"); status != "" {
+		if opts.Status = moderation_model.ParseRecordStatus(status); opts.Status == 0 {
+			ctx.Error(http.StatusUnprocessableEntity, "", "invalid status")
+			return
+		}
+	}
+
+	records, count, err := moderation_model.FindRecords(ctx, opts)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindRecords", err)
+		return
+	}
+	apiRecords := make([]*api.ModerationRecord, 0, len(records))
+	for _, record := range records {
+		if err := record.LoadAttributes(ctx); err != nil {
+			ctx.Error(http.StatusInternalServerError, "LoadAttributes", err)
+			return
+		}
+		apiRecords = append(apiRecords, convert.ToModerationRecord(ctx, record, ctx.Doer))
+	}
+
+	ctx.SetTotalCountHeader(count)
+	ctx.JSON(http.StatusOK, apiRecords)
+}
+
+// AllowModerationRecord api for allow-listing the content of a rejected push
+func AllowModerationRecord(ctx *context.APIContext) {
+	// swagger:operation POST /admin/moderation/records/{id}/allow admin adminAllowModerationRecord
+	// ---
+	// summary: Allow the content rejected by a moderation record, the same content is not moderated again
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the moderation record
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ModerationRecord"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	record := getReviewableModerationRecord(ctx)
+	if ctx.Written() {
+		return
+	}
+	if err := moderation_model.AllowRecord(ctx, record, ctx.Doer.ID); err != nil {
+		ctx.Error(http.StatusInternalServerError, "AllowRecord", err)
+		return
+	}
+	responseModerationRecord(ctx, record)
+}
+
+// DismissModerationRecord api for confirming the rejection of a push
+func DismissModerationRecord(ctx *context.APIContext) {
+	// swagger:operation POST /admin/moderation/records/{id}/dismiss admin adminDismissModerationRecord
+	// ---
+	// summary: Confirm the rejection of a moderation record
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the moderation record
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ModerationRecord"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	record := getReviewableModerationRecord(ctx)
+	if ctx.Written() {
+		return
+	}
+	if err := moderation_model.DismissRecord(ctx, record, ctx.Doer.ID); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DismissRecord", err)
+		return
+	}
+	responseModerationRecord(ctx, record)
+}
+
+func getReviewableModerationRecord(ctx *context.APIContext) *moderation_model.Record {
+	record, err := moderation_model.GetRecordByID(ctx, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if moderation_model.IsErrRecordNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetRecordByID", err)
+		}
+		return nil
+	}
+	if !record.IsReviewable() {
+		ctx.Error(http.StatusUnprocessableEntity, "", "only the rejected or appealed records can be reviewed")
+		return nil
+	}
+	return record
+}
+
+func responseModerationRecord(ctx *context.APIContext, record *moderation_model.Record) {
+	if err := record.LoadAttributes(ctx); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadAttributes", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToModerationRecord(ctx, record, ctx.Doer))
+}
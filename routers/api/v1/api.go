@@ -393,6 +393,14 @@ func reqRepoWriter(unitTypes ...unit.Type) func(ctx *context.APIContext) {
 	}
 }
 
+// mustEnableActions makes sure the actions are enabled
+func mustEnableActions(ctx *context.APIContext) {
+	if !setting.Actions.Enabled || unit.TypeActions.UnitGlobalDisabled() {
+		ctx.NotFound()
+		return
+	}
+}
+
 // reqRepoBranchWriter user should have a permission to write to a branch, or be a site admin
 func reqRepoBranchWriter(ctx *context.APIContext) {
 	options, ok := web.GetForm(ctx).(api.FileOptionInterface)
@@ -928,6 +936,11 @@ func Routes() *web.Route {
 					Delete(user.DeleteSecret)
 			})
 
+			m.Group("/moderation/records", func() {
+				m.Get("", user.ListMyModerationRecords)
+				m.Post("/{id}/appeal", bind(api.AppealModerationRecordOption{}), user.AppealModerationRecord)
+			})
+
 			m.Get("/followers", user.ListMyFollowers)
 			m.Group("/following", func() {
 				m.Get("", user.ListMyFollowing)
@@ -1011,6 +1024,7 @@ func Routes() *web.Route {
 		// Repos (requires repo scope)
 		m.Group("/repos", func() {
 			m.Get("/search", repo.Search)
+			m.Get("/downloads/top", repo.GetTopDownloads)
 
 			// (repo scope)
 			m.Post("/migrate", reqToken(), bind(api.MigrateRepoOptions{}), repo.Migrate)
@@ -1030,6 +1044,33 @@ func Routes() *web.Route {
 						Put(reqToken(), reqOwner(), bind(api.CreateOrUpdateSecretOption{}), repo.CreateOrUpdateSecret).
 						Delete(reqToken(), reqOwner(), repo.DeleteSecret)
 				})
+				m.Post("/actions/workflows/{workflow_id}/dispatches", reqToken(), reqRepoWriter(unit.TypeActions), mustEnableActions,
+					context.ReferencesGitRepo(), bind(api.CreateActionWorkflowDispatchOption{}), repo.DispatchWorkflow)
+				m.Group("/actions", func() {
+					m.Group("/runs", func() {
+						m.Get("", repo.ListActionRuns)
+						m.Group("/{run_id}", func() {
+							m.Get("", repo.GetActionRun)
+							m.Get("/jobs", repo.ListActionRunJobs)
+							m.Get("/artifacts", repo.ListActionRunArtifacts)
+							m.Group("", func() {
+								m.Post("/rerun", repo.RerunActionRun)
+								m.Post("/rerun-failed-jobs", repo.RerunFailedActionJobs)
+								m.Post("/cancel", repo.CancelActionRun)
+							}, reqToken(), reqRepoWriter(unit.TypeActions))
+						})
+					})
+					m.Group("/jobs/{job_id}", func() {
+						m.Get("", repo.GetActionJob)
+						m.Get("/logs", repo.DownloadActionJobLogs)
+						m.Post("/rerun", reqToken(), reqRepoWriter(unit.TypeActions), repo.RerunActionJob)
+					})
+					m.Group("/artifacts", func() {
+						m.Get("", repo.ListActionArtifacts)
+						m.Get("/{artifact_id}", repo.GetActionArtifact)
+						m.Get("/{artifact_id}/zip", repo.DownloadActionArtifact)
+					})
+				}, reqRepoReader(unit.TypeActions), mustEnableActions)
 				m.Group("/hooks/git", func() {
 					m.Combo("").Get(repo.ListGitHooks)
 					m.Group("/{id}", func() {
@@ -1091,6 +1132,8 @@ func Routes() *web.Route {
 					m.Post("", reqToken(), reqRepoWriter(unit.TypeCode), mustNotBeArchived, bind(api.CreateTagOption{}), repo.CreateTag)
 					m.Delete("/*", reqToken(), reqRepoWriter(unit.TypeCode), mustNotBeArchived, repo.DeleteTag)
 				}, reqRepoReader(unit.TypeCode), context.ReferencesGitRepo(true))
+				m.Combo("/push_policy", reqToken(), reqAdmin()).Get(repo.GetPushPolicy).
+					Put(bind(api.EditPushPolicyOption{}), repo.EditPushPolicy)
 				m.Group("/keys", func() {
 					m.Combo("").Get(repo.ListDeployKeys).
 						Post(bind(api.CreateKeyOption{}), repo.CreateDeployKey)
@@ -1241,6 +1284,7 @@ func Routes() *web.Route {
 				m.Get("/issue_config", context.ReferencesGitRepo(), repo.GetIssueConfig)
 				m.Get("/issue_config/validate", context.ReferencesGitRepo(), repo.ValidateIssueConfig)
 				m.Get("/languages", reqRepoReader(unit.TypeCode), repo.GetLanguages)
+				m.Get("/stats/downloads", reqRepoReader(unit.TypeCode), repo.GetDownloadStats)
 				m.Get("/activities/feeds", repo.ListRepoActivityFeeds)
 				m.Get("/new_pin_allowed", repo.AreNewIssuePinsAllowed)
 				m.Group("/avatar", func() {
@@ -1402,6 +1446,8 @@ func Routes() *web.Route {
 				m.Combo("/{username}").Get(reqToken(), org.IsMember).
 					Delete(reqToken(), reqOrgOwnership(), org.DeleteMember)
 			})
+			m.Combo("/push_policy", reqToken(), reqOrgOwnership()).Get(org.GetPushPolicy).
+				Put(bind(api.EditPushPolicyOption{}), org.EditPushPolicy)
 			m.Group("/actions/secrets", func() {
 				m.Get("", reqToken(), reqOrgOwnership(), org.ListActionsSecrets)
 				m.Combo("/{secretname}").
@@ -1494,6 +1540,11 @@ func Routes() *web.Route {
 				m.Post("/{username}/{reponame}", admin.AdoptRepository)
 				m.Delete("/{username}/{reponame}", admin.DeleteUnadoptedRepository)
 			})
+			m.Group("/moderation/records", func() {
+				m.Get("", admin.ListModerationRecords)
+				m.Post("/{id}/allow", admin.AllowModerationRecord)
+				m.Post("/{id}/dismiss", admin.DismissModerationRecord)
+			})
 			m.Group("/hooks", func() {
 				m.Combo("").Get(admin.ListHooks).
 					Post(bind(api.CreateHookOption{}), admin.CreateHook)
@@ -0,0 +1,100 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package org
+
+import (
+	"net/http"
+
+	git_model "code.gitea.io/gitea/models/git"
+	"code.gitea.io/gitea/modules/context"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/convert"
+	repo_service "code.gitea.io/gitea/services/repository"
+)
+
+// GetPushPolicy get the push policy inherited by the repositories of an organization
+func GetPushPolicy(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/push_policy organization orgGetPushPolicy
+	// ---
+	// summary: Get the push policy inherited by the repositories of an organization
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PushPolicy"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	responsePushPolicy(ctx)
+}
+
+// EditPushPolicy edit the push policy inherited by the repositories of an organization
+func EditPushPolicy(ctx *context.APIContext) {
+	// swagger:operation PUT /orgs/{org}/push_policy organization orgEditPushPolicy
+	// ---
+	// summary: Edit the push policy inherited by the repositories of an organization, it can only restrict the global policy
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditPushPolicyOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PushPolicy"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.EditPushPolicyOption)
+	policy := &git_model.PushPolicy{
+		OwnerID:     ctx.Org.Organization.ID,
+		Licenses:    form.Licenses,
+		MaxFileSize: form.MaxFileSize,
+	}
+	if err := repo_service.UpdatePushPolicy(ctx, policy, 0); err != nil {
+		if git_model.IsErrPushPolicyNotPermitted(err) {
+			ctx.Error(http.StatusForbidden, "UpdatePushPolicy", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "UpdatePushPolicy", err)
+		}
+		return
+	}
+	responsePushPolicy(ctx)
+}
+
+func responsePushPolicy(ctx *context.APIContext) {
+	orgID := ctx.Org.Organization.ID
+	policy, err := git_model.GetPushPolicy(ctx, orgID, 0)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetPushPolicy", err)
+		return
+	}
+	effective, err := git_model.GetEffectivePushPolicy(ctx, orgID, 0)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetEffectivePushPolicy", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToPushPolicy(policy, effective))
+}
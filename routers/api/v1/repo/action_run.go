@@ -0,0 +1,887 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package repo
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unit"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/actions"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/git"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
+	webhook_module "code.gitea.io/gitea/modules/webhook"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+	actions_service "code.gitea.io/gitea/services/actions"
+	"code.gitea.io/gitea/services/convert"
+)
+
+// getActionsListOptions returns the list options of the request, `per_page` is accepted for compatibility with GitHub
+func getActionsListOptions(ctx *context.APIContext) db.ListOptions {
+	listOptions := utils.GetListOptions(ctx)
+	if ctx.FormInt("limit") == 0 && ctx.FormInt("per_page") != 0 {
+		listOptions.PageSize = convert.ToCorrectPageSize(ctx.FormInt("per_page"))
+	}
+	if listOptions.Page <= 0 {
+		listOptions.Page = 1
+	}
+	return listOptions
+}
+
+// getActionRun returns the run of the repository given by the run_id parameter, with its attributes loaded
+func getActionRun(ctx *context.APIContext) *actions_model.ActionRun {
+	run, err := actions_model.GetRunByID(ctx, ctx.ParamsInt64("run_id"))
+	if err != nil {
+		if errors.Is(err, util.ErrNotExist) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetRunByID", err)
+		}
+		return nil
+	}
+	if run.RepoID != ctx.Repo.Repository.ID {
+		ctx.NotFound()
+		return nil
+	}
+	run.Repo = ctx.Repo.Repository
+	if err := run.LoadAttributes(ctx); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadAttributes", err)
+		return nil
+	}
+	return run
+}
+
+// getActionJob returns the job of the repository given by the job_id parameter, with its run and the jobs of the run
+func getActionJob(ctx *context.APIContext) (*actions_model.ActionRunJob, []*actions_model.ActionRunJob) {
+	job, err := actions_model.GetRunJobByID(ctx, ctx.ParamsInt64("job_id"))
+	if err != nil {
+		if errors.Is(err, util.ErrNotExist) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetRunJobByID", err)
+		}
+		return nil, nil
+	}
+	if job.RepoID != ctx.Repo.Repository.ID {
+		ctx.NotFound()
+		return nil, nil
+	}
+	run, err := actions_model.GetRunByID(ctx, job.RunID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetRunByID", err)
+		return nil, nil
+	}
+	run.Repo = ctx.Repo.Repository
+	if err := run.LoadAttributes(ctx); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadAttributes", err)
+		return nil, nil
+	}
+	jobs, err := actions_model.GetRunJobsByRunID(ctx, run.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetRunJobsByRunID", err)
+		return nil, nil
+	}
+	for _, v := range jobs {
+		v.Run = run
+		if v.ID == job.ID {
+			job = v
+		}
+	}
+	return job, jobs
+}
+
+// ListActionRuns lists the workflow runs of a repository
+func ListActionRuns(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/actions/runs repository listRepoActionRuns
+	// ---
+	// summary: List the workflow runs of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repository
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repository
+	//   type: string
+	//   required: true
+	// - name: branch
+	//   in: query
+	//   description: only the runs of the branch
+	//   type: string
+	// - name: event
+	//   in: query
+	//   description: only the runs triggered by the event, like push or pull_request
+	//   type: string
+	// - name: status
+	//   in: query
+	//   description: only the runs with the status or the conclusion
+	//   type: string
+	//   enum: [queued, waiting, in_progress, completed, success, failure, cancelled, skipped]
+	// - name: actor
+	//   in: query
+	//   description: only the runs triggered by the user
+	//   type: string
+	// - name: head_sha
+	//   in: query
+	//   description: only the runs of the commit
+	//   type: string
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ActionWorkflowRunsResponse"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	opts := actions_model.FindRunOptions{
+		ListOptions: getActionsListOptions(ctx),
+		RepoID:      ctx.Repo.Repository.ID,
+		Event:       webhook_module.HookEventType(ctx.FormString("event")),
+		CommitSHA:   ctx.FormString("head_sha"),
+	}
+	if branch := ctx.FormString("branch"); branch != "" {
+		opts.Ref = git.RefNameFromBranch(branch).String()
+	}
+	if status := ctx.FormString("status"); status != "" {
+		statuses, err := convert.ParseActionsStatus(status)
+		if err != nil {
+			ctx.Error(http.StatusUnprocessableEntity, "ParseActionsStatus", err)
+			return
+		}
+		opts.Status = statuses
+	}
+	if actor := ctx.FormString("actor"); actor != "" {
+		user, err := user_model.GetUserByName(ctx, actor)
+		if err != nil {
+			if !user_model.IsErrUserNotExist(err) {
+				ctx.Error(http.StatusInternalServerError, "GetUserByName", err)
+				return
+			}
+			ctx.SetTotalCountHeader(0)
+			ctx.JSON(http.StatusOK, &api.ActionWorkflowRunsResponse{WorkflowRuns: []*api.ActionWorkflowRun{}})
+			return
+		}
+		opts.TriggerUserID = user.ID
+	}
+
+	runs, total, err := actions_model.FindRuns(ctx, opts)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindRuns", err)
+		return
+	}
+	if err := runs.LoadTriggerUser(ctx); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadTriggerUser", err)
+		return
+	}
+
+	resp := &api.ActionWorkflowRunsResponse{
+		TotalCount:   total,
+		WorkflowRuns: make([]*api.ActionWorkflowRun, 0, len(runs)),
+	}
+	for _, run := range runs {
+		run.Repo = ctx.Repo.Repository
+		resp.WorkflowRuns = append(resp.WorkflowRuns, convert.ToActionWorkflowRun(ctx, run))
+	}
+
+	ctx.SetLinkHeader(int(total), opts.PageSize)
+	ctx.SetTotalCountHeader(total)
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// GetActionRun gets a workflow run of a repository
+func GetActionRun(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/actions/runs/{run_id} repository getRepoActionRun
+	// ---
+	// summary: Get a workflow run of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repository
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repository
+	//   type: string
+	//   required: true
+	// - name: run_id
+	//   in: path
+	//   description: id of the run
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ActionWorkflowRun"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	run := getActionRun(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToActionWorkflowRun(ctx, run))
+}
+
+// ListActionRunJobs lists the jobs of a workflow run
+func ListActionRunJobs(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/actions/runs/{run_id}/jobs repository listRepoActionRunJobs
+	// ---
+	// summary: List the jobs of a workflow run
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repository
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repository
+	//   type: string
+	//   required: true
+	// - name: run_id
+	//   in: path
+	//   description: id of the run
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ActionWorkflowJobsResponse"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	run := getActionRun(ctx)
+	if ctx.Written() {
+		return
+	}
+	jobs, err := actions_model.GetRunJobsByRunID(ctx, run.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetRunJobsByRunID", err)
+		return
+	}
+
+	listOptions := getActionsListOptions(ctx)
+	start, end := listOptions.GetStartEnd()
+	start, end = min(start, len(jobs)), min(end, len(jobs))
+	resp := &api.ActionWorkflowJobsResponse{
+		TotalCount: int64(len(jobs)),
+		Jobs:       make([]*api.ActionWorkflowJob, 0, end-start),
+	}
+	for i := start; i < end; i++ {
+		job, err := convert.ToActionWorkflowJob(ctx, run, jobs[i], i)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "ToActionWorkflowJob", err)
+			return
+		}
+		resp.Jobs = append(resp.Jobs, job)
+	}
+
+	ctx.SetLinkHeader(len(jobs), listOptions.PageSize)
+	ctx.SetTotalCountHeader(int64(len(jobs)))
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// RerunActionRun reruns all the jobs of a workflow run
+func RerunActionRun(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/actions/runs/{run_id}/rerun repository rerunRepoActionRun
+	// ---
+	// summary: Rerun all the jobs of a workflow run
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repository
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repository
+	//   type: string
+	//   required: true
+	// - name: run_id
+	//   in: path
+	//   description: id of the run
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "409":
+	//     "$ref": "#/responses/conflict"
+
+	rerunActionRun(ctx, false)
+}
+
+// RerunFailedActionJobs reruns the failed jobs of a workflow run and the jobs depending on them
+func RerunFailedActionJobs(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/actions/runs/{run_id}/rerun-failed-jobs repository rerunRepoActionRunFailedJobs
+	// ---
+	// summary: Rerun the failed jobs of a workflow run and the jobs depending on them
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repository
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repository
+	//   type: string
+	//   required: true
+	// - name: run_id
+	//   in: path
+	//   description: id of the run
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "409":
+	//     "$ref": "#/responses/conflict"
+
+	rerunActionRun(ctx, true)
+}
+
+func rerunActionRun(ctx *context.APIContext, onlyFailed bool) {
+	run := getActionRun(ctx)
+	if ctx.Written() {
+		return
+	}
+	if !checkActionRunRerunnable(ctx, run) {
+		return
+	}
+
+	jobs, err := actions_model.GetRunJobsByRunID(ctx, run.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetRunJobsByRunID", err)
+		return
+	}
+	if onlyFailed {
+		jobs = actions_service.GetFailedJobs(jobs)
+	}
+	if err := actions_service.RerunJobs(ctx, run, jobs); err != nil {
+		ctx.Error(http.StatusInternalServerError, "RerunJobs", err)
+		return
+	}
+
+	ctx.Status(http.StatusCreated)
+}
+
+// checkActionRunRerunnable checks that the run is done and its workflow is enabled
+func checkActionRunRerunnable(ctx *context.APIContext, run *actions_model.ActionRun) bool {
+	if !run.Status.IsDone() {
+		ctx.Error(http.StatusConflict, "RerunJobs", "the run is not done")
+		return false
+	}
+	if ctx.Repo.Repository.MustGetUnit(ctx, unit.TypeActions).ActionsConfig().IsWorkflowDisabled(run.WorkflowID) {
+		ctx.Error(http.StatusForbidden, "RerunJobs", fmt.Sprintf("workflow %q is disabled", run.WorkflowID))
+		return false
+	}
+	return true
+}
+
+// CancelActionRun cancels a workflow run
+func CancelActionRun(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/actions/runs/{run_id}/cancel repository cancelRepoActionRun
+	// ---
+	// summary: Cancel a workflow run
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repository
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repository
+	//   type: string
+	//   required: true
+	// - name: run_id
+	//   in: path
+	//   description: id of the run
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "202":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "409":
+	//     "$ref": "#/responses/conflict"
+
+	run := getActionRun(ctx)
+	if ctx.Written() {
+		return
+	}
+	if run.Status.IsDone() {
+		ctx.Error(http.StatusConflict, "CancelRun", "the run is already done")
+		return
+	}
+
+	jobs, err := actions_model.GetRunJobsByRunID(ctx, run.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetRunJobsByRunID", err)
+		return
+	}
+	if err := actions_service.CancelRun(ctx, jobs); err != nil {
+		ctx.Error(http.StatusInternalServerError, "CancelRun", err)
+		return
+	}
+
+	ctx.Status(http.StatusAccepted)
+}
+
+// GetActionJob gets a job of a workflow run
+func GetActionJob(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/actions/jobs/{job_id} repository getRepoActionJob
+	// ---
+	// summary: Get a job of a workflow run
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repository
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repository
+	//   type: string
+	//   required: true
+	// - name: job_id
+	//   in: path
+	//   description: id of the job
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ActionWorkflowJob"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	job, jobs := getActionJob(ctx)
+	if ctx.Written() {
+		return
+	}
+	index := 0
+	for i, v := range jobs {
+		if v.ID == job.ID {
+			index = i
+		}
+	}
+	resp, err := convert.ToActionWorkflowJob(ctx, job.Run, job, index)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "ToActionWorkflowJob", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// RerunActionJob reruns a job of a workflow run
+func RerunActionJob(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/actions/jobs/{job_id}/rerun repository rerunRepoActionJob
+	// ---
+	// summary: Rerun a job of a workflow run
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repository
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repository
+	//   type: string
+	//   required: true
+	// - name: job_id
+	//   in: path
+	//   description: id of the job
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "409":
+	//     "$ref": "#/responses/conflict"
+
+	job, jobs := getActionJob(ctx)
+	if ctx.Written() {
+		return
+	}
+	if !checkActionRunRerunnable(ctx, job.Run) {
+		return
+	}
+	if err := actions_service.RerunJobs(ctx, job.Run, actions_service.GetRerunJobs(job, jobs)); err != nil {
+		ctx.Error(http.StatusInternalServerError, "RerunJobs", err)
+		return
+	}
+
+	ctx.Status(http.StatusCreated)
+}
+
+// DownloadActionJobLogs downloads the logs of a job
+func DownloadActionJobLogs(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/actions/jobs/{job_id}/logs repository downloadRepoActionJobLogs
+	// ---
+	// summary: Download the logs of the latest attempt of a job
+	// produces:
+	// - text/plain
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repository
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repository
+	//   type: string
+	//   required: true
+	// - name: job_id
+	//   in: path
+	//   description: id of the job
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     description: the logs of the job
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "410":
+	//     description: the logs have been cleaned up
+
+	job, _ := getActionJob(ctx)
+	if ctx.Written() {
+		return
+	}
+	if job.TaskID == 0 {
+		ctx.NotFound("job is not started")
+		return
+	}
+
+	task, err := actions_model.GetTaskByID(ctx, job.TaskID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetTaskByID", err)
+		return
+	}
+	if task.LogExpired {
+		ctx.Error(http.StatusGone, "DownloadActionJobLogs", "logs have been cleaned up")
+		return
+	}
+
+	reader, err := actions.OpenLogs(ctx, task.LogInStorage, task.LogFilename)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "OpenLogs", err)
+		return
+	}
+	defer reader.Close()
+
+	ctx.ServeContent(reader, &context.ServeHeaderOptions{
+		Filename:           fmt.Sprintf("%v-%v-%v.log", job.Run.WorkflowID, job.Name, task.ID),
+		ContentLength:      &task.LogSize,
+		ContentType:        "text/plain",
+		ContentTypeCharset: "utf-8",
+		Disposition:        "attachment",
+	})
+}
+
+// ListActionRunArtifacts lists the artifacts of a workflow run
+func ListActionRunArtifacts(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/actions/runs/{run_id}/artifacts repository listRepoActionRunArtifacts
+	// ---
+	// summary: List the artifacts of a workflow run
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repository
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repository
+	//   type: string
+	//   required: true
+	// - name: run_id
+	//   in: path
+	//   description: id of the run
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: name
+	//   in: query
+	//   description: only the artifacts with the name
+	//   type: string
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ActionArtifactsResponse"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	run := getActionRun(ctx)
+	if ctx.Written() {
+		return
+	}
+	listActionArtifacts(ctx, run.ID)
+}
+
+// ListActionArtifacts lists the artifacts of a repository
+func ListActionArtifacts(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/actions/artifacts repository listRepoActionArtifacts
+	// ---
+	// summary: List the artifacts of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repository
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repository
+	//   type: string
+	//   required: true
+	// - name: name
+	//   in: query
+	//   description: only the artifacts with the name
+	//   type: string
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ActionArtifactsResponse"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	listActionArtifacts(ctx, 0)
+}
+
+func listActionArtifacts(ctx *context.APIContext, runID int64) {
+	opts := actions_model.FindArtifactsOptions{
+		ListOptions:  getActionsListOptions(ctx),
+		RepoID:       ctx.Repo.Repository.ID,
+		RunID:        runID,
+		ArtifactName: ctx.FormString("name"),
+	}
+	arts, total, err := actions_model.FindArtifactSummaries(ctx, opts)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindArtifactSummaries", err)
+		return
+	}
+
+	resp := &api.ActionArtifactsResponse{
+		TotalCount: total,
+		Artifacts:  make([]*api.ActionArtifact, 0, len(arts)),
+	}
+	runs := make(map[int64]*actions_model.ActionRun)
+	for _, art := range arts {
+		run, ok := runs[art.RunID]
+		if !ok {
+			if run, err = actions_model.GetRunByID(ctx, art.RunID); err != nil {
+				ctx.Error(http.StatusInternalServerError, "GetRunByID", err)
+				return
+			}
+			runs[art.RunID] = run
+		}
+		resp.Artifacts = append(resp.Artifacts, convert.ToActionArtifact(ctx.Repo.Repository, art, run))
+	}
+
+	ctx.SetLinkHeader(int(total), opts.PageSize)
+	ctx.SetTotalCountHeader(total)
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// getActionArtifact returns the artifact of the repository given by the artifact_id parameter, and its run
+func getActionArtifact(ctx *context.APIContext) (*actions_model.ActionArtifactSummary, *actions_model.ActionRun) {
+	art, err := actions_model.GetArtifactSummaryByID(ctx, ctx.Repo.Repository.ID, ctx.ParamsInt64("artifact_id"))
+	if err != nil {
+		if errors.Is(err, util.ErrNotExist) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetArtifactSummaryByID", err)
+		}
+		return nil, nil
+	}
+	run, err := actions_model.GetRunByID(ctx, art.RunID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetRunByID", err)
+		return nil, nil
+	}
+	return art, run
+}
+
+// GetActionArtifact gets an artifact of a repository
+func GetActionArtifact(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/actions/artifacts/{artifact_id} repository getRepoActionArtifact
+	// ---
+	// summary: Get an artifact of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repository
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repository
+	//   type: string
+	//   required: true
+	// - name: artifact_id
+	//   in: path
+	//   description: id of the artifact
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ActionArtifact"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	art, run := getActionArtifact(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToActionArtifact(ctx.Repo.Repository, art, run))
+}
+
+// DownloadActionArtifact downloads the files of an artifact as a zip archive
+func DownloadActionArtifact(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/actions/artifacts/{artifact_id}/zip repository downloadRepoActionArtifact
+	// ---
+	// summary: Download the files of an artifact as a zip archive
+	// produces:
+	// - application/zip
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repository
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repository
+	//   type: string
+	//   required: true
+	// - name: artifact_id
+	//   in: path
+	//   description: id of the artifact
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     description: the zip archive of the artifact
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "410":
+	//     description: the artifact has expired
+
+	art, _ := getActionArtifact(ctx)
+	if ctx.Written() {
+		return
+	}
+	if art.IsExpired() {
+		ctx.Error(http.StatusGone, "DownloadActionArtifact", "the artifact has expired")
+		return
+	}
+
+	arts, err := actions_model.ListArtifactsByRunIDAndName(ctx, art.RunID, art.ArtifactName)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "ListArtifactsByRunIDAndName", err)
+		return
+	}
+	files := make([]*actions_model.ActionArtifact, 0, len(arts))
+	for _, file := range arts {
+		if file.Status == int64(actions_model.ArtifactStatusUploadConfirmed) {
+			files = append(files, file)
+		}
+	}
+
+	ctx.Resp.Header().Set("Content-Type", "application/zip")
+	ctx.Resp.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s.zip; filename*=UTF-8''%s.zip", url.PathEscape(art.ArtifactName), art.ArtifactName))
+	if err := actions_service.WriteArtifactZip(ctx.Resp, files); err != nil {
+		ctx.Error(http.StatusInternalServerError, "WriteArtifactZip", err)
+		return
+	}
+}
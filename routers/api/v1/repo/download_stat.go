@@ -0,0 +1,200 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package repo
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	access_model "code.gitea.io/gitea/models/perm/access"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/context"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+	"code.gitea.io/gitea/services/convert"
+)
+
+// defaultDownloadStatsPeriod is the period of the download statistics if no start is given
+const defaultDownloadStatsPeriod = 30 * 24 * time.Hour
+
+// getDownloadStatsOptions reads the kind and the period of the download statistics from the query
+func getDownloadStatsOptions(ctx *context.APIContext) (repo_model.FindDownloadStatsOptions, bool) {
+	before, since, err := context.GetQueryBeforeSince(ctx.Base)
+	if err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "GetQueryBeforeSince", err)
+		return repo_model.FindDownloadStatsOptions{}, false
+	}
+	until := time.Now()
+	if before > 0 {
+		until = time.Unix(before, 0)
+	}
+	from := until.Add(-defaultDownloadStatsPeriod)
+	if since > 0 {
+		from = time.Unix(since, 0)
+	}
+
+	kind := repo_model.DownloadKind(ctx.FormTrim("kind"))
+	if kind != "" && !kind.IsValid() {
+		ctx.Error(http.StatusUnprocessableEntity, "", fmt.Errorf("unknown download kind %q", kind))
+		return repo_model.FindDownloadStatsOptions{}, false
+	}
+
+	return repo_model.FindDownloadStatsOptions{
+		Kind:  kind,
+		Since: repo_model.DownloadDay(from),
+		Until: repo_model.DownloadDay(until),
+	}, true
+}
+
+// GetDownloadStats returns the daily download statistics of a repository
+func GetDownloadStats(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/stats/downloads repository repoGetDownloadStats
+	// ---
+	// summary: Get the daily clone and download counts of a repository
+	// produces:
+	//   - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: kind
+	//   in: query
+	//   description: only count this kind of downloads
+	//   type: string
+	//   enum: [clone, archive, raw, lfs]
+	// - name: since
+	//   in: query
+	//   description: start of the period in ISO 8601 format, defaults to 30 days before its end
+	//   type: string
+	//   format: date-time
+	// - name: before
+	//   in: query
+	//   description: end of the period in ISO 8601 format, defaults to now
+	//   type: string
+	//   format: date-time
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/RepoDownloadStatList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	opts, ok := getDownloadStatsOptions(ctx)
+	if !ok {
+		return
+	}
+	opts.RepoID = ctx.Repo.Repository.ID
+
+	stats, err := repo_model.FindDownloadStats(ctx, opts)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindDownloadStats", err)
+		return
+	}
+
+	result := make([]*api.RepoDownloadStat, 0, len(stats))
+	for _, stat := range stats {
+		result = append(result, &api.RepoDownloadStat{
+			Date:   stat.Day.AsTime().UTC().Format(time.DateOnly),
+			Kind:   string(stat.Kind),
+			Count:  stat.Count,
+			Unique: stat.UniqueCount,
+		})
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+// GetTopDownloads returns the most downloaded repositories
+func GetTopDownloads(ctx *context.APIContext) {
+	// swagger:operation GET /repos/downloads/top repository repoGetTopDownloads
+	// ---
+	// summary: Get the most cloned and downloaded repositories
+	// produces:
+	//   - application/json
+	// parameters:
+	// - name: kind
+	//   in: query
+	//   description: only count this kind of downloads
+	//   type: string
+	//   enum: [clone, archive, raw, lfs]
+	// - name: since
+	//   in: query
+	//   description: start of the period in ISO 8601 format, defaults to 30 days before its end
+	//   type: string
+	//   format: date-time
+	// - name: before
+	//   in: query
+	//   description: end of the period in ISO 8601 format, defaults to now
+	//   type: string
+	//   format: date-time
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/RepoDownloadCountList"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	statsOpts, ok := getDownloadStatsOptions(ctx)
+	if !ok {
+		return
+	}
+
+	listOpts := utils.GetListOptions(ctx)
+	if listOpts.Page <= 0 {
+		listOpts.Page = 1
+	}
+	counts, err := repo_model.FindTopDownloadedRepos(ctx, repo_model.TopDownloadsOptions{
+		ListOptions:              listOpts,
+		FindDownloadStatsOptions: statsOpts,
+		Actor:                    ctx.Doer,
+	})
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindTopDownloadedRepos", err)
+		return
+	}
+
+	repoIDs := make([]int64, 0, len(counts))
+	for _, c := range counts {
+		repoIDs = append(repoIDs, c.RepoID)
+	}
+	repos, err := repo_model.GetRepositoriesMapByIDs(ctx, repoIDs)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetRepositoriesMapByIDs", err)
+		return
+	}
+
+	result := make([]*api.RepoDownloadCount, 0, len(counts))
+	for _, c := range counts {
+		repo, ok := repos[c.RepoID]
+		if !ok {
+			continue
+		}
+		permission, err := access_model.GetUserRepoPermission(ctx, repo, ctx.Doer)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "GetUserRepoPermission", err)
+			return
+		}
+		result = append(result, &api.RepoDownloadCount{
+			Repository: convert.ToRepo(ctx, repo, permission),
+			Count:      c.Count,
+			Unique:     c.UniqueCount,
+		})
+	}
+	ctx.JSON(http.StatusOK, result)
+}
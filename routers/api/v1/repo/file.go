@@ -31,6 +31,7 @@ import (
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/common"
 	archiver_service "code.gitea.io/gitea/services/repository/archiver"
+	"code.gitea.io/gitea/services/repository/downloadstat"
 	files_service "code.gitea.io/gitea/services/repository/files"
 )
 
@@ -79,6 +80,7 @@ func GetRawFile(ctx *context.APIContext) {
 	if ctx.Written() {
 		return
 	}
+	defer downloadstat.RecordServed(ctx.Req, ctx.Resp, ctx.Repo.Repository.ID, repo_model.DownloadKindRaw)
 
 	ctx.RespHeader().Set(giteaObjectTypeHeader, string(files_service.GetObjectTypeFromTreeEntry(entry)))
 
@@ -128,6 +130,7 @@ func GetRawFileOrLFS(ctx *context.APIContext) {
 	if ctx.Written() {
 		return
 	}
+	defer downloadstat.RecordServed(ctx.Req, ctx.Resp, ctx.Repo.Repository.ID, repo_model.DownloadKindRaw)
 
 	ctx.RespHeader().Set(giteaObjectTypeHeader, string(files_service.GetObjectTypeFromTreeEntry(entry)))
 
@@ -201,7 +204,7 @@ func GetRawFileOrLFS(ctx *context.APIContext) {
 		return
 	}
 
-	if setting.LFS.Storage.MinioConfig.ServeDirect {
+	if setting.LFS.Storage.ServeDirect() {
 		// If we have a signed url (S3, object storage), redirect to this directly.
 		u, err := storage.LFS.URL(pointer.RelativePath(), blob.Name())
 		if u != nil && err == nil {
@@ -319,12 +322,17 @@ func archiveDownload(ctx *context.APIContext) {
 
 func download(ctx *context.APIContext, archiveName string, archiver *repo_model.RepoArchiver) {
 	downloadName := ctx.Repo.Repository.Name + "-" + archiveName
+	defer downloadstat.RecordServed(ctx.Req, ctx.Resp, ctx.Repo.Repository.ID, repo_model.DownloadKindArchive)
 
 	rPath := archiver.RelativePath()
-	if setting.RepoArchive.Storage.MinioConfig.ServeDirect {
+	if setting.RepoArchive.Storage.ServeDirect() {
 		// If we have a signed url (S3, object storage), redirect to this directly.
 		u, err := storage.RepoArchives.URL(rPath, downloadName)
 		if u != nil && err == nil {
+			// the archive is downloaded from the storage, so the download is counted when redirecting to it
+			if ctx.Req.Method == http.MethodGet {
+				downloadstat.Record(ctx.Req, ctx.Repo.Repository.ID, repo_model.DownloadKindArchive)
+			}
 			ctx.Redirect(u.String())
 			return
 		}
@@ -417,6 +425,30 @@ func base64Reader(s string) (io.Reader, error) {
 	return bytes.NewReader(b), nil
 }
 
+// checkMetadataContent validates the front matter of the model card uploaded through the API
+func checkMetadataContent(ctx *context.APIContext, contentBase64 string) bool {
+	content, err := base64.StdEncoding.DecodeString(contentBase64)
+	if err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "Invalid base64 content", err)
+		return false
+	}
+	repo := ctx.Repo.Repository
+	policy, err := git_model.GetEffectivePushPolicy(ctx, repo.OwnerID, repo.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetEffectivePushPolicy", err)
+		return false
+	}
+	if err := merlin.CheckMetadata(content, policy.Licenses); err != nil {
+		if merlin.IsErrInvalidMetadata(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "Invalid metadata", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "CheckMetadata", err)
+		}
+		return false
+	}
+	return true
+}
+
 // FetchUploadModes to determine whether each input file should be uploaded as a regular git blob or as git LFS blob.
 func FetchUploadModes(ctx *context.APIContext) {
 	apiOpts := web.GetForm(ctx).(*api.PreUploadFilesOption)
@@ -501,11 +533,8 @@ func ChangeFilesAndGitAttribute(ctx *context.APIContext) {
 			return
 		}
 
-		if file.Operation != "delete" && file.Path == "README.md" {
-			if err = merlin.CheckLicense(file.ContentBase64); err != nil {
-				ctx.Error(http.StatusUnprocessableEntity, "Invalid license", err)
-				return
-			}
+		if file.Operation != "delete" && file.Path == merlin.MetadataFile && !checkMetadataContent(ctx, file.ContentBase64) {
+			return
 		}
 
 		changeRepoFile := &files_service.ChangeRepoFile{
@@ -518,7 +547,7 @@ func ChangeFilesAndGitAttribute(ctx *context.APIContext) {
 		files = append(files, changeRepoFile)
 	}
 
-	if err := merlin.CheckText(FilesList, apiOpts.Message); err != nil {
+	if err := merlin.CheckText(ctx, FilesList, apiOpts.Message); err != nil {
 		ctx.Error(http.StatusUnprocessableEntity, err.Error(), err)
 		return
 	}
@@ -614,11 +643,8 @@ func ChangeFiles(ctx *context.APIContext) {
 			return
 		}
 
-		if file.Operation == "update" && file.Path == "README.md" {
-			if err = merlin.CheckLicense(file.ContentBase64); err != nil {
-				ctx.Error(http.StatusUnprocessableEntity, "Invalid license", err)
-				return
-			}
+		if file.Operation != "delete" && file.Path == merlin.MetadataFile && !checkMetadataContent(ctx, file.ContentBase64) {
+			return
 		}
 
 		changeRepoFile := &files_service.ChangeRepoFile{
@@ -631,7 +657,7 @@ func ChangeFiles(ctx *context.APIContext) {
 		files = append(files, changeRepoFile)
 	}
 
-	if err := merlin.CheckText(FilesList, apiOpts.Message); err != nil {
+	if err := merlin.CheckText(ctx, FilesList, apiOpts.Message); err != nil {
 		ctx.Error(http.StatusUnprocessableEntity, err.Error(), err)
 		return
 	}
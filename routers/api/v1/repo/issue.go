@@ -5,6 +5,7 @@
 package repo
 
 import (
+	stdCtx "context"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -28,6 +29,7 @@ import (
 	"code.gitea.io/gitea/routers/api/v1/utils"
 	"code.gitea.io/gitea/services/convert"
 	issue_service "code.gitea.io/gitea/services/issue"
+	"code.gitea.io/gitea/services/mqevent"
 	notify_service "code.gitea.io/gitea/services/notify"
 )
 
@@ -854,7 +856,18 @@ func EditIssue(ctx *context.APIContext) {
 		}
 		issue.IsClosed = api.StateClosed == api.StateType(*form.State)
 	}
-	statusChangeComment, titleChanged, err := issues_model.UpdateIssueByAPI(ctx, issue, ctx.Doer)
+	var statusChangeComment *issues_model.Comment
+	var titleChanged bool
+	err = db.WithTx(ctx, func(dbCtx stdCtx.Context) error {
+		var err error
+		if statusChangeComment, titleChanged, err = issues_model.UpdateIssueByAPI(dbCtx, issue, ctx.Doer); err != nil {
+			return err
+		}
+		if statusChangeComment != nil {
+			return mqevent.IssueChangeStatus(dbCtx, ctx.Doer, issue, issue.IsClosed)
+		}
+		return nil
+	})
 	if err != nil {
 		if issues_model.IsErrDependenciesLeft(err) {
 			ctx.Error(http.StatusPreconditionFailed, "DependenciesLeft", "cannot close this issue because it still has open dependencies")
@@ -0,0 +1,109 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package repo
+
+import (
+	"net/http"
+
+	git_model "code.gitea.io/gitea/models/git"
+	"code.gitea.io/gitea/modules/context"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/convert"
+	repo_service "code.gitea.io/gitea/services/repository"
+)
+
+// GetPushPolicy get the push policy of a repository
+func GetPushPolicy(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/push_policy repository repoGetPushPolicy
+	// ---
+	// summary: Get the push policy of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PushPolicy"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	responsePushPolicy(ctx)
+}
+
+// EditPushPolicy edit the push policy of a repository
+func EditPushPolicy(ctx *context.APIContext) {
+	// swagger:operation PUT /repos/{owner}/{repo}/push_policy repository repoEditPushPolicy
+	// ---
+	// summary: Edit the push policy of a repository, it can only restrict the policy of the owner
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditPushPolicyOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PushPolicy"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.EditPushPolicyOption)
+	repo := ctx.Repo.Repository
+	policy := &git_model.PushPolicy{
+		RepoID:      repo.ID,
+		Licenses:    form.Licenses,
+		MaxFileSize: form.MaxFileSize,
+	}
+	if err := repo_service.UpdatePushPolicy(ctx, policy, repo.OwnerID); err != nil {
+		if git_model.IsErrPushPolicyNotPermitted(err) {
+			ctx.Error(http.StatusForbidden, "UpdatePushPolicy", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "UpdatePushPolicy", err)
+		}
+		return
+	}
+	responsePushPolicy(ctx)
+}
+
+func responsePushPolicy(ctx *context.APIContext) {
+	repo := ctx.Repo.Repository
+	policy, err := git_model.GetPushPolicy(ctx, 0, repo.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetPushPolicy", err)
+		return
+	}
+	effective, err := git_model.GetEffectivePushPolicy(ctx, repo.OwnerID, repo.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetEffectivePushPolicy", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToPushPolicy(policy, effective))
+}
@@ -18,3 +18,45 @@ type swaggerResponseSecret struct {
 	// in:body
 	Body api.Secret `json:"body"`
 }
+
+// ActionWorkflowRunsResponse
+// swagger:response ActionWorkflowRunsResponse
+type swaggerResponseActionWorkflowRunsResponse struct {
+	// in:body
+	Body api.ActionWorkflowRunsResponse `json:"body"`
+}
+
+// ActionWorkflowRun
+// swagger:response ActionWorkflowRun
+type swaggerResponseActionWorkflowRun struct {
+	// in:body
+	Body api.ActionWorkflowRun `json:"body"`
+}
+
+// ActionWorkflowJobsResponse
+// swagger:response ActionWorkflowJobsResponse
+type swaggerResponseActionWorkflowJobsResponse struct {
+	// in:body
+	Body api.ActionWorkflowJobsResponse `json:"body"`
+}
+
+// ActionWorkflowJob
+// swagger:response ActionWorkflowJob
+type swaggerResponseActionWorkflowJob struct {
+	// in:body
+	Body api.ActionWorkflowJob `json:"body"`
+}
+
+// ActionArtifactsResponse
+// swagger:response ActionArtifactsResponse
+type swaggerResponseActionArtifactsResponse struct {
+	// in:body
+	Body api.ActionArtifactsResponse `json:"body"`
+}
+
+// ActionArtifact
+// swagger:response ActionArtifact
+type swaggerResponseActionArtifact struct {
+	// in:body
+	Body api.ActionArtifact `json:"body"`
+}
@@ -0,0 +1,22 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package swagger
+
+import (
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ModerationRecord
+// swagger:response ModerationRecord
+type swaggerResponseModerationRecord struct {
+	// in:body
+	Body api.ModerationRecord `json:"body"`
+}
+
+// ModerationRecordList
+// swagger:response ModerationRecordList
+type swaggerResponseModerationRecordList struct {
+	// in:body
+	Body []api.ModerationRecord `json:"body"`
+}
@@ -190,4 +190,13 @@ type swaggerParameterBodies struct {
 
 	// in:body
 	CreateOrUpdateSecretOption api.CreateOrUpdateSecretOption
+
+	// in:body
+	CreateActionWorkflowDispatchOption api.CreateActionWorkflowDispatchOption
+
+	// in:body
+	AppealModerationRecordOption api.AppealModerationRecordOption
+
+	// in:body
+	EditPushPolicyOption api.EditPushPolicyOption
 }
@@ -21,6 +21,20 @@ type swaggerResponseRepositoryList struct {
 	Body []api.Repository `json:"body"`
 }
 
+// RepoDownloadStatList
+// swagger:response RepoDownloadStatList
+type swaggerResponseRepoDownloadStatList struct {
+	// in:body
+	Body []api.RepoDownloadStat `json:"body"`
+}
+
+// RepoDownloadCountList
+// swagger:response RepoDownloadCountList
+type swaggerResponseRepoDownloadCountList struct {
+	// in:body
+	Body []api.RepoDownloadCount `json:"body"`
+}
+
 // Branch
 // swagger:response Branch
 type swaggerResponseBranch struct {
@@ -414,3 +428,10 @@ type swaggerRepoNewIssuePinsAllowed struct {
 	// in:body
 	Body api.NewIssuePinsAllowed `json:"body"`
 }
+
+// PushPolicy
+// swagger:response PushPolicy
+type swaggerResponsePushPolicy struct {
+	// in:body
+	Body api.PushPolicy `json:"body"`
+}
@@ -0,0 +1,113 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package user
+
+import (
+	"net/http"
+
+	moderation_model "code.gitea.io/gitea/models/moderation"
+	"code.gitea.io/gitea/modules/context"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+	"code.gitea.io/gitea/services/convert"
+)
+
+// ListMyModerationRecords list the content moderation records of the authenticated user's pushes
+func ListMyModerationRecords(ctx *context.APIContext) {
+	// swagger:operation GET /user/moderation/records user userListModerationRecords
+	// ---
+	// summary: List the content moderation records of the authenticated user's pushes
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ModerationRecordList"
+
+	records, count, err := moderation_model.FindRecords(ctx, moderation_model.FindRecordsOptions{
+		ListOptions: utils.GetListOptions(ctx),
+		DoerID:      ctx.Doer.ID,
+	})
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindRecords", err)
+		return
+	}
+	apiRecords := make([]*api.ModerationRecord, 0, len(records))
+	for _, record := range records {
+		if err := record.LoadAttributes(ctx); err != nil {
+			ctx.Error(http.StatusInternalServerError, "LoadAttributes", err)
+			return
+		}
+		apiRecords = append(apiRecords, convert.ToModerationRecord(ctx, record, ctx.Doer))
+	}
+
+	ctx.SetTotalCountHeader(count)
+	ctx.JSON(http.StatusOK, apiRecords)
+}
+
+// AppealModerationRecord appeal the rejection of one of the authenticated user's pushes
+func AppealModerationRecord(ctx *context.APIContext) {
+	// swagger:operation POST /user/moderation/records/{id}/appeal user userAppealModerationRecord
+	// ---
+	// summary: Appeal the rejection of one of the authenticated user's pushes
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the moderation record
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/AppealModerationRecordOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ModerationRecord"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.AppealModerationRecordOption)
+	record, err := moderation_model.GetRecordByID(ctx, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if moderation_model.IsErrRecordNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetRecordByID", err)
+		}
+		return
+	}
+	if record.DoerID != ctx.Doer.ID {
+		ctx.NotFound()
+		return
+	}
+	if record.Status != moderation_model.RecordStatusRejected {
+		ctx.Error(http.StatusUnprocessableEntity, "", "only the rejected records can be appealed")
+		return
+	}
+	if err := moderation_model.AppealRecord(ctx, record, form.Reason); err != nil {
+		ctx.Error(http.StatusInternalServerError, "AppealRecord", err)
+		return
+	}
+	if err := record.LoadAttributes(ctx); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadAttributes", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToModerationRecord(ctx, record, ctx.Doer))
+}
@@ -17,6 +17,8 @@ import (
 	"code.gitea.io/gitea/utils"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.18.0"
+	"go.opentelemetry.io/otel/trace"
 
 	"gitea.com/go-chi/session"
 	"github.com/chi-middleware/proxy"
@@ -80,6 +82,7 @@ func ResponseTraceMiddlewares() (handlers []any) {
 		httpHandler := http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
 			ResponseTraceID(resp, req)
 			next.ServeHTTP(resp, req)
+			labelRoute(req)
 		})
 		otelHandler := otelhttp.NewHandler(httpHandler, "otelHttpHandler")
 		return otelHandler
@@ -87,6 +90,20 @@ func ResponseTraceMiddlewares() (handlers []any) {
 	return handlers
 }
 
+// labelRoute adds the matched route pattern to the span and the request latency histogram of otelhttp,
+// the pattern is only known once the request has been routed
+func labelRoute(req *http.Request) {
+	rctx := chi.RouteContext(req.Context())
+	if rctx == nil || rctx.RoutePattern() == "" {
+		return
+	}
+	route := semconv.HTTPRoute(rctx.RoutePattern())
+	trace.SpanFromContext(req.Context()).SetAttributes(route)
+	if labeler, ok := otelhttp.LabelerFromContext(req.Context()); ok {
+		labeler.Add(route)
+	}
+}
+
 func ResponseTraceID(w http.ResponseWriter, r *http.Request) {
 	if r.URL.Path == "/" {
 		return
@@ -0,0 +1,171 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package private
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"code.gitea.io/gitea/merlin"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/typesniffer"
+)
+
+// This file contains the functions collecting the pushed text files for content review
+
+type changedBlob struct {
+	sha  string
+	path string
+}
+
+// collectChangedBlobs returns the files added or modified between the two commits which may be reviewed,
+// the files larger than [merlin] CONTENT_REVIEW_MAX_SIZE are skipped.
+func collectChangedBlobs(ctx context.Context, repoPath string, env []string, oldCommitID, newCommitID string) ([]*changedBlob, error) {
+	base := oldCommitID
+	if base == git.EmptySHA {
+		base = git.EmptyTreeSHA
+	}
+	stdout, _, runErr := git.NewCommand(ctx, "diff", "--raw", "-z", "--no-renames", "--no-abbrev", "--diff-filter=AM").
+		AddDynamicArguments(base, newCommitID).RunStdBytes(&git.RunOpts{Dir: repoPath, Env: env})
+	if runErr != nil {
+		return nil, runErr
+	}
+	changed := parseRawDiffBlobs(stdout)
+	if len(changed) == 0 {
+		return nil, nil
+	}
+
+	sizes, err := catFileBatchCheck(ctx, repoPath, env, changed)
+	if err != nil {
+		return nil, err
+	}
+	candidates := make([]*changedBlob, 0, len(changed))
+	for _, blob := range changed {
+		if size, ok := sizes[blob.sha]; ok && size > 0 && size <= setting.Moderation.ContentReviewMaxSize {
+			candidates = append(candidates, blob)
+		}
+	}
+	return candidates, nil
+}
+
+// parseRawDiffBlobs parses the output of "git diff --raw -z" and returns the new blobs of regular files
+func parseRawDiffBlobs(raw []byte) []*changedBlob {
+	fields := bytes.Split(raw, []byte{0})
+	blobs := make([]*changedBlob, 0, len(fields)/2)
+	seen := make(map[string]bool, len(fields)/2)
+	for i := 0; i+1 < len(fields); i += 2 {
+		// :<old mode> <new mode> <old sha> <new sha> <status>
+		meta := strings.Fields(strings.TrimPrefix(string(fields[i]), ":"))
+		if len(meta) < 5 || !strings.HasPrefix(meta[1], "100") {
+			continue
+		}
+		if seen[meta[3]] {
+			continue
+		}
+		seen[meta[3]] = true
+		blobs = append(blobs, &changedBlob{sha: meta[3], path: string(fields[i+1])})
+	}
+	return blobs
+}
+
+func catFileBatchCheck(ctx context.Context, repoPath string, env []string, blobs []*changedBlob) (map[string]int64, error) {
+	var stdin strings.Builder
+	for _, blob := range blobs {
+		stdin.WriteString(blob.sha)
+		stdin.WriteByte('\n')
+	}
+	stdout, _, err := git.NewCommand(ctx, "cat-file", "--batch-check").RunStdString(&git.RunOpts{
+		Dir:   repoPath,
+		Env:   env,
+		Stdin: strings.NewReader(stdin.String()),
+	})
+	if err != nil {
+		return nil, err
+	}
+	sizes := make(map[string]int64, len(blobs))
+	for _, line := range strings.Split(stdout, "\n") {
+		// <sha> <type> <size>
+		fields := strings.Fields(line)
+		if len(fields) != 3 || fields[1] != "blob" {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		sizes[fields[0]] = size
+	}
+	return sizes, nil
+}
+
+// catFileBatchText reads the blobs one by one and passes the ones detected as text to fn, so only one blob is kept
+// in memory at a time. The reading stops at the first error returned by fn, which is returned.
+func catFileBatchText(ctx context.Context, repoPath string, env []string, blobs []*changedBlob, fn func(blob *merlin.ContentBlob) error) error {
+	stdoutReader, stdoutWriter, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = stdoutReader.Close()
+		_ = stdoutWriter.Close()
+	}()
+
+	var stdin strings.Builder
+	for _, blob := range blobs {
+		stdin.WriteString(blob.sha)
+		stdin.WriteByte('\n')
+	}
+
+	return git.NewCommand(ctx, "cat-file", "--batch").Run(&git.RunOpts{
+		Dir:    repoPath,
+		Env:    env,
+		Stdin:  strings.NewReader(stdin.String()),
+		Stdout: stdoutWriter,
+		PipelineFunc: func(ctx context.Context, cancel context.CancelFunc) error {
+			_ = stdoutWriter.Close()
+			defer stdoutReader.Close()
+
+			rd := bufio.NewReader(stdoutReader)
+			for _, blob := range blobs {
+				header, err := rd.ReadString('\n')
+				if err != nil {
+					cancel()
+					return err
+				}
+				fields := strings.Fields(header)
+				if len(fields) != 3 {
+					cancel()
+					return fmt.Errorf("unexpected cat-file header %q", header)
+				}
+				size, err := strconv.ParseInt(fields[2], 10, 64)
+				if err != nil {
+					cancel()
+					return err
+				}
+				content := make([]byte, size+1) // the content is followed by a LF
+				if _, err := io.ReadFull(rd, content); err != nil {
+					cancel()
+					return err
+				}
+				content = content[:size]
+				if !typesniffer.DetectContentType(content).IsText() || !utf8.Valid(content) {
+					continue
+				}
+				if err := fn(&merlin.ContentBlob{SHA: blob.sha, Path: blob.path, Content: string(content)}); err != nil {
+					cancel()
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,27 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package private
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRawDiffBlobs(t *testing.T) {
+	raw := ":000000 100644 0000000000000000000000000000000000000000 1111111111111111111111111111111111111111 A\x00README.md\x00" +
+		":100644 100755 2222222222222222222222222222222222222222 3333333333333333333333333333333333333333 M\x00run.sh\x00" +
+		":000000 160000 0000000000000000000000000000000000000000 4444444444444444444444444444444444444444 A\x00submodule\x00" +
+		":000000 120000 0000000000000000000000000000000000000000 5555555555555555555555555555555555555555 A\x00link\x00" +
+		":000000 100644 0000000000000000000000000000000000000000 1111111111111111111111111111111111111111 A\x00copy/README.md\x00"
+
+	blobs := parseRawDiffBlobs([]byte(raw))
+	assert.Len(t, blobs, 2)
+	assert.Equal(t, "1111111111111111111111111111111111111111", blobs[0].sha)
+	assert.Equal(t, "README.md", blobs[0].path)
+	assert.Equal(t, "3333333333333333333333333333333333333333", blobs[1].sha)
+	assert.Equal(t, "run.sh", blobs[1].path)
+
+	assert.Empty(t, parseRawDiffBlobs(nil))
+}
@@ -0,0 +1,105 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package private
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/private"
+)
+
+type oversizedBlob struct {
+	path string
+	size int64
+}
+
+// preReceiveFileSize rejects the push if any of its commits adds a file larger than the max file size of the push policy
+func preReceiveFileSize(ctx *preReceiveContext, oldCommitID, newCommitID string, refFullName git.RefName) {
+	if newCommitID == git.EmptySHA || !ctx.loadPushPolicy() || ctx.pushPolicy.MaxFileSize <= 0 {
+		return
+	}
+	repo := ctx.Repo.Repository
+
+	blobs, err := findOversizedBlobs(ctx, repo.RepoPath(), ctx.env, oldCommitID, newCommitID, ctx.pushPolicy.MaxFileSize)
+	if err != nil {
+		log.Error("Unable to check the file sizes from %s to %s in %-v: %v", oldCommitID, newCommitID, repo, err)
+		ctx.JSON(http.StatusInternalServerError, private.Response{
+			Err: err.Error(),
+		})
+		return
+	}
+	if len(blobs) == 0 {
+		return
+	}
+
+	log.Warn("Forbidden: %s in %-v pushes %d files larger than %d bytes", refFullName, repo, len(blobs), ctx.pushPolicy.MaxFileSize)
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("The size of each file should be within %s:", base.FileSize(ctx.pushPolicy.MaxFileSize)))
+	for _, blob := range blobs {
+		sb.WriteString(fmt.Sprintf("\n - %s (%s)", blob.path, base.FileSize(blob.size)))
+	}
+	ctx.JSON(http.StatusForbidden, private.Response{
+		UserMsg: sb.String(),
+	})
+}
+
+// findOversizedBlobs returns the files larger than maxSize in the objects which are reachable from the new commit
+// but not from the old commit, or from any existing ref if the ref is created
+func findOversizedBlobs(ctx context.Context, repoPath string, env []string, oldCommitID, newCommitID string, maxSize int64) ([]*oversizedBlob, error) {
+	cmd := git.NewCommand(ctx, "rev-list", "--objects").AddDynamicArguments(newCommitID).AddArguments("--not")
+	if oldCommitID == git.EmptySHA {
+		cmd.AddArguments("--all")
+	} else {
+		cmd.AddDynamicArguments(oldCommitID)
+	}
+	objects, _, runErr := cmd.RunStdBytes(&git.RunOpts{Dir: repoPath, Env: env})
+	if runErr != nil {
+		return nil, runErr
+	}
+	if len(bytes.TrimSpace(objects)) == 0 {
+		return nil, nil
+	}
+
+	stdout, _, runErr := git.NewCommand(ctx, "cat-file", "--batch-check=%(objecttype) %(objectsize) %(rest)").RunStdString(&git.RunOpts{
+		Dir:   repoPath,
+		Env:   env,
+		Stdin: bytes.NewReader(objects),
+	})
+	if runErr != nil {
+		return nil, runErr
+	}
+	return parseOversizedBlobs(stdout, maxSize)
+}
+
+// parseOversizedBlobs parses the "<type> <size> <path>" lines of "git cat-file --batch-check"
+func parseOversizedBlobs(stdout string, maxSize int64) ([]*oversizedBlob, error) {
+	var blobs []*oversizedBlob
+	for _, line := range strings.Split(stdout, "\n") {
+		fields := strings.SplitN(line, " ", 3)
+		if len(fields) < 2 || fields[0] != "blob" {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		if size <= maxSize {
+			continue
+		}
+		blob := &oversizedBlob{size: size}
+		if len(fields) == 3 {
+			blob.path = fields[2]
+		}
+		blobs = append(blobs, blob)
+	}
+	return blobs, nil
+}
@@ -0,0 +1,29 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package private
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOversizedBlobs(t *testing.T) {
+	stdout := "commit 250 \n" +
+		"tree 120 \n" +
+		"blob 10 README.md\n" +
+		"blob 2048 models/weights with space.bin\n" +
+		"tree 40 models\n" +
+		"blob 4096 \n"
+
+	blobs, err := parseOversizedBlobs(stdout, 1024)
+	assert.NoError(t, err)
+	assert.Equal(t, []*oversizedBlob{
+		{path: "models/weights with space.bin", size: 2048},
+		{path: "", size: 4096},
+	}, blobs)
+
+	_, err = parseOversizedBlobs("blob invalid README.md\n", 1024)
+	assert.Error(t, err)
+}
@@ -0,0 +1,89 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package private
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"code.gitea.io/gitea/merlin"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/private"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// preReceiveMetadata validates the front matter of the model card if it is changed by the push
+func preReceiveMetadata(ctx *preReceiveContext, oldCommitID, newCommitID string, refFullName git.RefName) {
+	if !setting.Metadata.Enabled || newCommitID == git.EmptySHA || refFullName.IsTag() {
+		return
+	}
+	repo := ctx.Repo.Repository
+
+	content, err := changedMetadataFile(ctx, repo.RepoPath(), ctx.env, oldCommitID, newCommitID)
+	if err != nil {
+		log.Error("Unable to read %s of %s in %-v: %v", merlin.MetadataFile, newCommitID, repo, err)
+		ctx.JSON(http.StatusInternalServerError, private.Response{
+			Err: err.Error(),
+		})
+		return
+	}
+	if content == nil {
+		return
+	}
+
+	if !ctx.loadPushPolicy() {
+		return
+	}
+	if err := merlin.CheckMetadata(content, ctx.pushPolicy.Licenses); err != nil {
+		var metadataErr merlin.ErrInvalidMetadata
+		if !errors.As(err, &metadataErr) {
+			log.Error("Unable to check the metadata of %s in %-v: %v", newCommitID, repo, err)
+			ctx.JSON(http.StatusInternalServerError, private.Response{
+				Err: err.Error(),
+			})
+			return
+		}
+		log.Warn("Forbidden: %s of %s in %-v has invalid metadata: %v", merlin.MetadataFile, newCommitID, repo, err)
+		ctx.JSON(http.StatusForbidden, private.Response{
+			UserMsg: metadataErrorMessage(metadataErr),
+		})
+	}
+}
+
+// changedMetadataFile returns the content of the model card at the new commit, or nil if it is not changed or removed
+func changedMetadataFile(ctx *preReceiveContext, repoPath string, env []string, oldCommitID, newCommitID string) ([]byte, error) {
+	base := oldCommitID
+	if base == git.EmptySHA {
+		base = git.EmptyTreeSHA
+	}
+	changed, _, runErr := git.NewCommand(ctx, "diff", "--name-only", "--no-renames", "--diff-filter=AM").
+		AddDynamicArguments(base, newCommitID).AddDashesAndList(merlin.MetadataFile).
+		RunStdString(&git.RunOpts{Dir: repoPath, Env: env})
+	if runErr != nil {
+		return nil, runErr
+	}
+	if strings.TrimSpace(changed) == "" {
+		return nil, nil
+	}
+	content, _, runErr := git.NewCommand(ctx, "cat-file", "blob").
+		AddDynamicArguments(newCommitID + ":" + merlin.MetadataFile).
+		RunStdBytes(&git.RunOpts{Dir: repoPath, Env: env})
+	if runErr != nil {
+		return nil, runErr
+	}
+	return content, nil
+}
+
+func metadataErrorMessage(err merlin.ErrInvalidMetadata) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Sorry, your push was rejected during YAML metadata verification of %s:", merlin.MetadataFile))
+	for _, field := range err.Fields {
+		sb.WriteString("\n - Error: ")
+		sb.WriteString(field.String())
+	}
+	return sb.String()
+}
@@ -14,6 +14,7 @@ import (
 	asymkey_model "code.gitea.io/gitea/models/asymkey"
 	git_model "code.gitea.io/gitea/models/git"
 	issues_model "code.gitea.io/gitea/models/issues"
+	moderation_model "code.gitea.io/gitea/models/moderation"
 	perm_model "code.gitea.io/gitea/models/perm"
 	access_model "code.gitea.io/gitea/models/perm/access"
 	"code.gitea.io/gitea/models/unit"
@@ -22,6 +23,7 @@ import (
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/private"
+	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/web"
 	pull_service "code.gitea.io/gitea/services/pull"
 )
@@ -44,6 +46,8 @@ type preReceiveContext struct {
 	protectedTags    []*git_model.ProtectedTag
 	gotProtectedTags bool
 
+	pushPolicy *git_model.EffectivePushPolicy
+
 	env []string
 
 	opts *private.HookOptions
@@ -77,6 +81,24 @@ func (ctx *preReceiveContext) AssertCanWriteCode() bool {
 	return true
 }
 
+// loadPushPolicy loads the push policy of the repository, it returns false and writes the error if it fails
+func (ctx *preReceiveContext) loadPushPolicy() bool {
+	if ctx.pushPolicy != nil {
+		return true
+	}
+	repo := ctx.Repo.Repository
+	policy, err := git_model.GetEffectivePushPolicy(ctx, repo.OwnerID, repo.ID)
+	if err != nil {
+		log.Error("Unable to get the push policy of %-v: %v", repo, err)
+		ctx.JSON(http.StatusInternalServerError, private.Response{
+			Err: fmt.Sprintf("Unable to get the push policy of %s: %v", repo.FullName(), err),
+		})
+		return false
+	}
+	ctx.pushPolicy = policy
+	return true
+}
+
 // CanCreatePullRequest returns true if pusher can create pull requests
 func (ctx *preReceiveContext) CanCreatePullRequest() bool {
 	if !ctx.checkedCanCreatePullRequest {
@@ -132,16 +154,41 @@ func HookPreReceive(ctx *gitea_context.PrivateContext) {
 		if ctx.Written() {
 			return
 		}
+		preReceiveFileSize(ourCtx, oldCommitID, newCommitID, refFullName)
+		if ctx.Written() {
+			return
+		}
+		preReceiveMetadata(ourCtx, oldCommitID, newCommitID, refFullName)
+		if ctx.Written() {
+			return
+		}
 		//  Text content review
 		preReceiveContentReview(ourCtx, oldCommitID, newCommitID, refFullName)
+		if ctx.Written() {
+			return
+		}
 	}
 
 	ctx.PlainText(http.StatusOK, "ok")
 }
 
 func preReceiveContentReview(ctx *preReceiveContext, oldCommitID, newCommitID string, refFullName git.RefName) {
-	if git.EmptySHA != oldCommitID {
-		repo := ctx.Repo.Repository
+	if newCommitID == git.EmptySHA {
+		return
+	}
+	repo := ctx.Repo.Repository
+
+	allowed, err := moderation_model.IsAllowed(ctx, moderation_model.AllowTypeCommit, newCommitID)
+	if err != nil {
+		log.Error("Unable to check the moderation allowlist for %s in %-v: %v", newCommitID, repo, err)
+		ctx.JSON(http.StatusInternalServerError, private.Response{
+			Err: err.Error(),
+		})
+		return
+	}
+
+	reviewed := false
+	if git.EmptySHA != oldCommitID && !allowed {
 		fileName, _, err := git.NewCommand(ctx, "diff", "--name-only").AddDynamicArguments(oldCommitID, newCommitID).RunStdString(&git.RunOpts{Dir: repo.RepoPath(), Env: ctx.env})
 		processedFileName := strings.Split(fileName, "\n")
 		commitMessage, _, err := git.NewCommand(ctx, "log", "--pretty=format:%s").AddDynamicArguments(oldCommitID, ".."+newCommitID).RunStdString(&git.RunOpts{Dir: repo.RepoPath(), Env: ctx.env})
@@ -153,15 +200,123 @@ func preReceiveContentReview(ctx *preReceiveContext, oldCommitID, newCommitID st
 			return
 		}
 
-		if err := merlin.CheckText(processedFileName, commitMessage); err != nil {
+		if err := merlin.CheckText(ctx, processedFileName, commitMessage); err != nil {
 			log.Error("moderation text failed, %v", err)
+			if textErr, ok := err.(merlin.ErrSensitiveText); ok {
+				record := newModerationRecord(ctx, oldCommitID, newCommitID, refFullName, "", textErr.Hash, textErr.Verdict)
+				preReceiveModerationRejected(ctx, record, err)
+				return
+			}
 			ctx.JSON(http.StatusInternalServerError, private.Response{
 				UserMsg: fmt.Sprintf("Forbidden: %v", err),
 			})
 			return
 		}
+		reviewed = true
+	}
+	if setting.Moderation.ContentReview && !refFullName.IsTag() {
+		blobs, err := collectChangedBlobs(ctx, repo.RepoPath(), ctx.env, oldCommitID, newCommitID)
+		if err == nil {
+			blobs, err = filterAllowedBlobs(ctx, blobs)
+		}
+		if err != nil {
+			log.Error("Unable to collect text files from %s to %s in %-v: %v", oldCommitID, newCommitID, repo, err)
+			ctx.JSON(http.StatusInternalServerError, private.Response{
+				Err: err.Error(),
+			})
+			return
+		}
+		// the text files are moderated while they are read, so a large push doesn't hold all of them in memory
+		checker := merlin.NewContentChecker(ctx)
+		texts := 0
+		if len(blobs) > 0 {
+			err = catFileBatchText(ctx, repo.RepoPath(), ctx.env, blobs, func(blob *merlin.ContentBlob) error {
+				texts++
+				return checker.Add(blob)
+			})
+		}
+		if err == nil {
+			err = checker.Flush()
+		}
+		if err != nil {
+			log.Error("moderation file content failed, %v", err)
+			if blobErr, ok := err.(merlin.ErrSensitiveBlob); ok {
+				record := newModerationRecord(ctx, oldCommitID, newCommitID, refFullName, blobErr.Path, blobErr.SHA, blobErr.Verdict)
+				preReceiveModerationRejected(ctx, record, err)
+				return
+			}
+			ctx.JSON(http.StatusInternalServerError, private.Response{
+				UserMsg: fmt.Sprintf("Forbidden: %v", err),
+			})
+			return
+		}
+		reviewed = reviewed || texts > 0
+	}
+
+	if reviewed {
+		record := newModerationRecord(ctx, oldCommitID, newCommitID, refFullName, "", "", &merlin.Verdict{Pass: true})
+		if err := moderation_model.InsertRecord(ctx, record); err != nil {
+			log.Error("Unable to insert the moderation record of %s in %-v: %v", newCommitID, repo, err)
+		}
+	}
+}
+
+func newModerationRecord(ctx *preReceiveContext, oldCommitID, newCommitID string, refFullName git.RefName, path, hash string, verdict *merlin.Verdict) *moderation_model.Record {
+	record := &moderation_model.Record{
+		RepoID:      ctx.Repo.Repository.ID,
+		DoerID:      ctx.opts.UserID,
+		RefName:     refFullName.String(),
+		OldCommitID: oldCommitID,
+		NewCommitID: newCommitID,
+		Path:        path,
+		SegmentHash: hash,
+		Provider:    merlin.ProviderName(),
+		Status:      moderation_model.RecordStatusRejected,
+	}
+	if verdict != nil && verdict.Pass {
+		record.Status = moderation_model.RecordStatusPassed
+	}
+	record.Verdict = record.Status.String()
+	if verdict != nil && verdict.Label != "" {
+		record.Verdict = verdict.Label
+	}
+	return record
+}
+
+// preReceiveModerationRejected persists the rejected record and reports it to the pusher so it can be appealed
+func preReceiveModerationRejected(ctx *preReceiveContext, record *moderation_model.Record, err error) {
+	if insertErr := moderation_model.InsertRecord(ctx, record); insertErr != nil {
+		log.Error("Unable to insert the moderation record of %s in %-v: %v", record.NewCommitID, ctx.Repo.Repository, insertErr)
+		ctx.JSON(http.StatusInternalServerError, private.Response{
+			UserMsg: fmt.Sprintf("Forbidden: %v", err),
+		})
+		return
+	}
+	ctx.JSON(http.StatusInternalServerError, private.Response{
+		UserMsg: fmt.Sprintf("Forbidden: %v (moderation record #%d, it can be appealed)", err, record.ID),
+	})
+}
+
+// filterAllowedBlobs removes the blobs allowed by an admin after a manual review
+func filterAllowedBlobs(ctx *preReceiveContext, blobs []*changedBlob) ([]*changedBlob, error) {
+	if len(blobs) == 0 {
+		return blobs, nil
+	}
+	hashes := make([]string, 0, len(blobs))
+	for _, blob := range blobs {
+		hashes = append(hashes, blob.sha)
+	}
+	allowed, err := moderation_model.GetAllowedHashes(ctx, moderation_model.AllowTypeBlob, hashes)
+	if err != nil || len(allowed) == 0 {
+		return blobs, err
+	}
+	filtered := make([]*changedBlob, 0, len(blobs))
+	for _, blob := range blobs {
+		if !allowed[blob.sha] {
+			filtered = append(filtered, blob)
+		}
 	}
-	return
+	return filtered, nil
 }
 
 func preReceiveBranch(ctx *preReceiveContext, oldCommitID, newCommitID string, refFullName git.RefName) {
@@ -16,6 +16,8 @@ import (
 
 	"gitea.com/go-chi/binding"
 	chi_middleware "github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // CheckInternalToken check internal token is set
@@ -46,6 +48,19 @@ func bind[T any](_ T) any {
 	}
 }
 
+// traceRequest names the span of an internal request and adds the repository to its attributes. The span is started
+// from the trace context sent by the sub command, so the handling of a push is part of its trace.
+func traceRequest(name string) func(ctx *context.PrivateContext) {
+	return func(ctx *context.PrivateContext) {
+		span := trace.SpanFromContext(ctx)
+		span.SetName(name)
+		span.SetAttributes(attribute.String("repo.name", ctx.Params(":owner")+"/"+ctx.Params(":repo")))
+		if keyID := ctx.Params(":keyid"); keyID != "" {
+			span.SetAttributes(attribute.String("ssh.key_id", keyID))
+		}
+	}
+}
+
 // Routes registers all internal APIs routes to web application.
 // These APIs will be invoked by internal commands for example `gitea serv` and etc.
 func Routes() *web.Route {
@@ -58,13 +73,14 @@ func Routes() *web.Route {
 
 	r.Post("/ssh/authorized_keys", AuthorizedPublicKeyByContent)
 	r.Post("/ssh/{id}/update/{repoid}", UpdatePublicKeyInRepo)
+	r.Post("/ssh/{id}/clone/{repoid}", RecordSSHClone)
 	r.Post("/ssh/log", bind(private.SSHLogOption{}), SSHLog)
-	r.Post("/hook/pre-receive/{owner}/{repo}", RepoAssignment, bind(private.HookOptions{}), HookPreReceive)
-	r.Post("/hook/post-receive/{owner}/{repo}", context.OverrideContext, bind(private.HookOptions{}), HookPostReceive)
-	r.Post("/hook/proc-receive/{owner}/{repo}", context.OverrideContext, RepoAssignment, bind(private.HookOptions{}), HookProcReceive)
+	r.Post("/hook/pre-receive/{owner}/{repo}", traceRequest("hook pre-receive"), RepoAssignment, bind(private.HookOptions{}), HookPreReceive)
+	r.Post("/hook/post-receive/{owner}/{repo}", traceRequest("hook post-receive"), context.OverrideContext, bind(private.HookOptions{}), HookPostReceive)
+	r.Post("/hook/proc-receive/{owner}/{repo}", traceRequest("hook proc-receive"), context.OverrideContext, RepoAssignment, bind(private.HookOptions{}), HookProcReceive)
 	r.Post("/hook/set-default-branch/{owner}/{repo}/{branch}", RepoAssignment, SetDefaultBranch)
 	r.Get("/serv/none/{keyid}", ServNoCommand)
-	r.Get("/serv/command/{keyid}/{owner}/{repo}", ServCommand)
+	r.Get("/serv/command/{keyid}/{owner}/{repo}", traceRequest("serv command"), ServCommand)
 	r.Post("/manager/shutdown", Shutdown)
 	r.Post("/manager/restart", Restart)
 	r.Post("/manager/reload-templates", ReloadTemplates)
@@ -7,9 +7,11 @@ import (
 	"net/http"
 
 	asymkey_model "code.gitea.io/gitea/models/asymkey"
+	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/private"
 	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/services/repository/downloadstat"
 )
 
 // UpdatePublicKeyInRepo update public key and deploy key updates
@@ -45,6 +47,12 @@ func UpdatePublicKeyInRepo(ctx *context.PrivateContext) {
 	ctx.PlainText(http.StatusOK, "success")
 }
 
+// RecordSSHClone counts a clone or a fetch of a repository over SSH which has received a pack
+func RecordSSHClone(ctx *context.PrivateContext) {
+	downloadstat.RecordSSH(ctx.ParamsInt64(":repoid"), repo_model.DownloadKindClone, ctx.ParamsInt64(":id"))
+	ctx.PlainText(http.StatusOK, "success")
+}
+
 // AuthorizedPublicKeyByContent searches content as prefix (leak e-mail part)
 // and returns public key found.
 func AuthorizedPublicKeyByContent(ctx *context.PrivateContext) {
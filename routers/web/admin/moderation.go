@@ -0,0 +1,107 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package admin
+
+import (
+	"net/http"
+	"net/url"
+
+	"code.gitea.io/gitea/models/db"
+	moderation_model "code.gitea.io/gitea/models/moderation"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+const (
+	tplModeration base.TplName = "admin/moderation"
+)
+
+// ModerationRecords shows the moderation audit log, the rejected and appealed pushes are waiting for a review
+func ModerationRecords(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.moderation")
+	ctx.Data["PageIsAdminModeration"] = true
+
+	page := ctx.FormInt("page")
+	if page <= 1 {
+		page = 1
+	}
+	status := ctx.FormString("status")
+
+	records, total, err := moderation_model.FindRecords(ctx, moderation_model.FindRecordsOptions{
+		ListOptions: db.ListOptions{
+			Page:     page,
+			PageSize: setting.UI.Admin.NoticePagingNum,
+		},
+		Status: moderation_model.ParseRecordStatus(status),
+	})
+	if err != nil {
+		ctx.ServerError("FindRecords", err)
+		return
+	}
+	for _, record := range records {
+		if err := record.LoadAttributes(ctx); err != nil {
+			ctx.ServerError("LoadAttributes", err)
+			return
+		}
+	}
+	ctx.Data["Records"] = records
+	ctx.Data["Total"] = total
+	ctx.Data["Status"] = status
+
+	pager := context.NewPagination(int(total), setting.UI.Admin.NoticePagingNum, page, 5)
+	pager.AddParam(ctx, "status", "Status")
+	ctx.Data["Page"] = pager
+
+	ctx.HTML(http.StatusOK, tplModeration)
+}
+
+// AllowModerationRecord allow-lists the content of a rejected record
+func AllowModerationRecord(ctx *context.Context) {
+	record := getReviewableModerationRecord(ctx)
+	if ctx.Written() {
+		return
+	}
+	if err := moderation_model.AllowRecord(ctx, record, ctx.Doer.ID); err != nil {
+		ctx.ServerError("AllowRecord", err)
+		return
+	}
+	log.Trace("Moderation record %d allowed by admin (%s)", record.ID, ctx.Doer.Name)
+	ctx.Flash.Success(ctx.Tr("admin.moderation.allow_success"))
+	ctx.Redirect(setting.AppSubURL + "/admin/moderation?status=" + url.QueryEscape(ctx.FormString("status")))
+}
+
+// DismissModerationRecord confirms the rejection of a record
+func DismissModerationRecord(ctx *context.Context) {
+	record := getReviewableModerationRecord(ctx)
+	if ctx.Written() {
+		return
+	}
+	if err := moderation_model.DismissRecord(ctx, record, ctx.Doer.ID); err != nil {
+		ctx.ServerError("DismissRecord", err)
+		return
+	}
+	log.Trace("Moderation record %d dismissed by admin (%s)", record.ID, ctx.Doer.Name)
+	ctx.Flash.Success(ctx.Tr("admin.moderation.dismiss_success"))
+	ctx.Redirect(setting.AppSubURL + "/admin/moderation?status=" + url.QueryEscape(ctx.FormString("status")))
+}
+
+func getReviewableModerationRecord(ctx *context.Context) *moderation_model.Record {
+	record, err := moderation_model.GetRecordByID(ctx, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if moderation_model.IsErrRecordNotExist(err) {
+			ctx.NotFound("GetRecordByID", err)
+		} else {
+			ctx.ServerError("GetRecordByID", err)
+		}
+		return nil
+	}
+	if !record.IsReviewable() {
+		ctx.Flash.Error(ctx.Tr("admin.moderation.not_reviewable"))
+		ctx.Redirect(setting.AppSubURL + "/admin/moderation")
+		return nil
+	}
+	return record
+}
@@ -0,0 +1,101 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package admin
+
+import (
+	"net/http"
+	"net/url"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/outbox"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/services/mqevent"
+)
+
+const (
+	tplOutbox base.TplName = "admin/outbox"
+)
+
+// OutboxMessages shows the messages which could not be published to the message queue yet
+func OutboxMessages(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.outbox")
+	ctx.Data["PageIsAdminOutbox"] = true
+
+	page := ctx.FormInt("page")
+	if page <= 1 {
+		page = 1
+	}
+	status := ctx.FormString("status")
+
+	opts := outbox.FindMessagesOptions{
+		ListOptions: db.ListOptions{
+			Page:     page,
+			PageSize: setting.UI.Admin.NoticePagingNum,
+		},
+		Status: outbox.ParseMessageStatus(status),
+	}
+	// the messages which have not been tried yet are not interesting unless explicitly asked for
+	opts.Failed = status == ""
+
+	messages, total, err := outbox.FindMessages(ctx, opts)
+	if err != nil {
+		ctx.ServerError("FindMessages", err)
+		return
+	}
+	ctx.Data["Messages"] = messages
+	ctx.Data["Total"] = total
+	ctx.Data["Status"] = status
+
+	pager := context.NewPagination(int(total), setting.UI.Admin.NoticePagingNum, page, 5)
+	pager.AddParam(ctx, "status", "Status")
+	ctx.Data["Page"] = pager
+
+	ctx.HTML(http.StatusOK, tplOutbox)
+}
+
+// RetryOutboxMessage queues an outbox message to be published right away
+func RetryOutboxMessage(ctx *context.Context) {
+	m := getOutboxMessage(ctx)
+	if ctx.Written() {
+		return
+	}
+	if err := mqevent.RetryMessage(ctx, m.ID); err != nil {
+		ctx.ServerError("RetryMessage", err)
+		return
+	}
+	log.Trace("Outbox message %d retried by admin (%s)", m.ID, ctx.Doer.Name)
+	ctx.Flash.Success(ctx.Tr("admin.outbox.retry_success"))
+	ctx.Redirect(setting.AppSubURL + "/admin/outbox?status=" + url.QueryEscape(ctx.FormString("status")))
+}
+
+// DeleteOutboxMessage discards an outbox message without publishing it
+func DeleteOutboxMessage(ctx *context.Context) {
+	m := getOutboxMessage(ctx)
+	if ctx.Written() {
+		return
+	}
+	if err := outbox.DeleteMessage(ctx, m.ID); err != nil {
+		ctx.ServerError("DeleteMessage", err)
+		return
+	}
+	log.Trace("Outbox message %d deleted by admin (%s)", m.ID, ctx.Doer.Name)
+	ctx.Flash.Success(ctx.Tr("admin.outbox.delete_success"))
+	ctx.Redirect(setting.AppSubURL + "/admin/outbox?status=" + url.QueryEscape(ctx.FormString("status")))
+}
+
+func getOutboxMessage(ctx *context.Context) *outbox.Message {
+	m, err := outbox.GetMessageByID(ctx, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if outbox.IsErrMessageNotExist(err) {
+			ctx.NotFound("GetMessageByID", err)
+		} else {
+			ctx.ServerError("GetMessageByID", err)
+		}
+		return nil
+	}
+	return m
+}
@@ -0,0 +1,39 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package org
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/routers/web/shared/pushpolicy"
+	shared_user "code.gitea.io/gitea/routers/web/shared/user"
+)
+
+const tplSettingsPushPolicy base.TplName = "org/settings/push_policy"
+
+// PushPolicy render the push policy inherited by the repositories of the organization
+func PushPolicy(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("push_policy.title")
+	ctx.Data["PageIsOrgSettings"] = true
+	ctx.Data["PageIsSettingsPushPolicy"] = true
+
+	if err := shared_user.LoadHeaderCount(ctx); err != nil {
+		ctx.ServerError("LoadHeaderCount", err)
+		return
+	}
+
+	pushpolicy.SetPushPolicyContext(ctx, ctx.Org.Organization.ID, 0, 0)
+	if ctx.Written() {
+		return
+	}
+
+	ctx.HTML(http.StatusOK, tplSettingsPushPolicy)
+}
+
+// PushPolicyPost saves the push policy of the organization
+func PushPolicyPost(ctx *context.Context) {
+	pushpolicy.PerformPushPolicyPost(ctx, ctx.Org.Organization.ID, 0, 0, ctx.Org.OrgLink+"/settings/push_policy")
+}
@@ -5,12 +5,15 @@ package actions
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
 
 	actions_model "code.gitea.io/gitea/models/actions"
 	"code.gitea.io/gitea/models/db"
+	git_model "code.gitea.io/gitea/models/git"
 	"code.gitea.io/gitea/models/unit"
 	"code.gitea.io/gitea/modules/actions"
 	"code.gitea.io/gitea/modules/base"
@@ -18,7 +21,9 @@ import (
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/routers/web/repo"
+	actions_service "code.gitea.io/gitea/services/actions"
 	"code.gitea.io/gitea/services/convert"
 
 	"github.com/nektos/act/pkg/model"
@@ -89,6 +94,7 @@ func List(ctx *context.Context) {
 			allRunnerLabels.AddMultiple(r.AgentLabels...)
 		}
 
+		curWorkflow := ctx.FormString("workflow")
 		workflows = make([]Workflow, 0, len(entries))
 		for _, entry := range entries {
 			workflow := Workflow{Entry: *entry}
@@ -97,6 +103,12 @@ func List(ctx *context.Context) {
 				ctx.Error(http.StatusInternalServerError, err.Error())
 				return
 			}
+			if entry.Name() == curWorkflow && ctx.Repo.CanWrite(unit.TypeActions) {
+				prepareWorkflowDispatch(ctx, content)
+				if ctx.Written() {
+					return
+				}
+			}
 			wf, err := model.ReadWorkflow(bytes.NewReader(content))
 			if err != nil {
 				workflow.ErrMsg = ctx.Locale.Tr("actions.runs.invalid_workflow_helper", err.Error())
@@ -204,3 +216,60 @@ func List(ctx *context.Context) {
 
 	ctx.HTML(http.StatusOK, tplListActions)
 }
+
+// prepareWorkflowDispatch prepares the form to run the workflow manually if it has a workflow_dispatch trigger
+func prepareWorkflowDispatch(ctx *context.Context, content []byte) {
+	dispatch, err := actions.GetWorkflowDispatch(content)
+	if err != nil || dispatch == nil {
+		// the invalid workflows are reported by the list
+		return
+	}
+	branches, err := git_model.FindBranchNames(ctx, git_model.FindBranchOptions{
+		RepoID:          ctx.Repo.Repository.ID,
+		IsDeletedBranch: util.OptionalBoolFalse,
+		ListOptions:     db.ListOptions{ListAll: true},
+	})
+	if err != nil {
+		ctx.ServerError("FindBranchNames", err)
+		return
+	}
+	ctx.Data["WorkflowDispatch"] = dispatch
+	ctx.Data["WorkflowDispatchBranches"] = branches
+}
+
+// Run triggers the workflow manually with the values of its workflow_dispatch inputs
+func Run(ctx *context.Context) {
+	workflow := ctx.FormString("workflow")
+	redirectURL := fmt.Sprintf("%s/actions?workflow=%s", ctx.Repo.RepoLink, url.QueryEscape(workflow))
+
+	ref := ctx.FormString("ref")
+	if ref == "" {
+		ref = ctx.Repo.Repository.DefaultBranch
+	}
+	if err := ctx.Req.ParseForm(); err != nil {
+		ctx.ServerError("ParseForm", err)
+		return
+	}
+	// the inputs are named inputs[<name>], a boolean input is followed by a hidden input with the value false
+	// which is only used when the checkbox isn't checked
+	inputs := map[string]string{}
+	for key, values := range ctx.Req.PostForm {
+		if name, ok := strings.CutPrefix(key, "inputs["); ok && strings.HasSuffix(name, "]") && len(values) > 0 {
+			inputs[strings.TrimSuffix(name, "]")] = values[0]
+		}
+	}
+
+	run, err := actions_service.DispatchWorkflow(ctx, ctx.Doer, ctx.Repo.Repository, ctx.Repo.GitRepo, workflow, ref, inputs)
+	if err != nil {
+		if errors.Is(err, util.ErrInvalidArgument) || errors.Is(err, util.ErrNotExist) || errors.Is(err, util.ErrPermissionDenied) {
+			ctx.Flash.Error(ctx.Tr("actions.workflow.run_failed", err.Error()))
+			ctx.Redirect(redirectURL)
+			return
+		}
+		ctx.ServerError("DispatchWorkflow", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("actions.workflow.run_success", workflow))
+	ctx.Redirect(fmt.Sprintf("%s/actions/runs/%d", ctx.Repo.RepoLink, run.Index))
+}
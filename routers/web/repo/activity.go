@@ -5,22 +5,29 @@ package repo
 
 import (
 	"net/http"
+	"slices"
 	"time"
 
 	activities_model "code.gitea.io/gitea/models/activities"
+	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/models/unit"
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/services/repository/downloadstat"
 )
 
 const (
-	tplActivity base.TplName = "repo/activity"
+	tplActivity          base.TplName = "repo/activity"
+	tplActivityDownloads base.TplName = "repo/activity_downloads"
 )
 
 // Activity render the page to show repository latest changes
 func Activity(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("repo.activity")
 	ctx.Data["PageIsActivity"] = true
+	ctx.Data["PageIsPulse"] = true
+	ctx.Data["EnableDownloadStats"] = setting.Repository.DownloadStats.Enabled
 
 	ctx.Data["Period"] = ctx.Params("period")
 
@@ -101,3 +108,54 @@ func ActivityAuthors(ctx *context.Context) {
 
 	ctx.JSON(http.StatusOK, authors)
 }
+
+// ActivityDownloads renders the page with the daily clones and downloads of the repository for given time period
+func ActivityDownloads(ctx *context.Context) {
+	if !setting.Repository.DownloadStats.Enabled {
+		ctx.NotFound("ActivityDownloads", nil)
+		return
+	}
+
+	ctx.Data["Title"] = ctx.Tr("repo.activity.navbar.downloads")
+	ctx.Data["PageIsActivity"] = true
+	ctx.Data["PageIsDownloads"] = true
+	ctx.Data["EnableDownloadStats"] = true
+
+	ctx.Data["Period"] = ctx.Params("period")
+
+	timeUntil := time.Now()
+	var timeFrom time.Time
+
+	switch ctx.Data["Period"] {
+	case "weekly":
+		timeFrom = timeUntil.Add(-time.Hour * 168)
+	case "monthly":
+		timeFrom = timeUntil.AddDate(0, -1, 0)
+	case "quarterly":
+		timeFrom = timeUntil.AddDate(0, -3, 0)
+	case "semiyearly":
+		timeFrom = timeUntil.AddDate(0, -6, 0)
+	case "yearly":
+		timeFrom = timeUntil.AddDate(-1, 0, 0)
+	default:
+		ctx.Data["Period"] = "monthly"
+		timeFrom = timeUntil.AddDate(0, -1, 0)
+	}
+	ctx.Data["DateFrom"] = timeFrom.UTC().Format(time.RFC3339)
+	ctx.Data["DateUntil"] = timeUntil.UTC().Format(time.RFC3339)
+	ctx.Data["PeriodText"] = ctx.Tr("repo.activity.period." + ctx.Data["Period"].(string))
+
+	days, totals, err := downloadstat.GetDailyDownloads(ctx, ctx.Repo.Repository.ID, timeFrom, timeUntil)
+	if err != nil {
+		ctx.ServerError("GetDailyDownloads", err)
+		return
+	}
+	// the most recent day first
+	slices.Reverse(days)
+
+	ctx.Data["DownloadKinds"] = repo_model.DownloadKinds
+	ctx.Data["DailyDownloads"] = days
+	ctx.Data["DownloadTotals"] = totals
+
+	ctx.HTML(http.StatusOK, tplActivityDownloads)
+}
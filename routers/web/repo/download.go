@@ -9,6 +9,7 @@ import (
 	"time"
 
 	git_model "code.gitea.io/gitea/models/git"
+	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/httpcache"
@@ -17,6 +18,7 @@ import (
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/storage"
 	"code.gitea.io/gitea/routers/common"
+	"code.gitea.io/gitea/services/repository/downloadstat"
 )
 
 // ServeBlobOrLFS download a git.Blob redirecting to LFS if necessary
@@ -53,7 +55,7 @@ func ServeBlobOrLFS(ctx *context.Context, blob *git.Blob, lastModified *time.Tim
 			return nil
 		}
 
-		if setting.LFS.Storage.MinioConfig.ServeDirect {
+		if setting.LFS.Storage.ServeDirect() {
 			// If we have a signed url (S3, object storage), redirect to this directly.
 			u, err := storage.LFS.URL(pointer.RelativePath(), blob.Name())
 			if u != nil && err == nil {
@@ -119,6 +121,7 @@ func SingleDownload(ctx *context.Context) {
 	if blob == nil {
 		return
 	}
+	defer downloadstat.RecordServed(ctx.Req, ctx.Resp, ctx.Repo.Repository.ID, repo_model.DownloadKindRaw)
 
 	if err := common.ServeBlob(ctx.Base, ctx.Repo.TreePath, blob, lastModified); err != nil {
 		ctx.ServerError("ServeBlob", err)
@@ -131,6 +134,7 @@ func SingleDownloadOrLFS(ctx *context.Context) {
 	if blob == nil {
 		return
 	}
+	defer downloadstat.RecordServed(ctx.Req, ctx.Resp, ctx.Repo.Repository.ID, repo_model.DownloadKindRaw)
 
 	if err := ServeBlobOrLFS(ctx, blob, lastModified); err != nil {
 		ctx.ServerError("ServeBlobOrLFS", err)
@@ -148,6 +152,7 @@ func DownloadByID(ctx *context.Context) {
 		}
 		return
 	}
+	defer downloadstat.RecordServed(ctx.Req, ctx.Resp, ctx.Repo.Repository.ID, repo_model.DownloadKindRaw)
 	if err = common.ServeBlob(ctx.Base, ctx.Repo.TreePath, blob, nil); err != nil {
 		ctx.ServerError("ServeBlob", err)
 	}
@@ -164,6 +169,7 @@ func DownloadByIDOrLFS(ctx *context.Context) {
 		}
 		return
 	}
+	defer downloadstat.RecordServed(ctx.Req, ctx.Resp, ctx.Repo.Repository.ID, repo_model.DownloadKindRaw)
 	if err = ServeBlobOrLFS(ctx, blob, nil); err != nil {
 		ctx.ServerError("ServeBlob", err)
 	}
@@ -9,6 +9,7 @@ import (
 	"compress/gzip"
 	gocontext "context"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"path"
@@ -32,6 +33,7 @@ import (
 	"code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/util"
 	repo_service "code.gitea.io/gitea/services/repository"
+	"code.gitea.io/gitea/services/repository/downloadstat"
 
 	"github.com/go-chi/cors"
 )
@@ -304,11 +306,13 @@ func httpBase(ctx *context.Context) *serviceHandler {
 	r.URL.Path = strings.ToLower(r.URL.Path) // blue: In case some repo name has upper case name
 
 	dir := repo_model.RepoPath(username, reponame)
+	codeRepoID := repo.ID
 	if isWiki {
 		dir = repo_model.RepoPath(username, wikiRepoName)
+		codeRepoID = 0
 	}
 
-	return &serviceHandler{cfg, w, r, dir, cfg.Env}
+	return &serviceHandler{cfg, w, r, dir, cfg.Env, codeRepoID}
 }
 
 var (
@@ -365,6 +369,8 @@ type serviceHandler struct {
 	r       *http.Request
 	dir     string
 	environ []string
+	// codeRepoID is the id of the repository if its code is served, it is 0 for a wiki
+	codeRepoID int64
 }
 
 func (h *serviceHandler) setHeaderNoCache() {
@@ -471,13 +477,22 @@ func serviceRPC(h *serviceHandler, service string) {
 		h.environ = append(h.environ, "GIT_PROTOCOL="+protocol)
 	}
 
+	// only the fetches and the clones which receive a pack are counted, neither the listings of the refs nor the
+	// rounds of the negotiation are
+	var stdout io.Writer = h.w
+	var packDetector *git.PackDetector
+	if service == "upload-pack" && h.codeRepoID > 0 {
+		packDetector = git.NewPackDetector(h.w)
+		stdout = packDetector
+	}
+
 	var stderr bytes.Buffer
 	cmd.AddArguments("--stateless-rpc").AddDynamicArguments(h.dir)
 	cmd.SetDescription(fmt.Sprintf("%s %s %s [repo_path: %s]", git.GitExecutable, service, "--stateless-rpc", h.dir))
 	if err := cmd.Run(&git.RunOpts{
 		Dir:               h.dir,
 		Env:               append(os.Environ(), h.environ...),
-		Stdout:            h.w,
+		Stdout:            stdout,
 		Stdin:             reqBody,
 		Stderr:            &stderr,
 		UseContextTimeout: true,
@@ -487,6 +502,9 @@ func serviceRPC(h *serviceHandler, service string) {
 		}
 		return
 	}
+	if packDetector != nil && packDetector.PackSent() {
+		downloadstat.Record(h.r, h.codeRepoID, repo_model.DownloadKindClone)
+	}
 }
 
 // ServiceUploadPack implements Git Smart HTTP protocol
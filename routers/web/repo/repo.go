@@ -34,6 +34,7 @@ import (
 	"code.gitea.io/gitea/services/forms"
 	repo_service "code.gitea.io/gitea/services/repository"
 	archiver_service "code.gitea.io/gitea/services/repository/archiver"
+	"code.gitea.io/gitea/services/repository/downloadstat"
 )
 
 const (
@@ -441,12 +442,17 @@ func Download(ctx *context.Context) {
 
 func download(ctx *context.Context, archiveName string, archiver *repo_model.RepoArchiver) {
 	downloadName := ctx.Repo.Repository.Name + "-" + archiveName
+	defer downloadstat.RecordServed(ctx.Req, ctx.Resp, ctx.Repo.Repository.ID, repo_model.DownloadKindArchive)
 
 	rPath := archiver.RelativePath()
-	if setting.RepoArchive.Storage.MinioConfig.ServeDirect {
+	if setting.RepoArchive.Storage.ServeDirect() {
 		// If we have a signed url (S3, object storage), redirect to this directly.
 		u, err := storage.RepoArchives.URL(rPath, downloadName)
 		if u != nil && err == nil {
+			// the archive is downloaded from the storage, so the download is counted when redirecting to it
+			if ctx.Req.Method == http.MethodGet {
+				downloadstat.Record(ctx.Req, ctx.Repo.Repository.ID, repo_model.DownloadKindArchive)
+			}
 			ctx.Redirect(u.String())
 			return
 		}
@@ -0,0 +1,102 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package setting
+
+import (
+	"errors"
+	"net/http"
+
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	shared "code.gitea.io/gitea/routers/web/shared/actions"
+	shared_user "code.gitea.io/gitea/routers/web/shared/user"
+)
+
+const (
+	tplRepoArtifacts base.TplName = "repo/settings/actions"
+	tplOrgArtifacts  base.TplName = "org/settings/actions"
+)
+
+type artifactsCtx struct {
+	OwnerID           int64
+	RepoID            int64
+	RepoOwnerID       int64
+	IsRepo            bool
+	IsOrg             bool
+	ArtifactsTemplate base.TplName
+	RedirectLink      string
+}
+
+func getArtifactsCtx(ctx *context.Context) (*artifactsCtx, error) {
+	if ctx.Data["PageIsRepoSettings"] == true {
+		return &artifactsCtx{
+			RepoID:            ctx.Repo.Repository.ID,
+			RepoOwnerID:       ctx.Repo.Repository.OwnerID,
+			IsRepo:            true,
+			ArtifactsTemplate: tplRepoArtifacts,
+			RedirectLink:      ctx.Repo.RepoLink + "/settings/actions/artifacts",
+		}, nil
+	}
+
+	if ctx.Data["PageIsOrgSettings"] == true {
+		err := shared_user.LoadHeaderCount(ctx)
+		if err != nil {
+			ctx.ServerError("LoadHeaderCount", err)
+			return nil, nil
+		}
+		return &artifactsCtx{
+			OwnerID:           ctx.Org.Organization.ID,
+			IsOrg:             true,
+			ArtifactsTemplate: tplOrgArtifacts,
+			RedirectLink:      ctx.Org.OrgLink + "/settings/actions/artifacts",
+		}, nil
+	}
+
+	return nil, errors.New("unable to set Artifacts context")
+}
+
+// Artifacts render the artifact retention and the artifacts usage of the organization or the repository
+func Artifacts(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("actions.artifacts")
+	ctx.Data["PageType"] = "artifacts"
+	ctx.Data["PageIsSharedSettingsArtifacts"] = true
+
+	aCtx, err := getArtifactsCtx(ctx)
+	if err != nil {
+		ctx.ServerError("getArtifactsCtx", err)
+		return
+	}
+	if ctx.Written() {
+		return
+	}
+
+	shared.SetArtifactRetentionContext(ctx, aCtx.OwnerID, aCtx.RepoID, aCtx.RepoOwnerID)
+	if ctx.Written() {
+		return
+	}
+	if aCtx.IsRepo {
+		shared.SetRepoArtifactsUsageContext(ctx, ctx.Repo.Repository)
+	} else {
+		shared.SetOwnerArtifactsUsageContext(ctx, aCtx.OwnerID)
+	}
+	if ctx.Written() {
+		return
+	}
+
+	ctx.HTML(http.StatusOK, aCtx.ArtifactsTemplate)
+}
+
+// ArtifactsPost saves the artifact retention of the organization or the repository
+func ArtifactsPost(ctx *context.Context) {
+	aCtx, err := getArtifactsCtx(ctx)
+	if err != nil {
+		ctx.ServerError("getArtifactsCtx", err)
+		return
+	}
+	if ctx.Written() {
+		return
+	}
+
+	shared.PerformArtifactRetentionPost(ctx, aCtx.OwnerID, aCtx.RepoID, aCtx.RepoOwnerID, aCtx.RedirectLink)
+}
@@ -28,6 +28,7 @@ import (
 	"code.gitea.io/gitea/modules/storage"
 	"code.gitea.io/gitea/modules/typesniffer"
 	"code.gitea.io/gitea/modules/util"
+	lfs_service "code.gitea.io/gitea/services/lfs"
 )
 
 const (
@@ -369,6 +370,10 @@ func LFSDelete(ctx *context.Context) {
 			ctx.ServerError("LFSDelete", err)
 			return
 		}
+		if err := lfs_service.DeleteArchivedObject(ctx, p); err != nil {
+			ctx.ServerError("LFSDelete", err)
+			return
+		}
 	}
 	ctx.Redirect(ctx.Repo.RepoLink + "/settings/lfs")
 }
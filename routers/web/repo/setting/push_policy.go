@@ -0,0 +1,32 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package setting
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/routers/web/shared/pushpolicy"
+)
+
+const tplPushPolicy base.TplName = "repo/settings/push_policy"
+
+// PushPolicy render the push policy of the repository
+func PushPolicy(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("push_policy.title")
+	ctx.Data["PageIsSettingsPushPolicy"] = true
+
+	pushpolicy.SetPushPolicyContext(ctx, 0, ctx.Repo.Repository.ID, ctx.Repo.Repository.OwnerID)
+	if ctx.Written() {
+		return
+	}
+
+	ctx.HTML(http.StatusOK, tplPushPolicy)
+}
+
+// PushPolicyPost saves the push policy of the repository
+func PushPolicyPost(ctx *context.Context) {
+	pushpolicy.PerformPushPolicyPost(ctx, 0, ctx.Repo.Repository.ID, ctx.Repo.Repository.OwnerID, ctx.Repo.RepoLink+"/settings/push_policy")
+}
@@ -558,9 +558,15 @@ func SettingsPost(ctx *context.Context) {
 		}
 
 		if form.EnableActions && !unit_model.TypeActions.UnitGlobalDisabled() {
+			actionsConfig := &repo_model.ActionsConfig{}
+			if actionsUnit, err := repo.GetUnit(ctx, unit_model.TypeActions); err == nil {
+				actionsConfig.DisabledWorkflows = actionsUnit.ActionsConfig().DisabledWorkflows
+			}
+			actionsConfig.ShareWorkflows = form.ShareActionsWorkflows
 			units = append(units, repo_model.RepoUnit{
 				RepoID: repo.ID,
 				Type:   unit_model.TypeActions,
+				Config: actionsConfig,
 			})
 		} else if !unit_model.TypeActions.UnitGlobalDisabled() {
 			deleteUnitTypes = append(deleteUnitTypes, unit_model.TypeActions)
@@ -0,0 +1,143 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/web"
+	actions_service "code.gitea.io/gitea/services/actions"
+	"code.gitea.io/gitea/services/forms"
+)
+
+const mebibyte = 1024 * 1024
+
+// SetArtifactRetentionContext sets the artifact retention of the organization or the repository, the retention it inherits
+// and the retention applied to the artifacts, repoOwnerID is the owner of the repository
+func SetArtifactRetentionContext(ctx *context.Context, ownerID, repoID, repoOwnerID int64) {
+	retention, err := actions_model.GetArtifactRetention(ctx, ownerID, repoID)
+	if err != nil {
+		ctx.ServerError("GetArtifactRetention", err)
+		return
+	}
+	inherited, err := actions_service.GetInheritedArtifactRetention(ctx, repoOwnerID, repoID)
+	if err != nil {
+		ctx.ServerError("GetInheritedArtifactRetention", err)
+		return
+	}
+
+	maxSize := retention.MaxSize
+	if maxSize > 0 {
+		maxSize /= mebibyte
+	}
+	ctx.Data["ArtifactRetentionDays"] = retention.RetentionDays
+	ctx.Data["ArtifactMaxSize"] = maxSize
+	ctx.Data["InheritedArtifactRetention"] = inherited
+}
+
+// SetRepoArtifactsUsageContext sets the size of the artifacts of the repository in the storage and lists them
+func SetRepoArtifactsUsageContext(ctx *context.Context, repo *repo_model.Repository) {
+	effective, err := actions_model.GetEffectiveArtifactRetention(ctx, repo.OwnerID, repo.ID)
+	if err != nil {
+		ctx.ServerError("GetEffectiveArtifactRetention", err)
+		return
+	}
+	size, err := actions_model.GetArtifactsStorageSize(ctx, repo.ID)
+	if err != nil {
+		ctx.ServerError("GetArtifactsStorageSize", err)
+		return
+	}
+	ctx.Data["EffectiveArtifactRetention"] = effective
+	ctx.Data["ArtifactsStorageSize"] = size
+	if effective.MaxSize > 0 {
+		ctx.Data["ArtifactsUsagePercent"] = min(size*100/effective.MaxSize, 100)
+	}
+
+	opts := actions_model.FindArtifactsOptions{
+		ListOptions: db.ListOptions{
+			Page:     max(ctx.FormInt("page"), 1),
+			PageSize: 20,
+		},
+		RepoID: repo.ID,
+	}
+	artifacts, total, err := actions_model.FindArtifactSummaries(ctx, opts)
+	if err != nil {
+		ctx.ServerError("FindArtifactSummaries", err)
+		return
+	}
+	runs := make(map[int64]*actions_model.ActionRun)
+	for _, art := range artifacts {
+		if _, ok := runs[art.RunID]; ok {
+			continue
+		}
+		run, err := actions_model.GetRunByID(ctx, art.RunID)
+		if err != nil {
+			ctx.ServerError("GetRunByID", err)
+			return
+		}
+		runs[art.RunID] = run
+	}
+	ctx.Data["Artifacts"] = artifacts
+	ctx.Data["ArtifactRuns"] = runs
+
+	pager := context.NewPagination(int(total), opts.PageSize, opts.Page, 5)
+	ctx.Data["Page"] = pager
+}
+
+// SetOwnerArtifactsUsageContext sets the size of the artifacts of the repositories of the owner in the storage
+func SetOwnerArtifactsUsageContext(ctx *context.Context, ownerID int64) {
+	usages, err := actions_model.ListArtifactsStorageUsages(ctx, ownerID)
+	if err != nil {
+		ctx.ServerError("ListArtifactsStorageUsages", err)
+		return
+	}
+	repoIDs := make([]int64, 0, len(usages))
+	var size int64
+	for _, usage := range usages {
+		repoIDs = append(repoIDs, usage.RepoID)
+		size += usage.StorageSize
+	}
+	repos, err := repo_model.GetRepositoriesMapByIDs(ctx, repoIDs)
+	if err != nil {
+		ctx.ServerError("GetRepositoriesMapByIDs", err)
+		return
+	}
+	ctx.Data["ArtifactsStorageUsages"] = usages
+	ctx.Data["ArtifactsStorageSize"] = size
+	ctx.Data["ArtifactRepos"] = repos
+}
+
+// PerformArtifactRetentionPost saves the artifact retention of the organization or the repository
+func PerformArtifactRetentionPost(ctx *context.Context, ownerID, repoID, repoOwnerID int64, redirectURL string) {
+	form := web.GetForm(ctx).(*forms.ArtifactRetentionForm)
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.GetErrMsg())
+		ctx.Redirect(redirectURL)
+		return
+	}
+
+	retention := &actions_model.ActionArtifactRetention{
+		OwnerID:       ownerID,
+		RepoID:        repoID,
+		RetentionDays: form.RetentionDays,
+		MaxSize:       form.MaxSize,
+	}
+	if retention.MaxSize > 0 {
+		retention.MaxSize *= mebibyte
+	}
+
+	if err := actions_service.UpdateArtifactRetention(ctx, ctx.Doer, retention, repoOwnerID); err != nil {
+		if notPermitted, ok := err.(actions_model.ErrArtifactRetentionNotPermitted); ok {
+			ctx.Flash.Error(ctx.Tr("actions.artifacts.not_permitted", notPermitted.Field))
+			ctx.Redirect(redirectURL)
+			return
+		}
+		ctx.ServerError("UpdateArtifactRetention", err)
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("actions.artifacts.update_success"))
+	ctx.Redirect(redirectURL)
+}
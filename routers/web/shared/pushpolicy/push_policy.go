@@ -0,0 +1,77 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package pushpolicy
+
+import (
+	"strings"
+
+	git_model "code.gitea.io/gitea/models/git"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/forms"
+	repo_service "code.gitea.io/gitea/services/repository"
+)
+
+const mebibyte = 1024 * 1024
+
+// SetPushPolicyContext sets the push policy of the organization or the repository and the policy it inherits,
+// repoOwnerID is the owner of the repository
+func SetPushPolicyContext(ctx *context.Context, ownerID, repoID, repoOwnerID int64) {
+	policy, err := git_model.GetPushPolicy(ctx, ownerID, repoID)
+	if err != nil {
+		ctx.ServerError("GetPushPolicy", err)
+		return
+	}
+	inherited, err := repo_service.GetInheritedPushPolicy(ctx, repoOwnerID, repoID)
+	if err != nil {
+		ctx.ServerError("GetInheritedPushPolicy", err)
+		return
+	}
+
+	ctx.Data["PushPolicyLicenses"] = strings.Join(policy.Licenses, ", ")
+	ctx.Data["PushPolicyMaxFileSize"] = policy.MaxFileSize / mebibyte
+	ctx.Data["InheritedPushPolicy"] = inherited
+}
+
+// PerformPushPolicyPost saves the push policy of the organization or the repository
+func PerformPushPolicyPost(ctx *context.Context, ownerID, repoID, repoOwnerID int64, redirectURL string) {
+	form := web.GetForm(ctx).(*forms.PushPolicyForm)
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.GetErrMsg())
+		ctx.Redirect(redirectURL)
+		return
+	}
+
+	policy := &git_model.PushPolicy{
+		OwnerID:     ownerID,
+		RepoID:      repoID,
+		Licenses:    splitLicenses(form.Licenses),
+		MaxFileSize: form.MaxFileSize * mebibyte,
+	}
+
+	if err := repo_service.UpdatePushPolicy(ctx, policy, repoOwnerID); err != nil {
+		if notPermitted, ok := err.(git_model.ErrPushPolicyNotPermitted); ok {
+			ctx.Flash.Error(ctx.Tr("push_policy.not_permitted", notPermitted.Field))
+			ctx.Redirect(redirectURL)
+			return
+		}
+		ctx.ServerError("UpdatePushPolicy", err)
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("push_policy.update_success"))
+	ctx.Redirect(redirectURL)
+}
+
+func splitLicenses(s string) []string {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == '\n' || r == '\r'
+	})
+	licenses := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if license := strings.TrimSpace(field); license != "" {
+			licenses = append(licenses, license)
+		}
+	}
+	return licenses
+}
@@ -8,8 +8,8 @@ import (
 
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
-	"code.gitea.io/gitea/modules/messagequeue"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/services/mqevent"
 )
 
 // Constants for topics, types, and field names.
@@ -204,8 +204,8 @@ func GitCloneMessageMiddleware(ctx *context.Context) {
 		adaptedCtx := AdaptGitContext(ctx)
 		msg := prepareMessage(adaptedCtx, gitClone, "this message means someone cloned the repository")
 
-		if err := messagequeue.Publish(ctx, setting.MQ.TopicName, &msg, nil); err != nil {
-			log.Info("internal error of kafka sender: %s", err.Error())
+		if err := mqevent.Enqueue(ctx, setting.MQ.TopicName, &msg, nil); err != nil {
+			log.Error("Unable to write the clone message to the outbox: %v", err)
 		}
 	} else {
 		log.Warn("message queue not initialized, skip publish message")
@@ -434,6 +434,10 @@ func registerRoutes(m *web.Route) {
 		})
 	}
 
+	addSettingsArtifactsRoutes := func() {
+		m.Combo("/artifacts").Get(repo_setting.Artifacts).Post(web.Bind(forms.ArtifactRetentionForm{}), repo_setting.ArtifactsPost)
+	}
+
 	addSettingsRunnersRoutes := func() {
 		m.Group("/runners", func() {
 			m.Get("", repo_setting.Runners)
@@ -741,6 +745,18 @@ func registerRoutes(m *web.Route) {
 			m.Post("/empty", admin.EmptyNotices)
 		})
 
+		m.Group("/moderation", func() {
+			m.Get("", admin.ModerationRecords)
+			m.Post("/{id}/allow", admin.AllowModerationRecord)
+			m.Post("/{id}/dismiss", admin.DismissModerationRecord)
+		})
+
+		m.Group("/outbox", func() {
+			m.Get("", admin.OutboxMessages)
+			m.Post("/{id}/retry", admin.RetryOutboxMessage)
+			m.Post("/{id}/delete", admin.DeleteOutboxMessage)
+		})
+
 		m.Group("/applications", func() {
 			m.Get("", admin.Applications)
 			m.Post("/oauth2", web.Bind(forms.EditOAuth2ApplicationForm{}), admin.ApplicationsPost)
@@ -879,11 +895,14 @@ func registerRoutes(m *web.Route) {
 					m.Post("/initialize", web.Bind(forms.InitializeLabelsForm{}), org.InitializeLabels)
 				})
 
+				m.Combo("/push_policy").Get(org.PushPolicy).Post(web.Bind(forms.PushPolicyForm{}), org.PushPolicyPost)
+
 				m.Group("/actions", func() {
 					m.Get("", org_setting.RedirectToDefaultSetting)
 					addSettingsRunnersRoutes()
 					addSettingsSecretsRoutes()
 					addSettingVariablesRoutes()
+					addSettingsArtifactsRoutes()
 				}, actions.MustEnableActions)
 
 				m.Methods("GET,POST", "/delete", org.SettingsDelete)
@@ -1039,6 +1058,8 @@ func registerRoutes(m *web.Route) {
 				addWebhookEditRoutes()
 			}, webhooksEnabled)
 
+			m.Combo("/push_policy").Get(repo_setting.PushPolicy).Post(web.Bind(forms.PushPolicyForm{}), repo_setting.PushPolicyPost)
+
 			m.Group("/keys", func() {
 				m.Combo("").Get(repo_setting.DeployKeys).
 					Post(web.Bind(forms.AddKeyForm{}), repo_setting.DeployKeysPost)
@@ -1063,6 +1084,7 @@ func registerRoutes(m *web.Route) {
 				addSettingsRunnersRoutes()
 				addSettingsSecretsRoutes()
 				addSettingVariablesRoutes()
+				addSettingsArtifactsRoutes()
 			}, actions.MustEnableActions)
 			// the follow handler must be under "settings", otherwise this incomplete repo can't be accessed
 			m.Group("/migrate", func() {
@@ -1313,6 +1335,7 @@ func registerRoutes(m *web.Route) {
 			m.Get("", actions.List)
 			m.Post("/disable", reqRepoAdmin, actions.DisableWorkflowFile)
 			m.Post("/enable", reqRepoAdmin, actions.EnableWorkflowFile)
+			m.Post("/run", reqRepoActionsWriter, actions.Run)
 
 			m.Group("/runs/{run}", func() {
 				m.Combo("").
@@ -1356,6 +1379,11 @@ func registerRoutes(m *web.Route) {
 			m.Get("/{period}", repo.Activity)
 		}, context.RepoRef(), repo.MustBeNotEmpty, context.RequireRepoReaderOr(unit.TypePullRequests, unit.TypeIssues, unit.TypeReleases))
 
+		m.Group("/activity/downloads", func() {
+			m.Get("", repo.ActivityDownloads)
+			m.Get("/{period}", repo.ActivityDownloads)
+		}, repo.MustBeNotEmpty, reqRepoCodeReader)
+
 		m.Group("/activity_author_data", func() {
 			m.Get("", repo.ActivityAuthors)
 			m.Get("/{period}", repo.ActivityAuthors)
@@ -1501,6 +1529,7 @@ func registerRoutes(m *web.Route) {
 				m.Post("/objects/batch", lfs.CheckAcceptMediaType, lfs.BatchHandlerAdapter)
 				m.Get("/objects/urls", lfs.GetAllLFSObjectDirectDownloadUrls)
 				m.Put("/objects/{oid}/{size}", lfs.UploadHandler)
+				m.Put("/objects/{oid}/{size}/parts/{uploadid}/{index}", lfs.MultipartPartUploadHandler)
 				m.Get("/objects/{oid}/{filename}", lfs.DownloadHandler)
 				m.Get("/objects/{oid}", lfs.DownloadHandler)
 				m.Post("/verify", lfs.CheckAcceptMediaType, lfs.VerifyHandler)
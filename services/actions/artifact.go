@@ -0,0 +1,49 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"archive/zip"
+	"compress/gzip"
+	"io"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/modules/storage"
+)
+
+// WriteArtifactZip writes the files of an artifact to a zip archive
+func WriteArtifactZip(w io.Writer, artifacts []*actions_model.ActionArtifact) error {
+	writer := zip.NewWriter(w)
+	for _, art := range artifacts {
+		if err := writeArtifactFile(writer, art); err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+func writeArtifactFile(writer *zip.Writer, art *actions_model.ActionArtifact) error {
+	f, err := storage.ActionsArtifacts.Open(art.StoragePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if art.ContentEncoding == "gzip" {
+		gr, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer gr.Close()
+		r = gr
+	}
+
+	w, err := writer.Create(art.ArtifactPath)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, r)
+	return err
+}
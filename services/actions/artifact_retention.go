@@ -0,0 +1,52 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	user_model "code.gitea.io/gitea/models/user"
+)
+
+// GetInheritedArtifactRetention returns the retention overridden by the artifact retention: the retention of the owner
+// for a repository (repoID is set) or the global settings for an organization
+func GetInheritedArtifactRetention(ctx context.Context, ownerID, repoID int64) (*actions_model.EffectiveArtifactRetention, error) {
+	if repoID > 0 {
+		return actions_model.GetEffectiveArtifactRetention(ctx, ownerID, 0)
+	}
+	return actions_model.GetEffectiveArtifactRetention(ctx, 0, 0)
+}
+
+// UpdateArtifactRetention saves the artifact retention of an organization (retention.RepoID is 0) or a repository
+// (retention.OwnerID is 0), repoOwnerID is the owner of the repository. Only the site admins can loosen the inherited retention.
+// A lower max size is enforced by the queue for the repositories concerned.
+func UpdateArtifactRetention(ctx context.Context, doer *user_model.User, retention *actions_model.ActionArtifactRetention, repoOwnerID int64) error {
+	if !doer.IsAdmin {
+		inherited, err := GetInheritedArtifactRetention(ctx, repoOwnerID, retention.RepoID)
+		if err != nil {
+			return err
+		}
+		if err := inherited.CheckOverride(retention); err != nil {
+			return err
+		}
+	}
+	if err := actions_model.SetArtifactRetention(ctx, retention); err != nil {
+		return err
+	}
+
+	if retention.RepoID > 0 {
+		return queueArtifactsMaxSizeCheck(repoOwnerID, retention.RepoID)
+	}
+	usages, err := actions_model.ListArtifactsStorageUsages(ctx, retention.OwnerID)
+	if err != nil {
+		return err
+	}
+	for _, usage := range usages {
+		if err := queueArtifactsMaxSizeCheck(usage.OwnerID, usage.RepoID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
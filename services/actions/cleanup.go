@@ -5,13 +5,24 @@ package actions
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/modules/graceful"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/queue"
 	"code.gitea.io/gitea/modules/storage"
 )
 
+var artifactsMaxSizeQueue *queue.WorkerPoolQueue[*artifactsMaxSizeCheck]
+
+// artifactsMaxSizeCheck is a repository whose artifacts may exceed the max artifacts size
+type artifactsMaxSizeCheck struct {
+	OwnerID int64
+	RepoID  int64
+}
+
 // Cleanup removes expired actions logs, data and artifacts
 func Cleanup(taskCtx context.Context, olderThan time.Duration) error {
 	// TODO: clean up expired actions logs
@@ -20,7 +31,8 @@ func Cleanup(taskCtx context.Context, olderThan time.Duration) error {
 	return CleanupArtifacts(taskCtx)
 }
 
-// CleanupArtifacts removes expired artifacts and set records expired status
+// CleanupArtifacts removes expired artifacts and set records expired status,
+// then removes the oldest artifacts of the repositories exceeding their max artifacts size
 func CleanupArtifacts(taskCtx context.Context) error {
 	artifacts, err := actions.ListNeedExpiredArtifacts(taskCtx)
 	if err != nil {
@@ -28,15 +40,135 @@ func CleanupArtifacts(taskCtx context.Context) error {
 	}
 	log.Info("Found %d expired artifacts", len(artifacts))
 	for _, artifact := range artifacts {
-		if err := storage.ActionsArtifacts.Delete(artifact.StoragePath); err != nil {
-			log.Error("Cannot delete artifact %d: %v", artifact.ID, err)
-			continue
-		}
-		if err := actions.SetArtifactExpired(taskCtx, artifact.ID); err != nil {
-			log.Error("Cannot set artifact %d expired: %v", artifact.ID, err)
+		if err := expireArtifact(taskCtx, artifact); err != nil {
+			log.Error("Cannot expire artifact %d: %v", artifact.ID, err)
 			continue
 		}
 		log.Info("Artifact %d set expired", artifact.ID)
 	}
+
+	usages, err := actions.ListArtifactsStorageUsages(taskCtx, 0)
+	if err != nil {
+		return err
+	}
+	for _, usage := range usages {
+		if err := EnforceArtifactsMaxSize(taskCtx, usage.OwnerID, usage.RepoID); err != nil {
+			log.Error("Cannot enforce the max artifacts size of repository %d: %v", usage.RepoID, err)
+		}
+	}
+	return nil
+}
+
+func expireArtifact(ctx context.Context, artifact *actions.ActionArtifact) error {
+	if err := storage.ActionsArtifacts.Delete(artifact.StoragePath); err != nil {
+		return err
+	}
+	return actions.SetArtifactExpired(ctx, artifact.ID)
+}
+
+// CheckUploadedArtifactSize checks the size of the artifact of the run named name once it has been uploaded: the artifact
+// is removed if it alone exceeds the max artifacts size of the repository, and false is returned. Otherwise the oldest
+// artifacts of the repository are removed later by the queue if they exceed the max size.
+func CheckUploadedArtifactSize(ctx context.Context, ownerID, repoID, runID int64, name string) (bool, error) {
+	retention, err := actions.GetEffectiveArtifactRetention(ctx, ownerID, repoID)
+	if err != nil {
+		return false, err
+	}
+	if retention.MaxSize <= 0 {
+		return true, nil
+	}
+
+	arts, _, err := actions.FindArtifactSummaries(ctx, actions.FindArtifactsOptions{RepoID: repoID, RunID: runID, ArtifactName: name})
+	if err != nil {
+		return false, err
+	}
+	if len(arts) == 1 && arts[0].StorageSize > retention.MaxSize {
+		files, err := actions.ListArtifactsByRunIDAndName(ctx, runID, name)
+		if err != nil {
+			return false, err
+		}
+		for _, file := range files {
+			if err := expireArtifact(ctx, file); err != nil {
+				return false, err
+			}
+		}
+		return false, nil
+	}
+
+	return true, queueArtifactsMaxSizeCheck(ownerID, repoID)
+}
+
+// queueArtifactsMaxSizeCheck queues the enforcement of the max artifacts size of a repository, the cleanup of the
+// artifacts enforces it if the queue is not running
+func queueArtifactsMaxSizeCheck(ownerID, repoID int64) error {
+	if artifactsMaxSizeQueue == nil {
+		return nil
+	}
+	if err := artifactsMaxSizeQueue.Push(&artifactsMaxSizeCheck{OwnerID: ownerID, RepoID: repoID}); err != nil && !errors.Is(err, queue.ErrAlreadyInQueue) {
+		return err
+	}
+	return nil
+}
+
+func artifactsMaxSizeQueueHandler(items ...*artifactsMaxSizeCheck) []*artifactsMaxSizeCheck {
+	ctx := graceful.GetManager().ShutdownContext()
+	for _, item := range items {
+		// the max size is enforced again by the cleanup of the artifacts if it fails
+		if err := EnforceArtifactsMaxSize(ctx, item.OwnerID, item.RepoID); err != nil {
+			log.Error("Cannot enforce the max artifacts size of repository %d: %v", item.RepoID, err)
+		}
+	}
+	return nil
+}
+
+// EnforceArtifactsMaxSize removes the oldest artifacts of a repository until the size of its artifacts in the storage
+// fits the effective max size
+func EnforceArtifactsMaxSize(ctx context.Context, ownerID, repoID int64) error {
+	retention, err := actions.GetEffectiveArtifactRetention(ctx, ownerID, repoID)
+	if err != nil {
+		return err
+	}
+	if retention.MaxSize <= 0 {
+		return nil
+	}
+	size, err := actions.GetArtifactsStorageSize(ctx, repoID)
+	if err != nil || size <= retention.MaxSize {
+		return err
+	}
+
+	artifacts, err := actions.ListUploadedArtifactsByRepoID(ctx, repoID)
+	if err != nil {
+		return err
+	}
+	// the files of an artifact are removed together, oldest artifact first
+	type artifactKey struct {
+		runID int64
+		name  string
+	}
+	var keys []artifactKey
+	files := make(map[artifactKey][]*actions.ActionArtifact)
+	for _, artifact := range artifacts {
+		key := artifactKey{artifact.RunID, artifact.ArtifactName}
+		if _, ok := files[key]; !ok {
+			keys = append(keys, key)
+		}
+		files[key] = append(files[key], artifact)
+	}
+
+	for _, key := range keys {
+		if size <= retention.MaxSize {
+			return nil
+		}
+		for _, artifact := range files[key] {
+			if err := expireArtifact(ctx, artifact); err != nil {
+				return err
+			}
+			size -= artifact.FileCompressedSize
+		}
+		log.Info("Artifact %q of run %d in repository %d removed to fit the max artifacts size", key.name, key.runID, repoID)
+	}
+	if size > retention.MaxSize {
+		log.Warn("The artifacts of repository %d still exceed the max artifacts size: %d > %d", repoID, size, retention.MaxSize)
+	}
 	return nil
 }
@@ -0,0 +1,59 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func insertTestArtifact(t *testing.T, art *actions_model.ActionArtifact) {
+	assert.NoError(t, db.Insert(db.DefaultContext, art))
+	t.Cleanup(func() {
+		_, err := db.DeleteByID(db.DefaultContext, art.ID, &actions_model.ActionArtifact{})
+		assert.NoError(t, err)
+	})
+}
+
+func TestCheckUploadedArtifactSize(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	defer test.MockVariableValue(&setting.Actions.ArtifactMaxSize, 10)()
+
+	for _, art := range []*actions_model.ActionArtifact{
+		{RunID: 791, RepoID: 4, OwnerID: 5, ArtifactName: "dist", ArtifactPath: "a.txt", StoragePath: "791/dist/a.txt", FileSize: 6, FileCompressedSize: 6, Status: int64(actions_model.ArtifactStatusUploadConfirmed)},
+		{RunID: 791, RepoID: 4, OwnerID: 5, ArtifactName: "dist", ArtifactPath: "b.txt", StoragePath: "791/dist/b.txt", FileSize: 6, FileCompressedSize: 6, Status: int64(actions_model.ArtifactStatusUploadConfirmed)},
+		{RunID: 791, RepoID: 4, OwnerID: 5, ArtifactName: "report", ArtifactPath: "c.txt", StoragePath: "791/report/c.txt", FileSize: 8, FileCompressedSize: 8, Status: int64(actions_model.ArtifactStatusUploadConfirmed)},
+	} {
+		insertTestArtifact(t, art)
+	}
+
+	// the artifact alone exceeds the max size, so it is removed
+	fits, err := CheckUploadedArtifactSize(db.DefaultContext, 5, 4, 791, "dist")
+	assert.NoError(t, err)
+	assert.False(t, fits)
+	files, err := actions_model.ListArtifactsByRunIDAndName(db.DefaultContext, 791, "dist")
+	assert.NoError(t, err)
+	for _, file := range files {
+		assert.EqualValues(t, actions_model.ArtifactStatusExpired, file.Status)
+	}
+
+	// the artifact fits, the oldest artifacts are removed later if the repository exceeds the max size
+	fits, err = CheckUploadedArtifactSize(db.DefaultContext, 5, 4, 791, "report")
+	assert.NoError(t, err)
+	assert.True(t, fits)
+	unittest.AssertExistsAndLoadBean(t, &actions_model.ActionArtifact{RunID: 791, ArtifactName: "report", Status: int64(actions_model.ArtifactStatusUploadConfirmed)})
+
+	// there is no limit
+	defer test.MockVariableValue(&setting.Actions.ArtifactMaxSize, 0)()
+	fits, err = CheckUploadedArtifactSize(db.DefaultContext, 5, 4, 791, "dist")
+	assert.NoError(t, err)
+	assert.True(t, fits)
+}
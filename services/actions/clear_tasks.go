@@ -68,6 +68,7 @@ func stopTasks(ctx context.Context, opts actions_model.FindTaskOptions) error {
 	}
 
 	CreateCommitStatus(ctx, jobs...)
+	emitRunsOfJobs(jobs, 0)
 
 	return nil
 }
@@ -96,6 +97,7 @@ func CancelAbandonedJobs(ctx context.Context) error {
 		}
 		CreateCommitStatus(ctx, job)
 	}
+	emitRunsOfJobs(jobs, 0)
 
 	return nil
 }
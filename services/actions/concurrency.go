@@ -0,0 +1,281 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	actions_module "code.gitea.io/gitea/modules/actions"
+	"code.gitea.io/gitea/modules/container"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	webhook_module "code.gitea.io/gitea/modules/webhook"
+
+	"github.com/nektos/act/pkg/exprparser"
+	"github.com/nektos/act/pkg/jobparser"
+	"github.com/nektos/act/pkg/model"
+	"gopkg.in/yaml.v3"
+)
+
+// insertRun inserts a run with its jobs parsed from the workflow content, see parseRunJobs.
+// Without a workflow-level concurrency, a push cancels the in-progress runs of the same workflow and ref,
+// otherwise the concurrency group decides which runs are cancelled and which ones have to wait.
+func insertRun(ctx context.Context, run *actions_model.ActionRun, content []byte) error {
+	content, jobs, err := parseRunJobs(ctx, run, content)
+	if err != nil {
+		return err
+	}
+
+	rawConcurrency, jobsConcurrency, err := actions_module.GetConcurrency(content)
+	if err != nil {
+		return fmt.Errorf("GetConcurrency: %w", err)
+	}
+	run.RawConcurrency = rawConcurrency
+
+	if run.RawConcurrency == "" && run.Event == webhook_module.HookEventPush {
+		// cancel running jobs of the same workflow
+		cancelledJobs, err := actions_model.CancelRunningJobs(ctx, run.RepoID, run.Ref, run.WorkflowID)
+		if err != nil {
+			log.Error("CancelRunningJobs: %v", err)
+		}
+		CreateCommitStatus(ctx, cancelledJobs...)
+	}
+
+	if err := actions_model.InsertRun(ctx, run, jobs, jobsConcurrency); err != nil {
+		return fmt.Errorf("InsertRun: %w", err)
+	}
+
+	if run.RawConcurrency == "" && len(jobsConcurrency) == 0 {
+		return nil
+	}
+	if run.RawConcurrency != "" {
+		if err := applyRunConcurrency(ctx, run); err != nil {
+			// go on, the run will be treated as if it doesn't belong to any concurrency group
+			log.Error("apply concurrency of run %d: %v", run.ID, err)
+		}
+	}
+	// the jobs have been blocked until their concurrency are evaluated by the job emitter
+	return EmitJobsIfReady(run.ID)
+}
+
+// applyRunConcurrency evaluates the workflow-level concurrency of a new run and cancels the previous runs it supersedes
+func applyRunConcurrency(ctx context.Context, run *actions_model.ActionRun) error {
+	if err := run.LoadAttributes(ctx); err != nil {
+		return err
+	}
+	concurrency, err := actions_module.EvaluateConcurrency(run.RawConcurrency, "", newConcurrencyEnvironment(ctx, run))
+	if err != nil {
+		return err
+	}
+	if concurrency == nil {
+		return nil
+	}
+
+	run.ConcurrencyGroup = concurrency.Group
+	run.ConcurrencyCancel = concurrency.CancelInProgress
+	if err := actions_model.UpdateRun(ctx, run, "concurrency_group", "concurrency_cancel"); err != nil {
+		return err
+	}
+
+	cancelledJobs, err := actions_model.CancelPreviousRunsByConcurrency(ctx, run)
+	CreateCommitStatus(ctx, cancelledJobs...)
+	emitRunsOfJobs(cancelledJobs, run.ID)
+	return err
+}
+
+// evaluateJobConcurrency evaluates the job-level concurrency of a job once its needs are done
+func evaluateJobConcurrency(ctx context.Context, run *actions_model.ActionRun, job *actions_model.ActionRunJob, jobs []*actions_model.ActionRunJob) (*actions_module.Concurrency, error) {
+	if err := run.LoadAttributes(ctx); err != nil {
+		return nil, err
+	}
+	env := newConcurrencyEnvironment(ctx, run)
+	env.Github.Job = job.JobID
+
+	var wf jobparser.SingleWorkflow
+	if err := yaml.Unmarshal(job.WorkflowPayload, &wf); err != nil {
+		return nil, fmt.Errorf("unmarshal workflow payload: %w", err)
+	}
+	if _, parsed := wf.Job(); parsed != nil {
+		var matrix map[string][]any
+		if err := parsed.Strategy.RawMatrix.Decode(&matrix); err == nil {
+			env.Matrix = make(map[string]any, len(matrix))
+			for k, v := range matrix {
+				if len(v) > 0 {
+					env.Matrix[k] = v[0]
+				}
+			}
+		}
+	}
+
+	// a job of a called reusable workflow refers to the other jobs of the workflow by their ids in it
+	callerPrefix := ""
+	if i := strings.LastIndex(job.JobID, "."); i >= 0 {
+		callerPrefix = job.JobID[:i+1]
+	}
+	env.Needs = make(map[string]exprparser.Needs, len(job.Needs))
+	for _, need := range jobs {
+		if !slices.Contains(job.Needs, need.JobID) {
+			continue
+		}
+		outputs := map[string]string{}
+		if need.TaskID != 0 {
+			got, err := actions_model.FindTaskOutputByTaskID(ctx, need.TaskID)
+			if err != nil {
+				return nil, fmt.Errorf("FindTaskOutputByTaskID: %w", err)
+			}
+			for _, v := range got {
+				outputs[v.OutputKey] = v.OutputValue
+			}
+		} else if need.Status.IsDone() && need.IsReusableWorkflowCall() {
+			got, err := EvaluateReusableWorkflowOutputs(ctx, need, jobs)
+			if err != nil {
+				return nil, err
+			}
+			outputs = got
+		}
+		env.Needs[strings.TrimPrefix(need.JobID, callerPrefix)] = exprparser.Needs{
+			Outputs: outputs,
+			Result:  need.Status.String(),
+		}
+	}
+
+	return actions_module.EvaluateConcurrency(job.RawConcurrency, job.JobID, env)
+}
+
+// checkJobConcurrency is called when a job is about to leave the blocked status,
+// it returns whether the job has to stay blocked because of its concurrency groups, and the jobs cancelled by it.
+func checkJobConcurrency(ctx context.Context, run *actions_model.ActionRun, job *actions_model.ActionRunJob, jobs []*actions_model.ActionRunJob) (bool, []*actions_model.ActionRunJob, error) {
+	if job.RawConcurrency == "" {
+		return false, nil, nil
+	}
+
+	var cancelledJobs []*actions_model.ActionRunJob
+	if !job.IsConcurrencyEvaluated {
+		concurrency, err := evaluateJobConcurrency(ctx, run, job, jobs)
+		if err != nil {
+			// go on, the job will be treated as if it doesn't belong to any concurrency group
+			log.Error("evaluate concurrency of job %d: %v", job.ID, err)
+		}
+		job.IsConcurrencyEvaluated = true
+		if concurrency != nil {
+			job.ConcurrencyGroup = concurrency.Group
+			job.ConcurrencyCancel = concurrency.CancelInProgress
+		}
+		if _, err := actions_model.UpdateRunJob(ctx, &actions_model.ActionRunJob{
+			ID:                     job.ID,
+			IsConcurrencyEvaluated: job.IsConcurrencyEvaluated,
+			ConcurrencyGroup:       job.ConcurrencyGroup,
+			ConcurrencyCancel:      job.ConcurrencyCancel,
+		}, nil, "is_concurrency_evaluated", "concurrency_group", "concurrency_cancel"); err != nil {
+			return false, nil, err
+		}
+
+		cancelledJobs, err = actions_model.CancelPreviousJobsByConcurrency(ctx, job)
+		if err != nil {
+			return false, cancelledJobs, err
+		}
+	}
+
+	blocked, err := actions_model.ShouldBlockJobByConcurrency(ctx, job)
+	return blocked, cancelledJobs, err
+}
+
+// emitRunsOfJobs lets the job emitter check the runs of the jobs which have been stopped,
+// their remaining jobs or the next runs of their concurrency groups may be able to go on.
+func emitRunsOfJobs(jobs []*actions_model.ActionRunJob, exceptRunID int64) {
+	runIDs := make(container.Set[int64])
+	for _, job := range jobs {
+		if job.RunID != exceptRunID && runIDs.Add(job.RunID) {
+			if err := EmitJobsIfReady(job.RunID); err != nil {
+				log.Error("EmitJobsIfReady: %v", err)
+			}
+		}
+	}
+}
+
+// newConcurrencyEnvironment returns the contexts available to concurrency expressions:
+// github, inputs and vars, plus needs, strategy and matrix for job-level concurrency.
+func newConcurrencyEnvironment(ctx context.Context, run *actions_model.ActionRun) *exprparser.EvaluationEnvironment {
+	event := map[string]any{}
+	_ = json.Unmarshal([]byte(run.EventPayload), &event)
+
+	eventName := run.TriggerEvent
+	if eventName == "" {
+		eventName = run.Event.Event()
+	}
+
+	baseRef, headRef := "", ""
+	ref, sha := run.Ref, run.CommitSHA
+	if pullPayload, err := run.GetPullRequestEventPayload(); err == nil && pullPayload.PullRequest != nil && pullPayload.PullRequest.Base != nil && pullPayload.PullRequest.Head != nil {
+		baseRef = pullPayload.PullRequest.Base.Ref
+		headRef = pullPayload.PullRequest.Head.Ref
+		if run.TriggerEvent == actions_module.GithubEventPullRequestTarget {
+			ref = git.BranchPrefix + pullPayload.PullRequest.Base.Name
+			sha = pullPayload.PullRequest.Base.Sha
+		}
+	}
+	refName := git.RefName(ref)
+
+	inputs := map[string]any{}
+	if run.Event == webhook_module.HookEventWorkflowDispatch {
+		var payload api.WorkflowDispatchPayload
+		if err := json.Unmarshal([]byte(run.EventPayload), &payload); err == nil {
+			for k, v := range payload.Inputs {
+				inputs[k] = v
+			}
+		}
+	}
+
+	vars := map[string]string{}
+	ownerVariables, err := actions_model.FindVariables(ctx, actions_model.FindVariablesOpts{OwnerID: run.Repo.OwnerID})
+	if err != nil {
+		log.Error("find variables of owner %d: %v", run.Repo.OwnerID, err)
+	}
+	repoVariables, err := actions_model.FindVariables(ctx, actions_model.FindVariablesOpts{RepoID: run.RepoID})
+	if err != nil {
+		log.Error("find variables of repo %d: %v", run.RepoID, err)
+	}
+	// Level precedence: Repo > Org / User
+	for _, v := range append(ownerVariables, repoVariables...) {
+		vars[v.Name] = v.Data
+	}
+
+	actor := ""
+	if run.TriggerUser != nil {
+		actor = run.TriggerUser.Name
+	}
+
+	return &exprparser.EvaluationEnvironment{
+		Github: &model.GithubContext{
+			Event:           event,
+			EventName:       eventName,
+			Workflow:        run.WorkflowID,
+			RunID:           fmt.Sprint(run.ID),
+			RunNumber:       fmt.Sprint(run.Index),
+			Actor:           actor,
+			Repository:      run.Repo.OwnerName + "/" + run.Repo.Name,
+			RepositoryOwner: run.Repo.OwnerName,
+			Sha:             sha,
+			Ref:             ref,
+			RefName:         refName.ShortName(),
+			RefType:         refName.RefType(),
+			HeadRef:         headRef,
+			BaseRef:         baseRef,
+			ServerURL:       setting.AppURL,
+			APIURL:          setting.AppURL + "api/v1",
+		},
+		Vars:     vars,
+		Inputs:   inputs,
+		Strategy: map[string]any{},
+		Matrix:   map[string]any{},
+		Needs:    map[string]exprparser.Needs{},
+	}
+}
@@ -22,5 +22,11 @@ func Init() {
 	}
 	go graceful.GetManager().RunWithCancel(jobEmitterQueue)
 
+	artifactsMaxSizeQueue = queue.CreateUniqueQueue(graceful.GetManager().ShutdownContext(), "actions_artifacts_max_size", artifactsMaxSizeQueueHandler)
+	if artifactsMaxSizeQueue == nil {
+		log.Fatal("Unable to create actions_artifacts_max_size queue")
+	}
+	go graceful.GetManager().RunWithCancel(artifactsMaxSizeQueue)
+
 	notify_service.RegisterNotifier(NewNotifier())
 }
@@ -7,11 +7,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	actions_model "code.gitea.io/gitea/models/actions"
 	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/container"
 	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/modules/timeutil"
 
 	"xorm.io/builder"
 )
@@ -44,38 +48,118 @@ func jobEmitterQueueHandler(items ...*jobUpdate) []*jobUpdate {
 }
 
 func checkJobsOfRun(ctx context.Context, runID int64) error {
+	run, err := actions_model.GetRunByID(ctx, runID)
+	if err != nil {
+		return err
+	}
+	if run.NeedApproval {
+		// the jobs will be emitted once the run has been approved
+		return nil
+	}
 	jobs, _, err := actions_model.FindRunJobs(ctx, actions_model.FindRunJobOptions{RunID: runID})
 	if err != nil {
 		return err
 	}
+	var cancelledJobs []*actions_model.ActionRunJob
 	if err := db.WithTx(ctx, func(ctx context.Context) error {
-		idToJobs := make(map[string][]*actions_model.ActionRunJob, len(jobs))
-		for _, job := range jobs {
-			idToJobs[job.JobID] = append(idToJobs[job.JobID], job)
-		}
+		cancelledJobs = nil
 
 		updates := newJobStatusResolver(jobs).Resolve()
+		if len(updates) == 0 {
+			return nil
+		}
+
+		// a run waits for the previous unfinished runs of its concurrency group
+		runBlocked, err := actions_model.ShouldBlockRunByConcurrency(ctx, run)
+		if err != nil {
+			return err
+		}
+
+		cancelledIDs := make(container.Set[int64])
 		for _, job := range jobs {
-			if status, ok := updates[job.ID]; ok {
-				job.Status = status
-				if n, err := actions_model.UpdateRunJob(ctx, job, builder.Eq{"status": actions_model.StatusBlocked}, "status"); err != nil {
+			status, ok := updates[job.ID]
+			if !ok || cancelledIDs.Contains(job.ID) {
+				continue
+			}
+			if status == actions_model.StatusWaiting {
+				if runBlocked {
+					continue
+				}
+				blocked, cancelled, err := checkJobConcurrency(ctx, run, job, jobs)
+				cancelledJobs = append(cancelledJobs, cancelled...)
+				for _, v := range cancelled {
+					cancelledIDs.Add(v.ID)
+				}
+				if err != nil {
 					return err
-				} else if n != 1 {
-					return fmt.Errorf("no affected for updating blocked job %v", job.ID)
+				} else if blocked {
+					continue
 				}
 			}
+			job.Status = status
+			cols := []string{"status"}
+			if status.IsDone() && !status.IsSkipped() {
+				// a job calling a reusable workflow is done with the jobs of the called workflow
+				job.Started, job.Stopped = calledJobsDuration(job, jobs)
+				cols = append(cols, "started", "stopped")
+			}
+			if n, err := actions_model.UpdateRunJob(ctx, job, builder.Eq{"status": actions_model.StatusBlocked}, cols...); err != nil {
+				return err
+			} else if n != 1 {
+				return fmt.Errorf("no affected for updating blocked job %v", job.ID)
+			}
 		}
 		return nil
 	}); err != nil {
 		return err
 	}
 	CreateCommitStatus(ctx, jobs...)
+	CreateCommitStatus(ctx, cancelledJobs...)
+	emitRunsOfJobs(cancelledJobs, runID)
+
+	// the run or some of its jobs may have released their concurrency groups
+	if run, err = actions_model.GetRunByID(ctx, runID); err != nil {
+		return err
+	}
+	runIDs, err := actions_model.FindRunIDsUnblockedByConcurrency(ctx, run, jobs)
+	if err != nil {
+		return err
+	}
+	for _, id := range runIDs {
+		if err := EmitJobsIfReady(id); err != nil {
+			log.Error("EmitJobsIfReady: %v", err)
+		}
+	}
 	return nil
 }
 
+// calledJobsDuration returns the time range of the jobs of the workflow called by the job
+func calledJobsDuration(job *actions_model.ActionRunJob, jobs []*actions_model.ActionRunJob) (started, stopped timeutil.TimeStamp) {
+	for _, v := range jobs {
+		if !strings.HasPrefix(v.JobID, job.JobID+".") {
+			continue
+		}
+		if !v.Started.IsZero() && (started.IsZero() || v.Started < started) {
+			started = v.Started
+		}
+		if v.Stopped > stopped {
+			stopped = v.Stopped
+		}
+	}
+	if stopped.IsZero() {
+		stopped = timeutil.TimeStampNow()
+	}
+	if started.IsZero() {
+		started = stopped
+	}
+	return started, stopped
+}
+
 type jobStatusResolver struct {
 	statuses map[int64]actions_model.Status
 	needs    map[int64][]int64
+	// calls contains the jobs calling reusable workflows, their statuses are the results of the called jobs
+	calls container.Set[int64]
 }
 
 func newJobStatusResolver(jobs actions_model.ActionJobList) *jobStatusResolver {
@@ -86,8 +170,12 @@ func newJobStatusResolver(jobs actions_model.ActionJobList) *jobStatusResolver {
 
 	statuses := make(map[int64]actions_model.Status, len(jobs))
 	needs := make(map[int64][]int64, len(jobs))
+	calls := make(container.Set[int64])
 	for _, job := range jobs {
 		statuses[job.ID] = job.Status
+		if job.Status == actions_model.StatusBlocked && job.IsReusableWorkflowCall() {
+			calls.Add(job.ID)
+		}
 		for _, need := range job.Needs {
 			for _, v := range idToJobs[need] {
 				needs[job.ID] = append(needs[job.ID], v.ID)
@@ -97,6 +185,7 @@ func newJobStatusResolver(jobs actions_model.ActionJobList) *jobStatusResolver {
 	return &jobStatusResolver{
 		statuses: statuses,
 		needs:    needs,
+		calls:    calls,
 	}
 }
 
@@ -131,6 +220,10 @@ func (r *jobStatusResolver) resolve() map[int64]actions_model.Status {
 				allSucceed = false
 			}
 		}
+		if allDone && r.calls.Contains(id) {
+			ret[id] = r.resolveCall(id)
+			continue
+		}
 		if allDone {
 			if allSucceed {
 				ret[id] = actions_model.StatusWaiting
@@ -141,3 +234,26 @@ func (r *jobStatusResolver) resolve() map[int64]actions_model.Status {
 	}
 	return ret
 }
+
+// resolveCall returns the status of a job calling a reusable workflow, from the statuses of the called jobs
+func (r *jobStatusResolver) resolveCall(id int64) actions_model.Status {
+	allSkipped, hasCancelled := true, false
+	for _, need := range r.needs[id] {
+		switch r.statuses[need] {
+		case actions_model.StatusFailure:
+			return actions_model.StatusFailure
+		case actions_model.StatusCancelled:
+			hasCancelled = true
+		}
+		if !r.statuses[need].IsSkipped() {
+			allSkipped = false
+		}
+	}
+	if hasCancelled {
+		return actions_model.StatusCancelled
+	}
+	if allSkipped {
+		return actions_model.StatusSkipped
+	}
+	return actions_model.StatusSuccess
+}
@@ -12,6 +12,7 @@ import (
 )
 
 func Test_jobStatusResolver_Resolve(t *testing.T) {
+	callPayload := []byte("name: test\non: push\njobs:\n  build:\n    needs: [build.compile, build.package]\n    uses: ./.gitea/workflows/build.yml\n")
 	tests := []struct {
 		name string
 		jobs actions_model.ActionJobList
@@ -70,6 +71,32 @@ func Test_jobStatusResolver_Resolve(t *testing.T) {
 			},
 			want: map[int64]actions_model.Status{},
 		},
+		{
+			name: "reusable workflow call",
+			jobs: actions_model.ActionJobList{
+				{ID: 1, JobID: "build.compile", Status: actions_model.StatusSuccess, Needs: []string{}},
+				{ID: 2, JobID: "build.package", Status: actions_model.StatusSkipped, Needs: []string{"build.compile"}},
+				{ID: 3, JobID: "build", Status: actions_model.StatusBlocked, Needs: []string{"build.compile", "build.package"}, WorkflowPayload: callPayload},
+				{ID: 4, JobID: "deploy", Status: actions_model.StatusBlocked, Needs: []string{"build"}},
+			},
+			want: map[int64]actions_model.Status{
+				3: actions_model.StatusSuccess,
+				4: actions_model.StatusWaiting,
+			},
+		},
+		{
+			name: "failed reusable workflow call",
+			jobs: actions_model.ActionJobList{
+				{ID: 1, JobID: "build.compile", Status: actions_model.StatusFailure, Needs: []string{}},
+				{ID: 2, JobID: "build.package", Status: actions_model.StatusSkipped, Needs: []string{"build.compile"}},
+				{ID: 3, JobID: "build", Status: actions_model.StatusBlocked, Needs: []string{"build.compile", "build.package"}, WorkflowPayload: callPayload},
+				{ID: 4, JobID: "deploy", Status: actions_model.StatusBlocked, Needs: []string{"build"}},
+			},
+			want: map[int64]actions_model.Status{
+				3: actions_model.StatusFailure,
+				4: actions_model.StatusSkipped,
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
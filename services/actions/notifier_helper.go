@@ -24,7 +24,6 @@ import (
 	webhook_module "code.gitea.io/gitea/modules/webhook"
 	"code.gitea.io/gitea/services/convert"
 
-	"github.com/nektos/act/pkg/jobparser"
 	"github.com/nektos/act/pkg/model"
 )
 
@@ -249,27 +248,8 @@ func handleWorkflows(
 			run.NeedApproval = need
 		}
 
-		jobs, err := jobparser.Parse(dwf.Content)
-		if err != nil {
-			log.Error("jobparser.Parse: %v", err)
-			continue
-		}
-
-		// cancel running jobs if the event is push
-		if run.Event == webhook_module.HookEventPush {
-			// cancel running jobs of the same workflow
-			if err := actions_model.CancelRunningJobs(
-				ctx,
-				run.RepoID,
-				run.Ref,
-				run.WorkflowID,
-			); err != nil {
-				log.Error("CancelRunningJobs: %v", err)
-			}
-		}
-
-		if err := actions_model.InsertRun(ctx, run, jobs); err != nil {
-			log.Error("InsertRun: %v", err)
+		if err := insertRun(ctx, run, dwf.Content); err != nil {
+			log.Error("insertRun: %v", err)
 			continue
 		}
 
@@ -433,14 +413,16 @@ func handleSchedules(
 		// cancel running jobs if the event is push
 		if run.Event == webhook_module.HookEventPush {
 			// cancel running jobs of the same workflow
-			if err := actions_model.CancelRunningJobs(
+			cancelledJobs, err := actions_model.CancelRunningJobs(
 				ctx,
 				run.RepoID,
 				run.Ref,
 				run.WorkflowID,
-			); err != nil {
+			)
+			if err != nil {
 				log.Error("CancelRunningJobs: %v", err)
 			}
+			CreateCommitStatus(ctx, cancelledJobs...)
 		}
 		crons = append(crons, run)
 	}
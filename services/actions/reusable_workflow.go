@@ -0,0 +1,179 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unit"
+	actions_module "code.gitea.io/gitea/modules/actions"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/nektos/act/pkg/exprparser"
+	"github.com/nektos/act/pkg/jobparser"
+	"github.com/nektos/act/pkg/model"
+)
+
+// reusableWorkflowFetcher reads the reusable workflows called by the jobs of a run,
+// from the repository of the run at its commit, or from the other repositories which allow the repository of the run
+// to call their workflows.
+type reusableWorkflowFetcher struct {
+	ctx      context.Context
+	run      *actions_model.ActionRun
+	gitRepos map[int64]*git.Repository
+}
+
+func (f *reusableWorkflowFetcher) Fetch(workflow *actions_module.ReusableWorkflow) ([]byte, error) {
+	repo, ref := f.run.Repo, f.run.CommitSHA
+	if !workflow.IsLocal() {
+		var err error
+		if repo, err = repo_model.GetRepositoryByOwnerAndName(f.ctx, workflow.OwnerName, workflow.RepoName); err != nil {
+			if repo_model.IsErrRepoNotExist(err) {
+				return nil, util.NewNotExistErrorf("repository %s/%s doesn't exist", workflow.OwnerName, workflow.RepoName)
+			}
+			return nil, err
+		}
+		if repo.ID != f.run.RepoID {
+			// the called workflow is readable in the logs and the payloads of the run, so the access depends on
+			// the calling repository rather than on the user who triggered the run
+			allowed, err := canCallWorkflows(f.ctx, f.run.Repo, repo)
+			if err != nil {
+				return nil, err
+			}
+			if !allowed {
+				// don't reveal the existence of the repository
+				return nil, util.NewNotExistErrorf("repository %s/%s doesn't exist", workflow.OwnerName, workflow.RepoName)
+			}
+		}
+		ref = workflow.Ref
+	}
+
+	gitRepo, ok := f.gitRepos[repo.ID]
+	if !ok {
+		var err error
+		if gitRepo, err = git.OpenRepository(f.ctx, repo.RepoPath()); err != nil {
+			return nil, fmt.Errorf("git.OpenRepository: %w", err)
+		}
+		f.gitRepos[repo.ID] = gitRepo
+	}
+	commit, err := gitRepo.GetCommit(ref)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			return nil, util.NewNotExistErrorf("ref %q doesn't exist", ref)
+		}
+		return nil, err
+	}
+	content, err := commit.GetFileContent(workflow.Path, 0)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			return nil, util.NewNotExistErrorf("file %q doesn't exist", workflow.Path)
+		}
+		return nil, err
+	}
+	return []byte(content), nil
+}
+
+// canCallWorkflows returns whether the workflows of the called repository can be called by the jobs of the calling
+// repository: the repositories of the same owner and the public repositories are callable by any repository,
+// the other ones only if they share their workflows.
+func canCallWorkflows(ctx context.Context, caller, called *repo_model.Repository) (bool, error) {
+	if !called.UnitEnabled(ctx, unit.TypeCode) {
+		return false, nil
+	}
+	if called.OwnerID == caller.OwnerID {
+		return true, nil
+	}
+	if !called.IsPrivate {
+		if err := called.LoadOwner(ctx); err != nil {
+			return false, err
+		}
+		if called.Owner.Visibility.IsPublic() {
+			return true, nil
+		}
+	}
+	actionsUnit, err := called.GetUnit(ctx, unit.TypeActions)
+	if repo_model.IsErrUnitTypeNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return actionsUnit.ActionsConfig().ShareWorkflows, nil
+}
+
+func (f *reusableWorkflowFetcher) Close() {
+	for _, gitRepo := range f.gitRepos {
+		gitRepo.Close()
+	}
+}
+
+// parseRunJobs parses the jobs of a new run from its workflow content, with the reusable workflows called by them expanded.
+// It returns the expanded content, which the concurrency configurations are read from.
+func parseRunJobs(ctx context.Context, run *actions_model.ActionRun, content []byte) ([]byte, []*jobparser.SingleWorkflow, error) {
+	if err := run.LoadAttributes(ctx); err != nil {
+		return nil, nil, err
+	}
+	fetcher := &reusableWorkflowFetcher{ctx: ctx, run: run, gitRepos: map[int64]*git.Repository{}}
+	defer fetcher.Close()
+
+	content, err := actions_module.ExpandReusableWorkflows(content, fetcher.Fetch)
+	if err != nil {
+		return nil, nil, util.NewInvalidArgumentErrorf("invalid workflow %q: %v", run.WorkflowID, err)
+	}
+	jobs, err := jobparser.Parse(content)
+	if err != nil {
+		return nil, nil, util.NewInvalidArgumentErrorf("invalid workflow %q: %v", run.WorkflowID, err)
+	}
+	return content, jobs, nil
+}
+
+// EvaluateReusableWorkflowOutputs evaluates the outputs of a job calling a reusable workflow
+// from the outputs of the jobs of the called workflow.
+func EvaluateReusableWorkflowOutputs(ctx context.Context, job *actions_model.ActionRunJob, jobs []*actions_model.ActionRunJob) (map[string]string, error) {
+	workflows, err := jobparser.Parse(job.WorkflowPayload)
+	if err != nil || len(workflows) != 1 {
+		return nil, fmt.Errorf("parse workflow payload of job %d: %w", job.ID, err)
+	}
+	_, parsed := workflows[0].Job()
+	if len(parsed.Outputs) == 0 {
+		return map[string]string{}, nil
+	}
+
+	// the called jobs are referred by their ids in the called workflow
+	calledJobs := map[string]*model.WorkflowCallResult{}
+	prefix := job.JobID + "."
+	for _, called := range jobs {
+		id, ok := strings.CutPrefix(called.JobID, prefix)
+		if !ok || strings.Contains(id, ".") {
+			continue
+		}
+		outputs := map[string]string{}
+		if called.TaskID != 0 {
+			got, err := actions_model.FindTaskOutputByTaskID(ctx, called.TaskID)
+			if err != nil {
+				return nil, fmt.Errorf("FindTaskOutputByTaskID: %w", err)
+			}
+			for _, v := range got {
+				outputs[v.OutputKey] = v.OutputValue
+			}
+		} else if called.IsReusableWorkflowCall() && called.Status.IsDone() {
+			if outputs, err = EvaluateReusableWorkflowOutputs(ctx, called, jobs); err != nil {
+				return nil, err
+			}
+		}
+		calledJobs[id] = &model.WorkflowCallResult{Outputs: outputs}
+	}
+
+	interpreter := exprparser.NewInterpeter(&exprparser.EvaluationEnvironment{Jobs: &calledJobs}, exprparser.Config{})
+	evaluator := jobparser.NewExpressionEvaluator(interpreter)
+	outputs := make(map[string]string, len(parsed.Outputs))
+	for name, value := range parsed.Outputs {
+		outputs[name] = evaluator.Interpolate(value)
+	}
+	return outputs, nil
+}
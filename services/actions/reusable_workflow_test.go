@@ -0,0 +1,47 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unit"
+	"code.gitea.io/gitea/models/unittest"
+	actions_module "code.gitea.io/gitea/modules/actions"
+	"code.gitea.io/gitea/modules/git"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReusableWorkflowFetcher(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	// user2/repo1 calls the workflows of the other repositories
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1})
+	run := &actions_model.ActionRun{RepoID: repo.ID, Repo: repo}
+	fetcher := &reusableWorkflowFetcher{ctx: db.DefaultContext, run: run, gitRepos: map[int64]*git.Repository{}}
+	defer fetcher.Close()
+
+	fetch := func(owner, name string) error {
+		_, err := fetcher.Fetch(&actions_module.ReusableWorkflow{OwnerName: owner, RepoName: name, Path: ".gitea/workflows/build.yml", Ref: "master"})
+		return err
+	}
+
+	// the private repository of another owner doesn't exist for the calling repository
+	assert.EqualError(t, fetch("org3", "repo3"), "repository org3/repo3 doesn't exist")
+
+	// the private repository of the same owner is readable, so only the file is missing
+	assert.EqualError(t, fetch("user2", "repo2"), `file ".gitea/workflows/build.yml" doesn't exist`)
+
+	// the private repository of another owner sharing its workflows
+	assert.NoError(t, db.Insert(db.DefaultContext, &repo_model.RepoUnit{
+		RepoID: 3,
+		Type:   unit.TypeActions,
+		Config: &repo_model.ActionsConfig{ShareWorkflows: true},
+	}))
+	assert.EqualError(t, fetch("org3", "repo3"), `file ".gitea/workflows/build.yml" doesn't exist`)
+}
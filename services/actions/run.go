@@ -0,0 +1,149 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"strings"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/container"
+	"code.gitea.io/gitea/modules/log"
+
+	"xorm.io/builder"
+)
+
+// GetRerunJobs returns the jobs to rerun for a job of a run:
+// the job itself, plus the jobs of the called workflow if it calls a reusable workflow.
+func GetRerunJobs(job *actions_model.ActionRunJob, jobs []*actions_model.ActionRunJob) []*actions_model.ActionRunJob {
+	if !job.IsReusableWorkflowCall() {
+		return []*actions_model.ActionRunJob{job}
+	}
+	// rerun the jobs of the called workflow, the job is done again with them
+	ret := []*actions_model.ActionRunJob{job}
+	for _, j := range jobs {
+		if strings.HasPrefix(j.JobID, job.JobID+".") {
+			ret = append(ret, j)
+		}
+	}
+	return ret
+}
+
+// GetFailedJobs returns the failed or cancelled jobs of a run, plus the jobs depending on them
+func GetFailedJobs(jobs []*actions_model.ActionRunJob) []*actions_model.ActionRunJob {
+	rerun := make(container.Set[string])
+	for _, job := range jobs {
+		if job.Status == actions_model.StatusFailure || job.Status == actions_model.StatusCancelled {
+			rerun.Add(job.JobID)
+		}
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, job := range jobs {
+			if rerun.Contains(job.JobID) {
+				continue
+			}
+			for _, need := range job.Needs {
+				if rerun.Contains(need) {
+					changed = rerun.Add(job.JobID)
+					break
+				}
+			}
+		}
+	}
+
+	ret := make([]*actions_model.ActionRunJob, 0, len(rerun))
+	for _, job := range jobs {
+		if rerun.Contains(job.JobID) {
+			ret = append(ret, job)
+		}
+	}
+	return ret
+}
+
+// RerunJobs reruns the given jobs of a run, the jobs which aren't done are ignored
+func RerunJobs(ctx context.Context, run *actions_model.ActionRun, jobs []*actions_model.ActionRunJob) error {
+	rerun := make(container.Set[string], len(jobs))
+	for _, job := range jobs {
+		rerun.Add(job.JobID)
+	}
+
+	emit := false
+	for _, job := range jobs {
+		job.Run = run
+		// a job needing another rerun job waits for it again
+		needsRerun := false
+		for _, need := range job.Needs {
+			needsRerun = needsRerun || rerun.Contains(need)
+		}
+		blocked, err := rerunJob(ctx, job, needsRerun)
+		if err != nil {
+			return err
+		}
+		emit = emit || blocked
+	}
+	if emit {
+		return EmitJobsIfReady(run.ID)
+	}
+	return nil
+}
+
+// rerunJob resets a done job, it returns whether the job is blocked until the job emitter checks it
+func rerunJob(ctx context.Context, job *actions_model.ActionRunJob, needsRerun bool) (bool, error) {
+	status := job.Status
+	if !status.IsDone() {
+		return false, nil
+	}
+
+	job.TaskID = 0
+	job.Status = actions_model.StatusWaiting
+	job.Started = 0
+	job.Stopped = 0
+
+	// the concurrency of the job is evaluated again by the job emitter,
+	// and a job calling a reusable workflow waits for the called jobs
+	hasConcurrency := job.Run.RawConcurrency != "" || job.RawConcurrency != ""
+	if hasConcurrency || job.IsReusableWorkflowCall() {
+		job.Status = actions_model.StatusBlocked
+		job.IsConcurrencyEvaluated = false
+		job.ConcurrencyGroup = ""
+		job.ConcurrencyCancel = false
+	} else if needsRerun {
+		job.Status = actions_model.StatusBlocked
+	}
+
+	if err := db.WithTx(ctx, func(ctx context.Context) error {
+		_, err := actions_model.UpdateRunJob(ctx, job, builder.Eq{"status": status}, "task_id", "status", "started", "stopped",
+			"is_concurrency_evaluated", "concurrency_group", "concurrency_cancel")
+		return err
+	}); err != nil {
+		return false, err
+	}
+
+	CreateCommitStatus(ctx, job)
+
+	return job.Status == actions_model.StatusBlocked, nil
+}
+
+// CancelRun cancels the unfinished jobs of a run
+func CancelRun(ctx context.Context, jobs []*actions_model.ActionRunJob) error {
+	if len(jobs) == 0 {
+		return nil
+	}
+	if err := db.WithTx(ctx, func(ctx context.Context) error {
+		_, err := actions_model.CancelJobs(ctx, jobs)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	CreateCommitStatus(ctx, jobs...)
+
+	// the next run of the concurrency groups may start now
+	if err := EmitJobsIfReady(jobs[0].RunID); err != nil {
+		log.Error("EmitJobsIfReady: %v", err)
+	}
+	return nil
+}
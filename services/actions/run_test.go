@@ -0,0 +1,29 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetFailedJobs(t *testing.T) {
+	jobs := []*actions_model.ActionRunJob{
+		{ID: 1, JobID: "lint", Status: actions_model.StatusSuccess},
+		{ID: 2, JobID: "build", Status: actions_model.StatusFailure},
+		{ID: 3, JobID: "test", Status: actions_model.StatusSkipped, Needs: []string{"build"}},
+		{ID: 4, JobID: "deploy", Status: actions_model.StatusSkipped, Needs: []string{"lint", "test"}},
+		{ID: 5, JobID: "docs", Status: actions_model.StatusCancelled},
+		{ID: 6, JobID: "notify", Status: actions_model.StatusSuccess, Needs: []string{"lint"}},
+	}
+
+	var ids []int64
+	for _, job := range GetFailedJobs(jobs) {
+		ids = append(ids, job.ID)
+	}
+	assert.Equal(t, []int64{2, 3, 4, 5}, ids)
+}
@@ -13,9 +13,6 @@ import (
 	"code.gitea.io/gitea/models/unit"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/timeutil"
-	webhook_module "code.gitea.io/gitea/modules/webhook"
-
-	"github.com/nektos/act/pkg/jobparser"
 )
 
 // StartScheduleTasks start the task
@@ -51,19 +48,6 @@ func startTasks(ctx context.Context) error {
 
 		// Loop through each spec and create a schedule task for it
 		for _, row := range specs {
-			// cancel running jobs if the event is push
-			if row.Schedule.Event == webhook_module.HookEventPush {
-				// cancel running jobs of the same workflow
-				if err := actions_model.CancelRunningJobs(
-					ctx,
-					row.RepoID,
-					row.Schedule.Ref,
-					row.Schedule.WorkflowID,
-				); err != nil {
-					log.Error("CancelRunningJobs: %v", err)
-				}
-			}
-
 			cfg := row.Repo.MustGetUnit(ctx, unit.TypeActions).ActionsConfig()
 			if cfg.IsWorkflowDisabled(row.Schedule.WorkflowID) {
 				continue
@@ -117,14 +101,9 @@ func CreateScheduleTask(ctx context.Context, cron *actions_model.ActionSchedule)
 		Status:        actions_model.StatusWaiting,
 	}
 
-	// Parse the workflow specification from the cron schedule
-	workflows, err := jobparser.Parse(cron.Content)
-	if err != nil {
-		return err
-	}
-
-	// Insert the action run and its associated jobs into the database
-	if err := actions_model.InsertRun(ctx, run, workflows); err != nil {
+	// Insert the action run and its associated jobs parsed from the workflow specification of the cron schedule into the database,
+	// running jobs of the same workflow are cancelled if the event is push and the workflow doesn't define a concurrency
+	if err := insertRun(ctx, run, cron.Content); err != nil {
 		return err
 	}
 
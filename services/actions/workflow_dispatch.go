@@ -0,0 +1,135 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	access_model "code.gitea.io/gitea/models/perm/access"
+	repo_model "code.gitea.io/gitea/models/repo"
+	unit_model "code.gitea.io/gitea/models/unit"
+	user_model "code.gitea.io/gitea/models/user"
+	actions_module "code.gitea.io/gitea/modules/actions"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/json"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
+	webhook_module "code.gitea.io/gitea/modules/webhook"
+	"code.gitea.io/gitea/services/convert"
+)
+
+// DispatchWorkflow triggers the workflow manually on the branch or tag, with the values of its workflow_dispatch inputs
+func DispatchWorkflow(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, gitRepo *git.Repository, workflowID, ref string, inputs map[string]string) (*actions_model.ActionRun, error) {
+	if err := repo.LoadUnits(ctx); err != nil {
+		return nil, fmt.Errorf("repo.LoadUnits: %w", err)
+	} else if !repo.UnitEnabled(ctx, unit_model.TypeActions) {
+		return nil, util.NewNotExistErrorf("actions are disabled")
+	}
+	if repo.MustGetUnit(ctx, unit_model.TypeActions).ActionsConfig().IsWorkflowDisabled(workflowID) {
+		return nil, util.NewPermissionDeniedErrorf("workflow %q is disabled", workflowID)
+	}
+
+	refName, commit, err := getDispatchRefCommit(gitRepo, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := actions_module.ListWorkflows(commit)
+	if err != nil {
+		return nil, err
+	}
+	var content []byte
+	for _, entry := range entries {
+		if entry.Name() == workflowID {
+			if content, err = actions_module.GetContentFromEntry(entry); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	if content == nil {
+		return nil, util.NewNotExistErrorf("workflow %q doesn't exist in %s", workflowID, refName.ShortName())
+	}
+
+	dispatch, err := actions_module.GetWorkflowDispatch(content)
+	if err != nil {
+		return nil, util.NewInvalidArgumentErrorf("invalid workflow %q: %v", workflowID, err)
+	} else if dispatch == nil {
+		return nil, util.NewInvalidArgumentErrorf("workflow %q has no workflow_dispatch trigger", workflowID)
+	}
+	if inputs, err = dispatch.ResolveInputs(inputs); err != nil {
+		return nil, err
+	}
+
+	permission, err := access_model.GetUserRepoPermission(ctx, repo, doer)
+	if err != nil {
+		return nil, err
+	}
+	p, err := json.Marshal(&api.WorkflowDispatchPayload{
+		Workflow:   workflowID,
+		Ref:        refName.String(),
+		Inputs:     inputs,
+		Repository: convert.ToRepo(ctx, repo, permission),
+		Sender:     convert.ToUser(ctx, doer, nil),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	run := &actions_model.ActionRun{
+		Title:         strings.SplitN(commit.CommitMessage, "\n", 2)[0],
+		RepoID:        repo.ID,
+		OwnerID:       repo.OwnerID,
+		WorkflowID:    workflowID,
+		TriggerUserID: doer.ID,
+		Ref:           refName.String(),
+		CommitSHA:     commit.ID.String(),
+		Event:         webhook_module.HookEventWorkflowDispatch,
+		EventPayload:  string(p),
+		TriggerEvent:  actions_module.GithubEventWorkflowDispatch,
+		Status:        actions_model.StatusWaiting,
+	}
+	if err := insertRun(ctx, run, content); err != nil {
+		return nil, err
+	}
+
+	alljobs, _, err := actions_model.FindRunJobs(ctx, actions_model.FindRunJobOptions{RunID: run.ID})
+	if err != nil {
+		return nil, fmt.Errorf("FindRunJobs: %w", err)
+	}
+	CreateCommitStatus(ctx, alljobs...)
+	return run, nil
+}
+
+// getDispatchRefCommit returns the full name and the commit of a branch or tag, given by its full or short name
+func getDispatchRefCommit(gitRepo *git.Repository, ref string) (git.RefName, *git.Commit, error) {
+	refName := git.RefName(ref)
+	if !strings.HasPrefix(ref, "refs/") {
+		if gitRepo.IsBranchExist(ref) {
+			refName = git.RefNameFromBranch(ref)
+		} else if gitRepo.IsTagExist(ref) {
+			refName = git.RefNameFromTag(ref)
+		}
+	}
+
+	var commit *git.Commit
+	var err error
+	switch {
+	case refName.IsBranch():
+		commit, err = gitRepo.GetBranchCommit(refName.BranchName())
+	case refName.IsTag():
+		commit, err = gitRepo.GetTagCommit(refName.TagName())
+	default:
+		return "", nil, util.NewNotExistErrorf("branch or tag %q doesn't exist", ref)
+	}
+	if git.IsErrNotExist(err) {
+		return "", nil, util.NewNotExistErrorf("branch or tag %q doesn't exist", ref)
+	} else if err != nil {
+		return "", nil, err
+	}
+	return refName, commit, nil
+}
@@ -0,0 +1,164 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package convert
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/git"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// ToActionsStatus converts the status of a run, a job or a step to the status and the conclusion of the API
+func ToActionsStatus(status actions_model.Status) (string, string) {
+	switch status {
+	case actions_model.StatusRunning:
+		return "in_progress", ""
+	case actions_model.StatusBlocked:
+		return "waiting", ""
+	case actions_model.StatusSuccess, actions_model.StatusFailure, actions_model.StatusCancelled, actions_model.StatusSkipped:
+		return "completed", strings.ToLower(status.String())
+	default:
+		return "queued", ""
+	}
+}
+
+// ParseActionsStatus returns the statuses matching a status or a conclusion of the API
+func ParseActionsStatus(s string) ([]actions_model.Status, error) {
+	switch s {
+	case "queued":
+		return []actions_model.Status{actions_model.StatusUnknown, actions_model.StatusWaiting}, nil
+	case "waiting", "pending":
+		return []actions_model.Status{actions_model.StatusBlocked}, nil
+	case "in_progress":
+		return []actions_model.Status{actions_model.StatusRunning}, nil
+	case "completed":
+		return []actions_model.Status{actions_model.StatusSuccess, actions_model.StatusFailure, actions_model.StatusCancelled, actions_model.StatusSkipped}, nil
+	case "success":
+		return []actions_model.Status{actions_model.StatusSuccess}, nil
+	case "failure":
+		return []actions_model.Status{actions_model.StatusFailure}, nil
+	case "cancelled":
+		return []actions_model.Status{actions_model.StatusCancelled}, nil
+	case "skipped":
+		return []actions_model.Status{actions_model.StatusSkipped}, nil
+	}
+	return nil, fmt.Errorf("unknown status %q", s)
+}
+
+func timeOrNil(ts timeutil.TimeStamp) *time.Time {
+	if ts.IsZero() {
+		return nil
+	}
+	t := ts.AsTime()
+	return &t
+}
+
+func actionRunAPIURL(repo *repo_model.Repository, runID int64) string {
+	return fmt.Sprintf("%s/actions/runs/%d", repo.APIURL(), runID)
+}
+
+// ToActionWorkflowRun converts an ActionRun to API format, its repository and trigger user have to be loaded
+func ToActionWorkflowRun(ctx context.Context, run *actions_model.ActionRun) *api.ActionWorkflowRun {
+	status, conclusion := ToActionsStatus(run.Status)
+	url := actionRunAPIURL(run.Repo, run.ID)
+	return &api.ActionWorkflowRun{
+		ID:              run.ID,
+		DisplayTitle:    run.Title,
+		WorkflowID:      run.WorkflowID,
+		RunNumber:       run.Index,
+		Event:           string(run.Event),
+		Status:          status,
+		Conclusion:      conclusion,
+		HeadBranch:      git.RefName(run.Ref).ShortName(),
+		HeadSha:         run.CommitSHA,
+		URL:             url,
+		HTMLURL:         run.HTMLURL(),
+		JobsURL:         url + "/jobs",
+		ArtifactsURL:    url + "/artifacts",
+		CancelURL:       url + "/cancel",
+		RerunURL:        url + "/rerun",
+		TriggeringActor: ToUser(ctx, run.TriggerUser, nil),
+		CreatedAt:       run.Created.AsTime(),
+		UpdatedAt:       run.Updated.AsTime(),
+		RunStartedAt:    timeOrNil(run.Started),
+	}
+}
+
+// ToActionWorkflowJob converts an ActionRunJob to API format, index is the index of the job in the jobs of its run
+func ToActionWorkflowJob(ctx context.Context, run *actions_model.ActionRun, job *actions_model.ActionRunJob, index int) (*api.ActionWorkflowJob, error) {
+	status, conclusion := ToActionsStatus(job.Status)
+	result := &api.ActionWorkflowJob{
+		ID:          job.ID,
+		RunID:       job.RunID,
+		RunURL:      actionRunAPIURL(run.Repo, run.ID),
+		HeadSha:     job.CommitSHA,
+		URL:         fmt.Sprintf("%s/actions/jobs/%d", run.Repo.APIURL(), job.ID),
+		HTMLURL:     fmt.Sprintf("%s/jobs/%d", run.HTMLURL(), index),
+		Name:        job.Name,
+		Status:      status,
+		Conclusion:  conclusion,
+		RunAttempt:  job.Attempt,
+		Labels:      job.RunsOn,
+		Steps:       []*api.ActionWorkflowStep{},
+		CreatedAt:   job.Created.AsTime(),
+		StartedAt:   timeOrNil(job.Started),
+		CompletedAt: timeOrNil(job.Stopped),
+	}
+	if job.TaskID == 0 {
+		return result, nil
+	}
+
+	task, err := actions_model.GetTaskByID(ctx, job.TaskID)
+	if err != nil {
+		return nil, err
+	}
+	result.RunnerID = task.RunnerID
+	if runner, err := actions_model.GetRunnerByID(ctx, task.RunnerID); err == nil {
+		result.RunnerName = runner.Name
+	}
+	steps, err := actions_model.GetTaskStepsByTaskID(ctx, task.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, step := range steps {
+		status, conclusion := ToActionsStatus(step.Status)
+		result.Steps = append(result.Steps, &api.ActionWorkflowStep{
+			Name:        step.Name,
+			Number:      step.Index + 1,
+			Status:      status,
+			Conclusion:  conclusion,
+			StartedAt:   timeOrNil(step.Started),
+			CompletedAt: timeOrNil(step.Stopped),
+		})
+	}
+	return result, nil
+}
+
+// ToActionArtifact converts an artifact to API format
+func ToActionArtifact(repo *repo_model.Repository, art *actions_model.ActionArtifactSummary, run *actions_model.ActionRun) *api.ActionArtifact {
+	url := fmt.Sprintf("%s/actions/artifacts/%d", repo.APIURL(), art.ID)
+	return &api.ActionArtifact{
+		ID:                 art.ID,
+		Name:               art.ArtifactName,
+		SizeInBytes:        art.FileSize,
+		URL:                url,
+		ArchiveDownloadURL: url + "/zip",
+		Expired:            art.IsExpired(),
+		WorkflowRun: &api.ActionWorkflowRunSummary{
+			ID:         run.ID,
+			HeadBranch: git.RefName(run.Ref).ShortName(),
+			HeadSha:    run.CommitSHA,
+		},
+		CreatedAt: art.CreatedUnix.AsTime(),
+		UpdatedAt: art.UpdatedUnix.AsTime(),
+		ExpiresAt: art.ExpiredUnix.AsTime(),
+	}
+}
@@ -440,3 +440,21 @@ func ToChangedFile(f *gitdiff.DiffFile, repo *repo_model.Repository, commit stri
 
 	return file
 }
+
+// ToPushPolicy converts a push policy and its effective policy to API format
+func ToPushPolicy(policy *git_model.PushPolicy, effective *git_model.EffectivePushPolicy) *api.PushPolicy {
+	licenses := policy.Licenses
+	if licenses == nil {
+		licenses = []string{}
+	}
+	effectiveLicenses := effective.Licenses
+	if effectiveLicenses == nil {
+		effectiveLicenses = []string{}
+	}
+	return &api.PushPolicy{
+		Licenses:             licenses,
+		MaxFileSize:          policy.MaxFileSize,
+		EffectiveLicenses:    effectiveLicenses,
+		EffectiveMaxFileSize: effective.MaxFileSize,
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package convert
+
+import (
+	"context"
+
+	moderation_model "code.gitea.io/gitea/models/moderation"
+	user_model "code.gitea.io/gitea/models/user"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ToModerationRecord converts a moderation record to API format, the attributes of the record must be loaded
+func ToModerationRecord(ctx context.Context, record *moderation_model.Record, doer *user_model.User) *api.ModerationRecord {
+	result := &api.ModerationRecord{
+		ID:           record.ID,
+		RepoID:       record.RepoID,
+		Ref:          record.RefName,
+		Before:       record.OldCommitID,
+		After:        record.NewCommitID,
+		Path:         record.Path,
+		SegmentHash:  record.SegmentHash,
+		Provider:     record.Provider,
+		Verdict:      record.Verdict,
+		Status:       record.Status.String(),
+		AppealReason: record.AppealReason,
+		Created:      record.CreatedUnix.AsTime(),
+		Updated:      record.UpdatedUnix.AsTime(),
+	}
+	if record.Repo != nil {
+		result.RepoName = record.Repo.FullName()
+	}
+	if record.Doer != nil {
+		result.Pusher = ToUser(ctx, record.Doer, doer)
+	}
+	if record.Reviewer != nil {
+		result.Reviewer = ToUser(ctx, record.Reviewer, doer)
+	}
+	return result
+}
@@ -20,6 +20,7 @@ import (
 	packages_cleanup_service "code.gitea.io/gitea/services/packages/cleanup"
 	repo_service "code.gitea.io/gitea/services/repository"
 	archiver_service "code.gitea.io/gitea/services/repository/archiver"
+	"code.gitea.io/gitea/services/repository/downloadstat"
 )
 
 func registerUpdateMirrorTask() {
@@ -171,6 +172,16 @@ func registerActionsCleanup() {
 	})
 }
 
+func registerDownloadVisitorsCleanup() {
+	RegisterTaskFatal("cleanup_download_visitors", &BaseConfig{
+		Enabled:    true,
+		RunAtStart: true,
+		Schedule:   "@midnight",
+	}, func(ctx context.Context, _ *user_model.User, _ Config) error {
+		return downloadstat.DeleteOldVisitors(ctx)
+	})
+}
+
 func initBasicTasks() {
 	if setting.Mirror.Enabled {
 		registerUpdateMirrorTask()
@@ -190,4 +201,7 @@ func initBasicTasks() {
 	if setting.Actions.Enabled {
 		registerActionsCleanup()
 	}
+	if setting.Repository.DownloadStats.Enabled {
+		registerDownloadVisitorsCleanup()
+	}
 }
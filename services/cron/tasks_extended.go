@@ -15,6 +15,7 @@ import (
 	issue_indexer "code.gitea.io/gitea/modules/indexer/issues"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/updatechecker"
+	lfs_service "code.gitea.io/gitea/services/lfs"
 	repo_service "code.gitea.io/gitea/services/repository"
 	archiver_service "code.gitea.io/gitea/services/repository/archiver"
 	user_service "code.gitea.io/gitea/services/user"
@@ -213,6 +214,40 @@ func registerGCLFS() {
 	})
 }
 
+func registerArchiveLFSObjects() {
+	if !setting.LFS.StartServer {
+		return
+	}
+	RegisterTaskFatal("archive_lfs_objects", &OlderThanConfig{
+		BaseConfig: BaseConfig{
+			Enabled:    false,
+			RunAtStart: false,
+			Schedule:   "@midnight",
+		},
+		OlderThan: 90 * 24 * time.Hour,
+	}, func(ctx context.Context, _ *user_model.User, config Config) error {
+		olderThanConfig := config.(*OlderThanConfig)
+		return lfs_service.ArchiveUnusedObjects(ctx, olderThanConfig.OlderThan)
+	})
+}
+
+func registerAbortStaleLFSMultipartUploads() {
+	if !setting.LFS.StartServer {
+		return
+	}
+	RegisterTaskFatal("abort_stale_lfs_multipart_uploads", &OlderThanConfig{
+		BaseConfig: BaseConfig{
+			Enabled:    false,
+			RunAtStart: false,
+			Schedule:   "@midnight",
+		},
+		OlderThan: 7 * 24 * time.Hour,
+	}, func(ctx context.Context, _ *user_model.User, config Config) error {
+		olderThanConfig := config.(*OlderThanConfig)
+		return lfs_service.AbortStaleMultipartUploads(ctx, olderThanConfig.OlderThan)
+	})
+}
+
 func registerRebuildIssueIndexer() {
 	RegisterTaskFatal("rebuild_issue_indexer", &BaseConfig{
 		Enabled:    false,
@@ -237,5 +272,7 @@ func initExtendedTasks() {
 	registerUpdateGiteaChecker()
 	registerDeleteOldSystemNotices()
 	registerGCLFS()
+	registerArchiveLFSObjects()
+	registerAbortStaleLFSMultipartUploads()
 	registerRebuildIssueIndexer()
 }
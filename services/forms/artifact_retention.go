@@ -0,0 +1,27 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package forms
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/web/middleware"
+
+	"gitea.com/go-chi/binding"
+)
+
+// ArtifactRetentionForm form for overriding the artifact retention of an organization or a repository
+type ArtifactRetentionForm struct {
+	// RetentionDays 0 inherits the upper level
+	RetentionDays int64 `binding:"Range(0,36500)"`
+	// MaxSize is in MiB, 0 inherits the upper level and -1 disables the limit
+	MaxSize int64 `binding:"Range(-1,1073741824)"`
+}
+
+// Validate validates the fields
+func (f *ArtifactRetentionForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetValidateContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
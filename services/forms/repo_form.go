@@ -154,6 +154,7 @@ type RepoSettingForm struct {
 	EnablePackages                        bool
 	EnablePulls                           bool
 	EnableActions                         bool
+	ShareActionsWorkflows                 bool
 	PullsIgnoreWhitespace                 bool
 	PullsAllowMerge                       bool
 	PullsAllowRebase                      bool
@@ -904,3 +905,17 @@ func (f *DeadlineForm) Validate(req *http.Request, errs binding.Errors) binding.
 	ctx := context.GetValidateContext(req)
 	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
 }
+
+// PushPolicyForm form for overriding the push policy of an organization or a repository
+type PushPolicyForm struct {
+	// Licenses are separated by commas or new lines
+	Licenses string `binding:"MaxSize(4096)"`
+	// MaxFileSize is in MiB, 0 inherits the upper level
+	MaxFileSize int64 `binding:"Range(0,1048576)"`
+}
+
+// Validate validates the fields
+func (f *PushPolicyForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetValidateContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
@@ -17,12 +17,18 @@ import (
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/storage"
+	"code.gitea.io/gitea/services/mqevent"
 	notify_service "code.gitea.io/gitea/services/notify"
 )
 
 // NewIssue creates new issue with labels for repository.
 func NewIssue(ctx context.Context, repo *repo_model.Repository, issue *issues_model.Issue, labelIDs []int64, uuids []string, assigneeIDs []int64) error {
-	if err := issues_model.NewIssue(ctx, repo, issue, labelIDs, uuids); err != nil {
+	if err := db.WithTx(ctx, func(ctx context.Context) error {
+		if err := issues_model.NewIssue(ctx, repo, issue, labelIDs, uuids); err != nil {
+			return err
+		}
+		return mqevent.NewIssue(ctx, issue)
+	}); err != nil {
 		return err
 	}
 
@@ -152,7 +158,12 @@ func DeleteIssue(ctx context.Context, doer *user_model.User, gitRepo *git.Reposi
 	}
 
 	// delete entries in database
-	if err := deleteIssue(ctx, issue); err != nil {
+	if err := db.WithTx(ctx, func(ctx context.Context) error {
+		if err := deleteIssue(ctx, issue); err != nil {
+			return err
+		}
+		return mqevent.DeleteIssue(ctx, doer, issue)
+	}); err != nil {
 		return err
 	}
 
@@ -6,16 +6,24 @@ package issue
 import (
 	"context"
 
+	"code.gitea.io/gitea/models/db"
 	issues_model "code.gitea.io/gitea/models/issues"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/services/mqevent"
 	notify_service "code.gitea.io/gitea/services/notify"
 )
 
 // ChangeStatus changes issue status to open or closed.
 func ChangeStatus(ctx context.Context, issue *issues_model.Issue, doer *user_model.User, commitID string, closed bool) error {
-	comment, err := issues_model.ChangeIssueStatus(ctx, issue, doer, closed)
-	if err != nil {
+	var comment *issues_model.Comment
+	if err := db.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		if comment, err = issues_model.ChangeIssueStatus(ctx, issue, doer, closed); err != nil {
+			return err
+		}
+		return mqevent.IssueChangeStatus(ctx, doer, issue, closed)
+	}); err != nil {
 		if issues_model.IsErrDependenciesLeft(err) && closed {
 			if err := issues_model.FinishIssueStopwatchIfPossible(ctx, doer, issue); err != nil {
 				log.Error("Unable to stop stopwatch for issue[%d]#%d: %v", issue.ID, issue.Index, err)
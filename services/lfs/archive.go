@@ -0,0 +1,311 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package lfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	git_model "code.gitea.io/gitea/models/git"
+	"code.gitea.io/gitea/modules/graceful"
+	lfs_module "code.gitea.io/gitea/modules/lfs"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+const (
+	// archiveBatchSize is the number of objects loaded at once by the archiving
+	archiveBatchSize = 100
+	// restoreRetryInterval is the interval after which the restore of an object is attempted again, e.g. when the object
+	// is in a cold storage class and takes hours to be readable
+	restoreRetryInterval = time.Minute
+	// restoreDays is how long the cold storage classes keep the readable copy of a restored object,
+	// it is copied back to the LFS storage as soon as it is readable
+	restoreDays = 1
+)
+
+// pendingError is the error of the archived objects requested by the clients, they can retry once they are restored
+var pendingError = &lfs_module.ObjectError{
+	Code:    http.StatusServiceUnavailable,
+	Message: "Object is being restored from the archive storage, retry later",
+}
+
+var restoreQueue *queue.WorkerPoolQueue[string]
+
+// objectLocks keeps the LFS objects from being moved between the LFS storage and the archive storage while they are
+// downloaded, the downloads share the lock of an object and the archiving and the restore hold it exclusively
+var objectLocks = newObjectLockPool()
+
+type objectLock struct {
+	sync.RWMutex
+	count int
+}
+
+// objectLockPool is a pool of locks per oid, a lock is removed from the pool once it is released by all its holders
+type objectLockPool struct {
+	mu    sync.Mutex
+	locks map[string]*objectLock
+}
+
+func newObjectLockPool() *objectLockPool {
+	return &objectLockPool{locks: make(map[string]*objectLock)}
+}
+
+func (p *objectLockPool) acquire(oid string) *objectLock {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	l, ok := p.locks[oid]
+	if !ok {
+		l = &objectLock{}
+		p.locks[oid] = l
+	}
+	l.count++
+	return l
+}
+
+func (p *objectLockPool) release(oid string) *objectLock {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	l := p.locks[oid]
+	l.count--
+	if l.count == 0 {
+		delete(p.locks, oid)
+	}
+	return l
+}
+
+// RLock shares the lock of the object, e.g. while it is downloaded
+func (p *objectLockPool) RLock(oid string) {
+	p.acquire(oid).RLock()
+}
+
+// RUnlock releases a shared lock of the object
+func (p *objectLockPool) RUnlock(oid string) {
+	p.release(oid).RUnlock()
+}
+
+// Lock holds the lock of the object exclusively
+func (p *objectLockPool) Lock(oid string) {
+	p.acquire(oid).Lock()
+}
+
+// TryLock holds the lock of the object exclusively if it isn't held, it returns false otherwise
+func (p *objectLockPool) TryLock(oid string) bool {
+	if p.acquire(oid).TryLock() {
+		return true
+	}
+	p.release(oid)
+	return false
+}
+
+// Unlock releases the exclusive lock of the object
+func (p *objectLockPool) Unlock(oid string) {
+	p.release(oid).Unlock()
+}
+
+// Init starts the queue restoring the archived objects requested by the clients
+func Init() error {
+	if !setting.LFS.StartServer {
+		return nil
+	}
+
+	restoreQueue = queue.CreateSimpleQueue(graceful.GetManager().ShutdownContext(), "lfs_restore", restoreHandler)
+	if restoreQueue == nil {
+		return errors.New("unable to create lfs_restore queue")
+	}
+	go graceful.GetManager().RunWithCancel(restoreQueue)
+	return nil
+}
+
+func restoreHandler(oids ...string) []string {
+	ctx := graceful.GetManager().ShutdownContext()
+	for _, oid := range oids {
+		if err := restoreObject(ctx, oid); err != nil {
+			// the restore is attempted again by the next request of the object after restoreRetryInterval
+			log.Error("Unable to restore LFS OID[%s] from the archive storage: %v", oid, err)
+		}
+	}
+	return nil
+}
+
+// restoreObject copies an archived object back to the LFS storage
+func restoreObject(ctx context.Context, oid string) error {
+	archived, err := git_model.GetLFSArchivedObject(ctx, oid)
+	if errors.Is(err, git_model.ErrLFSArchivedObjectNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	p := archived.Pointer()
+
+	if restorer, ok := storage.As[storage.Restorer](storage.LFSArchive); ok {
+		ready, err := restorer.Restore(p.RelativePath(), restoreDays)
+		if err != nil {
+			return fmt.Errorf("restore from the cold storage class: %w", err)
+		} else if !ready {
+			log.Debug("LFS OID[%s] is being restored from the cold storage class", oid)
+			return nil
+		}
+	}
+
+	objectLocks.Lock(oid)
+	defer objectLocks.Unlock(oid)
+
+	f, err := storage.LFSArchive.Open(p.RelativePath())
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	// the content store checks the hash and the size of the restored content
+	if err := lfs_module.NewContentStore().Put(p, f); err != nil {
+		return err
+	}
+
+	if err := git_model.DeleteLFSArchivedObject(ctx, oid); err != nil {
+		return err
+	}
+	if err := storage.LFSArchive.Delete(p.RelativePath()); err != nil {
+		log.Error("Unable to delete the restored LFS OID[%s] from the archive storage: %v", oid, err)
+	}
+	log.Trace("LFS OID[%s] restored from the archive storage", oid)
+	return nil
+}
+
+// requestRestore starts the restore of an archived object requested by a client, unless it is already in progress
+func requestRestore(ctx context.Context, archived *git_model.LFSArchivedObject) {
+	start, err := git_model.MarkLFSArchivedObjectRestoring(ctx, archived.Oid, restoreRetryInterval)
+	if err != nil {
+		log.Error("Unable to mark LFS OID[%s] as being restored: %v", archived.Oid, err)
+		return
+	}
+	if !start || restoreQueue == nil {
+		return
+	}
+	if err := restoreQueue.Push(archived.Oid); err != nil {
+		log.Error("Unable to push LFS OID[%s] to the restore queue: %v", archived.Oid, err)
+	}
+}
+
+// getArchivedObjects returns the archived objects among the pointers, keyed by their oid
+func getArchivedObjects(ctx context.Context, pointers []lfs_module.Pointer) (map[string]*git_model.LFSArchivedObject, error) {
+	oids := make([]string, 0, len(pointers))
+	for _, p := range pointers {
+		oids = append(oids, p.Oid)
+	}
+	return git_model.GetLFSArchivedObjectsByOids(ctx, oids)
+}
+
+// markAccessed records the access to the objects of the repository, the objects which are not accessed for a while
+// are moved to the archive storage
+func markAccessed(ctx context.Context, repoID int64, oids ...string) {
+	if err := git_model.MarkLFSMetaObjectsAccessed(ctx, repoID, oids...); err != nil {
+		log.Error("Unable to record the access to %d LFS objects of repository[%d]: %v", len(oids), repoID, err)
+	}
+}
+
+// ArchiveUnusedObjects moves the objects which have not been accessed since olderThan to the archive storage
+func ArchiveUnusedObjects(ctx context.Context, olderThan time.Duration) error {
+	before := timeutil.TimeStamp(time.Now().Add(-olderThan).Unix())
+	contentStore := lfs_module.NewContentStore()
+
+	archived, missing, inUse, failed := 0, 0, 0, 0
+	afterOid := ""
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		pointers, err := git_model.FindLFSObjectsToArchive(ctx, before, afterOid, archiveBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(pointers) == 0 {
+			break
+		}
+
+		for _, p := range pointers {
+			if err := archiveObject(ctx, contentStore, p); errors.Is(err, errObjectNotInStorage) {
+				log.Warn("LFS OID[%s] does not exist in the LFS storage, it can't be archived", p.Oid)
+				missing++
+				continue
+			} else if errors.Is(err, errObjectInUse) {
+				log.Debug("LFS OID[%s] is being downloaded, it is not archived", p.Oid)
+				inUse++
+				continue
+			} else if err != nil {
+				log.Error("Unable to archive LFS OID[%s]: %v", p.Oid, err)
+				failed++
+				continue
+			}
+			archived++
+		}
+		afterOid = pointers[len(pointers)-1].Oid
+	}
+
+	log.Info("Archived %d LFS objects not accessed since %s, %d missing from the LFS storage, %d being downloaded, %d failed", archived, before.FormatLong(), missing, inUse, failed)
+	return nil
+}
+
+// errObjectNotInStorage is returned when the object to archive is missing from the LFS storage
+var errObjectNotInStorage = errors.New("object does not exist in the LFS storage")
+
+// errObjectInUse is returned when the object to archive is being downloaded
+var errObjectInUse = errors.New("object is being downloaded")
+
+// archiveObject moves an object from the LFS storage to the archive storage
+func archiveObject(ctx context.Context, contentStore *lfs_module.ContentStore, p lfs_module.Pointer) error {
+	exists, err := contentStore.Exists(p)
+	if err != nil {
+		return err
+	} else if !exists {
+		return errObjectNotInStorage
+	}
+
+	f, err := contentStore.Get(p)
+	if err != nil {
+		return err
+	}
+	written, err := storage.LFSArchive.Save(p.RelativePath(), f, p.Size)
+	f.Close()
+	if err != nil {
+		return err
+	} else if written != p.Size {
+		return fmt.Errorf("%d bytes archived instead of %d", written, p.Size)
+	}
+
+	// the object isn't deleted while it is downloaded, it is accessed anyway so it isn't archived yet
+	if !objectLocks.TryLock(p.Oid) {
+		if err := storage.LFSArchive.Delete(p.RelativePath()); err != nil {
+			log.Error("Unable to delete the archive copy of LFS OID[%s]: %v", p.Oid, err)
+		}
+		return errObjectInUse
+	}
+	defer objectLocks.Unlock(p.Oid)
+
+	// the object is recorded as archived before its deletion so it is always found in one of the storages
+	if err := git_model.NewLFSArchivedObject(ctx, p); err != nil {
+		return err
+	}
+	return contentStore.Delete(p.RelativePath())
+}
+
+// DeleteArchivedObject deletes an archived object whose last LFSMetaObject has been removed
+func DeleteArchivedObject(ctx context.Context, p lfs_module.Pointer) error {
+	if _, err := git_model.GetLFSArchivedObject(ctx, p.Oid); errors.Is(err, git_model.ErrLFSArchivedObjectNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if err := storage.LFSArchive.Delete(p.RelativePath()); err != nil {
+		return err
+	}
+	return git_model.DeleteLFSArchivedObject(ctx, p.Oid)
+}
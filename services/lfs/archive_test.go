@@ -0,0 +1,58 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package lfs
+
+import (
+	"bytes"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	git_model "code.gitea.io/gitea/models/git"
+	"code.gitea.io/gitea/models/unittest"
+	lfs_module "code.gitea.io/gitea/modules/lfs"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
+	"code.gitea.io/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArchiveObjectBeingDownloaded(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	lfsStorage, err := storage.NewLocalStorage(db.DefaultContext, &setting.Storage{Path: t.TempDir()})
+	assert.NoError(t, err)
+	defer test.MockVariableValue(&storage.LFS, lfsStorage)()
+	archiveStorage, err := storage.NewLocalStorage(db.DefaultContext, &setting.Storage{Path: t.TempDir()})
+	assert.NoError(t, err)
+	defer test.MockVariableValue(&storage.LFSArchive, archiveStorage)()
+
+	content := []byte("archived while downloaded")
+	p, err := lfs_module.GeneratePointer(bytes.NewReader(content))
+	assert.NoError(t, err)
+	contentStore := lfs_module.NewContentStore()
+	assert.NoError(t, contentStore.Put(p, bytes.NewReader(content)))
+	t.Cleanup(func() {
+		assert.NoError(t, git_model.DeleteLFSArchivedObject(db.DefaultContext, p.Oid))
+	})
+
+	// the object is kept in the LFS storage while it is downloaded
+	objectLocks.RLock(p.Oid)
+	assert.ErrorIs(t, archiveObject(db.DefaultContext, contentStore, p), errObjectInUse)
+	objectLocks.RUnlock(p.Oid)
+	exists, err := contentStore.Exists(p)
+	assert.NoError(t, err)
+	assert.True(t, exists)
+	_, err = storage.LFSArchive.Stat(p.RelativePath())
+	assert.Error(t, err)
+	_, err = git_model.GetLFSArchivedObject(db.DefaultContext, p.Oid)
+	assert.ErrorIs(t, err, git_model.ErrLFSArchivedObjectNotExist)
+
+	assert.NoError(t, archiveObject(db.DefaultContext, contentStore, p))
+	exists, err = contentStore.Exists(p)
+	assert.NoError(t, err)
+	assert.False(t, exists)
+	_, err = git_model.GetLFSArchivedObject(db.DefaultContext, p.Oid)
+	assert.NoError(t, err)
+	assert.Empty(t, objectLocks.locks)
+}
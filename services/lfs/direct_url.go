@@ -0,0 +1,313 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package lfs
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	git_model "code.gitea.io/gitea/models/git"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/cache"
+	gitea_context "code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/json"
+	lfs_module "code.gitea.io/gitea/modules/lfs"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/gobwas/glob"
+)
+
+const (
+	// directURLConcurrency bounds the number of objects whose existence is checked at once in the storage
+	directURLConcurrency = 16
+	// pointerMaxSize is the maximum size of the blobs read as LFS pointers, the pointer files are about 130 bytes
+	pointerMaxSize = 1024
+)
+
+// directURLOptions are the options of the listing of the direct urls of the LFS objects of a repository
+type directURLOptions struct {
+	// Ref is the branch, tag or commit whose tree the objects are resolved from, all the objects of the repository
+	// are listed if both Ref and Path are empty
+	Ref string
+	// Path is a glob filtering the paths of the pointer files in the tree, "*" doesn't match "/" but "**" does
+	Path string
+	// Paginated is true if a page or a limit is requested, otherwise all the objects are listed and those without
+	// url are skipped, as the listing did before it was paginated
+	Paginated bool
+	Page      int
+	PageSize  int
+	// Expiry is the validity of the signed urls, the default validity of the storage is used if zero
+	Expiry time.Duration
+}
+
+// parseDirectURLOptions parses the options of the listing from the query of the request
+func parseDirectURLOptions(ctx *gitea_context.Context) (*directURLOptions, error) {
+	opts := &directURLOptions{
+		Ref:       ctx.FormTrim("ref"),
+		Path:      ctx.FormTrim("path"),
+		Paginated: ctx.FormString("page") != "" || ctx.FormString("limit") != "",
+		Page:      ctx.FormInt("page"),
+		PageSize:  ctx.FormInt("limit"),
+	}
+	if opts.Page <= 0 {
+		opts.Page = 1
+	}
+	if opts.PageSize <= 0 {
+		opts.PageSize = setting.API.DefaultPagingNum
+	} else if opts.PageSize > setting.API.MaxResponseItems {
+		opts.PageSize = setting.API.MaxResponseItems
+	}
+	if expiresIn := ctx.FormString("expires_in"); expiresIn != "" {
+		seconds, err := strconv.ParseInt(expiresIn, 10, 64)
+		if err != nil || seconds <= 0 || time.Duration(seconds)*time.Second > storage.MaxSignedURLExpiry {
+			return nil, util.NewInvalidArgumentErrorf("expires_in must be between 1 and %d seconds", int64(storage.MaxSignedURLExpiry.Seconds()))
+		}
+		opts.Expiry = time.Duration(seconds) * time.Second
+	}
+	return opts, nil
+}
+
+// directURLObject is a LFS object of the listing, with the path of its pointer file if resolved from a tree
+type directURLObject struct {
+	Path string `json:"path"`
+	lfs_module.Pointer
+}
+
+// listDirectURLObjects returns a page of the LFS objects of the repository, or all of them if the listing isn't
+// paginated, the total number of objects, and the commit they are resolved from if any
+func listDirectURLObjects(ctx context.Context, repo *repo_model.Repository, opts *directURLOptions) ([]*directURLObject, int64, string, error) {
+	if opts.Ref == "" && opts.Path == "" {
+		page, pageSize := opts.Page, opts.PageSize
+		if !opts.Paginated {
+			page, pageSize = 0, 0
+		}
+		metas, err := git_model.GetLFSMetaObjects(ctx, repo.ID, page, pageSize)
+		if err != nil {
+			return nil, 0, "", err
+		}
+		total := int64(len(metas))
+		if opts.Paginated {
+			if total, err = git_model.CountLFSMetaObjects(ctx, repo.ID); err != nil {
+				return nil, 0, "", err
+			}
+		}
+		objects := make([]*directURLObject, 0, len(metas))
+		for _, meta := range metas {
+			objects = append(objects, &directURLObject{Pointer: meta.Pointer})
+		}
+		return objects, total, "", nil
+	}
+
+	pathGlob, err := glob.Compile(opts.Path, '/')
+	if opts.Path == "" {
+		pathGlob, err = glob.Compile("**")
+	}
+	if err != nil {
+		return nil, 0, "", util.NewInvalidArgumentErrorf("invalid path glob %q: %v", opts.Path, err)
+	}
+	ref := opts.Ref
+	if ref == "" {
+		ref = repo.DefaultBranch
+	}
+
+	gitRepo, closer, err := git.RepositoryFromContextOrOpen(ctx, repo.RepoPath())
+	if err != nil {
+		return nil, 0, "", err
+	}
+	defer closer.Close()
+
+	commit, err := gitRepo.GetCommit(ref)
+	if err != nil {
+		return nil, 0, "", err
+	}
+	pointers, err := getTreePointers(commit)
+	if err != nil {
+		return nil, 0, "", err
+	}
+	objects := make([]*directURLObject, 0, len(pointers))
+	for _, o := range pointers {
+		if pathGlob.Match(o.Path) {
+			objects = append(objects, o)
+		}
+	}
+
+	total := int64(len(objects))
+	if !opts.Paginated {
+		return objects, total, commit.ID.String(), nil
+	}
+	start := (opts.Page - 1) * opts.PageSize
+	if start >= len(objects) {
+		return []*directURLObject{}, total, commit.ID.String(), nil
+	}
+	end := start + opts.PageSize
+	if end > len(objects) {
+		end = len(objects)
+	}
+	return objects[start:end], total, commit.ID.String(), nil
+}
+
+// getTreePointers returns the LFS pointers of the tree of the commit. The pointer files have to be read to know
+// which entries are LFS objects, the trees are immutable so the pointers are cached by commit instead of being read
+// again by every page of the listing.
+func getTreePointers(commit *git.Commit) ([]*directURLObject, error) {
+	key := "lfs_tree_pointers:" + commit.ID.String()
+	c := cache.GetCache()
+	if c != nil && setting.CacheService.TTL > 0 {
+		if data, ok := c.Get(key).(string); ok {
+			var pointers []*directURLObject
+			if err := json.Unmarshal([]byte(data), &pointers); err == nil {
+				return pointers, nil
+			}
+		}
+	}
+
+	entries, err := commit.Tree.ListEntriesRecursiveWithSize()
+	if err != nil {
+		return nil, err
+	}
+	pointers := make([]*directURLObject, 0, 10)
+	for _, entry := range entries {
+		if !(entry.IsRegular() || entry.IsExecutable()) || entry.Size() > pointerMaxSize {
+			continue
+		}
+		p, err := readPointer(entry)
+		if err != nil {
+			return nil, err
+		}
+		if p.IsValid() {
+			pointers = append(pointers, &directURLObject{Path: entry.Name(), Pointer: p})
+		}
+	}
+
+	if c != nil && setting.CacheService.TTL > 0 {
+		data, err := json.Marshal(pointers)
+		if err == nil {
+			err = c.Put(key, string(data), setting.CacheService.TTLSeconds())
+		}
+		if err != nil {
+			log.Warn("Unable to cache the LFS pointers of commit %s: %v", commit.ID, err)
+		}
+	}
+	return pointers, nil
+}
+
+// readPointer reads the LFS pointer of a tree entry, the pointer is invalid if the entry isn't a pointer file
+func readPointer(entry *git.TreeEntry) (lfs_module.Pointer, error) {
+	rd, err := entry.Blob().DataAsync()
+	if err != nil {
+		return lfs_module.Pointer{}, err
+	}
+	defer rd.Close()
+	p, _ := lfs_module.ReadPointer(rd)
+	return p, nil
+}
+
+// resolveDirectURLs returns the direct urls of the objects, the existence of the objects in the storage is checked
+// and the urls are signed in parallel. The objects which aren't associated with the repository or aren't in the
+// storage are returned with the missing status, the archived ones with the pending status while they are restored.
+func resolveDirectURLs(ctx context.Context, repoID int64, objects []*directURLObject, expiry time.Duration) ([]*lfs_module.ObjectDirectUrl, error) {
+	oids := make([]string, 0, len(objects))
+	pointers := make([]lfs_module.Pointer, 0, len(objects))
+	for _, o := range objects {
+		oids = append(oids, o.Oid)
+		pointers = append(pointers, o.Pointer)
+	}
+	metas, err := git_model.GetLFSMetaObjectsByOids(ctx, repoID, oids)
+	if err != nil {
+		return nil, err
+	}
+	associated := make(map[string]bool, len(metas))
+	for _, meta := range metas {
+		associated[meta.Oid] = true
+	}
+	archivedObjects, err := getArchivedObjects(ctx, pointers)
+	if err != nil {
+		return nil, err
+	}
+
+	contentStore := lfs_module.NewContentStore()
+	urls := make([]*lfs_module.ObjectDirectUrl, len(objects))
+	errs := make([]error, len(objects))
+	sem := make(chan struct{}, directURLConcurrency)
+	var wg sync.WaitGroup
+	for i, o := range objects {
+		urls[i] = &lfs_module.ObjectDirectUrl{Pointer: o.Pointer, Path: o.Path}
+		if !associated[o.Oid] {
+			urls[i].Status = lfs_module.ObjectStatusMissing
+			continue
+		}
+		if archived := archivedObjects[o.Oid]; archived != nil {
+			requestRestore(ctx, archived)
+			urls[i].Status = lfs_module.ObjectStatusPending
+			continue
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(u *lfs_module.ObjectDirectUrl, errp *error) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			*errp = signDirectURL(contentStore, u, expiry)
+		}(urls[i], &errs[i])
+	}
+	wg.Wait()
+
+	accessed := make([]string, 0, len(objects))
+	for i, u := range urls {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		if u.URL != "" {
+			accessed = append(accessed, u.Oid)
+		}
+	}
+	markAccessed(ctx, repoID, accessed...)
+	return urls, nil
+}
+
+// signDirectURL fills the signed url of an object if it exists in the storage
+func signDirectURL(contentStore *lfs_module.ContentStore, u *lfs_module.ObjectDirectUrl, expiry time.Duration) error {
+	exists, err := contentStore.Exists(u.Pointer)
+	if err != nil {
+		return fmt.Errorf("unable to check whether LFS OID[%s] exists: %w", u.Oid, err)
+	} else if !exists {
+		log.Warn("LFS OID[%s] does not exist in the LFS storage", u.Oid)
+		u.Status = lfs_module.ObjectStatusMissing
+		return nil
+	}
+
+	signed, err := storage.SignedURL(storage.LFS, u.RelativePath(), u.Oid, expiry)
+	if err != nil {
+		log.Error("Unable to generate LFS OID[%s] direct url. Error: %v, object will be skipped", u.Oid, err)
+		u.Status = lfs_module.ObjectStatusMissing
+		return nil
+	}
+	u.URL = signed.String()
+	if expiry > 0 {
+		expiresAt := time.Now().Add(expiry)
+		u.ExpiresAt = &expiresAt
+	}
+	return nil
+}
+
+// availableDirectURLs returns the urls of the objects which can be downloaded or are being restored, the listing
+// skipped the missing objects before it was paginated
+func availableDirectURLs(urls []*lfs_module.ObjectDirectUrl) []*lfs_module.ObjectDirectUrl {
+	available := make([]*lfs_module.ObjectDirectUrl, 0, len(urls))
+	for _, u := range urls {
+		if u.Status != lfs_module.ObjectStatusMissing {
+			available = append(available, u)
+		}
+	}
+	return available
+}
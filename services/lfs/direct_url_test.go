@@ -0,0 +1,264 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package lfs
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	git_model "code.gitea.io/gitea/models/git"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/contexttest"
+	"code.gitea.io/gitea/modules/git"
+	lfs_module "code.gitea.io/gitea/modules/lfs"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
+	"code.gitea.io/gitea/modules/test"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	jpegOid         = "0b8d8b5f15046343fd32f451df93acc2bdd9e6373be478b968e4cad6b6647351"
+	cryptOid        = "2eccdb43825d2a49d99d542daa20075cff1d97d9d2349a8977efe9c03661737c"
+	contributingOid = "7b6b2c88dba9f760a1a58469b67fee2b698ef7e9399c4ca4f34a14ccbe39f623"
+	subdirReadmeOid = "9d172e5c64b4f0024b9901ec6afe9ea052f3c9b6ff9f4b07956d8c48c86fca82"
+)
+
+func TestParseDirectURLOptions(t *testing.T) {
+	defer test.MockVariableValue(&setting.API.DefaultPagingNum, 30)()
+	defer test.MockVariableValue(&setting.API.MaxResponseItems, 50)()
+
+	for _, tc := range []struct {
+		query    string
+		expected *directURLOptions
+		err      bool
+	}{
+		{query: "", expected: &directURLOptions{Page: 1, PageSize: 30}},
+		{query: "ref=main&path=*.bin", expected: &directURLOptions{Ref: "main", Path: "*.bin", Page: 1, PageSize: 30}},
+		{query: "page=2", expected: &directURLOptions{Paginated: true, Page: 2, PageSize: 30}},
+		{query: "limit=10", expected: &directURLOptions{Paginated: true, Page: 1, PageSize: 10}},
+		{query: "page=0&limit=100", expected: &directURLOptions{Paginated: true, Page: 1, PageSize: 50}},
+		{query: "expires_in=60", expected: &directURLOptions{Page: 1, PageSize: 30, Expiry: time.Minute}},
+		{query: "expires_in=0", err: true},
+		{query: "expires_in=abc", err: true},
+		{query: "expires_in=604801", err: true},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			ctx, _ := contexttest.MockContext(t, "user2/lfs.git/info/lfs/direct_urls?"+tc.query)
+			ctx.Req.Form, _ = url.ParseQuery(tc.query)
+			opts, err := parseDirectURLOptions(ctx)
+			if tc.err {
+				assert.ErrorIs(t, err, util.ErrInvalidArgument)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, opts)
+		})
+	}
+}
+
+func TestListDirectURLObjects(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 54})
+
+	for _, tc := range []struct {
+		name   string
+		opts   *directURLOptions
+		oids   []string
+		paths  []string
+		total  int64
+		commit bool
+		err    func(error) bool
+	}{
+		{
+			name:  "AllObjects",
+			opts:  &directURLOptions{Page: 1, PageSize: 2},
+			oids:  []string{jpegOid, cryptOid, contributingOid, subdirReadmeOid},
+			paths: []string{"", "", "", ""},
+			total: 4,
+		},
+		{
+			name:  "AllObjectsPage",
+			opts:  &directURLOptions{Paginated: true, Page: 2, PageSize: 3},
+			oids:  []string{subdirReadmeOid},
+			paths: []string{""},
+			total: 4,
+		},
+		{
+			name:   "DefaultBranch",
+			opts:   &directURLOptions{Path: "**", Page: 1, PageSize: 2},
+			oids:   []string{contributingOid, cryptOid, jpegOid, subdirReadmeOid},
+			paths:  []string{"CONTRIBUTING.md", "crypt.bin", "jpeg.jpg", "subdir/README.md"},
+			total:  4,
+			commit: true,
+		},
+		{
+			name:   "RefPage",
+			opts:   &directURLOptions{Ref: "master", Paginated: true, Page: 2, PageSize: 2},
+			oids:   []string{jpegOid, subdirReadmeOid},
+			paths:  []string{"jpeg.jpg", "subdir/README.md"},
+			total:  4,
+			commit: true,
+		},
+		{
+			name:   "RefPageOutOfRange",
+			opts:   &directURLOptions{Ref: "master", Paginated: true, Page: 3, PageSize: 2},
+			oids:   []string{},
+			paths:  []string{},
+			total:  4,
+			commit: true,
+		},
+		{
+			name:   "StarDoesNotMatchSlash",
+			opts:   &directURLOptions{Path: "*.md", Page: 1, PageSize: 30},
+			oids:   []string{contributingOid},
+			paths:  []string{"CONTRIBUTING.md"},
+			total:  1,
+			commit: true,
+		},
+		{
+			name:   "DoubleStarMatchesSlash",
+			opts:   &directURLOptions{Path: "**.md", Page: 1, PageSize: 30},
+			oids:   []string{contributingOid, subdirReadmeOid},
+			paths:  []string{"CONTRIBUTING.md", "subdir/README.md"},
+			total:  2,
+			commit: true,
+		},
+		{
+			name:   "Directory",
+			opts:   &directURLOptions{Ref: "master", Path: "subdir/*", Page: 1, PageSize: 30},
+			oids:   []string{subdirReadmeOid},
+			paths:  []string{"subdir/README.md"},
+			total:  1,
+			commit: true,
+		},
+		{
+			name:   "NoMatch",
+			opts:   &directURLOptions{Path: "*.txt", Page: 1, PageSize: 30},
+			oids:   []string{},
+			paths:  []string{},
+			commit: true,
+		},
+		{
+			name: "UnknownRef",
+			opts: &directURLOptions{Ref: "no-such-branch", Page: 1, PageSize: 30},
+			err:  git.IsErrNotExist,
+		},
+		{
+			name: "InvalidGlob",
+			opts: &directURLOptions{Path: "[", Page: 1, PageSize: 30},
+			err:  func(err error) bool { return strings.Contains(err.Error(), "invalid path glob") },
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			objects, total, commitID, err := listDirectURLObjects(git.DefaultContext, repo, tc.opts)
+			if tc.err != nil {
+				assert.True(t, tc.err(err), "unexpected error: %v", err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.total, total)
+			assert.Equal(t, tc.commit, commitID != "")
+			oids := make([]string, 0, len(objects))
+			paths := make([]string, 0, len(objects))
+			for _, o := range objects {
+				oids = append(oids, o.Oid)
+				paths = append(paths, o.Path)
+			}
+			assert.Equal(t, tc.oids, oids)
+			assert.Equal(t, tc.paths, paths)
+		})
+	}
+}
+
+// urlStorage serves the urls of the objects of the wrapped storage
+type urlStorage struct {
+	storage.ObjectStorage
+}
+
+func (s urlStorage) URL(path, name string) (*url.URL, error) {
+	return url.Parse("https://cdn.example.com/" + path + "?name=" + name)
+}
+
+func TestResolveDirectURLs(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	local, err := storage.NewLocalStorage(git.DefaultContext, &setting.Storage{Path: t.TempDir()})
+	assert.NoError(t, err)
+	defer test.MockVariableValue(&storage.LFS, storage.ObjectStorage(urlStorage{local}))()
+
+	stored := lfs_module.Pointer{Oid: jpegOid, Size: 107}
+	_, err = storage.LFS.Save(stored.RelativePath(), strings.NewReader(strings.Repeat("a", 107)), stored.Size)
+	assert.NoError(t, err)
+	archived := lfs_module.Pointer{Oid: cryptOid, Size: 107}
+	assert.NoError(t, git_model.NewLFSArchivedObject(git.DefaultContext, archived))
+	notInStorage := lfs_module.Pointer{Oid: contributingOid, Size: 27}
+	notAssociated := lfs_module.Pointer{Oid: strings.Repeat("1", 64), Size: 10}
+
+	objects := []*directURLObject{
+		{Path: "jpeg.jpg", Pointer: stored},
+		{Path: "crypt.bin", Pointer: archived},
+		{Path: "CONTRIBUTING.md", Pointer: notInStorage},
+		{Path: "other.bin", Pointer: notAssociated},
+	}
+
+	for _, tc := range []struct {
+		name      string
+		expiry    time.Duration
+		expected  []*lfs_module.ObjectDirectUrl
+		available []string
+	}{
+		{
+			name: "DefaultExpiry",
+			expected: []*lfs_module.ObjectDirectUrl{
+				{Pointer: stored, Path: "jpeg.jpg", URL: "https://cdn.example.com/" + stored.RelativePath() + "?name=" + stored.Oid},
+				{Pointer: archived, Path: "crypt.bin", Status: lfs_module.ObjectStatusPending},
+				{Pointer: notInStorage, Path: "CONTRIBUTING.md", Status: lfs_module.ObjectStatusMissing},
+				{Pointer: notAssociated, Path: "other.bin", Status: lfs_module.ObjectStatusMissing},
+			},
+			available: []string{stored.Oid, archived.Oid},
+		},
+		{
+			name:   "Expiry",
+			expiry: time.Hour,
+			expected: []*lfs_module.ObjectDirectUrl{
+				{Pointer: stored, Path: "jpeg.jpg", URL: "https://cdn.example.com/" + stored.RelativePath() + "?name=" + stored.Oid},
+				{Pointer: archived, Path: "crypt.bin", Status: lfs_module.ObjectStatusPending},
+				{Pointer: notInStorage, Path: "CONTRIBUTING.md", Status: lfs_module.ObjectStatusMissing},
+				{Pointer: notAssociated, Path: "other.bin", Status: lfs_module.ObjectStatusMissing},
+			},
+			available: []string{stored.Oid, archived.Oid},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			urls, err := resolveDirectURLs(git.DefaultContext, 54, objects, tc.expiry)
+			assert.NoError(t, err)
+			for _, u := range urls {
+				if u.URL != "" && tc.expiry > 0 {
+					if assert.NotNil(t, u.ExpiresAt) {
+						assert.WithinDuration(t, time.Now().Add(tc.expiry), *u.ExpiresAt, time.Minute)
+					}
+					u.ExpiresAt = nil
+				}
+			}
+			assert.Equal(t, tc.expected, urls)
+
+			available := make([]string, 0, len(urls))
+			for _, u := range availableDirectURLs(urls) {
+				available = append(available, u.Oid)
+			}
+			assert.Equal(t, tc.available, available)
+		})
+	}
+
+	meta := unittest.AssertExistsAndLoadBean(t, &git_model.LFSMetaObject{RepositoryID: 54, Pointer: lfs_module.Pointer{Oid: stored.Oid}})
+	assert.NotZero(t, meta.AccessedUnix)
+	o, err := git_model.GetLFSArchivedObject(git.DefaultContext, archived.Oid)
+	assert.NoError(t, err)
+	assert.True(t, o.IsPending())
+}
@@ -0,0 +1,56 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package lfs
+
+import (
+	stdCtx "context"
+	"io"
+
+	"code.gitea.io/gitea/modules/context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const meterName = "gitea_lfs_meter"
+
+var transferredBytes, _ = otel.Meter(meterName).Int64Counter("gitea.lfs.transferred",
+	metric.WithDescription("Bytes of LFS objects transferred through the server"),
+	metric.WithUnit("By"),
+)
+
+func recordTransferred(ctx stdCtx.Context, direction string, n int64) {
+	if n > 0 {
+		transferredBytes.Add(ctx, n, metric.WithAttributes(attribute.String("direction", direction)))
+	}
+}
+
+var verifiedObjects, _ = otel.Meter(meterName).Int64Counter("gitea.lfs.verified",
+	metric.WithDescription("LFS objects assembled from multipart uploads and verified by the server"),
+)
+
+func recordVerified(ctx stdCtx.Context, outcome string) {
+	verifiedObjects.Add(ctx, 1, metric.WithAttributes(attribute.String("outcome", outcome)))
+}
+
+type countingBody struct {
+	io.ReadCloser
+	n int64
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+	return n, err
+}
+
+// countUpload counts the bytes read from the request body, the returned function records them as uploaded
+func countUpload(ctx *context.Context) func() {
+	body := &countingBody{ReadCloser: ctx.Req.Body}
+	ctx.Req.Body = body
+	return func() {
+		recordTransferred(ctx, "upload", body.n)
+	}
+}
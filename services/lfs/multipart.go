@@ -0,0 +1,87 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package lfs
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	git_model "code.gitea.io/gitea/models/git"
+	lfs_module "code.gitea.io/gitea/modules/lfs"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/storage"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// AbortStaleMultipartUploads aborts the multipart uploads which haven't progressed since olderThan.
+// The uploads served by Gitea are found through their recorded parts, the uploads sent directly to the storage
+// are listed from the storage if it supports it, their progress is given by their newest part.
+func AbortStaleMultipartUploads(ctx context.Context, olderThan time.Duration) error {
+	before := timeutil.TimeStamp(time.Now().Add(-olderThan).Unix())
+	contentStore := lfs_module.NewContentStore()
+
+	aborted, failed := 0, 0
+	uploads, err := git_model.FindStaleLFSMultipartUploads(ctx, before)
+	if err != nil {
+		return err
+	}
+	for _, upload := range uploads {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		p := lfs_module.Pointer{Oid: upload.Oid}
+		if err := contentStore.AbortUpload(p, upload.UploadID); err != nil && !errors.Is(err, storage.ErrMultipartNotSupported) {
+			log.Error("Unable to abort the multipart upload %s of LFS OID[%s]: %v", upload.UploadID, upload.Oid, err)
+			failed++
+			continue
+		}
+		if err := git_model.DeleteLFSMultipartParts(ctx, upload.UploadID); err != nil {
+			return err
+		}
+		aborted++
+	}
+
+	if lister, ok := storage.As[storage.MultipartLister](contentStore.ObjectStorage); ok {
+		listedAborted, listedFailed, err := abortStaleListedUploads(ctx, lister, before)
+		aborted += listedAborted
+		failed += listedFailed
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Info("Aborted %d LFS multipart uploads not updated since %s, %d failed", aborted, before.FormatLong(), failed)
+	return nil
+}
+
+// abortStaleListedUploads aborts the multipart uploads listed by the storage whose newest part, or their initiation if
+// they have no part, is older than before
+func abortStaleListedUploads(ctx context.Context, lister storage.MultipartLister, before timeutil.TimeStamp) (aborted, failed int, err error) {
+	err = lister.IterateMultipartUploads(func(upload *storage.MultipartUpload) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if upload.Initiated.Unix() >= int64(before) {
+			return nil
+		}
+		// a long upload started before the cutoff may still be uploading its parts
+		modified, err := lister.LastPartModified(upload.Path, upload.UploadID)
+		if err != nil {
+			log.Error("Unable to list the parts of the multipart upload %s of %s: %v", upload.UploadID, upload.Path, err)
+			failed++
+			return nil
+		} else if modified.Unix() >= int64(before) {
+			return nil
+		}
+		if err := lister.AbortUpload(upload.Path, upload.UploadID); err != nil {
+			log.Error("Unable to abort the multipart upload %s of %s: %v", upload.UploadID, upload.Path, err)
+			failed++
+			return nil
+		}
+		aborted++
+		return nil
+	})
+	return aborted, failed, err
+}
@@ -0,0 +1,69 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package lfs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/storage"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryMultipartLister struct {
+	uploads      []*storage.MultipartUpload
+	lastModified map[string]time.Time
+	aborted      []string
+}
+
+func (l *memoryMultipartLister) IterateMultipartUploads(fn func(upload *storage.MultipartUpload) error) error {
+	for _, upload := range l.uploads {
+		if err := fn(upload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *memoryMultipartLister) LastPartModified(path, uploadID string) (time.Time, error) {
+	if uploadID == "broken" {
+		return time.Time{}, errors.New("unable to list the parts")
+	}
+	return l.lastModified[uploadID], nil
+}
+
+func (l *memoryMultipartLister) AbortUpload(path, uploadID string) error {
+	l.aborted = append(l.aborted, uploadID)
+	return nil
+}
+
+func TestAbortStaleListedUploads(t *testing.T) {
+	now := time.Now()
+	old := now.Add(-48 * time.Hour)
+	before := timeutil.TimeStamp(now.Add(-24 * time.Hour).Unix())
+
+	lister := &memoryMultipartLister{
+		uploads: []*storage.MultipartUpload{
+			{Path: "a", UploadID: "recent", Initiated: now},
+			{Path: "b", UploadID: "no-part", Initiated: old},
+			{Path: "c", UploadID: "stale-part", Initiated: old},
+			{Path: "d", UploadID: "uploading", Initiated: old},
+			{Path: "e", UploadID: "broken", Initiated: old},
+		},
+		lastModified: map[string]time.Time{
+			"stale-part": old.Add(time.Hour),
+			"uploading":  now.Add(-time.Minute),
+		},
+	}
+
+	aborted, failed, err := abortStaleListedUploads(context.Background(), lister, before)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, aborted)
+	assert.Equal(t, 1, failed)
+	assert.Equal(t, []string{"no-part", "stale-part"}, lister.aborted)
+}
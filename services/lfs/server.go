@@ -12,6 +12,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"os"
 	"path"
 	"regexp"
 	"strconv"
@@ -28,11 +29,15 @@ import (
 	"code.gitea.io/gitea/models/unit"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/json"
 	lfs_module "code.gitea.io/gitea/modules/lfs"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/storage"
+	"code.gitea.io/gitea/modules/util"
+	notify_service "code.gitea.io/gitea/services/notify"
+	"code.gitea.io/gitea/services/repository/downloadstat"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/minio/sha256-simd"
@@ -73,6 +78,11 @@ func (rc *requestContext) MultipartVerifyLink(p lfs_module.Pointer) string {
 	return setting.AppURL + path.Join(url.PathEscape(rc.User), url.PathEscape(rc.Repo+".git"), fmt.Sprintf("info/lfs/multipart-verify?oid=%s&size=%s", url.PathEscape(p.Oid), strconv.FormatInt(p.Size, 10)))
 }
 
+// MultipartPartLink builds a URL for uploading a part of the object through Gitea in the case multipart.
+func (rc *requestContext) MultipartPartLink(p lfs_module.Pointer, uploadID string, index int) string {
+	return setting.AppURL + path.Join(url.PathEscape(rc.User), url.PathEscape(rc.Repo+".git"), "info/lfs/objects", url.PathEscape(p.Oid), strconv.FormatInt(p.Size, 10), "parts", url.PathEscape(uploadID), strconv.Itoa(index))
+}
+
 // CheckAcceptMediaType checks if the client accepts the LFS media type.
 func CheckAcceptMediaType(ctx *context.Context) {
 	mediaParts := strings.Split(ctx.Req.Header.Get("Accept"), ";")
@@ -96,6 +106,23 @@ func DownloadHandler(ctx *context.Context) {
 		return
 	}
 
+	// the object is neither archived nor restored while it is downloaded
+	objectLocks.RLock(meta.Oid)
+	defer objectLocks.RUnlock(meta.Oid)
+
+	archived, err := git_model.GetLFSArchivedObject(ctx, meta.Oid)
+	if err == nil {
+		requestRestore(ctx, archived)
+		ctx.Resp.Header().Set("Retry-After", strconv.Itoa(int(restoreRetryInterval.Seconds())))
+		writeStatusMessage(ctx, pendingError.Code, pendingError.Message)
+		return
+	} else if !errors.Is(err, git_model.ErrLFSArchivedObjectNotExist) {
+		log.Error("Unable to check whether LFS OID[%s] is archived. Error: %v", meta.Oid, err)
+		writeStatus(ctx, http.StatusInternalServerError)
+		return
+	}
+	markAccessed(ctx, meta.RepositoryID, meta.Oid)
+
 	// Support resume download using Range header
 	var fromByte, toByte int64
 	toByte = meta.Size - 1
@@ -140,6 +167,11 @@ func DownloadHandler(ctx *context.Context) {
 		}
 	}
 
+	// the resumed downloads have already been counted
+	if fromByte == 0 {
+		downloadstat.Record(ctx.Req, meta.RepositoryID, repo_model.DownloadKindLFS)
+	}
+
 	contentLength := toByte + 1 - fromByte
 	ctx.Resp.Header().Set("Content-Length", strconv.FormatInt(contentLength, 10))
 	ctx.Resp.Header().Set("Content-Type", "application/octet-stream")
@@ -154,19 +186,22 @@ func DownloadHandler(ctx *context.Context) {
 	}
 
 	ctx.Resp.WriteHeader(statusCode)
-	if written, err := io.CopyN(ctx.Resp, content, contentLength); err != nil {
+	written, err := io.CopyN(ctx.Resp, content, contentLength)
+	if err != nil {
 		log.Error("Error whilst copying LFS OID[%s] to the response after %d bytes. Error: %v", meta.Oid, written, err)
 	}
+	recordTransferred(ctx, "download", written)
 }
 
-// GetAllLFSObjectDirectDownloadUrls get all lfs object download url of a single repository
+// GetAllLFSObjectDirectDownloadUrls lists the direct download urls of the lfs objects of a single repository
 // Steps
 // 1. Authenticate request
-// 2. Collect all lfs pointers in repository
-// 3. Check whether pointer exists
-// 4. translate oid into direct urls
+// 2. Collect the lfs pointers of the repository, or of the tree of a ref filtered by a path glob, a page of them
+// if page or limit is requested
+// 3. Check whether the pointers exist, the missing ones are skipped unless the listing is paginated
+// 4. translate oids into direct urls signed for the requested duration
 func GetAllLFSObjectDirectDownloadUrls(ctx *context.Context) {
-	if !setting.LFS.Storage.MinioConfig.ServeDirect {
+	if !setting.LFS.Storage.ServeDirect() {
 		log.Trace("lfs serve direct is disabled. request direct url is not allowed")
 		writeStatus(ctx, http.StatusForbidden)
 		return
@@ -177,36 +212,35 @@ func GetAllLFSObjectDirectDownloadUrls(ctx *context.Context) {
 		log.Trace("Unable to get auth repository")
 		return
 	}
-	contentStore := lfs_module.NewContentStore()
-	//NOTE: Pagination is not considered here.
-	metas, err := git_model.GetLFSMetaObjects(ctx, repository.ID, 0, 0)
+	opts, err := parseDirectURLOptions(ctx)
 	if err != nil {
-		log.Error("Unable to list repository's LFS MetaObjects for %s/%s. Error: %v", rc.User, rc.Repo, err)
-		writeStatus(ctx, http.StatusInternalServerError)
+		writeStatusMessage(ctx, http.StatusBadRequest, err.Error())
 		return
 	}
-	var urls []*lfs_module.ObjectDirectUrl
-	for _, meta := range metas {
-		exists, err := contentStore.Exists(meta.Pointer)
-		if err != nil {
-			log.Error("Unable to check whether LFS OID[%s] for %s/%s exist. Error: %v", meta.Pointer.Oid, rc.User, rc.Repo, err)
-			writeStatus(ctx, http.StatusInternalServerError)
-			return
-		} else if exists {
-			u, err := storage.LFS.URL(meta.Pointer.RelativePath(), meta.Pointer.Oid)
-			if err != nil {
-				log.Error("Unable to generate LFS OID[%s] direct url for %s/%s. Error: %v, object will be skipped", meta.Pointer.Oid, rc.User, rc.Repo, err)
-			} else {
-				urls = append(urls, &lfs_module.ObjectDirectUrl{
-					Pointer: meta.Pointer,
-					URL:     u.String(),
-				})
-			}
+	objects, total, commitID, err := listDirectURLObjects(ctx, repository, opts)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			writeStatusMessage(ctx, http.StatusNotFound, fmt.Sprintf("ref %q does not exist", opts.Ref))
+		} else if errors.Is(err, util.ErrInvalidArgument) {
+			writeStatusMessage(ctx, http.StatusBadRequest, err.Error())
 		} else {
-			log.Warn("LFS OID[%s] for %s/%s does not existed on backend storage, object will be skipped", meta.Pointer.Oid, rc.User, rc.Repo)
+			log.Error("Unable to list LFS objects for %s/%s. Error: %v", rc.User, rc.Repo, err)
+			writeStatus(ctx, http.StatusInternalServerError)
 		}
+		return
+	}
+	urls, err := resolveDirectURLs(ctx, repository.ID, objects, opts.Expiry)
+	if err != nil {
+		log.Error("Unable to resolve LFS direct urls for %s/%s. Error: %v", rc.User, rc.Repo, err)
+		writeStatus(ctx, http.StatusInternalServerError)
+		return
+	}
+	if opts.Paginated {
+		ctx.SetTotalCountHeader(total)
+	} else {
+		urls = availableDirectURLs(urls)
 	}
-	response := &lfs_module.ObjectDirectUrls{Objects: urls}
+	response := &lfs_module.ObjectDirectUrls{Commit: commitID, Objects: urls}
 	ctx.Resp.Header().Set("Content-Type", "application/json")
 	enc := json.NewEncoder(ctx.Resp)
 	if err := enc.Encode(response); err != nil {
@@ -267,6 +301,14 @@ func MultipartBatchHandler(ctx *context.Context, br *lfs_module.BatchRequest) {
 		return
 	}
 
+	archivedObjects, err := getArchivedObjects(ctx, br.Objects)
+	if err != nil {
+		log.Error("Unable to get the archived LFS objects. Error: %v", err)
+		writeStatus(ctx, http.StatusInternalServerError)
+		return
+	}
+	accessed := make([]string, 0, len(br.Objects))
+
 	for _, p := range br.Objects {
 		if !p.IsValid() {
 			responseObjects = append(responseObjects, buildMultiPartObjectResponse(rc, p, false, false, &lfs_module.ObjectError{
@@ -277,7 +319,9 @@ func MultipartBatchHandler(ctx *context.Context, br *lfs_module.BatchRequest) {
 		}
 
 		meta := git_model.ContainsLFSMetaObject(metas, p.Oid)
-		exists := pointersExistenceMap[p.Oid]
+		archived := archivedObjects[p.Oid]
+		// the archived objects are restored on demand, they don't have to be uploaded again
+		exists := pointersExistenceMap[p.Oid] || archived != nil
 
 		if err != nil && err != git_model.ErrLFSObjectNotExist {
 			log.Error("Unable to get LFS MetaObject [%s] for %s/%s. Error: %v", p.Oid, rc.User, rc.Repo, err)
@@ -329,6 +373,11 @@ func MultipartBatchHandler(ctx *context.Context, br *lfs_module.BatchRequest) {
 			//		exists = false
 			//	}
 			//}
+			if archived != nil {
+				responseObject = buildMultiPartObjectResponse(rc, p, false, false, err, nil, nil)
+				responseObjects = append(responseObjects, responseObject)
+				continue
+			}
 			//get multipart information
 			part, _, verify, errorMessage := contentStore.GenerateMultipartParts(p)
 			if errorMessage != nil {
@@ -336,6 +385,11 @@ func MultipartBatchHandler(ctx *context.Context, br *lfs_module.BatchRequest) {
 				writeStatus(ctx, http.StatusInternalServerError)
 				return
 			}
+			if errorMessage = fillServedMultipartParts(ctx, rc, contentStore, repository.ID, p, part, verify); errorMessage != nil {
+				log.Error("Unable to fill multipart parts served by gitea for LFS OID[%s]. Error: %v", p.Oid, errorMessage)
+				writeStatus(ctx, http.StatusInternalServerError)
+				return
+			}
 			responseObject = buildMultiPartObjectResponse(rc, p, false, true, err, part, verify)
 		} else {
 			var err *lfs_module.ObjectError
@@ -344,12 +398,24 @@ func MultipartBatchHandler(ctx *context.Context, br *lfs_module.BatchRequest) {
 					Code:    http.StatusNotFound,
 					Message: http.StatusText(http.StatusNotFound),
 				}
+			} else if archived != nil {
+				requestRestore(ctx, archived)
+				err = pendingError
+			} else if setting.LFS.Storage.ServeDirect() {
+				// the object is downloaded directly from the storage, so the download is counted when its url is issued
+				downloadstat.Record(ctx.Req, repository.ID, repo_model.DownloadKindLFS)
+				accessed = append(accessed, p.Oid)
 			}
 			responseObject = buildMultiPartObjectResponse(rc, p, true, false, err, nil, nil)
+			if err == pendingError {
+				responseObject.Status = lfs_module.ObjectStatusPending
+			}
 		}
 		responseObjects = append(responseObjects, responseObject)
 	}
 
+	markAccessed(ctx, repository.ID, accessed...)
+
 	respobj := &lfs_module.BatchResponseWithMultiPart{Objects: responseObjects, Transfer: "multipart"}
 
 	ctx.Resp.Header().Set("Content-Type", lfs_module.MediaType)
@@ -382,7 +448,15 @@ func BatchHandler(ctx *context.Context, br *lfs_module.BatchRequest) {
 
 	contentStore := lfs_module.NewContentStore()
 
+	archivedObjects, err := getArchivedObjects(ctx, br.Objects)
+	if err != nil {
+		log.Error("Unable to get the archived LFS objects. Error: %v", err)
+		writeStatus(ctx, http.StatusInternalServerError)
+		return
+	}
+
 	var responseObjects []*lfs_module.ObjectResponse
+	accessed := make([]string, 0, len(br.Objects))
 
 	for _, p := range br.Objects {
 		if !p.IsValid() {
@@ -393,11 +467,16 @@ func BatchHandler(ctx *context.Context, br *lfs_module.BatchRequest) {
 			continue
 		}
 
-		exists, err := contentStore.Exists(p)
-		if err != nil {
-			log.Error("Unable to check if LFS OID[%s] exist. Error: %v", p.Oid, rc.User, rc.Repo, err)
-			writeStatus(ctx, http.StatusInternalServerError)
-			return
+		archived := archivedObjects[p.Oid]
+		// the archived objects are restored on demand, they don't have to be uploaded again
+		exists := archived != nil
+		if !exists {
+			exists, err = contentStore.Exists(p)
+			if err != nil {
+				log.Error("Unable to check if LFS OID[%s] exist. Error: %v", p.Oid, rc.User, rc.Repo, err)
+				writeStatus(ctx, http.StatusInternalServerError)
+				return
+			}
 		}
 
 		meta, err := git_model.GetLFSMetaObjectByOid(ctx, repository.ID, p.Oid)
@@ -452,13 +531,25 @@ func BatchHandler(ctx *context.Context, br *lfs_module.BatchRequest) {
 					Code:    http.StatusNotFound,
 					Message: http.StatusText(http.StatusNotFound),
 				}
+			} else if archived != nil {
+				requestRestore(ctx, archived)
+				err = pendingError
+			} else if setting.LFS.Storage.ServeDirect() {
+				// the object is downloaded directly from the storage, so the download is counted when its url is issued
+				downloadstat.Record(ctx.Req, repository.ID, repo_model.DownloadKindLFS)
+				accessed = append(accessed, p.Oid)
 			}
 
 			responseObject = buildObjectResponse(rc, p, true, false, err)
+			if err == pendingError {
+				responseObject.Status = lfs_module.ObjectStatusPending
+			}
 		}
 		responseObjects = append(responseObjects, responseObject)
 	}
 
+	markAccessed(ctx, repository.ID, accessed...)
+
 	respobj := &lfs_module.BatchResponse{Objects: responseObjects}
 
 	ctx.Resp.Header().Set("Content-Type", lfs_module.MediaType)
@@ -489,6 +580,7 @@ func UploadHandler(ctx *context.Context) {
 	if repository == nil {
 		return
 	}
+	defer countUpload(ctx)()
 
 	contentStore := lfs_module.NewContentStore()
 	exists, err := contentStore.Exists(p)
@@ -545,6 +637,8 @@ func UploadHandler(ctx *context.Context) {
 		return
 	}
 
+	notify_service.LFSObjectUpload(ctx, ctx.Doer, repository, p)
+
 	writeStatus(ctx, http.StatusOK)
 }
 
@@ -603,46 +697,190 @@ func MultiPartVerifyHandler(ctx *context.Context) {
 		Oid:  ctx.Req.URL.Query().Get("oid"),
 		Size: size,
 	}
-
-	contentStore := lfs_module.NewContentStore()
-	//check whether object exists
-	//exists, err := contentStore.Exists(p)
-	if err != nil {
-		log.Error("lfs[multipart] unable to check if LFS OID[%s] exist. Error: %v", p.Oid, err)
-		writeStatus(ctx, http.StatusInternalServerError)
+	if !p.IsValid() {
+		log.Trace("Attempt to verify invalid LFS OID[%s] in %s/%s", p.Oid, rc.User, rc.Repo)
+		writeStatus(ctx, http.StatusUnprocessableEntity)
 		return
 	}
-	//if exists {
-	//	accessible, err := git_model.LFSObjectAccessible(ctx, ctx.Doer, p.Oid)
-	//	if err != nil || !accessible {
-	//		log.Error("lfs[multipart] unable to check if LFS MetaObject [%s] is accessible. Error: %v", p.Oid, err)
-	//		writeStatus(ctx, http.StatusInternalServerError)
-	//		return
-	//	}
-	//	log.Error("lfs[multipart] LFS Object already exists", p.Oid)
-	//	writeStatus(ctx, http.StatusOK)
-	//	return
-	//}
+
+	contentStore := lfs_module.NewContentStore()
 	ok, err := contentStore.CommitAndVerify(p, string(parameter))
 	if err != nil {
 		log.Error("lfs[multipart] failed to commit and verify LFS object %v", err)
-	} else {
+	} else if ok {
 		_, err = git_model.NewLFSMetaObject(ctx, &git_model.LFSMetaObject{Pointer: p, RepositoryID: repository.ID})
 		if err != nil {
 			log.Error("lfs[multipart] failed to create git lfs meta object OID[%s] %v", p.Oid, err)
 		}
 	}
+	recordVerification(ctx, repository.ID, p, ok, err)
+	if _, ok := storage.As[storage.MultipartUploader](contentStore.ObjectStorage); ok {
+		// the parts are either assembled or broken, a new upload has to start over in both cases
+		var commit storage.MultiPartCommitUpload
+		if err := json.Unmarshal(parameter, &commit); err == nil && commit.UploadID != "" {
+			if err := git_model.DeleteLFSMultipartParts(ctx, commit.UploadID); err != nil {
+				log.Error("lfs[multipart] failed to delete parts of LFS OID[%s] %v", p.Oid, err)
+			}
+		}
+	}
 
+	if errors.Is(err, lfs_module.ErrSizeMismatch) || errors.Is(err, lfs_module.ErrHashMismatch) {
+		writeStatusMessage(ctx, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
 	status := http.StatusOK
 	if err != nil {
 		log.Error("lfs[multipart] error commit and verify LFS OID[%s]: %v", p.Oid, err)
 		status = http.StatusInternalServerError
 	} else if !ok {
 		status = http.StatusNotFound
+	} else {
+		notify_service.LFSObjectUpload(ctx, ctx.Doer, repository, p)
 	}
 	writeStatus(ctx, status)
 }
 
+// recordVerification records the outcome of the verification of an object assembled from a multipart upload
+func recordVerification(ctx *context.Context, repoID int64, p lfs_module.Pointer, ok bool, err error) {
+	status := git_model.LFSVerificationSucceeded
+	switch {
+	case errors.Is(err, lfs_module.ErrSizeMismatch):
+		status = git_model.LFSVerificationSizeMismatch
+	case errors.Is(err, lfs_module.ErrHashMismatch):
+		status = git_model.LFSVerificationHashMismatch
+	case err != nil || !ok:
+		status = git_model.LFSVerificationFailed
+	}
+	recordVerified(ctx, status.String())
+
+	v := &git_model.LFSVerification{
+		RepositoryID: repoID,
+		Oid:          p.Oid,
+		Size:         p.Size,
+		Status:       status,
+	}
+	if ctx.Doer != nil {
+		v.DoerID = ctx.Doer.ID
+	}
+	if err := git_model.NewLFSVerification(ctx, v); err != nil {
+		log.Error("lfs[multipart] failed to record the verification of LFS OID[%s] %v", p.Oid, err)
+	}
+}
+
+// MultipartPartUploadHandler receives a part of a multipart upload served by gitea and puts it into the content store
+func MultipartPartUploadHandler(ctx *context.Context) {
+	rc := getRequestContext(ctx)
+
+	p := lfs_module.Pointer{Oid: ctx.Params("oid")}
+	var err error
+	if p.Size, err = strconv.ParseInt(ctx.Params("size"), 10, 64); err != nil {
+		writeStatusMessage(ctx, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	index, err := strconv.Atoi(ctx.Params("index"))
+	if err != nil {
+		writeStatusMessage(ctx, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	uploadID := ctx.Params("uploadid")
+
+	if !p.IsValid() || !storage.IsMultipartUploadIDOf(p.RelativePath(), uploadID) {
+		log.Trace("Attempt to upload invalid LFS OID[%s] part %d in %s/%s", p.Oid, index, rc.User, rc.Repo)
+		writeStatus(ctx, http.StatusUnprocessableEntity)
+		return
+	}
+
+	repository := getAuthenticatedRepository(ctx, rc, true)
+	if repository == nil {
+		return
+	}
+
+	defer ctx.Req.Body.Close()
+	defer countUpload(ctx)()
+	contentStore := lfs_module.NewContentStore()
+	etag, err := contentStore.UploadPart(p, uploadID, index, ctx.Req.Body)
+	if err != nil {
+		if errors.Is(err, storage.ErrMultipartNotSupported) {
+			writeStatus(ctx, http.StatusNotFound)
+			return
+		}
+		log.Error("lfs[multipart] Error putting LFS OID[%s] part %d into content store. Error: %v", p.Oid, index, err)
+		writeStatusMessage(ctx, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	if err := git_model.SaveLFSMultipartPart(ctx, &git_model.LFSMultipartPart{
+		RepositoryID: repository.ID,
+		Oid:          p.Oid,
+		UploadID:     uploadID,
+		PartIndex:    index,
+		Size:         storage.MultipartPartSize(p.Size, index),
+		Etag:         etag,
+	}); err != nil {
+		log.Error("lfs[multipart] Error saving LFS OID[%s] part %d. Error: %v", p.Oid, index, err)
+		writeStatus(ctx, http.StatusInternalServerError)
+		return
+	}
+
+	ctx.Resp.Header().Set("ETag", etag)
+	ctx.Resp.Header().Set("Access-Control-Expose-Headers", "ETag")
+	writeStatus(ctx, http.StatusOK)
+}
+
+// fillServedMultipartParts fills the endpoints of the parts which are uploaded through gitea,
+// parts which have already been uploaded are returned with their etag and without endpoint.
+// An unfinished upload of the object to the repository is resumed instead of starting a new one.
+func fillServedMultipartParts(ctx *context.Context, rc *requestContext, contentStore *lfs_module.ContentStore, repoID int64, p lfs_module.Pointer, parts []*structs.MultipartObjectPart, verify *structs.MultipartEndpoint) error {
+	if verify == nil || verify.Params == nil {
+		return nil
+	}
+	uploadID := (*verify.Params)["upload_id"]
+
+	var uploaded map[int]*git_model.LFSMultipartPart
+	for _, part := range parts {
+		if part.MultipartEndpoint == nil || part.MultipartEndpoint.Href != "" {
+			continue
+		}
+		if uploaded == nil {
+			pending, err := git_model.GetLFSMultipartUploadID(ctx, repoID, p.Oid)
+			if err != nil {
+				return err
+			}
+			uploaded = make(map[int]*git_model.LFSMultipartPart)
+			if storage.IsMultipartUploadIDOf(p.RelativePath(), pending) {
+				uploadID = pending
+				(*verify.Params)["upload_id"] = uploadID
+				existing, err := git_model.GetLFSMultipartParts(ctx, uploadID)
+				if err != nil {
+					return err
+				}
+				for _, e := range existing {
+					uploaded[e.PartIndex] = e
+				}
+			}
+		}
+		if e, ok := uploaded[part.Index]; ok && e.Size == part.Size {
+			// the part may have been removed from the storage since it was uploaded, it has to be uploaded again then
+			info, err := contentStore.StatPart(p, uploadID, part.Index)
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+			if err == nil && info.Size() == part.Size {
+				part.Etag = e.Etag
+				part.MultipartEndpoint = nil
+				continue
+			}
+		}
+		header := make(map[string]string)
+		if len(rc.Authorization) > 0 {
+			header["Authorization"] = rc.Authorization
+		}
+		part.Href = rc.MultipartPartLink(p, uploadID, part.Index)
+		part.Headers = &header
+	}
+	return nil
+}
+
 func decodeJSON(req *http.Request, v any) error {
 	defer req.Body.Close()
 
@@ -721,7 +959,7 @@ func buildMultiPartObjectResponse(rc *requestContext, pointer lfs_module.Pointer
 
 		if download {
 			var link *structs.MultipartEndpoint
-			if setting.LFS.Storage.MinioConfig.ServeDirect {
+			if setting.LFS.Storage.ServeDirect() {
 				// If we have a signed url (S3, object storage), redirect to this directly.
 				u, err := storage.LFS.URL(pointer.RelativePath(), pointer.Oid)
 				if u != nil && err == nil {
@@ -781,7 +1019,7 @@ func buildObjectResponse(rc *requestContext, pointer lfs_module.Pointer, downloa
 
 		if download {
 			var link *lfs_module.Link
-			if setting.LFS.Storage.MinioConfig.ServeDirect {
+			if setting.LFS.Storage.ServeDirect() {
 				// If we have a signed url (S3, object storage), redirect to this directly.
 				u, err := storage.LFS.URL(pointer.RelativePath(), pointer.Oid)
 				if u != nil && err == nil {
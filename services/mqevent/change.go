@@ -0,0 +1,129 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package mqevent
+
+import (
+	"context"
+
+	issues_model "code.gitea.io/gitea/models/issues"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/repository"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// The events of the pushes, the created repositories, the releases and the issues are written to the outbox by the
+// services with the context of the transaction recording the change, so they are published if and only if the change
+// is committed. A failure to write the event is returned so the caller can roll the change back.
+
+func enabled() bool {
+	return setting.MQ != nil && setting.MQEvents.Enabled
+}
+
+// PushCommits writes the event of commits pushed to a branch or a tag
+func PushCommits(ctx context.Context, pusher *user_model.User, repo *repo_model.Repository, opts *repository.PushUpdateOptions, commits *repository.PushCommits) error {
+	if !enabled() {
+		return nil
+	}
+	return writeEvent(ctx, pushEvent(pusher, repo, opts, commits, false))
+}
+
+// CreateRef writes the event of a created branch or tag
+func CreateRef(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, refFullName git.RefName, refID string) error {
+	if !enabled() {
+		return nil
+	}
+	return writeEvent(ctx, newEvent(api.MQEventRefCreate, doer, repo, &api.MQEventPushPayload{
+		Ref:   refFullName.String(),
+		After: refID,
+	}))
+}
+
+// DeleteRef writes the event of a deleted branch or tag
+func DeleteRef(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, refFullName git.RefName) error {
+	if !enabled() {
+		return nil
+	}
+	return writeEvent(ctx, newEvent(api.MQEventRefDelete, doer, repo, &api.MQEventPushPayload{
+		Ref: refFullName.String(),
+	}))
+}
+
+// CreateRepository writes the event of a repository created from scratch or from a template
+func CreateRepository(ctx context.Context, doer *user_model.User, repo *repo_model.Repository) error {
+	if !enabled() {
+		return nil
+	}
+	return writeEvent(ctx, newEvent(api.MQEventRepositoryCreate, doer, repo, &api.MQEventRepositoryPayload{Origin: "create"}))
+}
+
+// NewRelease writes the event of a published release
+func NewRelease(ctx context.Context, rel *repo_model.Release) error {
+	return writeReleaseEvent(ctx, rel.Publisher, rel, api.MQEventReleasePublish)
+}
+
+// UpdateRelease writes the event of an updated release
+func UpdateRelease(ctx context.Context, doer *user_model.User, rel *repo_model.Release) error {
+	return writeReleaseEvent(ctx, doer, rel, api.MQEventReleaseUpdate)
+}
+
+// DeleteRelease writes the event of a deleted release
+func DeleteRelease(ctx context.Context, doer *user_model.User, rel *repo_model.Release) error {
+	return writeReleaseEvent(ctx, doer, rel, api.MQEventReleaseDelete)
+}
+
+func writeReleaseEvent(ctx context.Context, doer *user_model.User, rel *repo_model.Release, typ api.MQEventType) error {
+	if !enabled() {
+		return nil
+	}
+	evt, err := releaseEvent(ctx, doer, rel, typ)
+	if err != nil {
+		return err
+	}
+	return writeEvent(ctx, evt)
+}
+
+// NewIssue writes the event of an opened issue
+func NewIssue(ctx context.Context, issue *issues_model.Issue) error {
+	if !enabled() {
+		return nil
+	}
+	if err := issue.LoadPoster(ctx); err != nil {
+		return err
+	}
+	return writeIssueEvent(ctx, issue.Poster, issue, api.MQEventIssueOpen)
+}
+
+// IssueChangeStatus writes the event of a closed or reopened issue or pull request
+func IssueChangeStatus(ctx context.Context, doer *user_model.User, issue *issues_model.Issue, isClosed bool) error {
+	typ := api.MQEventIssueReopen
+	if isClosed {
+		typ = api.MQEventIssueClose
+	}
+	if issue.IsPull {
+		typ = api.MQEventPullRequestReopen
+		if isClosed {
+			typ = api.MQEventPullRequestClose
+		}
+	}
+	return writeIssueEvent(ctx, doer, issue, typ)
+}
+
+// DeleteIssue writes the event of a deleted issue
+func DeleteIssue(ctx context.Context, doer *user_model.User, issue *issues_model.Issue) error {
+	return writeIssueEvent(ctx, doer, issue, api.MQEventIssueDelete)
+}
+
+func writeIssueEvent(ctx context.Context, doer *user_model.User, issue *issues_model.Issue, typ api.MQEventType) error {
+	if !enabled() {
+		return nil
+	}
+	evt, err := issueEvent(ctx, doer, issue, typ)
+	if err != nil {
+		return err
+	}
+	return writeEvent(ctx, evt)
+}
@@ -0,0 +1,89 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package mqevent
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/google/uuid"
+)
+
+// The headers of the published messages, they allow the consumers to route the events without decoding them
+const (
+	HeaderEventID      = "gitea-event-id"
+	HeaderEventType    = "gitea-event-type"
+	HeaderEventVersion = "gitea-event-version"
+)
+
+// publish is replaced in the tests
+var publish = Enqueue
+
+func newEvent(typ api.MQEventType, actor *user_model.User, repo *repo_model.Repository, payload any) *api.MQEvent {
+	return &api.MQEvent{
+		ID:         uuid.New().String(),
+		Type:       typ,
+		Version:    api.MQEventVersion,
+		Created:    time.Now().UTC(),
+		Actor:      toEventUser(actor),
+		Repository: toEventRepository(repo),
+		Payload:    payload,
+	}
+}
+
+// writeEvent writes the event to the outbox of the topic of its category, the events of the categories without a topic are dropped
+func writeEvent(ctx context.Context, evt *api.MQEvent) error {
+	topic, ok := setting.MQEvents.Topics[evt.Type.Category()]
+	if !ok {
+		return nil
+	}
+
+	header := map[string]string{
+		HeaderEventID:      evt.ID,
+		HeaderEventType:    string(evt.Type),
+		HeaderEventVersion: evt.Version,
+	}
+	if err := publish(ctx, topic, evt, header); err != nil {
+		return fmt.Errorf("unable to write %s event %s for topic %s to the outbox: %w", evt.Type, evt.ID, topic, err)
+	}
+	return nil
+}
+
+// publishEvent writes the event of a change which has already been committed, a failure can only be logged
+func publishEvent(ctx context.Context, evt *api.MQEvent) {
+	if err := writeEvent(ctx, evt); err != nil {
+		log.Error("%v", err)
+	}
+}
+
+func toEventUser(u *user_model.User) *api.MQEventUser {
+	if u == nil {
+		return nil
+	}
+	return &api.MQEventUser{
+		ID:    u.ID,
+		Name:  u.Name,
+		Email: u.GetEmail(),
+	}
+}
+
+func toEventRepository(repo *repo_model.Repository) *api.MQEventRepository {
+	if repo == nil {
+		return nil
+	}
+	return &api.MQEventRepository{
+		ID:       repo.ID,
+		Owner:    repo.OwnerName,
+		Name:     repo.Name,
+		FullName: repo.FullName(),
+		Private:  repo.IsPrivate,
+	}
+}
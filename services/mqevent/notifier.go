@@ -0,0 +1,221 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package mqevent
+
+import (
+	"context"
+
+	issues_model "code.gitea.io/gitea/models/issues"
+	packages_model "code.gitea.io/gitea/models/packages"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/lfs"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/repository"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	notify_service "code.gitea.io/gitea/services/notify"
+)
+
+// mqEventNotifier publishes the events which are not written by the services in the transaction of their change,
+// they are written once the change has been committed and are lost if Gitea stops in between
+type mqEventNotifier struct {
+	notify_service.NullNotifier
+}
+
+var _ notify_service.Notifier = &mqEventNotifier{}
+
+// Init starts the outbox queue and registers the notifier publishing the repository activity if it is enabled
+func Init() error {
+	if setting.MQ == nil {
+		return nil
+	}
+	if err := initOutbox(); err != nil {
+		return err
+	}
+	if setting.MQEvents.Enabled {
+		notify_service.RegisterNotifier(NewNotifier())
+	}
+
+	return nil
+}
+
+// NewNotifier create a new mqEventNotifier notifier
+func NewNotifier() notify_service.Notifier {
+	return &mqEventNotifier{}
+}
+
+func (n *mqEventNotifier) SyncPushCommits(ctx context.Context, pusher *user_model.User, repo *repo_model.Repository, opts *repository.PushUpdateOptions, commits *repository.PushCommits) {
+	publishEvent(ctx, pushEvent(pusher, repo, opts, commits, true))
+}
+
+func pushEvent(pusher *user_model.User, repo *repo_model.Repository, opts *repository.PushUpdateOptions, commits *repository.PushCommits, mirror bool) *api.MQEvent {
+	payload := &api.MQEventPushPayload{
+		Ref:          opts.RefFullName.String(),
+		Before:       opts.OldCommitID,
+		After:        opts.NewCommitID,
+		Commits:      make([]*api.MQEventCommit, 0, len(commits.Commits)),
+		TotalCommits: commits.Len,
+		Mirror:       mirror,
+	}
+	for _, c := range commits.Commits {
+		payload.Commits = append(payload.Commits, &api.MQEventCommit{
+			ID:      c.Sha1,
+			Message: c.Message,
+			Author:  c.AuthorName,
+			Time:    c.Timestamp,
+		})
+	}
+	return newEvent(api.MQEventPush, pusher, repo, payload)
+}
+
+func (n *mqEventNotifier) SyncCreateRef(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, refFullName git.RefName, refID string) {
+	publishEvent(ctx, newEvent(api.MQEventRefCreate, doer, repo, &api.MQEventPushPayload{
+		Ref:    refFullName.String(),
+		After:  refID,
+		Mirror: true,
+	}))
+}
+
+func (n *mqEventNotifier) SyncDeleteRef(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, refFullName git.RefName) {
+	publishEvent(ctx, newEvent(api.MQEventRefDelete, doer, repo, &api.MQEventPushPayload{
+		Ref:    refFullName.String(),
+		Mirror: true,
+	}))
+}
+
+func (n *mqEventNotifier) AdoptRepository(ctx context.Context, doer, u *user_model.User, repo *repo_model.Repository) {
+	publishEvent(ctx, newEvent(api.MQEventRepositoryCreate, doer, repo, &api.MQEventRepositoryPayload{Origin: "adopt"}))
+}
+
+func (n *mqEventNotifier) MigrateRepository(ctx context.Context, doer, u *user_model.User, repo *repo_model.Repository) {
+	publishEvent(ctx, newEvent(api.MQEventRepositoryCreate, doer, repo, &api.MQEventRepositoryPayload{Origin: "migrate"}))
+}
+
+func (n *mqEventNotifier) ForkRepository(ctx context.Context, doer *user_model.User, oldRepo, repo *repo_model.Repository) {
+	publishEvent(ctx, newEvent(api.MQEventRepositoryCreate, doer, repo, &api.MQEventRepositoryPayload{
+		Origin: "fork",
+		ForkOf: oldRepo.FullName(),
+	}))
+}
+
+func (n *mqEventNotifier) DeleteRepository(ctx context.Context, doer *user_model.User, repo *repo_model.Repository) {
+	publishEvent(ctx, newEvent(api.MQEventRepositoryDelete, doer, repo, nil))
+}
+
+func (n *mqEventNotifier) TransferRepository(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, oldOwnerName string) {
+	publishEvent(ctx, newEvent(api.MQEventRepositoryTransfer, doer, repo, &api.MQEventRepositoryPayload{OldOwner: oldOwnerName}))
+}
+
+func (n *mqEventNotifier) RenameRepository(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, oldRepoName string) {
+	publishEvent(ctx, newEvent(api.MQEventRepositoryRename, doer, repo, &api.MQEventRepositoryPayload{OldName: oldRepoName}))
+}
+
+func releaseEvent(ctx context.Context, doer *user_model.User, rel *repo_model.Release, typ api.MQEventType) (*api.MQEvent, error) {
+	if err := rel.LoadAttributes(ctx); err != nil {
+		return nil, err
+	}
+	if doer == nil {
+		doer = rel.Publisher
+	}
+
+	return newEvent(typ, doer, rel.Repo, &api.MQEventReleasePayload{
+		ID:           rel.ID,
+		TagName:      rel.TagName,
+		Target:       rel.Target,
+		Title:        rel.Title,
+		CommitID:     rel.Sha1,
+		IsDraft:      rel.IsDraft,
+		IsPrerelease: rel.IsPrerelease,
+	}), nil
+}
+
+func (n *mqEventNotifier) NewPullRequest(ctx context.Context, pr *issues_model.PullRequest, mentions []*user_model.User) {
+	if err := pr.LoadIssue(ctx); err != nil {
+		log.Error("LoadIssue: %v", err)
+		return
+	}
+	if err := pr.Issue.LoadPoster(ctx); err != nil {
+		log.Error("LoadPoster: %v", err)
+		return
+	}
+	notifyIssue(ctx, pr.Issue.Poster, pr.Issue, api.MQEventPullRequestOpen)
+}
+
+func (n *mqEventNotifier) MergePullRequest(ctx context.Context, doer *user_model.User, pr *issues_model.PullRequest) {
+	notifyPullRequest(ctx, doer, pr, api.MQEventPullRequestMerge)
+}
+
+func (n *mqEventNotifier) AutoMergePullRequest(ctx context.Context, doer *user_model.User, pr *issues_model.PullRequest) {
+	notifyPullRequest(ctx, doer, pr, api.MQEventPullRequestMerge)
+}
+
+func (n *mqEventNotifier) PullRequestSynchronized(ctx context.Context, doer *user_model.User, pr *issues_model.PullRequest) {
+	notifyPullRequest(ctx, doer, pr, api.MQEventPullRequestSync)
+}
+
+func notifyPullRequest(ctx context.Context, doer *user_model.User, pr *issues_model.PullRequest, typ api.MQEventType) {
+	if err := pr.LoadIssue(ctx); err != nil {
+		log.Error("LoadIssue: %v", err)
+		return
+	}
+	notifyIssue(ctx, doer, pr.Issue, typ)
+}
+
+func notifyIssue(ctx context.Context, doer *user_model.User, issue *issues_model.Issue, typ api.MQEventType) {
+	evt, err := issueEvent(ctx, doer, issue, typ)
+	if err != nil {
+		log.Error("Unable to build the %s event of issue %d: %v", typ, issue.ID, err)
+		return
+	}
+	publishEvent(ctx, evt)
+}
+
+func issueEvent(ctx context.Context, doer *user_model.User, issue *issues_model.Issue, typ api.MQEventType) (*api.MQEvent, error) {
+	if err := issue.LoadRepo(ctx); err != nil {
+		return nil, err
+	}
+
+	payload := &api.MQEventIssuePayload{
+		ID:     issue.ID,
+		Number: issue.Index,
+		Title:  issue.Title,
+		Closed: issue.IsClosed,
+	}
+	if issue.IsPull {
+		if err := issue.LoadPullRequest(ctx); err != nil {
+			return nil, err
+		}
+		payload.HeadBranch = issue.PullRequest.HeadBranch
+		payload.BaseBranch = issue.PullRequest.BaseBranch
+		payload.MergedCommitID = issue.PullRequest.MergedCommitID
+	}
+	return newEvent(typ, doer, issue.Repo, payload), nil
+}
+
+func (n *mqEventNotifier) LFSObjectUpload(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, pointer lfs.Pointer) {
+	publishEvent(ctx, newEvent(api.MQEventLFSObjectUpload, doer, repo, &api.MQEventLFSPayload{
+		Oid:  pointer.Oid,
+		Size: pointer.Size,
+	}))
+}
+
+func (n *mqEventNotifier) PackageCreate(ctx context.Context, doer *user_model.User, pd *packages_model.PackageDescriptor) {
+	notifyPackage(ctx, doer, pd, api.MQEventPackagePublish)
+}
+
+func (n *mqEventNotifier) PackageDelete(ctx context.Context, doer *user_model.User, pd *packages_model.PackageDescriptor) {
+	notifyPackage(ctx, doer, pd, api.MQEventPackageDelete)
+}
+
+func notifyPackage(ctx context.Context, doer *user_model.User, pd *packages_model.PackageDescriptor, typ api.MQEventType) {
+	publishEvent(ctx, newEvent(typ, doer, pd.Repository, &api.MQEventPackagePayload{
+		ID:      pd.Version.ID,
+		Owner:   pd.Owner.Name,
+		Type:    string(pd.Package.Type),
+		Name:    pd.Package.Name,
+		Version: pd.Version.Version,
+	}))
+}
@@ -0,0 +1,123 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package mqevent
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	issues_model "code.gitea.io/gitea/models/issues"
+	"code.gitea.io/gitea/models/outbox"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/test"
+
+	_ "code.gitea.io/gitea/models/actions"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	unittest.MainTest(m)
+}
+
+type publishedMessage struct {
+	topic  string
+	event  *api.MQEvent
+	header map[string]string
+}
+
+func TestWriteEvents(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	defer test.MockVariableValue(&setting.MQ, &setting.MQConfig{})()
+	defer test.MockVariableValue(&setting.MQEvents.Enabled, true)()
+	defer test.MockVariableValue(&setting.MQEvents.Topics, map[string]string{
+		"push":  "gitea-push",
+		"issue": "gitea-issue",
+	})()
+
+	var published []publishedMessage
+	defer test.MockVariableValue(&publish, func(_ context.Context, topic string, v any, header map[string]string) error {
+		published = append(published, publishedMessage{topic: topic, event: v.(*api.MQEvent), header: header})
+		return nil
+	})()
+
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1})
+
+	assert.NoError(t, CreateRef(db.DefaultContext, user, repo, git.RefNameFromTag("v1.0"), "65f1bf27bc3bf70f64657658635e66094edbcb4d"))
+	if assert.Len(t, published, 1) {
+		msg := published[0]
+		assert.Equal(t, "gitea-push", msg.topic)
+		assert.Equal(t, api.MQEventRefCreate, msg.event.Type)
+		assert.Equal(t, api.MQEventVersion, msg.event.Version)
+		assert.Equal(t, msg.event.ID, msg.header[HeaderEventID])
+		assert.Equal(t, "push.ref_create", msg.header[HeaderEventType])
+		assert.Equal(t, "user2/repo1", msg.event.Repository.FullName)
+		assert.Equal(t, user.ID, msg.event.Actor.ID)
+		payload := msg.event.Payload.(*api.MQEventPushPayload)
+		assert.Equal(t, "refs/tags/v1.0", payload.Ref)
+		assert.Equal(t, "65f1bf27bc3bf70f64657658635e66094edbcb4d", payload.After)
+	}
+
+	issue := unittest.AssertExistsAndLoadBean(t, &issues_model.Issue{ID: 1})
+	assert.NoError(t, NewIssue(db.DefaultContext, issue))
+	if assert.Len(t, published, 2) {
+		msg := published[1]
+		assert.Equal(t, "gitea-issue", msg.topic)
+		assert.Equal(t, api.MQEventIssueOpen, msg.event.Type)
+		assert.Equal(t, issue.PosterID, msg.event.Actor.ID)
+		payload := msg.event.Payload.(*api.MQEventIssuePayload)
+		assert.Equal(t, issue.Index, payload.Number)
+		assert.Equal(t, issue.Title, payload.Title)
+	}
+
+	// the release category has no topic
+	rel := unittest.AssertExistsAndLoadBean(t, &repo_model.Release{ID: 1})
+	assert.NoError(t, NewRelease(db.DefaultContext, rel))
+	assert.Len(t, published, 2)
+
+	// the events of the other changes are published by the notifier
+	NewNotifier().SyncDeleteRef(db.DefaultContext, user, repo, git.RefNameFromBranch("develop"))
+	if assert.Len(t, published, 3) {
+		assert.Equal(t, api.MQEventRefDelete, published[2].event.Type)
+		assert.True(t, published[2].event.Payload.(*api.MQEventPushPayload).Mirror)
+	}
+
+	// nothing is written if the events are disabled
+	defer test.MockVariableValue(&setting.MQEvents.Enabled, false)()
+	assert.NoError(t, NewIssue(db.DefaultContext, issue))
+	assert.Len(t, published, 3)
+}
+
+func TestWriteEventInTransaction(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	defer test.MockVariableValue(&setting.MQ, &setting.MQConfig{})()
+	defer test.MockVariableValue(&setting.MQEvents.Enabled, true)()
+	defer test.MockVariableValue(&setting.MQEvents.Topics, map[string]string{"issue": "gitea-issue"})()
+
+	issue := unittest.AssertExistsAndLoadBean(t, &issues_model.Issue{ID: 1})
+
+	// the event is rolled back with the change
+	assert.Error(t, db.WithTx(db.DefaultContext, func(ctx context.Context) error {
+		if err := NewIssue(ctx, issue); err != nil {
+			return err
+		}
+		return errors.New("the change failed")
+	}))
+	unittest.AssertCount(t, &outbox.Message{}, 0)
+
+	assert.NoError(t, db.WithTx(db.DefaultContext, func(ctx context.Context) error {
+		return NewIssue(ctx, issue)
+	}))
+	m := unittest.AssertExistsAndLoadBean(t, &outbox.Message{Topic: "gitea-issue"})
+	assert.Contains(t, m.Body, `"type":"issue.open"`)
+	assert.NoError(t, outbox.DeleteMessage(db.DefaultContext, m.ID))
+}
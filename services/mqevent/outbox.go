@@ -0,0 +1,175 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package mqevent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/outbox"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/messagequeue"
+	"code.gitea.io/gitea/modules/process"
+	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// outboxBatchSize is the max number of due messages queued on each poll of the outbox
+const outboxBatchSize = 100
+
+var outboxQueue *queue.WorkerPoolQueue[int64]
+
+func initOutbox() error {
+	outboxQueue = queue.CreateUniqueQueue(graceful.GetManager().ShutdownContext(), "mq_outbox", outboxHandler)
+	if outboxQueue == nil {
+		return fmt.Errorf("unable to create mq_outbox queue")
+	}
+	go graceful.GetManager().RunWithCancel(outboxQueue)
+	go graceful.GetManager().RunWithShutdownContext(pollOutbox)
+	return nil
+}
+
+// Enqueue writes the message to the outbox, it is published to the topic by the outbox queue.
+// If ctx is in a transaction the message is only written if the transaction is committed,
+// it is then published by the next poll of the outbox.
+func Enqueue(ctx context.Context, topic string, v any, header map[string]string) error {
+	if setting.MQ == nil {
+		return nil
+	}
+
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	m := &outbox.Message{
+		Topic: topic,
+		// the publishing is asynchronous, so the trace context has to be stored with the message
+		Header: messagequeue.WithTraceContext(ctx, header),
+		Body:   string(body),
+	}
+	inTx := db.InTransaction(ctx)
+	nextAttempt := timeutil.TimeStampNow()
+	if !inTx {
+		// the message is pushed to the queue right away, so the poll must not pick it up before it has been tried
+		nextAttempt = nextAttempt.Add(int64(setting.MQOutbox.RetryDelay.Seconds()))
+	}
+	if err := outbox.InsertMessage(ctx, m, nextAttempt); err != nil {
+		return err
+	}
+
+	if !inTx && outboxQueue != nil {
+		if err := outboxQueue.Push(m.ID); err != nil && !errors.Is(err, queue.ErrAlreadyInQueue) {
+			log.Error("Unable to push outbox message[%d] to the queue: %v", m.ID, err)
+		}
+	}
+	return nil
+}
+
+// RetryMessage makes a stuck outbox message pending again and queues it
+func RetryMessage(ctx context.Context, id int64) error {
+	if err := outbox.RetryMessage(ctx, id); err != nil {
+		return err
+	}
+	if outboxQueue == nil {
+		return nil
+	}
+	if err := outboxQueue.Push(id); err != nil && !errors.Is(err, queue.ErrAlreadyInQueue) {
+		return err
+	}
+	return nil
+}
+
+func outboxHandler(items ...int64) []int64 {
+	ctx := graceful.GetManager().HammerContext()
+
+	for _, id := range items {
+		if err := publishOutboxMessage(ctx, id); err != nil {
+			log.Error("Unable to publish outbox message[%d]: %v", id, err)
+		}
+	}
+	return nil
+}
+
+func publishOutboxMessage(ctx context.Context, id int64) error {
+	m, err := outbox.GetMessageByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, util.ErrNotExist) {
+			// already published in the meantime
+			return nil
+		}
+		return err
+	}
+	if m.Status != outbox.MessageStatusPending {
+		return nil
+	}
+
+	publishErr := messagequeue.PublishMessage(ctx, m.Topic, m.Header, []byte(m.Body))
+	if publishErr == nil {
+		return outbox.DeleteMessage(ctx, m.ID)
+	}
+
+	nextAttempt := timeutil.TimeStampNow().Add(int64(retryDelay(m.Attempts + 1).Seconds()))
+	if err := outbox.RecordFailedAttempt(ctx, m, publishErr, nextAttempt, setting.MQOutbox.MaxAttempts); err != nil {
+		return err
+	}
+	if m.Status == outbox.MessageStatusStuck {
+		return fmt.Errorf("giving up after %d attempts: %w", m.Attempts, publishErr)
+	}
+	return fmt.Errorf("attempt %d failed, retrying at %s: %w", m.Attempts, nextAttempt.AsTime(), publishErr)
+}
+
+// retryDelay returns the exponential backoff delay after the given number of failed attempts
+func retryDelay(attempts int) time.Duration {
+	delay := setting.MQOutbox.RetryDelay
+	for i := 1; i < attempts && delay < setting.MQOutbox.MaxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, setting.MQOutbox.MaxRetryDelay)
+}
+
+// pollOutbox queues the messages which are due, either because a previous attempt failed,
+// they were written in a transaction or the queue lost them on a shutdown
+func pollOutbox(ctx context.Context) {
+	ctx, _, finished := process.GetManager().AddTypedContext(ctx, "Service: Message queue outbox", process.SystemProcessType, true)
+	defer finished()
+
+	ticker := time.NewTicker(setting.MQOutbox.PollInterval)
+	defer ticker.Stop()
+	for {
+		queueDueMessages(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func queueDueMessages(ctx context.Context) {
+	for {
+		now := timeutil.TimeStampNow()
+		ids, err := outbox.LeaseDueMessages(ctx, now, now.Add(int64(setting.MQOutbox.RetryDelay.Seconds())), outboxBatchSize)
+		if err != nil {
+			log.Error("Unable to find the due outbox messages: %v", err)
+			return
+		}
+		for _, id := range ids {
+			if err := outboxQueue.Push(id); err != nil && !errors.Is(err, queue.ErrAlreadyInQueue) {
+				log.Error("Unable to push outbox message[%d] to the queue: %v", id, err)
+			}
+		}
+		if len(ids) < outboxBatchSize {
+			return
+		}
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package mqevent
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/outbox"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/messagequeue"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type failingQueue struct {
+	*messagequeue.MemoryQueue
+}
+
+func (failingQueue) Publish(context.Context, string, map[string]string, []byte) error {
+	return errors.New("broker down")
+}
+
+func TestOutbox(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	defer test.MockVariableValue(&setting.MQ, &setting.MQConfig{})()
+	defer test.MockVariableValue(&setting.MQOutbox.MaxAttempts, 2)()
+
+	mq := messagequeue.NewMemoryQueue()
+	defer messagequeue.SetMessageQueue(mq)()
+
+	assert.NoError(t, Enqueue(db.DefaultContext, "gitea-clone", map[string]string{"type": "git_clone"}, map[string]string{"k": "v"}))
+	messages, _, err := outbox.FindMessages(db.DefaultContext, outbox.FindMessagesOptions{})
+	assert.NoError(t, err)
+	if !assert.Len(t, messages, 1) {
+		return
+	}
+	id := messages[0].ID
+
+	// the broker is down, the message is kept and retried later
+	restore := messagequeue.SetMessageQueue(failingQueue{mq})
+	assert.Error(t, publishOutboxMessage(db.DefaultContext, id))
+	m := unittest.AssertExistsAndLoadBean(t, &outbox.Message{ID: id})
+	assert.Equal(t, outbox.MessageStatusPending, m.Status)
+	assert.Equal(t, 1, m.Attempts)
+	assert.Equal(t, "broker down", m.LastError)
+
+	assert.Error(t, publishOutboxMessage(db.DefaultContext, id))
+	m = unittest.AssertExistsAndLoadBean(t, &outbox.Message{ID: id})
+	assert.Equal(t, outbox.MessageStatusStuck, m.Status)
+	restore()
+
+	// stuck messages are only published after an admin retried them
+	assert.NoError(t, publishOutboxMessage(db.DefaultContext, id))
+	assert.Empty(t, mq.Messages(""))
+	assert.NoError(t, RetryMessage(db.DefaultContext, id))
+	assert.NoError(t, publishOutboxMessage(db.DefaultContext, id))
+
+	published := mq.Messages("gitea-clone")
+	if assert.Len(t, published, 1) {
+		assert.JSONEq(t, `{"type":"git_clone"}`, published[0].Body)
+		assert.Equal(t, "v", published[0].Header["k"])
+	}
+	unittest.AssertNotExistsBean(t, &outbox.Message{ID: id})
+}
+
+func TestRetryDelay(t *testing.T) {
+	defer test.MockVariableValue(&setting.MQOutbox.RetryDelay, 10*time.Second)()
+	defer test.MockVariableValue(&setting.MQOutbox.MaxRetryDelay, time.Minute)()
+
+	assert.Equal(t, 10*time.Second, retryDelay(1))
+	assert.Equal(t, 20*time.Second, retryDelay(2))
+	assert.Equal(t, 40*time.Second, retryDelay(3))
+	assert.Equal(t, time.Minute, retryDelay(4))
+	assert.Equal(t, time.Minute, retryDelay(30))
+}
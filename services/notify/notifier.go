@@ -11,6 +11,7 @@ import (
 	repo_model "code.gitea.io/gitea/models/repo"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/lfs"
 	"code.gitea.io/gitea/modules/repository"
 )
 
@@ -73,5 +74,7 @@ type Notifier interface {
 	PackageCreate(ctx context.Context, doer *user_model.User, pd *packages_model.PackageDescriptor)
 	PackageDelete(ctx context.Context, doer *user_model.User, pd *packages_model.PackageDescriptor)
 
+	LFSObjectUpload(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, pointer lfs.Pointer)
+
 	ChangeDefaultBranch(ctx context.Context, repo *repo_model.Repository)
 }
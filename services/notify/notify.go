@@ -11,6 +11,7 @@ import (
 	repo_model "code.gitea.io/gitea/models/repo"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/lfs"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/repository"
 )
@@ -361,6 +362,13 @@ func PackageDelete(ctx context.Context, doer *user_model.User, pd *packages_mode
 	}
 }
 
+// LFSObjectUpload notifies an uploaded LFS object to notifiers
+func LFSObjectUpload(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, pointer lfs.Pointer) {
+	for _, notifier := range notifiers {
+		notifier.LFSObjectUpload(ctx, doer, repo, pointer)
+	}
+}
+
 // ChangeDefaultBranch notifies change default branch to notifiers
 func ChangeDefaultBranch(ctx context.Context, repo *repo_model.Repository) {
 	for _, notifier := range notifiers {
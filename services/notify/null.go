@@ -11,6 +11,7 @@ import (
 	repo_model "code.gitea.io/gitea/models/repo"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/lfs"
 	"code.gitea.io/gitea/modules/repository"
 )
 
@@ -205,6 +206,10 @@ func (*NullNotifier) PackageCreate(ctx context.Context, doer *user_model.User, p
 func (*NullNotifier) PackageDelete(ctx context.Context, doer *user_model.User, pd *packages_model.PackageDescriptor) {
 }
 
+// LFSObjectUpload places a place holder function
+func (*NullNotifier) LFSObjectUpload(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, pointer lfs.Pointer) {
+}
+
 // ChangeDefaultBranch places a place holder function
 func (*NullNotifier) ChangeDefaultBranch(ctx context.Context, repo *repo_model.Repository) {
 }
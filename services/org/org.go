@@ -8,7 +8,9 @@ import (
 	"fmt"
 
 	"code.gitea.io/gitea/models"
+	actions_model "code.gitea.io/gitea/models/actions"
 	"code.gitea.io/gitea/models/db"
+	git_model "code.gitea.io/gitea/models/git"
 	org_model "code.gitea.io/gitea/models/organization"
 	packages_model "code.gitea.io/gitea/models/packages"
 	repo_model "code.gitea.io/gitea/models/repo"
@@ -52,6 +54,14 @@ func DeleteOrganization(ctx context.Context, org *org_model.Organization, purge
 		return fmt.Errorf("DeleteOrganization: %w", err)
 	}
 
+	if err := git_model.DeletePushPolicies(ctx, org.ID, 0); err != nil {
+		return fmt.Errorf("DeletePushPolicies: %w", err)
+	}
+
+	if err := actions_model.DeleteArtifactRetentions(ctx, org.ID, 0); err != nil {
+		return fmt.Errorf("DeleteArtifactRetentions: %w", err)
+	}
+
 	if err := commiter.Commit(); err != nil {
 		return err
 	}
@@ -21,6 +21,7 @@ import (
 	"code.gitea.io/gitea/modules/storage"
 	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/services/mqevent"
 	notify_service "code.gitea.io/gitea/services/notify"
 )
 
@@ -124,6 +125,19 @@ func createTag(ctx context.Context, gitRepo *git.Repository, rel *repo_model.Rel
 	return created, nil
 }
 
+// writeCreatedTagEvents writes the events of the tag created for the release to the outbox
+func writeCreatedTagEvents(ctx context.Context, rel *repo_model.Release) error {
+	refFullName := git.RefNameFromTag(rel.TagName)
+	if err := mqevent.PushCommits(ctx, rel.Publisher, rel.Repo, &repository.PushUpdateOptions{
+		RefFullName: refFullName,
+		OldCommitID: git.EmptySHA,
+		NewCommitID: rel.Sha1,
+	}, repository.NewPushCommits()); err != nil {
+		return err
+	}
+	return mqevent.CreateRef(ctx, rel.Publisher, rel.Repo, refFullName, rel.Sha1)
+}
+
 // CreateRelease creates a new release of repository.
 func CreateRelease(gitRepo *git.Repository, rel *repo_model.Release, attachmentUUIDs []string, msg string) error {
 	has, err := repo_model.IsReleaseExist(gitRepo.Ctx, rel.RepoID, rel.TagName)
@@ -135,16 +149,31 @@ func CreateRelease(gitRepo *git.Repository, rel *repo_model.Release, attachmentU
 		}
 	}
 
-	if _, err = createTag(gitRepo.Ctx, gitRepo, rel, msg); err != nil {
+	isCreated, err := createTag(gitRepo.Ctx, gitRepo, rel, msg)
+	if err != nil {
 		return err
 	}
 
 	rel.LowerTagName = strings.ToLower(rel.TagName)
-	if err = db.Insert(gitRepo.Ctx, rel); err != nil {
-		return err
-	}
+	if err = db.WithTx(gitRepo.Ctx, func(ctx context.Context) error {
+		if err := db.Insert(ctx, rel); err != nil {
+			return err
+		}
+
+		if err := repo_model.AddReleaseAttachments(ctx, rel.ID, attachmentUUIDs); err != nil {
+			return err
+		}
 
-	if err = repo_model.AddReleaseAttachments(gitRepo.Ctx, rel.ID, attachmentUUIDs); err != nil {
+		if isCreated {
+			if err := writeCreatedTagEvents(ctx, rel); err != nil {
+				return err
+			}
+		}
+		if !rel.IsDraft {
+			return mqevent.NewRelease(ctx, rel)
+		}
+		return nil
+	}); err != nil {
 		return err
 	}
 
@@ -184,11 +213,20 @@ func CreateNewTag(ctx context.Context, doer *user_model.User, repo *repo_model.R
 		IsTag:        true,
 	}
 
-	if _, err = createTag(ctx, gitRepo, rel, msg); err != nil {
+	isCreated, err := createTag(ctx, gitRepo, rel, msg)
+	if err != nil {
 		return err
 	}
 
-	return db.Insert(ctx, rel)
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		if err := db.Insert(ctx, rel); err != nil {
+			return err
+		}
+		if isCreated {
+			return writeCreatedTagEvents(ctx, rel)
+		}
+		return nil
+	})
 }
 
 // UpdateRelease updates information, attachments of a release and will create tag if it's not a draft and tag not exist.
@@ -274,6 +312,19 @@ func UpdateRelease(ctx context.Context, doer *user_model.User, gitRepo *git.Repo
 		}
 	}
 
+	if isCreated {
+		if err := writeCreatedTagEvents(ctx, rel); err != nil {
+			return err
+		}
+		if !rel.IsDraft {
+			if err := mqevent.NewRelease(ctx, rel); err != nil {
+				return err
+			}
+		}
+	} else if err := mqevent.UpdateRelease(ctx, doer, rel); err != nil {
+		return err
+	}
+
 	if err := committer.Commit(); err != nil {
 		return err
 	}
@@ -343,16 +394,8 @@ func DeleteReleaseByID(ctx context.Context, id int64, doer *user_model.User, del
 				NewCommitID: git.EmptySHA,
 			}, repository.NewPushCommits())
 		notify_service.DeleteRef(ctx, doer, repo, refName)
-
-		if err := repo_model.DeleteReleaseByID(ctx, id); err != nil {
-			return fmt.Errorf("DeleteReleaseByID: %w", err)
-		}
 	} else {
 		rel.IsTag = true
-
-		if err = repo_model.UpdateRelease(ctx, rel); err != nil {
-			return fmt.Errorf("Update: %w", err)
-		}
 	}
 
 	rel.Repo = repo
@@ -360,8 +403,33 @@ func DeleteReleaseByID(ctx context.Context, id int64, doer *user_model.User, del
 		return fmt.Errorf("LoadAttributes: %w", err)
 	}
 
-	if err := repo_model.DeleteAttachmentsByRelease(ctx, rel.ID); err != nil {
-		return fmt.Errorf("DeleteAttachments: %w", err)
+	if err := db.WithTx(ctx, func(ctx context.Context) error {
+		if delTag {
+			if err := repo_model.DeleteReleaseByID(ctx, id); err != nil {
+				return fmt.Errorf("DeleteReleaseByID: %w", err)
+			}
+
+			refName := git.RefNameFromTag(rel.TagName)
+			if err := mqevent.PushCommits(ctx, doer, repo, &repository.PushUpdateOptions{
+				RefFullName: refName,
+				OldCommitID: rel.Sha1,
+				NewCommitID: git.EmptySHA,
+			}, repository.NewPushCommits()); err != nil {
+				return err
+			}
+			if err := mqevent.DeleteRef(ctx, doer, repo, refName); err != nil {
+				return err
+			}
+		} else if err := repo_model.UpdateRelease(ctx, rel); err != nil {
+			return fmt.Errorf("Update: %w", err)
+		}
+
+		if err := repo_model.DeleteAttachmentsByRelease(ctx, rel.ID); err != nil {
+			return fmt.Errorf("DeleteAttachments: %w", err)
+		}
+		return mqevent.DeleteRelease(ctx, doer, rel)
+	}); err != nil {
+		return err
 	}
 
 	for i := range rel.Attachments {
@@ -21,6 +21,7 @@ import (
 	"code.gitea.io/gitea/modules/queue"
 	repo_module "code.gitea.io/gitea/modules/repository"
 	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/services/mqevent"
 	notify_service "code.gitea.io/gitea/services/notify"
 	files_service "code.gitea.io/gitea/services/repository/files"
 
@@ -294,28 +295,38 @@ func RenameBranch(ctx context.Context, repo *repo_model.Repository, doer *user_m
 		return "from_not_exist", nil
 	}
 
-	if err := git_model.RenameBranch(ctx, repo, from, to, func(isDefault bool) error {
-		err2 := gitRepo.RenameBranch(from, to)
-		if err2 != nil {
-			return err2
-		}
-
-		if isDefault {
-			err2 = gitRepo.SetDefaultBranch(to)
+	refNameTo := git.RefNameFromBranch(to)
+	var refID string
+	if err := db.WithTx(ctx, func(ctx context.Context) error {
+		if err := git_model.RenameBranch(ctx, repo, from, to, func(isDefault bool) error {
+			err2 := gitRepo.RenameBranch(from, to)
 			if err2 != nil {
 				return err2
 			}
+
+			if isDefault {
+				err2 = gitRepo.SetDefaultBranch(to)
+				if err2 != nil {
+					return err2
+				}
+			}
+
+			return nil
+		}); err != nil {
+			return err
 		}
 
-		return nil
+		var err error
+		if refID, err = gitRepo.GetRefCommitID(refNameTo.String()); err != nil {
+			return err
+		}
+		if err := mqevent.DeleteRef(ctx, doer, repo, git.RefNameFromBranch(from)); err != nil {
+			return err
+		}
+		return mqevent.CreateRef(ctx, doer, repo, refNameTo, refID)
 	}); err != nil {
 		return "", err
 	}
-	refNameTo := git.RefNameFromBranch(to)
-	refID, err := gitRepo.GetRefCommitID(refNameTo.String())
-	if err != nil {
-		return "", err
-	}
 
 	notify_service.DeleteRef(ctx, doer, repo, git.RefNameFromBranch(from))
 	notify_service.CreateRef(ctx, doer, repo, refNameTo, refID)
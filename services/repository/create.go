@@ -213,6 +213,11 @@ func initRepository(ctx context.Context, repoPath string, u *user_model.User, re
 
 // CreateRepositoryDirectly creates a repository for the user/organization.
 func CreateRepositoryDirectly(ctx context.Context, doer, u *user_model.User, opts CreateRepoOptions) (*repo_model.Repository, error) {
+	return createRepositoryDirectly(ctx, doer, u, opts, nil)
+}
+
+// createRepositoryDirectly creates a repository for the user/organization, onCreated is called in the transaction creating it
+func createRepositoryDirectly(ctx context.Context, doer, u *user_model.User, opts CreateRepoOptions, onCreated func(ctx context.Context, repo *repo_model.Repository) error) (*repo_model.Repository, error) {
 	if !doer.IsAdmin && !u.CanCreateRepo() {
 		return nil, repo_model.ErrReachLimitOfRepo{
 			Limit: u.MaxRepoCreation,
@@ -256,6 +261,11 @@ func CreateRepositoryDirectly(ctx context.Context, doer, u *user_model.User, opt
 		if err := repo_module.CreateRepositoryByExample(ctx, doer, u, repo, false, false); err != nil {
 			return err
 		}
+		if onCreated != nil {
+			if err := onCreated(ctx, repo); err != nil {
+				return err
+			}
+		}
 
 		// No need for init mirror.
 		if opts.IsMirror {
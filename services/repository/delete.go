@@ -27,6 +27,7 @@ import (
 	"code.gitea.io/gitea/modules/lfs"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/storage"
+	lfs_service "code.gitea.io/gitea/services/lfs"
 
 	"xorm.io/builder"
 )
@@ -138,12 +139,16 @@ func DeleteRepositoryDirectly(ctx context.Context, doer *user_model.User, repoID
 		&git_model.CommitStatus{RepoID: repoID},
 		&git_model.Branch{RepoID: repoID},
 		&git_model.LFSLock{RepoID: repoID},
+		&git_model.LFSVerification{RepositoryID: repoID},
+		&repo_model.DownloadStat{RepoID: repoID},
+		&repo_model.DownloadVisitor{RepoID: repoID},
 		&repo_model.LanguageStat{RepoID: repoID},
 		&issues_model.Milestone{RepoID: repoID},
 		&repo_model.Mirror{RepoID: repoID},
 		&activities_model.Notification{RepoID: repoID},
 		&git_model.ProtectedBranch{RepoID: repoID},
 		&git_model.ProtectedTag{RepoID: repoID},
+		&git_model.PushPolicy{RepoID: repoID},
 		&repo_model.PushMirror{RepoID: repoID},
 		&repo_model.Release{RepoID: repoID},
 		&repo_model.RepoIndexerStatus{RepoID: repoID},
@@ -162,6 +167,7 @@ func DeleteRepositoryDirectly(ctx context.Context, doer *user_model.User, repoID
 		&actions_model.ActionScheduleSpec{RepoID: repoID},
 		&actions_model.ActionSchedule{RepoID: repoID},
 		&actions_model.ActionArtifact{RepoID: repoID},
+		&actions_model.ActionArtifactRetention{RepoID: repoID},
 	); err != nil {
 		return fmt.Errorf("deleteBeans: %w", err)
 	}
@@ -214,6 +220,7 @@ func DeleteRepositoryDirectly(ctx context.Context, doer *user_model.User, repoID
 	}
 
 	lfsPaths := make([]string, 0, len(lfsObjects))
+	lfsPointers := make([]lfs.Pointer, 0, len(lfsObjects))
 	for _, v := range lfsObjects {
 		count, err := db.CountByBean(ctx, &git_model.LFSMetaObject{Pointer: lfs.Pointer{Oid: v.Oid}})
 		if err != nil {
@@ -224,6 +231,7 @@ func DeleteRepositoryDirectly(ctx context.Context, doer *user_model.User, repoID
 		}
 
 		lfsPaths = append(lfsPaths, v.RelativePath())
+		lfsPointers = append(lfsPointers, v.Pointer)
 	}
 
 	if _, err := db.DeleteByBean(ctx, &git_model.LFSMetaObject{RepositoryID: repoID}); err != nil {
@@ -303,6 +311,11 @@ func DeleteRepositoryDirectly(ctx context.Context, doer *user_model.User, repoID
 	for _, lfsObj := range lfsPaths {
 		system_model.RemoveStorageWithNotice(ctx, storage.LFS, "Delete orphaned LFS file", lfsObj)
 	}
+	for _, p := range lfsPointers {
+		if err := lfs_service.DeleteArchivedObject(ctx, p); err != nil {
+			log.Error("Unable to delete the archived LFS file %s: %v", p.Oid, err)
+		}
+	}
 
 	// Remove issue attachment files.
 	for _, attachment := range attachmentPaths {
@@ -0,0 +1,188 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package downloadstat
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+	web_types "code.gitea.io/gitea/modules/web/types"
+)
+
+// download is a download waiting to be counted
+type download struct {
+	RepoID int64
+	Kind   repo_model.DownloadKind
+	Day    timeutil.TimeStamp
+	// Visitor is the hash identifying the requester on the day
+	Visitor string
+}
+
+type downloadKey struct {
+	repoID int64
+	kind   repo_model.DownloadKind
+	day    timeutil.TimeStamp
+}
+
+var statQueue *queue.WorkerPoolQueue[*download]
+
+// Init starts the queue counting the downloads if the download statistics are enabled
+func Init() error {
+	if !setting.Repository.DownloadStats.Enabled {
+		return nil
+	}
+
+	statQueue = queue.CreateSimpleQueue(graceful.GetManager().ShutdownContext(), "repo_download_stats", handler)
+	if statQueue == nil {
+		return errors.New("unable to create repo_download_stats queue")
+	}
+	go graceful.GetManager().RunWithCancel(statQueue)
+	return nil
+}
+
+// handler adds the downloads of the batch, grouped by repository, kind and day
+func handler(items ...*download) []*download {
+	ctx := graceful.GetManager().HammerContext()
+
+	keys := make([]downloadKey, 0, len(items))
+	visitors := make(map[downloadKey][]string, len(items))
+	for _, item := range items {
+		key := downloadKey{repoID: item.RepoID, kind: item.Kind, day: item.Day}
+		if _, ok := visitors[key]; !ok {
+			keys = append(keys, key)
+		}
+		visitors[key] = append(visitors[key], item.Visitor)
+	}
+
+	for _, key := range keys {
+		if err := repo_model.AddDownloads(ctx, key.repoID, key.kind, key.day, int64(len(visitors[key])), visitors[key]); err != nil {
+			log.Error("Unable to add %d %s downloads of repository[%d]: %v", len(visitors[key]), key.kind, key.repoID, err)
+		}
+	}
+	return nil
+}
+
+// Record counts a download from the repository, the requester is identified by its IP address and user agent
+func Record(req *http.Request, repoID int64, kind repo_model.DownloadKind) {
+	ip := req.RemoteAddr
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+	record(repoID, kind, ip, req.UserAgent())
+}
+
+// RecordServed counts a download from the repository once the request has been served. Only the GET requests served
+// with the content are counted, so neither the HEAD requests nor the responses like 304 Not Modified are.
+// It is meant to be deferred by the handlers serving the downloads.
+func RecordServed(req *http.Request, resp web_types.ResponseStatusProvider, repoID int64, kind repo_model.DownloadKind) {
+	if isServedDownload(req.Method, resp.WrittenStatus()) {
+		Record(req, repoID, kind)
+	}
+}
+
+func isServedDownload(method string, status int) bool {
+	return method == http.MethodGet && (status == http.StatusOK || status == http.StatusPartialContent)
+}
+
+// RecordSSH counts a download over SSH, the requester is identified by its key as there is no address or user agent
+func RecordSSH(repoID int64, kind repo_model.DownloadKind, keyID int64) {
+	record(repoID, kind, "ssh", strconv.FormatInt(keyID, 10))
+}
+
+func record(repoID int64, kind repo_model.DownloadKind, identity ...string) {
+	if statQueue == nil {
+		return
+	}
+
+	day := repo_model.DownloadDay(time.Now())
+	if err := statQueue.Push(&download{
+		RepoID:  repoID,
+		Kind:    kind,
+		Day:     day,
+		Visitor: VisitorHash(day, identity...),
+	}); err != nil {
+		log.Error("Unable to push the %s download of repository[%d] to the queue: %v", kind, repoID, err)
+	}
+}
+
+// VisitorHash returns the hash identifying a requester on the day. It is keyed with the secret key of the instance
+// and salted with the day, so neither the identity can be recovered nor the requester be followed from one day to the next.
+func VisitorHash(day timeutil.TimeStamp, identity ...string) string {
+	h := hmac.New(sha256.New, []byte(setting.SecretKey))
+	_, _ = h.Write([]byte(strconv.FormatInt(int64(day), 10)))
+	for _, s := range identity {
+		_, _ = h.Write([]byte{0})
+		_, _ = h.Write([]byte(s))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// DeleteOldVisitors deletes the hashes of the requesters of the previous days, they are no longer needed once the day is over
+func DeleteOldVisitors(ctx context.Context) error {
+	return repo_model.DeleteDownloadVisitorsBefore(ctx, repo_model.DownloadDay(time.Now()))
+}
+
+// DownloadCount is a number of downloads and of distinct requesters
+type DownloadCount struct {
+	Count       int64
+	UniqueCount int64
+}
+
+// DailyDownloads are the downloads from a repository on a day by kind
+type DailyDownloads struct {
+	Day   time.Time
+	Kinds map[repo_model.DownloadKind]DownloadCount
+	Total int64
+}
+
+// GetDailyDownloads returns the downloads from the repository for every day from since to until, the days without downloads included,
+// and the totals by kind over the period
+func GetDailyDownloads(ctx context.Context, repoID int64, since, until time.Time) ([]*DailyDownloads, map[repo_model.DownloadKind]DownloadCount, error) {
+	sinceDay, untilDay := repo_model.DownloadDay(since), repo_model.DownloadDay(until)
+	stats, err := repo_model.FindDownloadStats(ctx, repo_model.FindDownloadStatsOptions{
+		RepoID: repoID,
+		Since:  sinceDay,
+		Until:  untilDay,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var days []*DailyDownloads
+	byDay := make(map[timeutil.TimeStamp]*DailyDownloads)
+	for day := sinceDay; day <= untilDay; day = day.AddDuration(24 * time.Hour) {
+		d := &DailyDownloads{Day: day.AsTime().UTC(), Kinds: make(map[repo_model.DownloadKind]DownloadCount, len(repo_model.DownloadKinds))}
+		days = append(days, d)
+		byDay[day] = d
+	}
+
+	totals := make(map[repo_model.DownloadKind]DownloadCount, len(repo_model.DownloadKinds))
+	for _, stat := range stats {
+		d, ok := byDay[stat.Day]
+		if !ok {
+			continue
+		}
+		d.Kinds[stat.Kind] = DownloadCount{Count: stat.Count, UniqueCount: stat.UniqueCount}
+		d.Total += stat.Count
+
+		total := totals[stat.Kind]
+		total.Count += stat.Count
+		total.UniqueCount += stat.UniqueCount
+		totals[stat.Kind] = total
+	}
+	return days, totals, nil
+}
@@ -0,0 +1,77 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package downloadstat
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+
+	_ "code.gitea.io/gitea/models"
+	_ "code.gitea.io/gitea/models/actions"
+	_ "code.gitea.io/gitea/models/activities"
+	_ "code.gitea.io/gitea/models/perm/access"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	unittest.MainTest(m)
+}
+
+func TestVisitorHash(t *testing.T) {
+	day := repo_model.DownloadDay(time.Now())
+	hash := VisitorHash(day, "127.0.0.1", "git/2.43.0")
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hash, VisitorHash(day, "127.0.0.1", "git/2.43.0"))
+	assert.NotEqual(t, hash, VisitorHash(day, "127.0.0.1", "git/2.44.0"))
+	assert.NotEqual(t, hash, VisitorHash(day.AddDuration(24*time.Hour), "127.0.0.1", "git/2.43.0"))
+	assert.NotEqual(t, VisitorHash(day, "ab", "c"), VisitorHash(day, "a", "bc"))
+}
+
+func TestIsServedDownload(t *testing.T) {
+	assert.True(t, isServedDownload(http.MethodGet, http.StatusOK))
+	assert.True(t, isServedDownload(http.MethodGet, http.StatusPartialContent))
+	assert.False(t, isServedDownload(http.MethodHead, http.StatusOK))
+	assert.False(t, isServedDownload(http.MethodGet, http.StatusNotModified))
+	assert.False(t, isServedDownload(http.MethodGet, http.StatusNotFound))
+	// nothing has been written
+	assert.False(t, isServedDownload(http.MethodGet, 0))
+}
+
+func TestDailyDownloads(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	now := time.Now()
+	today := repo_model.DownloadDay(now)
+	yesterday := today.AddDuration(-24 * time.Hour)
+	handler(
+		&download{RepoID: 1, Kind: repo_model.DownloadKindClone, Day: yesterday, Visitor: "a"},
+		&download{RepoID: 1, Kind: repo_model.DownloadKindClone, Day: today, Visitor: "a"},
+		&download{RepoID: 1, Kind: repo_model.DownloadKindClone, Day: today, Visitor: "a"},
+		&download{RepoID: 1, Kind: repo_model.DownloadKindLFS, Day: today, Visitor: "b"},
+		&download{RepoID: 2, Kind: repo_model.DownloadKindClone, Day: today, Visitor: "a"},
+	)
+
+	days, totals, err := GetDailyDownloads(db.DefaultContext, 1, now.Add(-72*time.Hour), now)
+	assert.NoError(t, err)
+	if assert.Len(t, days, 4) {
+		assert.Equal(t, today.AsTime().UTC(), days[3].Day)
+		assert.EqualValues(t, 3, days[3].Total)
+		assert.Equal(t, DownloadCount{Count: 2, UniqueCount: 1}, days[3].Kinds[repo_model.DownloadKindClone])
+		assert.Equal(t, DownloadCount{Count: 1, UniqueCount: 1}, days[3].Kinds[repo_model.DownloadKindLFS])
+		assert.EqualValues(t, 1, days[2].Total)
+		assert.Empty(t, days[0].Kinds)
+	}
+	assert.Equal(t, DownloadCount{Count: 3, UniqueCount: 2}, totals[repo_model.DownloadKindClone])
+	assert.Equal(t, DownloadCount{Count: 1, UniqueCount: 1}, totals[repo_model.DownloadKindLFS])
+
+	assert.NoError(t, DeleteOldVisitors(db.DefaultContext))
+	unittest.AssertCount(t, &repo_model.DownloadVisitor{Day: yesterday}, 0)
+	unittest.AssertCount(t, &repo_model.DownloadVisitor{Day: today}, 3)
+}
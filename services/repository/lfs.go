@@ -16,6 +16,7 @@ import (
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/timeutil"
+	lfs_service "code.gitea.io/gitea/services/lfs"
 )
 
 // GarbageCollectLFSMetaObjectsOptions provides options for GarbageCollectLFSMetaObjects function
@@ -103,6 +104,9 @@ func GarbageCollectLFSMetaObjectsForRepo(ctx context.Context, repo *repo_model.R
 			if err := store.Delete(metaObject.RelativePath()); err != nil {
 				log.Error("Unable to remove lfs metaobject %s from store: %v", metaObject.Oid, err)
 			}
+			if err := lfs_service.DeleteArchivedObject(ctx, metaObject.Pointer); err != nil {
+				log.Error("Unable to remove lfs metaobject %s from the archive store: %v", metaObject.Oid, err)
+			}
 			deleted++
 			return nil
 		})
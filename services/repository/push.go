@@ -24,6 +24,7 @@ import (
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/timeutil"
 	issue_service "code.gitea.io/gitea/services/issue"
+	"code.gitea.io/gitea/services/mqevent"
 	notify_service "code.gitea.io/gitea/services/notify"
 	pull_service "code.gitea.io/gitea/services/pull"
 )
@@ -98,6 +99,8 @@ func pushUpdates(optsList []*repo_module.PushUpdateOptions) error {
 
 	addTags := make([]string, 0, len(optsList))
 	delTags := make([]string, 0, len(optsList))
+	// the events of the tags are written with the releases recording them
+	tagEvents := make([]func(ctx context.Context) error, 0, len(optsList))
 	var pusher *user_model.User
 
 	for _, opts := range optsList {
@@ -119,17 +122,24 @@ func pushUpdates(optsList []*repo_module.PushUpdateOptions) error {
 			}
 			tagName := opts.RefFullName.TagName()
 			if opts.IsDelRef() {
-				notify_service.PushCommits(
-					ctx, pusher, repo,
-					&repo_module.PushUpdateOptions{
-						RefFullName: git.RefNameFromTag(tagName),
-						OldCommitID: opts.OldCommitID,
-						NewCommitID: git.EmptySHA,
-						RemoteAddr:  opts.RemoteAddr,
-					}, repo_module.NewPushCommits())
+				pushOpts := &repo_module.PushUpdateOptions{
+					RefFullName: git.RefNameFromTag(tagName),
+					OldCommitID: opts.OldCommitID,
+					NewCommitID: git.EmptySHA,
+					RemoteAddr:  opts.RemoteAddr,
+				}
+				notify_service.PushCommits(ctx, pusher, repo, pushOpts, repo_module.NewPushCommits())
 
 				delTags = append(delTags, tagName)
 				notify_service.DeleteRef(ctx, pusher, repo, opts.RefFullName)
+
+				pusher, refFullName := pusher, opts.RefFullName
+				tagEvents = append(tagEvents, func(ctx context.Context) error {
+					if err := mqevent.PushCommits(ctx, pusher, repo, pushOpts, repo_module.NewPushCommits()); err != nil {
+						return err
+					}
+					return mqevent.DeleteRef(ctx, pusher, repo, refFullName)
+				})
 			} else { // is new tag
 				newCommit, err := gitRepo.GetCommit(opts.NewCommitID)
 				if err != nil {
@@ -140,17 +150,24 @@ func pushUpdates(optsList []*repo_module.PushUpdateOptions) error {
 				commits.HeadCommit = repo_module.CommitToPushCommit(newCommit)
 				commits.CompareURL = repo.ComposeCompareURL(git.EmptySHA, opts.NewCommitID)
 
-				notify_service.PushCommits(
-					ctx, pusher, repo,
-					&repo_module.PushUpdateOptions{
-						RefFullName: opts.RefFullName,
-						OldCommitID: git.EmptySHA,
-						NewCommitID: opts.NewCommitID,
-						RemoteAddr:  opts.RemoteAddr,
-					}, commits)
+				pushOpts := &repo_module.PushUpdateOptions{
+					RefFullName: opts.RefFullName,
+					OldCommitID: git.EmptySHA,
+					NewCommitID: opts.NewCommitID,
+					RemoteAddr:  opts.RemoteAddr,
+				}
+				notify_service.PushCommits(ctx, pusher, repo, pushOpts, commits)
 
 				addTags = append(addTags, tagName)
 				notify_service.CreateRef(ctx, pusher, repo, opts.RefFullName, opts.NewCommitID)
+
+				pusher := pusher
+				tagEvents = append(tagEvents, func(ctx context.Context) error {
+					if err := mqevent.PushCommits(ctx, pusher, repo, pushOpts, commits); err != nil {
+						return err
+					}
+					return mqevent.CreateRef(ctx, pusher, repo, pushOpts.RefFullName, pushOpts.NewCommitID)
+				})
 			}
 		} else if opts.RefFullName.IsBranch() {
 			if pusher == nil || pusher.ID != opts.PusherID {
@@ -259,8 +276,18 @@ func pushUpdates(optsList []*repo_module.PushUpdateOptions) error {
 					commits.Commits = commits.Commits[:setting.UI.FeedMaxCommitNum]
 				}
 
-				if err = git_model.UpdateBranch(ctx, repo.ID, opts.PusherID, branch, newCommit); err != nil {
-					return fmt.Errorf("git_model.UpdateBranch %s:%s failed: %v", repo.FullName(), branch, err)
+				if err := db.WithTx(ctx, func(ctx context.Context) error {
+					if err := git_model.UpdateBranch(ctx, repo.ID, opts.PusherID, branch, newCommit); err != nil {
+						return fmt.Errorf("git_model.UpdateBranch %s:%s failed: %v", repo.FullName(), branch, err)
+					}
+					if opts.IsNewRef() {
+						if err := mqevent.CreateRef(ctx, pusher, repo, opts.RefFullName, opts.NewCommitID); err != nil {
+							return err
+						}
+					}
+					return mqevent.PushCommits(ctx, pusher, repo, opts, commits)
+				}); err != nil {
+					return err
 				}
 
 				notify_service.PushCommits(ctx, pusher, repo, opts, commits)
@@ -276,8 +303,13 @@ func pushUpdates(optsList []*repo_module.PushUpdateOptions) error {
 					log.Error("close related pull request failed: %v", err)
 				}
 
-				if err := git_model.AddDeletedBranch(ctx, repo.ID, branch, pusher.ID); err != nil {
-					return fmt.Errorf("AddDeletedBranch %s:%s failed: %v", repo.FullName(), branch, err)
+				if err := db.WithTx(ctx, func(ctx context.Context) error {
+					if err := git_model.AddDeletedBranch(ctx, repo.ID, branch, pusher.ID); err != nil {
+						return fmt.Errorf("AddDeletedBranch %s:%s failed: %v", repo.FullName(), branch, err)
+					}
+					return mqevent.DeleteRef(ctx, pusher, repo, opts.RefFullName)
+				}); err != nil {
+					return err
 				}
 			}
 
@@ -289,8 +321,18 @@ func pushUpdates(optsList []*repo_module.PushUpdateOptions) error {
 			log.Trace("Non-tag and non-branch commits pushed.")
 		}
 	}
-	if err := PushUpdateAddDeleteTags(ctx, repo, gitRepo, addTags, delTags); err != nil {
-		return fmt.Errorf("PushUpdateAddDeleteTags: %w", err)
+	if err := db.WithTx(ctx, func(ctx context.Context) error {
+		if err := PushUpdateAddDeleteTags(ctx, repo, gitRepo, addTags, delTags); err != nil {
+			return fmt.Errorf("PushUpdateAddDeleteTags: %w", err)
+		}
+		for _, writeEvents := range tagEvents {
+			if err := writeEvents(ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
 	}
 
 	// Change repository last updated time.
@@ -0,0 +1,32 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package repository
+
+import (
+	"context"
+
+	git_model "code.gitea.io/gitea/models/git"
+)
+
+// GetInheritedPushPolicy returns the policy overridden by the push policy: the policy of the owner
+// for a repository (repoID is set) or the global settings for an organization
+func GetInheritedPushPolicy(ctx context.Context, ownerID, repoID int64) (*git_model.EffectivePushPolicy, error) {
+	if repoID > 0 {
+		return git_model.GetEffectivePushPolicy(ctx, ownerID, 0)
+	}
+	return git_model.GetEffectivePushPolicy(ctx, 0, 0)
+}
+
+// UpdatePushPolicy saves the push policy of an organization (policy.RepoID is 0) or a repository (policy.OwnerID is 0),
+// repoOwnerID is the owner of the repository. The policy can only restrict the inherited policy.
+func UpdatePushPolicy(ctx context.Context, policy *git_model.PushPolicy, repoOwnerID int64) error {
+	inherited, err := GetInheritedPushPolicy(ctx, repoOwnerID, policy.RepoID)
+	if err != nil {
+		return err
+	}
+	if err := inherited.CheckOverride(policy); err != nil {
+		return err
+	}
+	return git_model.SetPushPolicy(ctx, policy)
+}
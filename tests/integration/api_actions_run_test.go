@@ -0,0 +1,320 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	auth_model "code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+	webhook_module "code.gitea.io/gitea/modules/webhook"
+	"code.gitea.io/gitea/tests"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type actionsRunsTestData struct {
+	doneRun, runningRun, failedRun *actions_model.ActionRun
+	doneJob, runningJob            *actions_model.ActionRunJob
+	artifact, expiredArtifact      *actions_model.ActionArtifact
+}
+
+// prepareActionsRunsTestData creates the runs, jobs, tasks and artifacts of user2/repo1 used by the tests of the API
+func prepareActionsRunsTestData(t *testing.T) *actionsRunsTestData {
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1})
+	sha := "65f1bf27bc3bf70f64657658635e66094edbcb4d"
+	now := timeutil.TimeStampNow()
+
+	newRun := func(index, triggerUserID int64, ref string, event webhook_module.HookEventType, status actions_model.Status) *actions_model.ActionRun {
+		run := &actions_model.ActionRun{
+			Title:         fmt.Sprintf("run %d", index),
+			RepoID:        repo.ID,
+			OwnerID:       repo.OwnerID,
+			WorkflowID:    "test.yaml",
+			Index:         index,
+			TriggerUserID: triggerUserID,
+			Ref:           ref,
+			CommitSHA:     sha,
+			Event:         event,
+			TriggerEvent:  string(event),
+			Status:        status,
+			Started:       now,
+		}
+		if status.IsDone() {
+			run.Stopped = now
+		}
+		require.NoError(t, db.Insert(db.DefaultContext, run))
+		return run
+	}
+	newJob := func(run *actions_model.ActionRun, taskID int64) *actions_model.ActionRunJob {
+		job := &actions_model.ActionRunJob{
+			RunID:     run.ID,
+			RepoID:    run.RepoID,
+			OwnerID:   run.OwnerID,
+			CommitSHA: run.CommitSHA,
+			Name:      "test",
+			Attempt:   1,
+			JobID:     "test",
+			TaskID:    taskID,
+			Status:    run.Status,
+			Started:   run.Started,
+			Stopped:   run.Stopped,
+		}
+		require.NoError(t, db.Insert(db.DefaultContext, job))
+		return job
+	}
+	newArtifact := func(run *actions_model.ActionRun, name string, status actions_model.ArtifactStatus) *actions_model.ActionArtifact {
+		art := &actions_model.ActionArtifact{
+			RunID:        run.ID,
+			RepoID:       run.RepoID,
+			OwnerID:      run.OwnerID,
+			CommitSHA:    run.CommitSHA,
+			StoragePath:  fmt.Sprintf("%d/%s/abc.txt", run.ID, name),
+			FileSize:     1024,
+			ArtifactPath: "abc.txt",
+			ArtifactName: name,
+			Status:       int64(status),
+		}
+		require.NoError(t, db.Insert(db.DefaultContext, art))
+		t.Cleanup(func() {
+			_, err := db.DeleteByID(db.DefaultContext, art.ID, &actions_model.ActionArtifact{})
+			assert.NoError(t, err)
+		})
+		return art
+	}
+
+	data := &actionsRunsTestData{
+		doneRun:    newRun(1, 2, "refs/heads/master", webhook_module.HookEventPush, actions_model.StatusSuccess),
+		runningRun: newRun(2, 1, "refs/heads/develop", webhook_module.HookEventPullRequest, actions_model.StatusRunning),
+		failedRun:  newRun(3, 2, "refs/heads/master", webhook_module.HookEventPush, actions_model.StatusFailure),
+	}
+
+	// the logs of the task of the done run have been cleaned up
+	task := &actions_model.ActionTask{
+		Attempt:    1,
+		Status:     actions_model.StatusSuccess,
+		Started:    now,
+		Stopped:    now,
+		RepoID:     repo.ID,
+		OwnerID:    repo.OwnerID,
+		CommitSHA:  sha,
+		TokenHash:  "actions-runs-test",
+		LogExpired: true,
+	}
+	require.NoError(t, db.Insert(db.DefaultContext, task))
+	data.doneJob = newJob(data.doneRun, task.ID)
+	task.JobID = data.doneJob.ID
+	_, err := db.GetEngine(db.DefaultContext).ID(task.ID).Cols("job_id").Update(task)
+	require.NoError(t, err)
+
+	data.runningJob = newJob(data.runningRun, 0)
+	newJob(data.failedRun, 0)
+
+	data.artifact = newArtifact(data.doneRun, "report", actions_model.ArtifactStatusUploadConfirmed)
+	data.expiredArtifact = newArtifact(data.doneRun, "coverage", actions_model.ArtifactStatusExpired)
+	return data
+}
+
+func TestAPIActionsListRuns(t *testing.T) {
+	defer tests.PrepareTestEnv(t)()
+	data := prepareActionsRunsTestData(t)
+
+	token := getUserToken(t, "user2", auth_model.AccessTokenScopeReadRepository)
+
+	cases := []struct {
+		query string
+		runs  []int64
+	}{
+		{query: "", runs: []int64{data.failedRun.ID, data.runningRun.ID, data.doneRun.ID}},
+		{query: "branch=master", runs: []int64{data.failedRun.ID, data.doneRun.ID}},
+		{query: "branch=develop", runs: []int64{data.runningRun.ID}},
+		{query: "event=pull_request", runs: []int64{data.runningRun.ID}},
+		{query: "status=completed", runs: []int64{data.failedRun.ID, data.doneRun.ID}},
+		{query: "status=in_progress", runs: []int64{data.runningRun.ID}},
+		{query: "status=failure", runs: []int64{data.failedRun.ID}},
+		{query: "actor=user1", runs: []int64{data.runningRun.ID}},
+		{query: "actor=user2&status=success", runs: []int64{data.doneRun.ID}},
+		{query: "actor=not-exist", runs: []int64{}},
+		{query: "branch=master&event=pull_request", runs: []int64{}},
+	}
+	for _, c := range cases {
+		t.Run(c.query, func(t *testing.T) {
+			req := NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/actions/runs?%s&token=%s", c.query, token)
+			resp := MakeRequest(t, req, http.StatusOK)
+			var runs api.ActionWorkflowRunsResponse
+			DecodeJSON(t, resp, &runs)
+
+			ids := make([]int64, 0, len(runs.WorkflowRuns))
+			for _, run := range runs.WorkflowRuns {
+				ids = append(ids, run.ID)
+			}
+			assert.Equal(t, c.runs, ids)
+			assert.EqualValues(t, len(c.runs), runs.TotalCount)
+			assert.Equal(t, fmt.Sprint(len(c.runs)), resp.Header().Get("X-Total-Count"))
+		})
+	}
+
+	req := NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/actions/runs?status=unknown&token=%s", token)
+	MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	t.Run("Pagination", func(t *testing.T) {
+		req := NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/actions/runs?limit=2&token=%s", token)
+		resp := MakeRequest(t, req, http.StatusOK)
+		var runs api.ActionWorkflowRunsResponse
+		DecodeJSON(t, resp, &runs)
+		assert.EqualValues(t, 3, runs.TotalCount)
+		if assert.Len(t, runs.WorkflowRuns, 2) {
+			assert.Equal(t, data.failedRun.ID, runs.WorkflowRuns[0].ID)
+		}
+		assert.Equal(t, "3", resp.Header().Get("X-Total-Count"))
+		assert.Regexp(t, `[?&]page=2[^>]*>; rel="next"`, resp.Header().Get("Link"))
+		assert.Regexp(t, `[?&]page=2[^>]*>; rel="last"`, resp.Header().Get("Link"))
+
+		// per_page is accepted for the compatibility with GitHub
+		req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/actions/runs?per_page=2&page=2&token=%s", token)
+		resp = MakeRequest(t, req, http.StatusOK)
+		DecodeJSON(t, resp, &runs)
+		assert.EqualValues(t, 3, runs.TotalCount)
+		if assert.Len(t, runs.WorkflowRuns, 1) {
+			assert.Equal(t, data.doneRun.ID, runs.WorkflowRuns[0].ID)
+		}
+		assert.Regexp(t, `[?&]page=1[^>]*>; rel="prev"`, resp.Header().Get("Link"))
+		assert.NotContains(t, resp.Header().Get("Link"), `rel="next"`)
+	})
+}
+
+func TestAPIActionsRunsPermissions(t *testing.T) {
+	defer tests.PrepareTestEnv(t)()
+	data := prepareActionsRunsTestData(t)
+
+	// user4 can only read the public repository of user2
+	readerToken := getUserToken(t, "user4", auth_model.AccessTokenScopeWriteRepository)
+	writerToken := getUserToken(t, "user2", auth_model.AccessTokenScopeWriteRepository)
+
+	runURL := func(run *actions_model.ActionRun, action string) string {
+		return fmt.Sprintf("/api/v1/repos/user2/repo1/actions/runs/%d/%s", run.ID, action)
+	}
+
+	t.Run("Reader", func(t *testing.T) {
+		req := NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/actions/runs/%d?token=%s", data.doneRun.ID, readerToken)
+		MakeRequest(t, req, http.StatusOK)
+
+		req = NewRequest(t, "POST", runURL(data.doneRun, "rerun")+"?token="+readerToken)
+		MakeRequest(t, req, http.StatusForbidden)
+		req = NewRequest(t, "POST", runURL(data.doneRun, "rerun-failed-jobs")+"?token="+readerToken)
+		MakeRequest(t, req, http.StatusForbidden)
+		req = NewRequest(t, "POST", runURL(data.runningRun, "cancel")+"?token="+readerToken)
+		MakeRequest(t, req, http.StatusForbidden)
+		req = NewRequestf(t, "POST", "/api/v1/repos/user2/repo1/actions/jobs/%d/rerun?token=%s", data.doneJob.ID, readerToken)
+		MakeRequest(t, req, http.StatusForbidden)
+
+		job := unittest.AssertExistsAndLoadBean(t, &actions_model.ActionRunJob{ID: data.runningJob.ID})
+		assert.Equal(t, actions_model.StatusRunning, job.Status)
+	})
+
+	t.Run("Writer", func(t *testing.T) {
+		// the run must be done to be rerun, and not done to be cancelled
+		req := NewRequest(t, "POST", runURL(data.runningRun, "rerun")+"?token="+writerToken)
+		MakeRequest(t, req, http.StatusConflict)
+		req = NewRequest(t, "POST", runURL(data.doneRun, "cancel")+"?token="+writerToken)
+		MakeRequest(t, req, http.StatusConflict)
+
+		req = NewRequest(t, "POST", runURL(data.runningRun, "cancel")+"?token="+writerToken)
+		MakeRequest(t, req, http.StatusAccepted)
+		job := unittest.AssertExistsAndLoadBean(t, &actions_model.ActionRunJob{ID: data.runningJob.ID})
+		assert.Equal(t, actions_model.StatusCancelled, job.Status)
+
+		req = NewRequest(t, "POST", runURL(data.doneRun, "rerun")+"?token="+writerToken)
+		MakeRequest(t, req, http.StatusCreated)
+		job = unittest.AssertExistsAndLoadBean(t, &actions_model.ActionRunJob{ID: data.doneJob.ID})
+		assert.False(t, job.Status.IsDone())
+		assert.EqualValues(t, 0, job.TaskID)
+	})
+}
+
+func TestAPIActionsRunsExpired(t *testing.T) {
+	defer tests.PrepareTestEnv(t)()
+	data := prepareActionsRunsTestData(t)
+
+	token := getUserToken(t, "user2", auth_model.AccessTokenScopeReadRepository)
+
+	req := NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/actions/jobs/%d/logs?token=%s", data.doneJob.ID, token)
+	MakeRequest(t, req, http.StatusGone)
+	// the job which isn't started has no logs
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/actions/jobs/%d/logs?token=%s", data.runningJob.ID, token)
+	MakeRequest(t, req, http.StatusNotFound)
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/actions/runs/%d/artifacts?token=%s", data.doneRun.ID, token)
+	resp := MakeRequest(t, req, http.StatusOK)
+	var arts api.ActionArtifactsResponse
+	DecodeJSON(t, resp, &arts)
+	if assert.Len(t, arts.Artifacts, 2) {
+		assert.Equal(t, "coverage", arts.Artifacts[0].Name)
+		assert.True(t, arts.Artifacts[0].Expired)
+		assert.Equal(t, "report", arts.Artifacts[1].Name)
+		assert.False(t, arts.Artifacts[1].Expired)
+	}
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/actions/artifacts/%d?token=%s", data.artifact.ID, token)
+	MakeRequest(t, req, http.StatusOK)
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/actions/artifacts/%d?token=%s", data.expiredArtifact.ID, token)
+	MakeRequest(t, req, http.StatusOK)
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/actions/artifacts/%d/zip?token=%s", data.expiredArtifact.ID, token)
+	MakeRequest(t, req, http.StatusGone)
+}
+
+func TestAPIActionsRunsOfOtherRepo(t *testing.T) {
+	defer tests.PrepareTestEnv(t)()
+	prepareActionsRunsTestData(t)
+
+	// the run 791 and its job 192 belong to user5/repo4
+	token := getUserToken(t, "user2", auth_model.AccessTokenScopeWriteRepository)
+	for _, url := range []string{
+		"/api/v1/repos/user2/repo1/actions/runs/791",
+		"/api/v1/repos/user2/repo1/actions/runs/791/jobs",
+		"/api/v1/repos/user2/repo1/actions/runs/791/artifacts",
+		"/api/v1/repos/user2/repo1/actions/jobs/192",
+		"/api/v1/repos/user2/repo1/actions/jobs/192/logs",
+	} {
+		req := NewRequest(t, "GET", url+"?token="+token)
+		MakeRequest(t, req, http.StatusNotFound)
+	}
+	for _, url := range []string{
+		"/api/v1/repos/user2/repo1/actions/runs/791/rerun",
+		"/api/v1/repos/user2/repo1/actions/runs/791/cancel",
+		"/api/v1/repos/user2/repo1/actions/jobs/192/rerun",
+	} {
+		req := NewRequest(t, "POST", url+"?token="+token)
+		MakeRequest(t, req, http.StatusNotFound)
+	}
+
+	// the artifact of user5/repo4 isn't found through user2/repo1
+	art := &actions_model.ActionArtifact{
+		RunID:        791,
+		RepoID:       4,
+		OwnerID:      1,
+		StoragePath:  "791/report/abc.txt",
+		FileSize:     1024,
+		ArtifactPath: "abc.txt",
+		ArtifactName: "report",
+		Status:       int64(actions_model.ArtifactStatusUploadConfirmed),
+	}
+	require.NoError(t, db.Insert(db.DefaultContext, art))
+	defer func() {
+		_, err := db.DeleteByID(db.DefaultContext, art.ID, &actions_model.ActionArtifact{})
+		assert.NoError(t, err)
+	}()
+	req := NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/actions/artifacts/%d?token=%s", art.ID, token)
+	MakeRequest(t, req, http.StatusNotFound)
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/actions/artifacts/%d/zip?token=%s", art.ID, token)
+	MakeRequest(t, req, http.StatusNotFound)
+}
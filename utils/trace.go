@@ -2,6 +2,8 @@ package utils
 
 import (
 	"context"
+	"os"
+	"strings"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
@@ -54,3 +56,47 @@ func SpanContextWithAttr(ctx context.Context, kv map[string]string) context.Cont
 
 	return context.WithValue(ctx, attrkey, attrkv)
 }
+
+// envCarrier carries the trace context in the environment variables of a process, the keys are upper cased
+// as in TRACEPARENT and TRACESTATE
+type envCarrier map[string]string
+
+func (c envCarrier) Get(key string) string {
+	return c[strings.ToUpper(key)]
+}
+
+func (c envCarrier) Set(key, value string) {
+	c[strings.ToUpper(key)] = value
+}
+
+func (c envCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// TraceEnv returns the environment variables passing the trace context of ctx to a sub process
+func TraceEnv(ctx context.Context) []string {
+	carrier := envCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+
+	env := make([]string, 0, len(carrier))
+	for k, v := range carrier {
+		env = append(env, k+"="+v)
+	}
+	return env
+}
+
+// ContextFromEnv returns ctx with the trace context passed in the environment by the parent process,
+// so the spans of a sub command are part of the trace of the process which started it
+func ContextFromEnv(ctx context.Context) context.Context {
+	carrier := envCarrier{}
+	for _, key := range otel.GetTextMapPropagator().Fields() {
+		if v, ok := os.LookupEnv(strings.ToUpper(key)); ok {
+			carrier[strings.ToUpper(key)] = v
+		}
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, carrier)
+}
@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTraceEnv(t *testing.T) {
+	oldPropagator := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTextMapPropagator(oldPropagator)
+
+	ctx, span := sdktrace.NewTracerProvider().Tracer("test").Start(context.Background(), "parent")
+	defer span.End()
+
+	env := TraceEnv(ctx)
+	if assert.Len(t, env, 1) {
+		key, value, _ := strings.Cut(env[0], "=")
+		assert.Equal(t, "TRACEPARENT", key)
+		t.Setenv(key, value)
+	}
+
+	sc := trace.SpanContextFromContext(ContextFromEnv(context.Background()))
+	assert.True(t, sc.IsRemote())
+	assert.Equal(t, span.SpanContext().TraceID(), sc.TraceID())
+	assert.Equal(t, span.SpanContext().SpanID(), sc.SpanID())
+}